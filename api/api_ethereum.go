@@ -430,6 +430,18 @@ func decodeHash(s string) (h common.Hash, inputLength int, err error) {
 	return common.BytesToHash(b), len(b), nil
 }
 
+// proofOutputKey encodes a storage key of a proof result. Output key encoding
+// is a bit special: if the input was a 32-byte hash, it is returned as such.
+// Otherwise, we apply the QUANTITY encoding mandated by the JSON-RPC spec for
+// getProof. This behavior exists to preserve backwards compatibility with older
+// client versions.
+func proofOutputKey(key common.Hash, inputLength int) string {
+	if inputLength != 32 {
+		return hexutil.EncodeBig(key.Big())
+	}
+	return hexutil.Encode(key[:])
+}
+
 func doGetProof(ctx context.Context, b Backend, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*EthAccountResult, error) {
 	var (
 		keys         = make([]common.Hash, len(storageKeys))
@@ -464,16 +476,7 @@ func doGetProof(ctx context.Context, b Backend, address common.Address, storageK
 		}
 		// Create the proofs for the storageKeys.
 		for i, key := range keys {
-			// Output key encoding is a bit special: if the input was a 32-byte hash, it is
-			// returned as such. Otherwise, we apply the QUANTITY encoding mandated by the
-			// JSON-RPC spec for getProof. This behavior exists to preserve backwards
-			// compatibility with older client versions.
-			var outputKey string
-			if keyLengths[i] != 32 {
-				outputKey = hexutil.EncodeBig(key.Big())
-			} else {
-				outputKey = hexutil.Encode(key[:])
-			}
+			outputKey := proofOutputKey(key, keyLengths[i])
 			if storageTrie == nil {
 				storageProof[i] = EthStorageResult{outputKey, &hexutil.Big{}, []string{}}
 				continue
@@ -745,25 +748,26 @@ func (api *EthereumAPI) GetBlockTransactionCountByHash(ctx context.Context, bloc
 // RPCTransaction in go-ethereum has been renamed to EthRPCTransaction.
 // RPCTransaction is defined in go-ethereum's internal package, so RPCTransaction is redefined here as EthRPCTransaction.
 type EthRPCTransaction struct {
-	BlockHash        *common.Hash      `json:"blockHash"`
-	BlockNumber      *hexutil.Big      `json:"blockNumber"`
-	From             common.Address    `json:"from"`
-	Gas              hexutil.Uint64    `json:"gas"`
-	GasPrice         *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap        *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap        *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	Hash             common.Hash       `json:"hash"`
-	Input            hexutil.Bytes     `json:"input"`
-	Nonce            hexutil.Uint64    `json:"nonce"`
-	To               *common.Address   `json:"to"`
-	TransactionIndex *hexutil.Uint64   `json:"transactionIndex"`
-	Value            *hexutil.Big      `json:"value"`
-	Type             hexutil.Uint64    `json:"type"`
-	Accesses         *types.AccessList `json:"accessList,omitempty"`
-	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
-	V                *hexutil.Big      `json:"v"`
-	R                *hexutil.Big      `json:"r"`
-	S                *hexutil.Big      `json:"s"`
+	BlockHash         *common.Hash                 `json:"blockHash"`
+	BlockNumber       *hexutil.Big                 `json:"blockNumber"`
+	From              common.Address               `json:"from"`
+	Gas               hexutil.Uint64               `json:"gas"`
+	GasPrice          *hexutil.Big                 `json:"gasPrice"`
+	GasFeeCap         *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	GasTipCap         *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	Hash              common.Hash                  `json:"hash"`
+	Input             hexutil.Bytes                `json:"input"`
+	Nonce             hexutil.Uint64               `json:"nonce"`
+	To                *common.Address              `json:"to"`
+	TransactionIndex  *hexutil.Uint64              `json:"transactionIndex"`
+	Value             *hexutil.Big                 `json:"value"`
+	Type              hexutil.Uint64               `json:"type"`
+	Accesses          *types.AccessList            `json:"accessList,omitempty"`
+	ChainID           *hexutil.Big                 `json:"chainId,omitempty"`
+	AuthorizationList []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	V                 *hexutil.Big                 `json:"v"`
+	R                 *hexutil.Big                 `json:"r"`
+	S                 *hexutil.Big                 `json:"s"`
 }
 
 // ethTxJSON is the JSON representation of Ethereum transaction.
@@ -793,6 +797,9 @@ type ethTxJSON struct {
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 
+	// Set code transaction fields:
+	AuthorizationList []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
 }
@@ -874,12 +881,13 @@ func newEthRPCTransaction(block *types.Block, tx *types.Transaction, blockHash c
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
-	case types.TxTypeEthereumDynamicFee:
+	case types.TxTypeEthereumDynamicFee, types.TxTypeEthereumSetCode:
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
 		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
 		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		result.AuthorizationList = tx.AuthorizationList()
 		if block != nil {
 			result.GasPrice = (*hexutil.Big)(tx.EffectiveGasPrice(block.Header()))
 		} else {
@@ -935,12 +943,13 @@ func formatTxToEthTxJSON(tx *types.Transaction) *ethTxJSON {
 		enc.AccessList = &al
 		enc.ChainID = (*hexutil.Big)(tx.ChainId())
 		enc.GasPrice = (*hexutil.Big)(tx.GasPrice())
-	case types.TxTypeEthereumDynamicFee:
+	case types.TxTypeEthereumDynamicFee, types.TxTypeEthereumSetCode:
 		al := tx.AccessList()
 		enc.AccessList = &al
 		enc.ChainID = (*hexutil.Big)(tx.ChainId())
 		enc.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
 		enc.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		enc.AuthorizationList = tx.AuthorizationList()
 	default:
 		enc.GasPrice = (*hexutil.Big)(tx.GasPrice())
 	}
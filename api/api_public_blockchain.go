@@ -36,11 +36,13 @@ import (
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/common/math"
+	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/node/cn/filters"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/statedb"
 )
 
 var logger = log.NewModuleLogger(log.API)
@@ -393,13 +395,116 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOr
 	return result.Return(), result.Unwrap()
 }
 
-func (s *PublicBlockChainAPI) EstimateComputationCost(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+// ComputationCostOptions are the options of klay_estimateComputationCost and
+// klay_estimateComputationCostDetail.
+type ComputationCostOptions struct {
+	Breakdown bool `json:"breakdown"` // break down the computation cost per call frame and per opcode class
+	NextFork  bool `json:"nextFork"`  // estimate the computation cost under the next scheduled fork
+}
+
+// ComputationCostResult is the result of klay_estimateComputationCostDetail.
+// Headroom is the computation cost left below the limit, zero if it is exceeded.
+type ComputationCostResult struct {
+	ComputationCost hexutil.Uint64            `json:"computationCost"`
+	Limit           hexutil.Uint64            `json:"limit"`
+	Headroom        hexutil.Uint64            `json:"headroom"`
+	Frames          []vm.ComputationCostFrame `json:"frames,omitempty"`
+	OpcodeClasses   []vm.OpcodeClassCost      `json:"opcodeClasses,omitempty"`
+	NextFork        *NextForkComputationCost  `json:"nextFork,omitempty"`
+}
+
+// NextForkComputationCost is the computation cost estimated with the computation
+// cost table and the limit of the next scheduled fork.
+type NextForkComputationCost struct {
+	Name            string         `json:"name"`
+	Block           *hexutil.Big   `json:"block"`
+	ComputationCost hexutil.Uint64 `json:"computationCost"`
+	Limit           hexutil.Uint64 `json:"limit"`
+	Headroom        hexutil.Uint64 `json:"headroom"`
+}
+
+// EstimateComputationCost returns the opcode computation cost of the given call. If the
+// NextFork option is set, it returns the computation cost under the next scheduled fork instead,
+// or under the current fork if no fork is scheduled.
+func (s *PublicBlockChainAPI) EstimateComputationCost(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, options *ComputationCostOptions) (hexutil.Uint64, error) {
+	if options == nil || !options.NextFork {
+		_, computationCost, err := s.doComputationCostCall(ctx, args, blockNrOrHash, nil)
+		return (hexutil.Uint64)(computationCost), err
+	}
+	result, err := s.EstimateComputationCostDetail(ctx, args, blockNrOrHash, &ComputationCostOptions{NextFork: true})
+	if err != nil {
+		return 0, err
+	}
+	if result.NextFork != nil {
+		return result.NextFork.ComputationCost, nil
+	}
+	return result.ComputationCost, nil
+}
+
+// EstimateComputationCostDetail returns a ComputationCostResult of the given call with the
+// limit in force at the block, which includes the breakdown and the estimate under the next
+// scheduled fork on request.
+func (s *PublicBlockChainAPI) EstimateComputationCostDetail(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, options *ComputationCostOptions) (*ComputationCostResult, error) {
+	if options == nil {
+		options = &ComputationCostOptions{}
+	}
+	var tracer *vm.ComputationCostTracer
+	if options.Breakdown || options.NextFork {
+		tracer = vm.NewComputationCostTracer()
+	}
+	_, computationCost, err := s.doComputationCostCall(ctx, args, blockNrOrHash, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := s.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	config := s.b.ChainConfig()
+	limit := vm.ComputationCostLimit(config.Rules(header.Number))
+	result := &ComputationCostResult{
+		ComputationCost: hexutil.Uint64(computationCost),
+		Limit:           hexutil.Uint64(limit),
+		Headroom:        computationCostHeadroom(computationCost, limit),
+	}
+	if options.Breakdown {
+		breakdown := tracer.GetResult()
+		result.Frames, result.OpcodeClasses = breakdown.Frames, breakdown.OpcodeClasses
+	}
+	if name, block := config.NextFork(header.Number); options.NextFork && block != nil {
+		rules := config.Rules(block)
+		nextComputationCost, nextLimit := tracer.ComputationCostUnder(rules), vm.ComputationCostLimit(rules)
+		result.NextFork = &NextForkComputationCost{
+			Name:            name,
+			Block:           (*hexutil.Big)(block),
+			ComputationCost: hexutil.Uint64(nextComputationCost),
+			Limit:           hexutil.Uint64(nextLimit),
+			Headroom:        computationCostHeadroom(nextComputationCost, nextLimit),
+		}
+	}
+	return result, nil
+}
+
+// doComputationCostCall executes the given call without the computation cost limit,
+// tracing it with the given tracer if it is not nil.
+func (s *PublicBlockChainAPI) doComputationCostCall(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, tracer *vm.ComputationCostTracer) (*blockchain.ExecutionResult, uint64, error) {
 	gasCap := big.NewInt(0)
 	if rpcGasCap := s.b.RPCGasCap(); rpcGasCap != nil {
 		gasCap = rpcGasCap
 	}
-	_, computationCost, err := DoCall(ctx, s.b, args, blockNrOrHash, vm.Config{ComputationCostLimit: params.OpcodeComputationCostLimitInfinite}, s.b.RPCEVMTimeout(), gasCap)
-	return (hexutil.Uint64)(computationCost), err
+	vmCfg := vm.Config{ComputationCostLimit: params.OpcodeComputationCostLimitInfinite}
+	if tracer != nil {
+		vmCfg.Debug, vmCfg.Tracer = true, tracer
+	}
+	return DoCall(ctx, s.b, args, blockNrOrHash, vmCfg, s.b.RPCEVMTimeout(), gasCap)
+}
+
+func computationCostHeadroom(computationCost, limit uint64) hexutil.Uint64 {
+	if computationCost > limit {
+		return 0
+	}
+	return hexutil.Uint64(limit - computationCost)
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the given transaction against the latest block.
@@ -470,6 +575,128 @@ func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Addre
 	return doGetProof(ctx, s.b, address, storageKeys, blockNrOrHash)
 }
 
+// maxMultiProofKeys is the limit of accounts and storage keys proven by a
+// GetMultiProof call.
+const maxMultiProofKeys = 10000
+
+// ProofQuery is an account and its storage keys to be proven by GetMultiProof.
+type ProofQuery struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []string       `json:"storageKeys"`
+}
+
+// MultiProofResult is the result of GetMultiProof. The nodes prove every
+// account and storage value against the state root of the block.
+type MultiProofResult struct {
+	BlockHash   common.Hash         `json:"blockHash"`
+	BlockNumber *hexutil.Big        `json:"blockNumber"`
+	StateRoot   common.Hash         `json:"stateRoot"`
+	Accounts    []MultiProofAccount `json:"accounts"`
+	Nodes       []hexutil.Bytes     `json:"nodes"`
+}
+
+// MultiProofAccount is an account proven by GetMultiProof.
+type MultiProofAccount struct {
+	Address     common.Address   `json:"address"`
+	Balance     *hexutil.Big     `json:"balance"`
+	CodeHash    common.Hash      `json:"codeHash"`
+	Nonce       hexutil.Uint64   `json:"nonce"`
+	StorageHash common.Hash      `json:"storageHash"`
+	Storage     []MultiProofSlot `json:"storage"`
+}
+
+// MultiProofSlot is a storage value proven by GetMultiProof.
+type MultiProofSlot struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+}
+
+// GetMultiProof returns the Merkle-proof of many accounts and their storage keys
+// at once. Unlike GetProof, the proofs are merged in a single set of trie nodes,
+// so a node shared by several proofs is returned once.
+func (s *PublicBlockChainAPI) GetMultiProof(ctx context.Context, queries []ProofQuery, blockNrOrHash rpc.BlockNumberOrHash) (*MultiProofResult, error) {
+	var (
+		count      = len(queries)
+		keys       = make([][]common.Hash, len(queries))
+		keyLengths = make([][]int, len(queries))
+	)
+	// Deserialize all keys. This prevents state access on invalid input.
+	for i, query := range queries {
+		count += len(query.StorageKeys)
+		keys[i] = make([]common.Hash, len(query.StorageKeys))
+		keyLengths[i] = make([]int, len(query.StorageKeys))
+		for j, hexKey := range query.StorageKeys {
+			var err error
+			keys[i][j], keyLengths[i][j], err = decodeHash(hexKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if count > maxMultiProofKeys {
+		return nil, fmt.Errorf("too many accounts and storage keys to prove: %d > %d", count, maxMultiProofKeys)
+	}
+	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	trieDB := state.Database().TrieDB()
+	accountTrie, err := statedb.NewTrie(header.Root, trieDB, nil)
+	if err != nil {
+		return nil, err
+	}
+	proof := statedb.NewProofSet()
+	accounts := make([]MultiProofAccount, len(queries))
+	for i, query := range queries {
+		address := query.Address
+		if err := accountTrie.Prove(crypto.Keccak256(address.Bytes()), 0, proof); err != nil {
+			return nil, err
+		}
+		// Only the storage of a contract has to be proven, the account proof
+		// shows that other accounts have no storage.
+		storageRoot := types.EmptyRootHashOriginal
+		var storageTrie *statedb.Trie
+		if root, err := state.GetContractStorageRoot(address); err == nil {
+			storageRoot = root.Unextend()
+			storageTrie, err = statedb.NewStorageTrie(root, trieDB, &statedb.TrieOpts{Owner: crypto.Keccak256Hash(address.Bytes())})
+			if err != nil {
+				return nil, err
+			}
+		}
+		storage := make([]MultiProofSlot, len(keys[i]))
+		for j, key := range keys[i] {
+			if storageTrie != nil {
+				if err := storageTrie.Prove(crypto.Keccak256(key.Bytes()), 0, proof); err != nil {
+					return nil, err
+				}
+			}
+			storage[j] = MultiProofSlot{
+				Key:   proofOutputKey(key, keyLengths[i][j]),
+				Value: (*hexutil.Big)(state.GetState(address, key).Big()),
+			}
+		}
+		accounts[i] = MultiProofAccount{
+			Address:     address,
+			Balance:     (*hexutil.Big)(state.GetBalance(address)),
+			CodeHash:    state.GetCodeHash(address),
+			Nonce:       hexutil.Uint64(state.GetNonce(address)),
+			StorageHash: storageRoot,
+			Storage:     storage,
+		}
+	}
+	nodes := make([]hexutil.Bytes, 0, proof.Len())
+	for _, node := range proof.List() {
+		nodes = append(nodes, node)
+	}
+	return &MultiProofResult{
+		BlockHash:   header.Hash(),
+		BlockNumber: (*hexutil.Big)(header.Number),
+		StateRoot:   header.Root,
+		Accounts:    accounts,
+		Nodes:       nodes,
+	}, state.Error()
+}
+
 // StructLogRes stores a structured log emitted by the EVM while replaying a
 // transaction in debug mode
 type StructLogRes struct {
@@ -630,7 +857,7 @@ func newRPCTransaction(b *types.Block, tx *types.Transaction, blockHash common.H
 	output["from"] = getFrom(tx)
 	output["hash"] = tx.Hash()
 	output["transactionIndex"] = hexutil.Uint(index)
-	if tx.Type() == types.TxTypeEthereumDynamicFee {
+	if tx.Type() == types.TxTypeEthereumDynamicFee || tx.Type() == types.TxTypeEthereumSetCode {
 		if b != nil {
 			output["gasPrice"] = (*hexutil.Big)(tx.EffectiveGasPrice(b.Header()))
 		} else {
@@ -18,13 +18,23 @@ package api
 
 import (
 	"context"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	mock_api "github.com/klaytn/klaytn/api/mocks"
 	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func testInitForKlayApi(t *testing.T) (*gomock.Controller, *mock_api.MockBackend, *PublicBlockChainAPI) {
@@ -60,3 +70,89 @@ func TestKlaytnAPI_EstimateGas(t *testing.T) {
 		return api.EstimateGas(context.Background(), args)
 	})
 }
+
+func TestKlaytnAPI_EstimateComputationCost(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForKlayApi(t)
+	defer mockCtrl.Finish()
+
+	chainConfig := &params.ChainConfig{
+		IstanbulCompatibleBlock:  common.Big0,
+		LondonCompatibleBlock:    common.Big0,
+		EthTxTypeCompatibleBlock: common.Big0,
+		MagmaCompatibleBlock:     common.Big0,
+		KoreCompatibleBlock:      common.Big0,
+		ShanghaiCompatibleBlock:  common.Big0,
+		CancunCompatibleBlock:    big.NewInt(10),
+	}
+	var (
+		sender   = common.HexToAddress("0xaaaa")
+		contract = common.HexToAddress("0xcccc")
+		// The contract stores 1 at the slot 0.
+		gspec = &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+			sender:   {Balance: big.NewInt(params.KLAY)},
+			contract: {Balance: common.Big0, Code: []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)}},
+		}, Config: chainConfig}
+		dbm    = database.NewMemoryDBManager()
+		db     = state.NewDatabase(dbm)
+		block  = gspec.MustCommit(dbm)
+		header = block.Header()
+		chain  = &testChainContext{header: header}
+	)
+
+	any := gomock.Any()
+	getStateAndHeader := func(...interface{}) (*state.StateDB, *types.Header, error) {
+		state, err := state.New(block.Root(), db, nil, nil)
+		return state, header, err
+	}
+	getEVM := func(_ context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmConfig vm.Config) (*vm.EVM, func() error, error) {
+		txContext := blockchain.NewEVMTxContext(msg, header)
+		blockContext := blockchain.NewEVMBlockContext(header, chain, nil)
+		return vm.NewEVM(blockContext, txContext, state, chainConfig, &vmConfig), func() error { return nil }, nil
+	}
+	mockBackend.EXPECT().ChainConfig().Return(chainConfig).AnyTimes()
+	mockBackend.EXPECT().RPCGasCap().Return(common.Big0).AnyTimes()
+	mockBackend.EXPECT().RPCEVMTimeout().Return(5 * time.Second).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(any, any).DoAndReturn(getStateAndHeader).AnyTimes()
+	mockBackend.EXPECT().HeaderByNumberOrHash(any, any).Return(header, nil).AnyTimes()
+	mockBackend.EXPECT().GetEVM(any, any, any, any, any).DoAndReturn(getEVM).AnyTimes()
+
+	var (
+		ctx           = context.Background()
+		args          = CallArgs{From: sender, To: &contract, Gas: 100000}
+		blockNrOrHash = rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	computationCost, err := api.EstimateComputationCost(ctx, args, blockNrOrHash, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, computationCost)
+
+	result, err := api.EstimateComputationCostDetail(ctx, args, blockNrOrHash, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &ComputationCostResult{
+		ComputationCost: computationCost,
+		Limit:           params.OpcodeComputationCostLimit,
+		Headroom:        params.OpcodeComputationCostLimit - computationCost,
+	}, result)
+
+	result, err = api.EstimateComputationCostDetail(ctx, args, blockNrOrHash, &ComputationCostOptions{Breakdown: true, NextFork: true})
+	require.NoError(t, err)
+	assert.Equal(t, computationCost, result.ComputationCost)
+	assert.Equal(t, []vm.ComputationCostFrame{{
+		Type: "CALL", From: sender, To: contract,
+		ComputationCost: uint64(computationCost), TotalComputationCost: uint64(computationCost),
+	}}, result.Frames)
+	assert.Contains(t, result.OpcodeClasses, vm.OpcodeClassCost{Class: "storage", Count: 1, ComputationCost: params.SstoreComputationCost})
+
+	// SSTORE is priced differently since Cancun, which raises the limit.
+	nextComputationCost := computationCost - params.SstoreComputationCost + params.SstoreComputationCostCancun
+	assert.Equal(t, &NextForkComputationCost{
+		Name:            "Cancun",
+		Block:           (*hexutil.Big)(big.NewInt(10)),
+		ComputationCost: nextComputationCost,
+		Limit:           params.OpcodeComputationCostLimitCancun,
+		Headroom:        params.OpcodeComputationCostLimitCancun - nextComputationCost,
+	}, result.NextFork)
+
+	estimated, err := api.EstimateComputationCost(ctx, args, blockNrOrHash, &ComputationCostOptions{NextFork: true})
+	require.NoError(t, err)
+	assert.Equal(t, nextComputationCost, estimated)
+}
@@ -77,6 +77,7 @@ var isTxField = func() map[types.TxType]map[string]bool {
 		types.TxTypeChainDataAnchoring:                          types.TxInternalDataChainDataAnchoring{},
 		types.TxTypeFeeDelegatedChainDataAnchoring:              types.TxInternalDataFeeDelegatedChainDataAnchoring{},
 		types.TxTypeFeeDelegatedChainDataAnchoringWithRatio:     types.TxInternalDataFeeDelegatedChainDataAnchoringWithRatio{},
+		types.TxTypeFeeDelegatedSetCode:                         types.TxInternalDataFeeDelegatedSetCode{},
 	}
 
 	// generate field maps for each tx type
@@ -134,6 +135,8 @@ type SendTxArgs struct {
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
 
+	AuthorizationList *[]types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+
 	FeePayer *common.Address `json:"feePayer"`
 	FeeRatio *types.FeeRatio `json:"feeRatio"`
 
@@ -159,7 +162,7 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 		}
 	}
 	// For the transaction that do not use the gasPrice field, the default value of gasPrice is not set.
-	if args.Price == nil && *args.TypeInt != types.TxTypeEthereumDynamicFee {
+	if args.Price == nil && !args.TypeInt.IsDynamicFeeTransaction() {
 		// b.SuggestPrice = unitPrice, for before Magma
 		//                = baseFee * 2,   for after Magma
 		price, err := b.SuggestPrice(ctx)
@@ -169,7 +172,7 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 		args.Price = (*hexutil.Big)(price)
 	}
 
-	if *args.TypeInt == types.TxTypeEthereumDynamicFee {
+	if args.TypeInt.IsDynamicFeeTransaction() {
 		gasPrice, err := b.SuggestPrice(ctx)
 		if err != nil {
 			return err
@@ -257,8 +260,8 @@ func (args *SendTxArgs) genTxValuesMap() map[types.TxValueKeyType]interface{} {
 	if args.TypeInt == nil || args.AccountNonce == nil || args.GasLimit == nil {
 		return values
 	}
-	// GasPrice can be an optional tx filed for TxTypeEthereumDynamicFee
-	if args.Price == nil && *args.TypeInt != types.TxTypeEthereumDynamicFee {
+	// GasPrice can be an optional tx filed for TxTypeEthereumDynamicFee and TxTypeEthereumSetCode
+	if args.Price == nil && !args.TypeInt.IsDynamicFeeTransaction() {
 		return values
 	}
 
@@ -324,6 +327,9 @@ func (args *SendTxArgs) genTxValuesMap() map[types.TxValueKeyType]interface{} {
 	if args.MaxFeePerGas != nil {
 		values[types.TxValueKeyGasFeeCap] = (*big.Int)(args.MaxFeePerGas)
 	}
+	if args.AuthorizationList != nil {
+		values[types.TxValueKeyAuthorizationList] = *args.AuthorizationList
+	}
 
 	return values
 }
@@ -551,6 +557,9 @@ type EthTransactionArgs struct {
 	// Introduced by AccessListTxType transaction.
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
+
+	// Introduced by SetCodeTxType transaction.
+	AuthorizationList []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
 }
 
 // from retrieves the transaction sender address.
@@ -601,6 +610,18 @@ func (args *EthTransactionArgs) setDefaults(ctx context.Context, b Backend) erro
 	head := b.CurrentBlock().Header()
 	isMagma := head.BaseFee != nil
 
+	if args.AuthorizationList != nil {
+		if !b.ChainConfig().IsPragueForkEnabled(new(big.Int).Add(head.Number, common.Big1)) {
+			return errors.New("set code transaction is not supported before the prague fork")
+		}
+		if args.To == nil {
+			return errors.New("set code transaction must have a recipient")
+		}
+		if args.GasPrice != nil {
+			return errors.New("gasPrice specified for a set code transaction")
+		}
+	}
+
 	fixedBaseFee := new(big.Int).SetUint64(params.ZeroBaseFee)
 
 	// b.SuggestPrice = unitPrice, for before Magma
@@ -779,6 +800,29 @@ func (args *EthTransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int,
 func (args *EthTransactionArgs) toTransaction() (*types.Transaction, error) {
 	var tx *types.Transaction
 	switch {
+	case args.AuthorizationList != nil:
+		if args.To == nil {
+			return nil, errors.New("set code transaction must have a recipient")
+		}
+		if args.MaxFeePerGas == nil || args.MaxPriorityFeePerGas == nil {
+			return nil, errors.New("set code transaction must have maxFeePerGas and maxPriorityFeePerGas")
+		}
+		al := types.AccessList{}
+		if args.AccessList != nil {
+			al = *args.AccessList
+		}
+		tx = types.NewTx(&types.TxInternalDataEthereumSetCode{
+			ChainID:           (*big.Int)(args.ChainID),
+			AccountNonce:      uint64(*args.Nonce),
+			GasTipCap:         (*big.Int)(args.MaxPriorityFeePerGas),
+			GasFeeCap:         (*big.Int)(args.MaxFeePerGas),
+			GasLimit:          uint64(*args.Gas),
+			Recipient:         *args.To,
+			Amount:            (*big.Int)(args.Value),
+			Payload:           args.data(),
+			AccessList:        al,
+			AuthorizationList: args.AuthorizationList,
+		})
 	case args.MaxFeePerGas != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
@@ -133,6 +133,10 @@ type CacheConfig struct {
 	TrieNodeCacheConfig  *statedb.TrieNodeCacheConfig // Configures trie node cache
 	SnapshotCacheSize    int                          // Memory allowance (MB) to use for caching snapshot entries in memory
 	SnapshotAsyncGen     bool                         // Enables snapshot data generation asynchronously
+	PathHistory          uint64                       // Number of recent states kept with the path trie node scheme. If zero, the default is used.
+
+	ParallelExecution      bool // Enables the parallel execution of the transactions of a block
+	ParallelExecutionCheck bool // Executes the transactions of a block both in parallel and sequentially, and compares the results
 }
 
 // gcBlock is used for priority queue for GC.
@@ -212,6 +216,8 @@ type BlockChain struct {
 	quitWarmUp         chan struct{}
 
 	prefetchTxCh chan prefetchTx
+
+	stateHistory bool // Records the change set of every block in the state history index
 }
 
 // prefetchTx is used to prefetch transactions, when fetcher works.
@@ -261,6 +267,7 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 		parallelDBWrite:    db.IsParallelDBWrite(),
 		stopStateMigration: make(chan struct{}),
 		prefetchTxCh:       make(chan prefetchTx, MaxPrefetchTxs),
+		stateHistory:       db.ReadStateHistoryEnabled(),
 	}
 
 	// set hardForkBlockNumberConfig which will be used as a global variable
@@ -268,6 +275,23 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 		return nil, err
 	}
 
+	// refuse to start if the custom precompiled contracts of the chain are not available
+	if err := vm.CheckPrecompiledContracts(bc.chainConfig); err != nil {
+		return nil, err
+	}
+
+	if trieDB := bc.stateCache.TrieDB(); trieDB.Scheme() == statedb.PathScheme {
+		if db.ReadPruningEnabled() {
+			return nil, errors.New("live pruning is not supported with the path trie node scheme")
+		}
+		history := cacheConfig.PathHistory
+		if history == 0 {
+			history = statedb.DefaultPathHistory
+		}
+		trieDB.SetPathHistory(history)
+		logger.Info("Using the path trie node scheme", "history", history)
+	}
+
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
@@ -288,6 +312,18 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	// refuse to start if the custom precompiled contracts are not the ones the chain has been executed with
+	if stored, ok := db.ReadPrecompileFingerprints(bc.genesisBlock.Hash()); ok {
+		if err := vm.CheckPrecompileFingerprints(stored, bc.chainConfig, bc.CurrentBlock().Number()); err != nil {
+			return nil, err
+		}
+	}
+	fingerprints, err := vm.PrecompileFingerprints(bc.chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	db.WritePrecompileFingerprints(bc.genesisBlock.Hash(), fingerprints)
+
 	// Make sure the state associated with the block is available
 	head := bc.CurrentBlock()
 	if _, err := state.New(head.Root(), bc.stateCache, bc.snaps, nil); err != nil {
@@ -731,16 +767,81 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
 	return state.New(root, bc.stateCache, bc.snaps, nil)
 }
 
+// HistoryStateAt returns a state of the given block served by the state history
+// index, for a block whose state trie is not available. The state can be
+// modified, but not committed.
+func (bc *BlockChain) HistoryStateAt(header *types.Header) (*state.StateDB, error) {
+	if !bc.stateHistory {
+		return nil, state.ErrStateHistoryIncomplete
+	}
+	if bc.db.ReadStateHistoryIndex(header.Number.Uint64()) != header.Hash() {
+		return nil, state.ErrStateHistoryIncomplete
+	}
+	db, err := state.NewHistoryDatabase(bc.stateCache, header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	return state.New(header.Root, db, nil, nil)
+}
+
+// ExecutionWitness executes the given block again on the state of its parent,
+// and returns the witness of the execution: the trie nodes, contract codes and
+// ancestor headers needed to execute the block without the state.
+func (bc *BlockChain) ExecutionWitness(block *types.Block) (*state.Witness, error) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	witness := state.NewWitness(parent)
+	statedb, err := state.NewWithWitness(parent.Root, bc.stateCache, witness)
+	if err != nil {
+		return nil, err
+	}
+	receipts, _, usedGas, _, _, err := bc.processor.Process(block, statedb, vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	// The state root is computed by the validation, which records the nodes
+	// read to update the tries.
+	if err := bc.validator.ValidateState(block, nil, statedb, receipts, usedGas); err != nil {
+		return nil, err
+	}
+	if err := statedb.Error(); err != nil {
+		return nil, err
+	}
+	return witness, nil
+}
+
+// indexStateHistory adds the change set of a new canonical block to the state
+// history index.
+func (bc *BlockChain) indexStateHistory(block *types.Block) {
+	if !bc.stateHistory {
+		return
+	}
+	if err := bc.db.IndexStateHistory(block.NumberU64(), block.Hash()); err != nil {
+		logger.Error("Failed to index the state history", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+	}
+}
+
 // PrunableStateAt returns a new mutable state based on a particular point in time.
 // If live pruning is enabled on the databse, and num is nonzero, then trie will mark obsolete nodes for pruning.
+// If the state history index is kept, the state records the change set of the block processed on it.
 func (bc *BlockChain) PrunableStateAt(root common.Hash, num uint64) (*state.StateDB, error) {
+	var (
+		stateDB *state.StateDB
+		err     error
+	)
 	if bc.IsLivePruningRequired() {
-		return state.New(root, bc.stateCache, bc.snaps, &statedb.TrieOpts{
+		stateDB, err = state.New(root, bc.stateCache, bc.snaps, &statedb.TrieOpts{
 			PruningBlockNumber: num,
 		})
 	} else {
-		return bc.StateAt(root)
+		stateDB, err = bc.StateAt(root)
+	}
+	if err == nil && bc.stateHistory {
+		stateDB.CaptureHistory()
 	}
+	return stateDB, err
 }
 
 // StateAtWithPersistent returns a new mutable state based on a particular point in time with persistent trie nodes.
@@ -1109,6 +1210,11 @@ func (bc *BlockChain) Stop() {
 			logger.Error("Dangling trie nodes after full cleanup")
 		}
 	}
+	if bc.cacheConfig.TrieNodeCacheConfig.WarmCacheMaxEntries > 0 {
+		if err := triedb.SaveWarmCache(); err != nil {
+			logger.Error("Failed to save hot trie nodes", "err", err)
+		}
+	}
 	if triedb.TrieNodeCache() != nil {
 		_ = triedb.TrieNodeCache().Close()
 	}
@@ -1356,9 +1462,32 @@ func (bc *BlockChain) writeStateTrie(block *types.Block, state *state.StateDB) e
 	if err != nil {
 		return err
 	}
+	if diff := state.HistoryDiff(); diff != nil {
+		bc.db.WriteStateHistoryDiff(block.NumberU64(), block.Hash(), diff)
+	}
 	trieDB := bc.stateCache.TrieDB()
 	trieDB.UpdateMetricNodes()
 
+	// With the path scheme, the disk holds the state of a single block, so only
+	// the state of a block extending the canonical head is flushed, on top of the
+	// state of its parent which is kept as a reverse diff. The state of a side
+	// chain block stays in memory, and it is flushed by reorg if the block
+	// becomes canonical.
+	if trieDB.Scheme() == statedb.PathScheme {
+		if block.ParentHash() != bc.CurrentBlock().Hash() {
+			trieDB.KeepState(root, block.NumberU64())
+			return nil
+		}
+		if err := trieDB.Recover(bc.CurrentBlock().Root()); err != nil {
+			return err
+		}
+		if err := trieDB.Commit(root, false, block.NumberU64()); err != nil {
+			return err
+		}
+		bc.lastCommittedBlock = block.NumberU64()
+		return nil
+	}
+
 	// If we're running an archive node, always flush
 	if bc.isArchiveMode() {
 		if err := trieDB.Commit(root, false, block.NumberU64()); err != nil {
@@ -1788,6 +1917,7 @@ func (bc *BlockChain) finalizeWriteBlockWithState(block *types.Block, status Wri
 	// Set new head.
 	if status == CanonStatTy {
 		bc.insert(block)
+		bc.indexStateHistory(block)
 		headBlockNumberGauge.Update(block.Number().Int64())
 		blockTxCountsGauge.Update(int64(block.Transactions().Len()))
 		blockTxCountsCounter.Inc(int64(block.Transactions().Len()))
@@ -2476,11 +2606,17 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	} else {
 		logger.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(), "oldhash", oldBlock.Hash(), "newnum", newBlock.Number(), "newhash", newBlock.Hash())
 	}
+	if bc.stateCache.TrieDB().Scheme() == statedb.PathScheme {
+		if err := bc.reorgPathState(commonBlock, newChain); err != nil {
+			return err
+		}
+	}
 	// Insert the new chain, taking care of the proper incremental order
 	var addedTxs types.Transactions
 	for i := len(newChain) - 1; i >= 0; i-- {
 		// insert the block in the canonical way, re-writing history
 		bc.insert(newChain[i])
+		bc.indexStateHistory(newChain[i])
 		// write lookup entries for hash based transaction/receipt searches
 		bc.db.WriteTxLookupEntries(newChain[i])
 		addedTxs = append(addedTxs, newChain[i].Transactions()...)
@@ -2506,6 +2642,35 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	return nil
 }
 
+// reorgPathState moves the state on disk to the head of the new chain with the
+// path scheme. The state of the common block is recovered from the reverse
+// diffs, and then the states of the new chain, which have been kept in memory
+// as they were side chain blocks, are flushed on top of it in order.
+func (bc *BlockChain) reorgPathState(commonBlock *types.Block, newChain types.Blocks) error {
+	trieDB := bc.stateCache.TrieDB()
+
+	// Check that every state can be flushed before the disk is reverted. A block
+	// without state changes has the state of its parent.
+	parentRoot := commonBlock.Root()
+	for i := len(newChain) - 1; i >= 0; i-- {
+		root := newChain[i].Root()
+		if root != parentRoot && !trieDB.DoesExistCachedNode(root.ExtendZero()) {
+			return fmt.Errorf("state of block %d (%x) is not available for the reorg", newChain[i].NumberU64(), newChain[i].Hash())
+		}
+		parentRoot = root
+	}
+	if err := trieDB.Recover(commonBlock.Root()); err != nil {
+		return err
+	}
+	for i := len(newChain) - 1; i >= 0; i-- {
+		if err := trieDB.Commit(newChain[i].Root(), false, newChain[i].NumberU64()); err != nil {
+			return err
+		}
+	}
+	bc.lastCommittedBlock = newChain[0].NumberU64()
+	return nil
+}
+
 // PostChainEvents iterates over the events generated by a chain insertion and
 // posts them into the event feed.
 // TODO: Should not expose PostChainEvents. The chain events should be posted in WriteBlock.
@@ -2723,11 +2888,49 @@ func (bc *BlockChain) SaveTrieNodeCacheToDisk() error {
 	return nil
 }
 
+// Backup writes a consistent point-in-time copy of every database partition
+// into dir, together with a manifest recording the head block and its state
+// root. Block insertion is blocked only while the state of the head block is
+// flushed and the database checkpoints are taken.
+func (bc *BlockChain) Backup(dir string) (*database.BackupManifest, error) {
+	bc.mu.Lock()
+	head := bc.CurrentBlock()
+	if !bc.isArchiveMode() {
+		if err := bc.stateCache.TrieDB().Commit(head.Root(), false, head.NumberU64()); err != nil {
+			bc.mu.Unlock()
+			return nil, err
+		}
+	}
+	cp, err := bc.db.NewCheckpoint()
+	bc.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer cp.Release()
+
+	logger.Info("Writing database backup", "dir", dir, "number", head.NumberU64(), "hash", head.Hash())
+	manifest, err := cp.Write(dir)
+	if err != nil {
+		return nil, err
+	}
+	manifest.BlockNumber, manifest.BlockHash, manifest.StateRoot = head.NumberU64(), head.Hash(), head.Root()
+	if err := database.WriteBackupManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	logger.Info("Wrote database backup", "dir", dir, "number", head.NumberU64(), "root", head.Root())
+	return manifest, nil
+}
+
 // ApplyTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
 func (bc *BlockChain) ApplyTransaction(chainConfig *params.ChainConfig, author *common.Address, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, vmConfig *vm.Config) (*types.Receipt, *vm.InternalTxTrace, error) {
+	return applyTransaction(bc, chainConfig, author, statedb, header, tx, usedGas, vmConfig)
+}
+
+// applyTransaction applies a transaction on the given chain, see ApplyTransaction.
+func applyTransaction(chain ChainContext, chainConfig *params.ChainConfig, author *common.Address, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, vmConfig *vm.Config) (*types.Receipt, *vm.InternalTxTrace, error) {
 	// TODO-Klaytn We reject transactions with unexpected gasPrice and do not put the transaction into TxPool.
 	//         And we run transactions regardless of gasPrice if we push transactions in the TxPool.
 	/*
@@ -2749,7 +2952,7 @@ func (bc *BlockChain) ApplyTransaction(chainConfig *params.ChainConfig, author *
 		return nil, nil, err
 	}
 	// Create a new context to be used in the EVM environment
-	blockContext := NewEVMBlockContext(header, bc, author)
+	blockContext := NewEVMBlockContext(header, chain, author)
 	txContext := NewEVMTxContext(msg, header)
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
@@ -2205,3 +2205,281 @@ func TestTransientStorageReset(t *testing.T) {
 		t.Fatalf("Unexpected dirty storage slot")
 	}
 }
+
+// TestBlockChain_PathScheme checks that a chain with the path trie node scheme
+// keeps the recent states, and loads them again after a restart.
+func TestBlockChain_PathScheme(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		gendb   = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.LatestSignerForChainID(gspec.Config.ChainID)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), gendb, 32, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.AddTx(tx)
+	})
+
+	db := database.NewMemoryDBManager()
+	if _, err := statedb.SetupTrieNodeScheme(db, statedb.PathScheme); err != nil {
+		t.Fatal(err)
+	}
+	gspec.MustCommit(db)
+	cacheConfig := &CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		PathHistory:         8,
+	}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+
+	check := func(chain *BlockChain) {
+		for _, block := range blocks {
+			state, err := chain.StateAt(block.Root())
+			if block.NumberU64()+8 < uint64(len(blocks)) {
+				assert.Error(t, err, "block %d", block.NumberU64())
+				continue
+			}
+			if assert.NoError(t, err, "block %d", block.NumberU64()) {
+				assert.Equal(t, block.NumberU64(), state.GetNonce(address))
+				assert.Equal(t, big.NewInt(1000), state.GetBalance(common.Address{byte(block.NumberU64() - 1)}))
+			}
+		}
+	}
+	check(chain)
+	chain.Stop()
+
+	// The recent states are still available when the chain is opened again
+	chain, err = NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	assert.Equal(t, blocks[len(blocks)-1].Hash(), chain.CurrentBlock().Hash())
+	check(chain)
+}
+
+// TestBlockChain_PathSchemeReorg checks that with the path trie node scheme,
+// the states of side chain blocks are kept in memory, and that the state on
+// disk follows the canonical chain when a reorg happens.
+func TestBlockChain_PathSchemeReorg(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		counter = common.HexToAddress("0x1000") // stores the block number in slot 0
+		writer  = common.HexToAddress("0x2000") // stores the call value in slot 1
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000)},
+				counter: {Code: []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.SSTORE)}, Balance: common.Big0},
+				writer:  {Code: []byte{byte(vm.CALLVALUE), byte(vm.PUSH1), 1, byte(vm.SSTORE)}, Balance: common.Big0},
+			},
+		}
+		gendb   = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.LatestSignerForChainID(gspec.Config.ChainID)
+	)
+	// Both branches update the same storage slots, the counter to the same
+	// values and the writer to different ones.
+	generate := func(parent *types.Block, n int, branch byte) []*types.Block {
+		blocks, _ := GenerateChain(gspec.Config, parent, gxhash.NewFaker(), gendb, n, func(i int, block *BlockGen) {
+			send := func(to common.Address, value int64) {
+				tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), to, big.NewInt(value), 100000, nil, nil), signer, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				block.AddTx(tx)
+			}
+			send(common.Address{branch, byte(block.Number().Uint64())}, 1000)
+			send(counter, 0)
+			send(writer, int64(branch))
+		})
+		return blocks
+	}
+	common3 := generate(genesis, 3, 1)
+	branchA := generate(common3[2], 3, 2)
+	branchB := generate(common3[2], 5, 3)
+
+	db := database.NewMemoryDBManager()
+	if _, err := statedb.SetupTrieNodeScheme(db, statedb.PathScheme); err != nil {
+		t.Fatal(err)
+	}
+	gspec.MustCommit(db)
+	cacheConfig := &CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		PathHistory:         8,
+	}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	// check checks the states of the given canonical blocks of a branch.
+	check := func(blocks []*types.Block, branch byte) {
+		for _, block := range blocks {
+			state, err := chain.StateAt(block.Root())
+			if !assert.NoError(t, err, "block %d", block.NumberU64()) {
+				continue
+			}
+			number := block.NumberU64()
+			assert.Equal(t, 3*number, state.GetNonce(address), "block %d", number)
+			assert.Equal(t, big.NewInt(1000), state.GetBalance(common.Address{branch, byte(number)}), "block %d", number)
+			assert.Equal(t, common.BigToHash(block.Number()), state.GetState(counter, common.Hash{}), "block %d", number)
+			if number > 3 {
+				assert.Equal(t, common.BigToHash(big.NewInt(int64(branch))), state.GetState(writer, common.BytesToHash([]byte{1})), "block %d", number)
+			}
+		}
+	}
+
+	if n, err := chain.InsertChain(append(common3, branchA...)); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	check(branchA, 2)
+
+	// The side chain blocks do not touch the state on disk, until the side
+	// chain becomes the canonical one.
+	if n, err := chain.InsertChain(branchB[:2]); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	assert.Equal(t, branchA[2].Hash(), chain.CurrentBlock().Hash())
+	check(branchA, 2)
+	check(branchB[:2], 3)
+
+	if n, err := chain.InsertChain(branchB[2:]); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	assert.Equal(t, branchB[4].Hash(), chain.CurrentBlock().Hash())
+	check(common3, 1)
+	check(branchB, 3)
+	for _, block := range branchA {
+		state, err := chain.StateAt(block.Root())
+		if err == nil {
+			assert.Equal(t, common.Big0, state.GetBalance(common.Address{2, byte(block.NumberU64())}), "block %d", block.NumberU64())
+		}
+	}
+
+	// The canonical chain goes on on top of the new state.
+	next := generate(branchB[4], 2, 3)
+	if n, err := chain.InsertChain(next); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	check(append(branchB, next...), 3)
+}
+
+// TestBlockChain_StateHistory checks that the state history index serves the
+// same states as the state tries.
+func TestBlockChain_StateHistory(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		counter  = common.HexToAddress("0x1000") // stores the block number in slot 0
+		cleaner  = common.HexToAddress("0x2000") // deletes slot 1
+		destruct = common.HexToAddress("0x3000") // self-destructs
+		gspec    = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000)},
+				counter: {Code: []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.SSTORE)}, Balance: common.Big0},
+				cleaner: {
+					Code:    []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 1, byte(vm.SSTORE)},
+					Storage: map[common.Hash]common.Hash{{1}: {1}},
+					Balance: common.Big0,
+				},
+				destruct: {
+					Code:    []byte{byte(vm.CALLER), byte(vm.SELFDESTRUCT)},
+					Storage: map[common.Hash]common.Hash{{2}: {2}},
+					Balance: big.NewInt(10),
+				},
+			},
+		}
+		gendb   = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.LatestSignerForChainID(gspec.Config.ChainID)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), gendb, 8, func(i int, block *BlockGen) {
+		send := func(to common.Address, value int64) {
+			tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), to, big.NewInt(value), 100000, nil, nil), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block.AddTx(tx)
+		}
+		send(common.Address{byte(i + 1)}, 1000)
+		send(counter, 0)
+		switch i {
+		case 2:
+			send(cleaner, 0)
+		case 4:
+			send(destruct, 0)
+		case 6:
+			send(destruct, 5) // resurrects the destructed account
+		}
+	})
+
+	db := database.NewMemoryDBManager()
+	db.WriteStateHistoryEnabled()
+	gspec.MustCommit(db)
+	cacheConfig := &CacheConfig{
+		ArchiveMode:         true,
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+	}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+
+	addrs := []common.Address{address, counter, cleaner, destruct}
+	for i := range blocks {
+		addrs = append(addrs, common.Address{byte(i + 1)})
+	}
+	for _, block := range append(types.Blocks{genesis}, blocks...) {
+		want, err := chain.StateAt(block.Root())
+		require.NoError(t, err)
+		got, err := chain.HistoryStateAt(block.Header())
+		require.NoError(t, err, "block %d", block.NumberU64())
+
+		for _, addr := range addrs {
+			assert.Equal(t, want.Exist(addr), got.Exist(addr), "block %d, %x", block.NumberU64(), addr)
+			assert.Equal(t, want.GetBalance(addr), got.GetBalance(addr), "block %d, %x", block.NumberU64(), addr)
+			assert.Equal(t, want.GetNonce(addr), got.GetNonce(addr), "block %d, %x", block.NumberU64(), addr)
+			assert.Equal(t, want.GetCode(addr), got.GetCode(addr), "block %d, %x", block.NumberU64(), addr)
+			for _, slot := range []common.Hash{{0}, {1}, {2}} {
+				assert.Equal(t, want.GetState(addr, slot), got.GetState(addr, slot), "block %d, %x, slot %x", block.NumberU64(), addr, slot)
+			}
+		}
+		assert.Equal(t, common.BigToHash(block.Number()), got.GetState(counter, common.Hash{}))
+	}
+
+	// A block which is not canonical is not served
+	header := types.CopyHeader(blocks[3].Header())
+	header.Extra = []byte("side")
+	_, err = chain.HistoryStateAt(header)
+	assert.ErrorIs(t, err, state.ErrStateHistoryIncomplete)
+}
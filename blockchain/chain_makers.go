@@ -189,6 +189,9 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 
 		b := &BlockGen{i: i, parent: parent, chain: blocks, chainReader: blockchain, statedb: stateDB, config: config, engine: engine}
 		b.header = makeHeader(b.chainReader, parent, stateDB, b.engine)
+		if err := ProcessParentBlockHash(config, b.header, stateDB); err != nil {
+			panic(fmt.Sprintf("parent block hash error: %v", err))
+		}
 
 		// Execute any user modifications to the block and finalize it
 		if gen != nil {
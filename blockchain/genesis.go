@@ -31,6 +31,7 @@ import (
 
 	"github.com/klaytn/klaytn/blockchain/state"
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/common/math"
@@ -299,6 +300,10 @@ func (g *Genesis) ToBlock(baseStateRoot common.Hash, db database.DBManager) *typ
 		db = database.NewMemoryDBManager()
 	}
 	stateDB, _ := state.New(baseStateRoot, state.NewDatabase(db), nil, nil)
+	// The genesis state is the base of the state history index
+	if baseStateRoot == (common.Hash{}) && db.ReadStateHistoryEnabled() {
+		stateDB.CaptureHistory()
+	}
 	for addr, account := range g.Alloc {
 		if len(account.Code) != 0 {
 			originalCode := stateDB.GetCode(addr)
@@ -346,7 +351,11 @@ func (g *Genesis) ToBlock(baseStateRoot common.Hash, db database.DBManager) *typ
 	stateDB.Commit(false)
 	stateDB.Database().TrieDB().Commit(root, true, g.Number)
 
-	return types.NewBlock(head, nil, nil)
+	block := types.NewBlock(head, nil, nil)
+	if diff := stateDB.HistoryDiff(); diff != nil {
+		db.WriteStateHistoryDiff(block.NumberU64(), block.Hash(), diff)
+	}
+	return block
 }
 
 // Commit writes the block and state of a genesis specification to the database.
@@ -362,6 +371,11 @@ func (g *Genesis) Commit(baseStateRoot common.Hash, db database.DBManager) (*typ
 	db.WriteCanonicalHash(block.Hash(), block.NumberU64())
 	db.WriteHeadBlockHash(block.Hash())
 	db.WriteHeadHeaderHash(block.Hash())
+	if db.ReadStateHistoryDiff(block.NumberU64(), block.Hash()) != nil {
+		if err := db.IndexStateHistory(block.NumberU64(), block.Hash()); err != nil {
+			return nil, err
+		}
+	}
 
 	config := g.Config
 	if config == nil {
@@ -370,7 +384,12 @@ func (g *Genesis) Commit(baseStateRoot common.Hash, db database.DBManager) (*typ
 	if err := config.CheckConfigForkOrder(); err != nil {
 		return nil, err
 	}
+	fingerprints, err := vm.PrecompileFingerprints(config)
+	if err != nil {
+		return nil, err
+	}
 	db.WriteChainConfig(block.Hash(), config)
+	db.WritePrecompileFingerprints(block.Hash(), fingerprints)
 	return block, nil
 }
 
@@ -381,3 +381,47 @@ func genCustomGenesisBlock(customChainId uint64) *Genesis {
 	InitDeriveSha(genesis.Config)
 	return genesis
 }
+
+// TestGenesisPrecompiles tests that a blockchain refuses to start if a custom
+// precompiled contract of the chain config is not registered, or is not the
+// implementation the chain has been executed with since the genesis.
+func TestGenesisPrecompiles(t *testing.T) {
+	name := "genesisTestPrecompile"
+	register := func(version string) {
+		vm.RegisterPrecompiledContract(name, version, &vm.CustomPrecompiledContract{
+			RunFunc: func(input []byte, contract *vm.Contract, evm *vm.EVM) ([]byte, error) { return input, nil },
+		})
+	}
+	t.Cleanup(func() { vm.UnregisterPrecompiledContract(name) })
+
+	genesis := &Genesis{Config: params.AllGxhashProtocolChanges.Copy()}
+	genesis.Config.Precompiles = []*params.PrecompileConfig{
+		{Name: name, Address: common.HexToAddress("0x0300"), Block: big.NewInt(0)},
+	}
+	db := database.NewMemoryDBManager()
+	_, err := genesis.Commit(common.Hash{}, db)
+	assert.ErrorContains(t, err, fmt.Sprintf("precompiled contract %q is not registered", name))
+
+	register("1")
+	block := genesis.MustCommit(db)
+	fingerprints, ok := db.ReadPrecompileFingerprints(block.Hash())
+	assert.True(t, ok)
+	assert.Equal(t, []*params.PrecompileFingerprint{
+		{Name: name, Address: common.HexToAddress("0x0300"), Block: big.NewInt(0), Version: "1"},
+	}, fingerprints)
+
+	bc, err := NewBlockChain(db, nil, genesis.Config, gxhash.NewFaker(), vm.Config{})
+	assert.NoError(t, err)
+	bc.Stop()
+
+	// Another implementation of the contract.
+	vm.UnregisterPrecompiledContract(name)
+	register("2")
+	_, err = NewBlockChain(db, nil, genesis.Config, gxhash.NewFaker(), vm.Config{})
+	assert.ErrorContains(t, err, fmt.Sprintf("precompiled contract %q (version \"1\")", name))
+	assert.ErrorContains(t, err, "has been executed, but is now")
+
+	vm.UnregisterPrecompiledContract(name)
+	_, err = NewBlockChain(db, nil, genesis.Config, gxhash.NewFaker(), vm.Config{})
+	assert.ErrorContains(t, err, fmt.Sprintf("precompiled contract %q is not registered", name))
+}
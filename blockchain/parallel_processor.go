@@ -0,0 +1,243 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/rcrowley/go-metrics"
+)
+
+var (
+	parallelMergedMeter     = metrics.NewRegisteredMeter("chain/parallel/merged", nil)
+	parallelReexecutedMeter = metrics.NewRegisteredMeter("chain/parallel/reexecuted", nil)
+	parallelMismatchMeter   = metrics.NewRegisteredMeter("chain/parallel/mismatches", nil)
+)
+
+// speculativeResult is the result of a transaction executed on a copy of the
+// state of the beginning of the block.
+type speculativeResult struct {
+	receipt  *types.Receipt
+	usedGas  uint64
+	accesses *state.TxAccesses
+	writes   *state.TxWrites // Nil if the transaction failed or cannot be merged
+}
+
+// writtenSet is the set of the state locations written by the transactions
+// merged so far.
+type writtenSet struct {
+	accounts map[common.Address]struct{}
+	slots    map[common.Address]map[common.Hash]struct{}
+}
+
+func newWrittenSet() *writtenSet {
+	return &writtenSet{
+		accounts: make(map[common.Address]struct{}),
+		slots:    make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+func (w *writtenSet) addSlot(addr common.Address, key common.Hash) {
+	if w.slots[addr] == nil {
+		w.slots[addr] = make(map[common.Hash]struct{})
+	}
+	w.slots[addr][key] = struct{}{}
+}
+
+// conflicts returns true if a state location read by a transaction was written
+// by a previous transaction.
+func (w *writtenSet) conflicts(accesses *state.TxAccesses) bool {
+	for addr := range accesses.Accounts {
+		if _, ok := w.accounts[addr]; ok {
+			return true
+		}
+	}
+	for addr, keys := range accesses.Slots {
+		written := w.slots[addr]
+		for key := range keys {
+			if _, ok := written[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addWrites marks the locations of a merged change set as written.
+func (w *writtenSet) addWrites(writes *state.TxWrites) {
+	for addr := range writes.Balances {
+		w.accounts[addr] = struct{}{}
+	}
+	for addr := range writes.Nonces {
+		w.accounts[addr] = struct{}{}
+	}
+	for addr, slots := range writes.Storage {
+		for key := range slots {
+			w.addSlot(addr, key)
+		}
+	}
+}
+
+// addAccesses marks all the locations accessed by a re-executed transaction as
+// written, since its change set is not known.
+func (w *writtenSet) addAccesses(accesses *state.TxAccesses) {
+	for addr := range accesses.Accounts {
+		w.accounts[addr] = struct{}{}
+	}
+	for addr := range accesses.Balances {
+		w.accounts[addr] = struct{}{}
+	}
+	for addr, keys := range accesses.Slots {
+		for key := range keys {
+			w.addSlot(addr, key)
+		}
+	}
+}
+
+// canExecuteInParallel returns true if the transactions of a block can be
+// executed by the parallel executor, which does not support tracing nor the
+// recording of preimages or witnesses.
+func canExecuteInParallel(block *types.Block, statedb *state.StateDB, cfg *vm.Config) bool {
+	return len(block.Transactions()) > 1 && statedb.Witness() == nil && !cfg.Debug && cfg.Tracer == nil &&
+		!cfg.EnableInternalTxTracing && !cfg.EnablePreimageRecording
+}
+
+// applyTransactionsParallel executes the transactions of a block with optimistic
+// concurrency control, in the manner of Block-STM. Every transaction is first
+// executed in parallel on a copy of the state of the beginning of the block,
+// recording the state it reads. The results are then merged in order: the change
+// set of a transaction is replayed on the state if nothing it read was written
+// by a previous transaction, or else the transaction is executed again on the
+// state. The result is the same as the one of applyTransactions.
+func (p *StateProcessor) applyTransactionsParallel(chain ChainContext, block *types.Block, statedb *state.StateDB, author *common.Address, cfg *vm.Config) (types.Receipts, uint64, error) {
+	var (
+		txs     = block.Transactions()
+		header  = block.Header()
+		results = make([]speculativeResult, len(txs))
+		base    = statedb.Copy()
+		workers = runtime.NumCPU()
+		next    = make(chan int, len(txs))
+		wg      sync.WaitGroup
+	)
+	for i := range txs {
+		next <- i
+	}
+	close(next)
+
+	for w := 0; w < workers && w < len(txs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The interpreter completes its config, so every worker needs a copy
+			workerCfg := *cfg
+			workerCfg.ExtraEips = append([]int(nil), cfg.ExtraEips...)
+			for i := range next {
+				results[i] = p.executeSpeculatively(chain, block, base, author, i, &workerCfg)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var (
+		receipts = make(types.Receipts, 0, len(txs))
+		usedGas  = new(uint64)
+		written  = newWrittenSet()
+	)
+	for i, tx := range txs {
+		statedb.SetTxContext(tx.Hash(), block.Hash(), i)
+
+		result := results[i]
+		if result.writes != nil && !written.conflicts(result.accesses) {
+			statedb.ApplyTxWrites(result.writes)
+			for _, log := range result.receipt.Logs {
+				statedb.AddLog(log)
+			}
+			statedb.Finalise(true, false)
+			*usedGas += result.usedGas
+
+			receipt := result.receipt
+			receipt.Logs = statedb.GetLogs(tx.Hash())
+			receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+			receipts = append(receipts, receipt)
+			written.addWrites(result.writes)
+			parallelMergedMeter.Mark(1)
+			continue
+		}
+		accesses := state.NewTxAccesses()
+		statedb.RecordAccesses(accesses)
+		receipt, _, err := applyTransaction(chain, p.config, author, statedb, header, tx, usedGas, cfg)
+		statedb.RecordAccesses(nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		receipts = append(receipts, receipt)
+		written.addAccesses(accesses)
+		parallelReexecutedMeter.Mark(1)
+	}
+	return receipts, *usedGas, nil
+}
+
+// executeSpeculatively executes the i-th transaction of a block on a copy of
+// the given state.
+func (p *StateProcessor) executeSpeculatively(chain ChainContext, block *types.Block, base *state.StateDB, author *common.Address, i int, cfg *vm.Config) speculativeResult {
+	var (
+		tx       = block.Transactions()[i]
+		spec     = base.Copy()
+		accesses = state.NewTxAccesses()
+		usedGas  uint64
+	)
+	spec.SetTxContext(tx.Hash(), block.Hash(), i)
+	spec.RecordAccesses(accesses)
+	receipt, _, err := applyTransaction(chain, p.config, author, spec, block.Header(), tx, &usedGas, cfg)
+	if err != nil {
+		// Executed again in order to return the error of the sequential execution
+		return speculativeResult{accesses: accesses}
+	}
+	writes, ok := spec.TxWrites(base.Copy())
+	if !ok {
+		return speculativeResult{accesses: accesses}
+	}
+	return speculativeResult{receipt: receipt, usedGas: usedGas, accesses: accesses, writes: writes}
+}
+
+// checkParallelResult compares the results of the parallel executor with the
+// ones of the sequential executor, and logs the differences.
+func checkParallelResult(block *types.Block, seqState, parState *state.StateDB, seqReceipts, parReceipts types.Receipts, seqGas, parGas uint64) {
+	seqRoot, parRoot := seqState.IntermediateRoot(true), parState.IntermediateRoot(true)
+	if seqRoot != parRoot || seqGas != parGas || len(seqReceipts) != len(parReceipts) {
+		logger.Error("Parallel execution mismatch", "number", block.Number(), "hash", block.Hash(),
+			"root", seqRoot, "parallelRoot", parRoot, "gas", seqGas, "parallelGas", parGas,
+			"receipts", len(seqReceipts), "parallelReceipts", len(parReceipts))
+		parallelMismatchMeter.Mark(1)
+		return
+	}
+	for i, seq := range seqReceipts {
+		par := parReceipts[i]
+		if seq.Status != par.Status || seq.GasUsed != par.GasUsed || seq.Bloom != par.Bloom || len(seq.Logs) != len(par.Logs) {
+			logger.Error("Parallel execution receipt mismatch", "number", block.Number(), "hash", block.Hash(),
+				"index", i, "tx", seq.TxHash, "status", seq.Status, "parallelStatus", par.Status,
+				"gas", seq.GasUsed, "parallelGas", par.GasUsed)
+			parallelMismatchMeter.Mark(1)
+			return
+		}
+	}
+}
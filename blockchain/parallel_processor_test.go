@@ -0,0 +1,166 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParallelExecution executes blocks of conflicting and independent
+// transactions with both executors and compares the results.
+func TestParallelExecution(t *testing.T) {
+	var (
+		keys    = make([]*ecdsa.PrivateKey, 6)
+		addrs   = make([]common.Address, len(keys))
+		counter = common.HexToAddress("0x1000") // increments the slot 0
+		setter  = common.HexToAddress("0x2000") // stores the value sent in the slot of the caller
+		logger  = common.HexToAddress("0x3000") // emits a log of the caller
+		alloc   = GenesisAlloc{
+			counter: {
+				Code: []byte{
+					byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD),
+					byte(vm.PUSH1), 0, byte(vm.SSTORE),
+				},
+				Balance: common.Big0,
+			},
+			setter: {
+				Code:    []byte{byte(vm.CALLVALUE), byte(vm.CALLER), byte(vm.SSTORE)},
+				Balance: common.Big0,
+			},
+			logger: {
+				Code:    []byte{byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1)},
+				Balance: common.Big0,
+			},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[addrs[i]] = GenesisAccount{Balance: big.NewInt(params.KLAY)}
+	}
+	var (
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+		gendb   = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.LatestSignerForChainID(gspec.Config.ChainID)
+		engine  = gxhash.NewFaker()
+		price   = new(big.Int).SetUint64(gspec.Config.UnitPrice)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, gendb, 4, func(i int, block *BlockGen) {
+		send := func(from int, to common.Address, value int64, gas uint64, data []byte) {
+			tx, err := types.SignTx(types.NewTransaction(block.TxNonce(addrs[from]), to, big.NewInt(value), gas, price, data), signer, keys[from])
+			require.NoError(t, err)
+			block.AddTx(tx)
+		}
+		// Independent transfers to new accounts
+		for from := range keys {
+			send(from, common.Address{byte(i + 1), byte(from + 1)}, 1000, params.TxGas, nil)
+		}
+		// A chain of transfers, every one reading the balance credited by the previous one
+		send(0, addrs[1], 500, params.TxGas, nil)
+		send(1, addrs[2], 500, params.TxGas, nil)
+		// Conflicting increments of the same slot
+		send(3, counter, 0, 100000, nil)
+		send(4, counter, 0, 100000, nil)
+		// Independent slots and logs
+		for from := range keys {
+			send(from, setter, int64(i+from), 100000, nil)
+			send(from, logger, 0, 100000, nil)
+		}
+		// A contract creation, which is always executed again
+		if i%2 == 1 {
+			tx, err := types.SignTx(types.NewContractCreation(block.TxNonce(addrs[5]), new(big.Int), 100000, price, []byte{byte(vm.STOP)}), signer, keys[5])
+			require.NoError(t, err)
+			block.AddTx(tx)
+		}
+		// A transaction reverted for lack of gas
+		send(2, counter, 0, params.TxGas+100, nil)
+	})
+
+	// The results are compared on the state of every block
+	cacheConfig := &CacheConfig{
+		ArchiveMode:         true,
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+	}
+	chain, err := NewBlockChain(gendb, cacheConfig, gspec.Config, engine, vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+
+	processor := NewStateProcessor(gspec.Config, chain, engine)
+	parent := genesis
+	merged, reexecuted := parallelMergedMeter.Count(), parallelReexecutedMeter.Count()
+	for _, block := range blocks {
+		require.True(t, canExecuteInParallel(block, new(state.StateDB), &vm.Config{}))
+		seqState, err := chain.StateAt(parent.Root())
+		require.NoError(t, err)
+		parState := seqState.Copy()
+		author, _ := engine.Author(block.Header())
+
+		seqReceipts, seqGas, _, err := processor.applyTransactions(chain, block, seqState, &author, &vm.Config{})
+		require.NoError(t, err)
+		parReceipts, parGas, err := processor.applyTransactionsParallel(chain, block, parState, &author, &vm.Config{})
+		require.NoError(t, err)
+
+		assert.Equal(t, seqGas, parGas)
+		assert.Equal(t, seqState.IntermediateRoot(true), parState.IntermediateRoot(true), "block %d", block.NumberU64())
+		seqEnc, _ := rlp.EncodeToBytes(seqReceipts)
+		parEnc, _ := rlp.EncodeToBytes(parReceipts)
+		assert.Equal(t, seqEnc, parEnc, "block %d", block.NumberU64())
+		for i := range seqReceipts {
+			assert.Equal(t, seqReceipts[i].Logs, parReceipts[i].Logs, "block %d tx %d", block.NumberU64(), i)
+		}
+		_, err = chain.InsertChain(types.Blocks{block})
+		require.NoError(t, err)
+		parent = block
+	}
+
+	// Both the merging and the re-execution are exercised
+	assert.Greater(t, parallelMergedMeter.Count(), merged)
+	assert.Greater(t, parallelReexecutedMeter.Count(), reexecuted)
+
+	// The blocks are imported with both switches, which validate the state roots
+	for _, conf := range []struct{ parallel, check bool }{{true, false}, {false, true}} {
+		db := database.NewMemoryDBManager()
+		gspec.MustCommit(db)
+		config := *cacheConfig
+		config.ParallelExecution, config.ParallelExecutionCheck = conf.parallel, conf.check
+		parChain, err := NewBlockChain(db, &config, gspec.Config, engine, vm.Config{})
+		require.NoError(t, err)
+		_, err = parChain.InsertChain(blocks)
+		assert.NoError(t, err)
+		assert.Equal(t, chain.CurrentBlock().Root(), parChain.CurrentBlock().Root())
+		parChain.Stop()
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/misc"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/rcrowley/go-metrics"
+)
+
+var (
+	poolPrefetchExecuteTimer   = metrics.NewRegisteredTimer("chain/prefetch/pool/executes", nil)
+	poolPrefetchTxMeter        = metrics.NewRegisteredMeter("chain/prefetch/pool/txs", nil)
+	poolPrefetchCoveredMeter   = metrics.NewRegisteredMeter("chain/prefetch/pool/covered", nil)
+	poolPrefetchUncoveredMeter = metrics.NewRegisteredMeter("chain/prefetch/pool/uncovered", nil)
+	poolPrefetchCoverageGauge  = metrics.NewRegisteredGauge("chain/prefetch/pool/coverage", nil)
+)
+
+// PendingTxSource provides the pending transactions prefetched for the next
+// block, like the tx pool.
+type PendingTxSource interface {
+	Pending() (map[common.Address]types.Transactions, error)
+}
+
+// StartPoolPrefetcher starts prefetching the state accessed by the next block
+// whenever a new head is set, by executing the first pending transactions of
+// the given pool on the state of the head. It does nothing unless enabled by
+// the trie node cache config.
+func (bc *BlockChain) StartPoolPrefetcher(pool PendingTxSource) {
+	config := bc.cacheConfig.TrieNodeCacheConfig
+	if config.PoolPrefetchTxs <= 0 {
+		return
+	}
+	bc.wg.Add(1)
+	go bc.poolPrefetchLoop(pool, config.PoolPrefetchTxs, config.PoolPrefetchCPUPercent)
+	logger.Info("Tx pool prefetcher is started", "txs", config.PoolPrefetchTxs, "cpu", config.PoolPrefetchCPUPercent)
+}
+
+// poolPrefetchLoop prefetches the next block of every new head, and reports the
+// share of the transactions of the block which were prefetched.
+func (bc *BlockChain) poolPrefetchLoop(pool PendingTxSource, maxTxs, cpuPercent int) {
+	defer bc.wg.Done()
+
+	headCh := make(chan ChainHeadEvent, 10)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	var (
+		interrupt  chan struct{}
+		done       chan struct{}
+		target     uint64                   // Number of the block prefetched
+		prefetched map[common.Hash]struct{} // Transactions prefetched, read once done is closed
+	)
+	stop := func() {
+		if interrupt != nil {
+			close(interrupt)
+			<-done
+			interrupt = nil
+		}
+	}
+	defer stop()
+
+	for {
+		select {
+		case ev := <-headCh:
+			stop()
+			if prefetched != nil && ev.Block.NumberU64() == target {
+				reportPoolPrefetchCoverage(ev.Block, prefetched)
+			}
+			var (
+				head = ev.Block
+				txs  = make(map[common.Hash]struct{})
+				intr = make(chan struct{})
+				ch   = make(chan struct{})
+			)
+			interrupt, done, target, prefetched = intr, ch, head.NumberU64()+1, txs
+			go func() {
+				defer close(ch)
+				defer func() {
+					if err := recover(); err != nil {
+						logger.Error("Got panic and recovered from tx pool prefetcher", "err", err)
+					}
+				}()
+				bc.prefetchPool(head, pool, maxTxs, cpuPercent, intr, txs)
+			}()
+		case <-sub.Err():
+			return
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// prefetchPool executes up to maxTxs pending transactions of the pool on the
+// state of the given head, in the order a block proposer would pick them, and
+// adds them to prefetched. The time spent executing is limited to the given
+// percentage of the elapsed time, by waiting between the transactions until
+// the prefetch is interrupted or the chain is stopped.
+func (bc *BlockChain) prefetchPool(head *types.Block, pool PendingTxSource, maxTxs, cpuPercent int, interrupt <-chan struct{}, prefetched map[common.Hash]struct{}) {
+	pending, err := pool.Pending()
+	if err != nil || len(pending) == 0 {
+		return
+	}
+	var snaps *snapshot.Tree
+	if bc.cacheConfig.TrieNodeCacheConfig.UseSnapshotForPrefetch {
+		snaps = bc.snaps
+	}
+	stateDB, err := state.New(head.Root(), bc.stateCache, snaps, &statedb.TrieOpts{Prefetching: true})
+	if err != nil {
+		logger.Debug("Failed to retrieve stateDB for tx pool prefetcher", "err", err)
+		return
+	}
+	var (
+		header = bc.nextHeader(head)
+		signer = types.MakeSigner(bc.chainConfig, header.Number)
+		txs    = types.NewTransactionsByTimeAndNonce(signer, pending)
+		vmCfg  = bc.vmConfig
+		start  = time.Now()
+	)
+	vmCfg.Prefetching = true
+	defer func() { poolPrefetchExecuteTimer.UpdateSince(start) }()
+
+	for i := 0; i < maxTxs; i++ {
+		tx := txs.Peek()
+		if tx == nil {
+			return
+		}
+		select {
+		case <-interrupt:
+			return
+		default:
+		}
+		txStart := time.Now()
+		stateDB.SetTxContext(tx.Hash(), common.Hash{}, i)
+		if err := precacheTransaction(bc.chainConfig, bc, nil, stateDB, header, tx, vmCfg); err != nil {
+			// The following transactions of the sender are likely to fail as well
+			txs.Pop()
+		} else {
+			stateDB.Finalise(true, false)
+			txs.Shift()
+		}
+		prefetched[tx.Hash()] = struct{}{}
+		poolPrefetchTxMeter.Mark(1)
+
+		if cpuPercent > 0 && cpuPercent < 100 {
+			timer := time.NewTimer(time.Since(txStart) * time.Duration(100-cpuPercent) / time.Duration(cpuPercent))
+			select {
+			case <-timer.C:
+			case <-interrupt:
+				timer.Stop()
+				return
+			case <-bc.quit:
+				timer.Stop()
+				return
+			}
+		}
+	}
+}
+
+// nextHeader returns the likely header of the block following the given head,
+// in which the pending transactions are executed.
+func (bc *BlockChain) nextHeader(head *types.Block) *types.Header {
+	header := &types.Header{
+		ParentHash: head.Hash(),
+		Number:     new(big.Int).Add(head.Number(), common.Big1),
+		Time:       new(big.Int).SetInt64(time.Now().Unix()),
+		BlockScore: head.BlockScore(),
+		Rewardbase: head.Rewardbase(),
+		BaseFee:    head.Header().BaseFee,
+	}
+	if bc.chainConfig.IsMagmaForkEnabled(header.Number) && bc.chainConfig.Governance != nil {
+		header.BaseFee = misc.NextMagmaBlockBaseFee(head.Header(), bc.chainConfig.Governance.KIP71)
+	}
+	return header
+}
+
+// reportPoolPrefetchCoverage reports how many transactions of the given block
+// were prefetched. It is the coverage of the block by the prefetcher, not the
+// hit rate of the caches warmed up by it.
+func reportPoolPrefetchCoverage(block *types.Block, prefetched map[common.Hash]struct{}) int {
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		return 0
+	}
+	covered := 0
+	for _, tx := range txs {
+		if _, ok := prefetched[tx.Hash()]; ok {
+			covered++
+		}
+	}
+	poolPrefetchCoveredMeter.Mark(int64(covered))
+	poolPrefetchUncoveredMeter.Mark(int64(len(txs) - covered))
+	poolPrefetchCoverageGauge.Update(int64(covered * 100 / len(txs)))
+	return covered
+}
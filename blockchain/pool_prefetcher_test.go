@@ -0,0 +1,99 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPendingTxs map[common.Address]types.Transactions
+
+func (p testPendingTxs) Pending() (map[common.Address]types.Transactions, error) {
+	pending := make(map[common.Address]types.Transactions, len(p))
+	for addr, txs := range p {
+		pending[addr] = txs
+	}
+	return pending, nil
+}
+
+func TestPoolPrefetcher(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{address: {Balance: big.NewInt(params.KLAY)}}}
+		db      = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSignerForChainID(gspec.Config.ChainID)
+		engine  = gxhash.NewFaker()
+	)
+	cacheConfig := &CacheConfig{
+		ArchiveMode:         true,
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+	}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, engine, vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+
+	var txs types.Transactions
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{byte(nonce + 1)}, big.NewInt(1), params.TxGas, big.NewInt(1), nil), signer, key)
+		require.NoError(t, err)
+		txs = append(txs, tx)
+	}
+	pool := testPendingTxs{address: txs}
+
+	// The number of transactions executed is capped
+	prefetched := make(map[common.Hash]struct{})
+	chain.prefetchPool(genesis, pool, 3, 100, make(chan struct{}), prefetched)
+	assert.Len(t, prefetched, 3)
+	for _, tx := range txs[:3] {
+		assert.Contains(t, prefetched, tx.Hash())
+	}
+
+	// An interrupted prefetch stops at once
+	interrupt, interrupted := make(chan struct{}), make(map[common.Hash]struct{})
+	close(interrupt)
+	chain.prefetchPool(genesis, pool, 3, 100, interrupt, interrupted)
+	assert.Empty(t, interrupted)
+
+	// The coverage is the share of the transactions of the next block which were prefetched
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, func(_ int, block *BlockGen) {
+		for _, tx := range txs {
+			block.AddTx(tx)
+		}
+	})
+	assert.Equal(t, 3, reportPoolPrefetchCoverage(blocks[0], prefetched))
+
+	header := chain.nextHeader(genesis)
+	assert.Equal(t, uint64(1), header.Number.Uint64())
+	assert.Equal(t, genesis.Hash(), header.ParentHash)
+}
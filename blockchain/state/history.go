@@ -0,0 +1,166 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/rcrowley/go-metrics"
+)
+
+var (
+	ErrStateHistoryIncomplete = errors.New("state is not covered by the state history index")
+	errStateHistoryReadOnly   = errors.New("state history is read-only")
+
+	stateHistoryReadMeter = metrics.NewRegisteredMeter("state/history/reads", nil)
+	stateHistoryMissMeter = metrics.NewRegisteredMeter("state/history/misses", nil)
+)
+
+// historyDB is a Database serving the state of a block from the state history
+// index instead of the tries. The tries it opens resolve every key with a point
+// read of the index, and keep the updates in memory.
+type historyDB struct {
+	Database
+	diskDB database.DBManager
+	number uint64
+	tail   uint64
+}
+
+// NewHistoryDatabase returns a Database serving the state of the given block
+// from the state history index. The block must be in the contiguous range of
+// indexed blocks.
+func NewHistoryDatabase(db Database, number uint64) (Database, error) {
+	diskDB := db.TrieDB().DiskDB()
+	tail, ok := diskDB.ReadStateHistoryTail()
+	if !ok || number < tail || diskDB.ReadStateHistoryIndex(number) == (common.Hash{}) {
+		return nil, ErrStateHistoryIncomplete
+	}
+	return &historyDB{Database: db, diskDB: diskDB, number: number, tail: tail}, nil
+}
+
+// OpenTrie opens the account trie of the block.
+func (db *historyDB) OpenTrie(root common.Hash, opts *statedb.TrieOpts) (Trie, error) {
+	return &historyTrie{db: db, root: root.ExtendZero(), dirties: make(map[string][]byte)}, nil
+}
+
+// OpenStorageTrie opens the storage trie of the account given as the owner.
+func (db *historyDB) OpenStorageTrie(root common.ExtHash, opts *statedb.TrieOpts) (Trie, error) {
+	if opts == nil {
+		return nil, errors.New("state history storage trie without owner")
+	}
+	return &historyTrie{db: db, owner: opts.Owner, storage: true, root: root, dirties: make(map[string][]byte)}, nil
+}
+
+// CopyTrie returns an independent copy of the given trie.
+func (db *historyDB) CopyTrie(t Trie) Trie {
+	if t, ok := t.(*historyTrie); ok {
+		return t.copy()
+	}
+	return db.Database.CopyTrie(t)
+}
+
+// checkChange returns the value of a change read from the index. An account
+// or slot not changed since the start of the index is only known if the index
+// starts at the genesis block.
+func (db *historyDB) checkChange(value []byte, changed uint64, ok bool) ([]byte, error) {
+	stateHistoryReadMeter.Mark(1)
+	if (!ok && db.tail > 0) || (ok && changed < db.tail) {
+		stateHistoryMissMeter.Mark(1)
+		return nil, ErrStateHistoryIncomplete
+	}
+	return value, nil
+}
+
+// historyTrie is an account or storage trie backed by the state history index.
+type historyTrie struct {
+	db      *historyDB
+	owner   common.Hash // Hash of the account of a storage trie
+	storage bool
+	root    common.ExtHash
+	dirties map[string][]byte // Updates, which are never written
+}
+
+func (t *historyTrie) copy() *historyTrie {
+	cpy := *t
+	cpy.dirties = make(map[string][]byte, len(t.dirties))
+	for k, v := range t.dirties {
+		cpy.dirties[k] = v
+	}
+	return &cpy
+}
+
+func (t *historyTrie) GetKey([]byte) []byte { return nil }
+
+func (t *historyTrie) TryGet(key []byte) ([]byte, error) {
+	if value, ok := t.dirties[string(key)]; ok {
+		return value, nil
+	}
+	keyHash := crypto.Keccak256Hash(key)
+	if !t.storage {
+		return t.db.checkChange(t.db.diskDB.ReadStateHistoryAccount(keyHash, t.db.number))
+	}
+	if common.EmptyExtHash(t.root) || t.root.Unextend() == emptyRoot {
+		return nil, nil
+	}
+	value, changed, ok := t.db.diskDB.ReadStateHistoryStorage(t.owner, keyHash, t.db.number)
+	// The slots changed before the storage was wiped are empty
+	if destructed, wiped := t.db.diskDB.ReadStateHistoryDestruct(t.owner, t.db.number); wiped && (!ok || destructed > changed) {
+		return nil, nil
+	}
+	return t.db.checkChange(value, changed, ok)
+}
+
+func (t *historyTrie) TryUpdate(key, value []byte) error {
+	t.dirties[string(key)] = common.CopyBytes(value)
+	return nil
+}
+
+func (t *historyTrie) TryUpdateWithKeys(key, hashKey, hexKey, value []byte) error {
+	return t.TryUpdate(key, value)
+}
+
+func (t *historyTrie) TryDelete(key []byte) error {
+	t.dirties[string(key)] = nil
+	return nil
+}
+
+// Hash returns the root of the trie in the block. The updates are not hashed.
+func (t *historyTrie) Hash() common.Hash { return t.root.Unextend() }
+
+func (t *historyTrie) HashExt() common.ExtHash { return t.root }
+
+func (t *historyTrie) Commit(onleaf statedb.LeafCallback) (common.Hash, error) {
+	return common.Hash{}, errStateHistoryReadOnly
+}
+
+func (t *historyTrie) CommitExt(onleaf statedb.LeafCallback) (common.ExtHash, error) {
+	return common.ExtHash{}, errStateHistoryReadOnly
+}
+
+// NodeIterator returns an iterator of an empty trie, the index has no nodes.
+func (t *historyTrie) NodeIterator(startKey []byte) statedb.NodeIterator {
+	return new(statedb.Trie).NodeIterator(startKey)
+}
+
+func (t *historyTrie) Prove(key []byte, fromLevel uint, proofDb database.DBManager) error {
+	return fmt.Errorf("cannot prove %x: %v", key, errStateHistoryReadOnly)
+}
@@ -126,7 +126,13 @@ func (it *NodeIterator) step() error {
 	obj := serializer.GetAccount()
 
 	if pa := account.GetProgramAccount(obj); pa != nil {
-		dataTrie, err := it.state.db.OpenStorageTrie(pa.GetStorageRoot(), nil)
+		// The owner is only needed by the path scheme, the leaf key of an
+		// iterator started in the middle of the trie is incomplete.
+		var opts *statedb.TrieOpts
+		if it.state.db.TrieDB().Scheme() == statedb.PathScheme {
+			opts = &statedb.TrieOpts{Owner: common.BytesToHash(it.stateIt.LeafKey())}
+		}
+		dataTrie, err := it.state.db.OpenStorageTrie(pa.GetStorageRoot(), opts)
 		if err != nil {
 			return err
 		}
@@ -23,6 +23,7 @@ package state
 import (
 	"math/big"
 
+	"github.com/klaytn/klaytn/blockchain/types/account"
 	"github.com/klaytn/klaytn/common"
 )
 
@@ -120,6 +121,10 @@ type (
 		account            *common.Address
 		prevcode, prevhash []byte
 	}
+	accountChange struct {
+		account *common.Address
+		prev    account.Account
+	}
 
 	// Changes to other state values.
 	refundChange struct {
@@ -162,7 +167,7 @@ func (ch createObjectChange) dirtied() *common.Address {
 
 func (ch resetObjectChange) revert(s *StateDB) {
 	s.setStateObject(ch.prev)
-	if !ch.prevdestruct && s.snap != nil {
+	if !ch.prevdestruct && s.trackDiffs() {
 		delete(s.snapDestructs, ch.prev.addrHash)
 	}
 }
@@ -216,6 +221,14 @@ func (ch codeChange) dirtied() *common.Address {
 	return ch.account
 }
 
+func (ch accountChange) revert(s *StateDB) {
+	s.getStateObject(*ch.account).account = ch.prev
+}
+
+func (ch accountChange) dirtied() *common.Address {
+	return ch.account
+}
+
 func (ch storageChange) revert(s *StateDB) {
 	s.getStateObject(*ch.account).setState(ch.key, ch.prevalue)
 }
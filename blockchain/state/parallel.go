@@ -0,0 +1,196 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// TxAccesses records the state accessed by the transactions executed on a
+// StateDB, for the conflict detection of the parallel executor.
+type TxAccesses struct {
+	Accounts map[common.Address]struct{}                 // Accounts read
+	Balances map[common.Address]struct{}                 // Accounts whose balance was changed, read or not
+	Slots    map[common.Address]map[common.Hash]struct{} // Storage slots read or written
+
+	fakeStorage bool // Set if a storage was replaced, which cannot be replayed
+	blind       bool // Set while a balance is changed, which is not a read of the account
+}
+
+// NewTxAccesses returns an empty access record.
+func NewTxAccesses() *TxAccesses {
+	return &TxAccesses{
+		Accounts: make(map[common.Address]struct{}),
+		Balances: make(map[common.Address]struct{}),
+		Slots:    make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+func (a *TxAccesses) readAccount(addr common.Address) {
+	if !a.blind {
+		a.Accounts[addr] = struct{}{}
+	}
+}
+
+func (a *TxAccesses) readSlot(addr common.Address, key common.Hash) {
+	slots := a.Slots[addr]
+	if slots == nil {
+		slots = make(map[common.Hash]struct{})
+		a.Slots[addr] = slots
+	}
+	slots[key] = struct{}{}
+}
+
+// RecordAccesses starts recording the state accessed through s in the given
+// record, or stops recording if it is nil.
+func (s *StateDB) RecordAccesses(accesses *TxAccesses) {
+	s.accesses = accesses
+}
+
+// getOrNewBalanceObject returns the object of an account whose balance is about
+// to be changed. The balance change is recorded, but not as a read of the
+// account, so that the fees paid to the same account by the transactions of a
+// block do not conflict.
+func (s *StateDB) getOrNewBalanceObject(addr common.Address) *stateObject {
+	if s.accesses == nil {
+		return s.GetOrNewStateObject(addr)
+	}
+	s.accesses.blind = true
+	obj := s.GetOrNewStateObject(addr)
+	s.accesses.blind = false
+	s.accesses.Balances[addr] = struct{}{}
+	return obj
+}
+
+// TxWrites is the change set of transactions executed on a copy of a state,
+// made of the changes which can be replayed with the setters of StateDB.
+type TxWrites struct {
+	Balances map[common.Address]*big.Int // Balance differences
+	Nonces   map[common.Address]uint64
+	Storage  map[common.Address]map[common.Hash]common.Hash
+}
+
+// TxWrites returns the change set of the transactions executed on s since it
+// was copied, given orig, another copy of the same state. The accesses of the
+// transactions must have been recorded. It returns false if the transactions
+// made a change which cannot be replayed: creating a contract, deleting an
+// account, or changing anything else than balances, nonces and storage slots.
+func (s *StateDB) TxWrites(orig *StateDB) (*TxWrites, bool) {
+	if s.accesses == nil || s.accesses.fakeStorage {
+		return nil, false
+	}
+	writes := &TxWrites{
+		Balances: make(map[common.Address]*big.Int),
+		Nonces:   make(map[common.Address]uint64),
+		Storage:  make(map[common.Address]map[common.Hash]common.Hash),
+	}
+	for addr := range s.stateObjectsDirty {
+		obj := s.stateObjects[addr]
+		if obj == nil {
+			continue
+		}
+		if obj.deleted || obj.selfDestructed {
+			if !obj.selfDestructed && orig.getStateObject(addr) == nil {
+				continue // A new empty account which was removed at once
+			}
+			return nil, false
+		}
+		var prev account.Account
+		if prevObj := orig.getStateObject(addr); prevObj != nil {
+			prev = prevObj.account
+		} else {
+			// A new account can only be an externally owned account
+			// created by a balance change.
+			prev, _ = account.NewAccountWithType(account.ExternallyOwnedAccountType)
+		}
+		if !sameAccountFields(prev, obj.account) {
+			return nil, false
+		}
+		if delta := new(big.Int).Sub(obj.account.GetBalance(), prev.GetBalance()); delta.Sign() != 0 {
+			writes.Balances[addr] = delta
+		}
+		if nonce := obj.account.GetNonce(); nonce != prev.GetNonce() {
+			writes.Nonces[addr] = nonce
+		}
+	}
+	for addr, keys := range s.accesses.Slots {
+		obj := s.stateObjects[addr]
+		if obj == nil || obj.deleted {
+			continue
+		}
+		prevObj := orig.getStateObject(addr)
+		for key := range keys {
+			var (
+				value = obj.GetState(s.db, key)
+				prev  common.Hash
+			)
+			if prevObj != nil {
+				prev = prevObj.GetState(orig.db, key)
+			}
+			if value == prev {
+				continue
+			}
+			if writes.Storage[addr] == nil {
+				writes.Storage[addr] = make(map[common.Hash]common.Hash)
+			}
+			writes.Storage[addr][key] = value
+		}
+	}
+	return writes, true
+}
+
+// sameAccountFields returns true if the given accounts only differ by their
+// balances and nonces.
+func sameAccountFields(prev, cur account.Account) bool {
+	cpy := cur.DeepCopy()
+	cpy.SetBalance(prev.GetBalance())
+	cpy.SetNonce(prev.GetNonce())
+
+	prevEnc, err := rlp.EncodeToBytes(account.NewAccountSerializerWithAccount(prev))
+	if err != nil {
+		return false
+	}
+	curEnc, err := rlp.EncodeToBytes(account.NewAccountSerializerWithAccount(cpy))
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(prevEnc, curEnc)
+}
+
+// ApplyTxWrites replays the given change set on the state.
+func (s *StateDB) ApplyTxWrites(writes *TxWrites) {
+	for addr, delta := range writes.Balances {
+		if delta.Sign() > 0 {
+			s.AddBalance(addr, delta)
+		} else {
+			s.SubBalance(addr, new(big.Int).Neg(delta))
+		}
+	}
+	for addr, nonce := range writes.Nonces {
+		s.SetNonce(addr, nonce)
+	}
+	for addr, slots := range writes.Storage {
+		for key, value := range slots {
+			s.SetState(addr, key, value)
+		}
+	}
+}
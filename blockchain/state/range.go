@@ -0,0 +1,210 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// RangeIterator iterates the accounts or the storage slots of a state in the
+// order of their hashes, from the snapshot or from the trie.
+type RangeIterator interface {
+	Next() bool
+	Hash() common.Hash
+	Value() []byte // Trie value of the account or the slot
+	Error() error
+	Release()
+}
+
+type snapAccountRangeIterator struct{ snapshot.AccountIterator }
+
+func (it snapAccountRangeIterator) Value() []byte { return it.Account() }
+
+type snapStorageRangeIterator struct{ snapshot.StorageIterator }
+
+func (it snapStorageRangeIterator) Value() []byte { return it.Slot() }
+
+type trieRangeIterator struct{ it *statedb.Iterator }
+
+// NewTrieRangeIterator returns a RangeIterator of the given trie, starting at
+// the given key.
+func NewTrieRangeIterator(tr Trie, start []byte) RangeIterator {
+	return &trieRangeIterator{it: statedb.NewIterator(tr.NodeIterator(start))}
+}
+
+func (it *trieRangeIterator) Next() bool        { return it.it.Next() }
+func (it *trieRangeIterator) Hash() common.Hash { return common.BytesToHash(it.it.Key) }
+func (it *trieRangeIterator) Value() []byte     { return it.it.Value }
+func (it *trieRangeIterator) Error() error      { return it.it.Err }
+func (it *trieRangeIterator) Release()          {}
+
+// snapCovers returns true if the snapshot serves the state, which is the case
+// if the state has a snapshot layer and was not modified.
+func (s *StateDB) snapCovers() bool {
+	return s.snap != nil && len(s.stateObjectsDirty) == 0 && s.journal.length() == 0 && s.snap.Root() == s.trie.Hash()
+}
+
+// SnapshotStorageIterator returns an iterator of the storage slots of the given
+// account from the snapshot, starting at the given slot hash. It returns false
+// if the snapshot does not serve the state.
+func (s *StateDB) SnapshotStorageIterator(addr common.Address, start common.Hash) (RangeIterator, bool) {
+	if !s.snapCovers() {
+		return nil, false
+	}
+	it, err := s.snaps.StorageIterator(s.snap.Root(), crypto.Keccak256Hash(addr[:]), start)
+	if err != nil {
+		return nil, false
+	}
+	return snapStorageRangeIterator{it}, true
+}
+
+// RangeConfig selects the accounts and the content of an account range.
+type RangeConfig struct {
+	Start       common.Hash // Hash of the first account
+	Max         int         // Maximum number of accounts, no limit if 0
+	SkipCode    bool
+	SkipStorage bool
+}
+
+// RangeAccount is an account of an account range.
+type RangeAccount struct {
+	Address  *common.Address             `json:"address,omitempty"` // Unset if the preimage is unknown
+	Balance  string                      `json:"balance"`
+	Nonce    uint64                      `json:"nonce"`
+	Root     common.Hash                 `json:"root"`
+	CodeHash hexutil.Bytes               `json:"codeHash"`
+	Code     hexutil.Bytes               `json:"code,omitempty"`
+	Storage  map[common.Hash]common.Hash `json:"storage,omitempty"` // Keyed by the hash of the slot
+}
+
+// AccountRange is a page of the accounts of a state, keyed by the hash of
+// their address.
+type AccountRange struct {
+	Root     common.Hash                  `json:"root"`
+	Accounts map[common.Hash]RangeAccount `json:"accounts"`
+	Next     *common.Hash                 `json:"next,omitempty"` // First account of the next page
+}
+
+// AccountRange returns the accounts of the state selected by the given config.
+// The accounts are read from the snapshot if it serves the state, or else from
+// the tries, which do not include the changes not hashed yet.
+func (s *StateDB) AccountRange(conf *RangeConfig) (*AccountRange, error) {
+	var (
+		root    = s.trie.Hash()
+		it      RangeIterator
+		useSnap bool
+	)
+	if s.snapCovers() {
+		if snapIt, err := s.snaps.AccountIterator(root, conf.Start); err == nil {
+			it, useSnap = snapAccountRangeIterator{snapIt}, true
+		}
+	}
+	if it == nil {
+		it = NewTrieRangeIterator(s.trie, conf.Start[:])
+	}
+	defer it.Release()
+
+	result := &AccountRange{Root: root, Accounts: make(map[common.Hash]RangeAccount)}
+	for it.Next() {
+		hash := it.Hash()
+		if conf.Max > 0 && len(result.Accounts) >= conf.Max {
+			result.Next = &hash
+			break
+		}
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(it.Value(), serializer); err != nil {
+			return nil, fmt.Errorf("invalid account %x: %v", hash, err)
+		}
+		data := serializer.GetAccount()
+		acc := RangeAccount{
+			Balance:  data.GetBalance().String(),
+			Nonce:    data.GetNonce(),
+			Root:     emptyRoot,
+			CodeHash: emptyCodeHash,
+		}
+		if preimage := s.trie.GetKey(hash[:]); preimage != nil {
+			addr := common.BytesToAddress(preimage)
+			acc.Address = &addr
+		}
+		if pa := account.GetProgramAccount(data); pa != nil {
+			acc.Root = pa.GetStorageRoot().Unextend()
+			acc.CodeHash = pa.GetCodeHash()
+			if !conf.SkipCode && !bytes.Equal(acc.CodeHash, emptyCodeHash) {
+				code, err := s.db.ContractCode(common.BytesToHash(acc.CodeHash))
+				if err != nil {
+					return nil, err
+				}
+				acc.Code = code
+			}
+			if !conf.SkipStorage && acc.Root != emptyRoot {
+				storage, err := s.rangeStorage(root, hash, pa.GetStorageRoot(), useSnap)
+				if err != nil {
+					return nil, err
+				}
+				acc.Storage = storage
+			}
+		}
+		result.Accounts[hash] = acc
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// rangeStorage returns the whole storage of an account of an account range.
+func (s *StateDB) rangeStorage(root, accHash common.Hash, storageRoot common.ExtHash, useSnap bool) (map[common.Hash]common.Hash, error) {
+	var it RangeIterator
+	if useSnap {
+		snapIt, err := s.snaps.StorageIterator(root, accHash, common.Hash{})
+		if err != nil {
+			return nil, err
+		}
+		it = snapStorageRangeIterator{snapIt}
+	} else {
+		opts := &statedb.TrieOpts{}
+		if s.trieOpts != nil {
+			*opts = *s.trieOpts
+		}
+		opts.Owner = accHash
+		tr, err := s.db.OpenStorageTrie(storageRoot, opts)
+		if err != nil {
+			return nil, err
+		}
+		it = NewTrieRangeIterator(tr, nil)
+	}
+	defer it.Release()
+
+	storage := make(map[common.Hash]common.Hash)
+	for it.Next() {
+		_, content, _, err := rlp.Split(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		storage[it.Hash()] = common.BytesToHash(content)
+	}
+	return storage, it.Error()
+}
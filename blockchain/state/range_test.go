@@ -0,0 +1,106 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectAccountRange reads the whole state page by page.
+func collectAccountRange(t *testing.T, s *StateDB, conf RangeConfig) (map[common.Hash]RangeAccount, int) {
+	accounts, pages := make(map[common.Hash]RangeAccount), 0
+	for {
+		result, err := s.AccountRange(&conf)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(result.Accounts), conf.Max)
+		for hash, acc := range result.Accounts {
+			assert.NotContains(t, accounts, hash)
+			accounts[hash] = acc
+		}
+		pages++
+		if result.Next == nil {
+			return accounts, pages
+		}
+		conf.Start = *result.Next
+	}
+}
+
+func TestAccountRange(t *testing.T) {
+	dbm := database.NewMemoryDBManager()
+	db := NewDatabase(dbm)
+	s, _ := New(common.Hash{}, db, nil, nil)
+
+	contract := common.HexToAddress("0xc0de")
+	s.CreateSmartContractAccount(contract, params.CodeFormatEVM, params.Rules{})
+	s.SetCode(contract, []byte{0x60, 0x01, 0x60, 0x00, 0x55})
+	for i := byte(1); i <= 5; i++ {
+		s.SetState(contract, common.Hash{i}, common.Hash{0xff, i})
+	}
+	for i := int64(1); i <= 10; i++ {
+		s.AddBalance(common.BigToAddress(big.NewInt(i)), big.NewInt(i))
+	}
+	root, err := s.Commit(false)
+	require.NoError(t, err)
+	require.NoError(t, db.TrieDB().Commit(root, false, 0))
+
+	snaps, err := snapshot.New(dbm, db.TrieDB(), 16, root, false, true, false)
+	require.NoError(t, err)
+
+	trieState, _ := New(root, db, nil, nil)
+	snapState, _ := New(root, db, snaps, nil)
+	assert.False(t, trieState.snapCovers())
+	assert.True(t, snapState.snapCovers())
+
+	want, pages := collectAccountRange(t, trieState, RangeConfig{Max: 4})
+	assert.Len(t, want, 11)
+	assert.Equal(t, 3, pages)
+
+	have, pages := collectAccountRange(t, snapState, RangeConfig{Max: 4})
+	assert.Equal(t, want, have)
+	assert.Equal(t, 3, pages)
+
+	contractAcc := have[crypto.Keccak256Hash(contract[:])]
+	assert.Len(t, contractAcc.Storage, 5)
+	assert.NotEmpty(t, contractAcc.Code)
+
+	noContent, _ := collectAccountRange(t, snapState, RangeConfig{Max: 100, SkipCode: true, SkipStorage: true})
+	assert.Empty(t, noContent[crypto.Keccak256Hash(contract[:])].Code)
+	assert.Empty(t, noContent[crypto.Keccak256Hash(contract[:])].Storage)
+
+	// The snapshot does not serve a modified state
+	it, ok := snapState.SnapshotStorageIterator(contract, common.Hash{})
+	require.True(t, ok)
+	count := 0
+	for it.Next() {
+		count++
+	}
+	it.Release()
+	assert.Equal(t, 5, count)
+
+	snapState.SetState(contract, common.Hash{0x06}, common.Hash{0x01})
+	_, ok = snapState.SnapshotStorageIterator(contract, common.Hash{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,291 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// StateDiffAccount is the state of an account on one side of a state diff.
+type StateDiffAccount struct {
+	Balance        *hexutil.Big               `json:"balance"`
+	Nonce          hexutil.Uint64             `json:"nonce"`
+	CodeHash       common.Hash                `json:"codeHash"`
+	AccountKeyType *accountkey.AccountKeyType `json:"accountKeyType,omitempty"`
+
+	storageRoot common.ExtHash
+}
+
+// StorageDiff is the change of a storage slot.
+type StorageDiff struct {
+	Old common.Hash `json:"old"`
+	New common.Hash `json:"new"`
+}
+
+// AccountDiff is the change of an account.
+type AccountDiff struct {
+	Address *common.Address             `json:"address,omitempty"` // Unset if the preimage is unknown
+	Old     *StateDiffAccount           `json:"old"`               // Nil if the account was created
+	New     *StateDiffAccount           `json:"new"`               // Nil if the account was deleted
+	Storage map[common.Hash]StorageDiff `json:"storage,omitempty"` // Keyed by the hash of the slot
+}
+
+// StateDiff is the change set between two states, keyed by the hash of the
+// account addresses.
+type StateDiff struct {
+	ParentRoot common.Hash                  `json:"parentRoot"`
+	Root       common.Hash                  `json:"root"`
+	Snapshot   bool                         `json:"snapshot"` // Whether the diff was read from the snapshot
+	Accounts   map[common.Hash]*AccountDiff `json:"accounts"`
+}
+
+// DiffStates returns the changes of the accounts and storage slots between the
+// states of the given roots. The changes are read from the snapshot diff layer
+// of root if it lies on the layer of parentRoot, or else computed by comparing
+// the tries.
+func DiffStates(db Database, snaps *snapshot.Tree, parentRoot, root common.Hash) (*StateDiff, error) {
+	tr, err := db.OpenTrie(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	diff := &StateDiff{ParentRoot: parentRoot, Root: root}
+	if snaps != nil {
+		diff.Snapshot = diff.diffSnapshot(snaps)
+	}
+	if !diff.Snapshot {
+		if err := diff.diffTries(db, tr); err != nil {
+			return nil, err
+		}
+	}
+	for hash, acc := range diff.Accounts {
+		if len(acc.Storage) == 0 && reflect.DeepEqual(acc.Old, acc.New) {
+			delete(diff.Accounts, hash) // Same content with another extended storage root
+			continue
+		}
+		if preimage := tr.GetKey(hash[:]); preimage != nil {
+			addr := common.BytesToAddress(preimage)
+			acc.Address = &addr
+		}
+	}
+	return diff, nil
+}
+
+func (d *StateDiff) account(hash common.Hash) *AccountDiff {
+	acc := d.Accounts[hash]
+	if acc == nil {
+		acc = &AccountDiff{Storage: make(map[common.Hash]StorageDiff)}
+		d.Accounts[hash] = acc
+	}
+	return acc
+}
+
+// diffSnapshot reads the changes from the snapshot diff layer, and returns
+// false if the snapshot does not serve them.
+func (d *StateDiff) diffSnapshot(snaps *snapshot.Tree) bool {
+	destructs, accounts, storage, ok := snaps.LayerDiff(d.Root, d.ParentRoot)
+	parent := snaps.Snapshot(d.ParentRoot)
+	if !ok || parent == nil {
+		return false
+	}
+	d.Accounts = make(map[common.Hash]*AccountDiff)
+	hashes := make(map[common.Hash]struct{}, len(accounts)+len(destructs))
+	for hash := range accounts {
+		hashes[hash] = struct{}{}
+	}
+	for hash := range destructs {
+		hashes[hash] = struct{}{}
+	}
+	for hash := range hashes {
+		old, err := parent.AccountRLP(hash)
+		if err != nil {
+			return false
+		}
+		acc := d.account(hash)
+		if acc.Old, err = decodeDiffAccount(old); err != nil {
+			return false
+		}
+		if acc.New, err = decodeDiffAccount(accounts[hash]); err != nil {
+			return false
+		}
+	}
+	for accHash, slots := range storage {
+		acc := d.account(accHash)
+		for slotHash, value := range slots {
+			old, err := parent.Storage(accHash, slotHash)
+			if err != nil {
+				return false
+			}
+			if change := (StorageDiff{Old: decodeDiffSlot(old), New: decodeDiffSlot(value)}); change.Old != change.New {
+				acc.Storage[slotHash] = change
+			}
+		}
+	}
+	// The slots of a destructed account not set again are deleted
+	for accHash := range destructs {
+		if d.Accounts[accHash].Old == nil {
+			continue
+		}
+		it, err := snaps.StorageIterator(d.ParentRoot, accHash, common.Hash{})
+		if err != nil {
+			return false
+		}
+		acc := d.account(accHash)
+		for it.Next() {
+			if _, ok := storage[accHash][it.Hash()]; !ok {
+				acc.Storage[it.Hash()] = StorageDiff{Old: decodeDiffSlot(it.Slot())}
+			}
+		}
+		err = it.Error()
+		it.Release()
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// diffTries computes the changes by iterating the nodes which differ between
+// the tries of the two states.
+func (d *StateDiff) diffTries(db Database, tr Trie) error {
+	parentTr, err := db.OpenTrie(d.ParentRoot, nil)
+	if err != nil {
+		return err
+	}
+	olds, news, err := diffTrieLeaves(parentTr, tr)
+	if err != nil {
+		return err
+	}
+	d.Accounts = make(map[common.Hash]*AccountDiff)
+	for hash, value := range olds {
+		if d.account(hash).Old, err = decodeDiffAccount(value); err != nil {
+			return err
+		}
+	}
+	for hash, value := range news {
+		if d.account(hash).New, err = decodeDiffAccount(value); err != nil {
+			return err
+		}
+	}
+	for hash, acc := range d.Accounts {
+		var oldRoot, newRoot common.ExtHash
+		if acc.Old != nil {
+			oldRoot = acc.Old.storageRoot
+		}
+		if acc.New != nil {
+			newRoot = acc.New.storageRoot
+		}
+		if oldRoot.Unextend() == newRoot.Unextend() {
+			continue
+		}
+		opts := &statedb.TrieOpts{Owner: hash}
+		oldTr, err := db.OpenStorageTrie(oldRoot, opts)
+		if err != nil {
+			return err
+		}
+		newTr, err := db.OpenStorageTrie(newRoot, opts)
+		if err != nil {
+			return err
+		}
+		oldSlots, newSlots, err := diffTrieLeaves(oldTr, newTr)
+		if err != nil {
+			return err
+		}
+		for slotHash, value := range oldSlots {
+			acc.Storage[slotHash] = StorageDiff{Old: decodeDiffSlot(value)}
+		}
+		for slotHash, value := range newSlots {
+			change := acc.Storage[slotHash]
+			if change.New = decodeDiffSlot(value); change.Old == change.New {
+				delete(acc.Storage, slotHash)
+			} else {
+				acc.Storage[slotHash] = change
+			}
+		}
+	}
+	return nil
+}
+
+// diffTrieLeaves returns the leaves of each trie which are not in the other.
+func diffTrieLeaves(a, b Trie) (map[common.Hash][]byte, map[common.Hash][]byte, error) {
+	collect := func(from, other Trie) (map[common.Hash][]byte, error) {
+		diff, _ := statedb.NewDifferenceIterator(other.NodeIterator(nil), from.NodeIterator(nil))
+		it := statedb.NewIterator(diff)
+		leaves := make(map[common.Hash][]byte)
+		for it.Next() {
+			leaves[common.BytesToHash(it.Key)] = common.CopyBytes(it.Value)
+		}
+		return leaves, it.Err
+	}
+	onlyA, err := collect(a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	onlyB, err := collect(b, a)
+	if err != nil {
+		return nil, nil, err
+	}
+	return onlyA, onlyB, nil
+}
+
+// decodeDiffAccount decodes an account of a trie or a snapshot, nil meaning
+// that the account does not exist.
+func decodeDiffAccount(data []byte) (*StateDiffAccount, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	serializer := account.NewAccountSerializer()
+	if err := rlp.DecodeBytes(data, serializer); err != nil {
+		return nil, fmt.Errorf("invalid account: %v", err)
+	}
+	acc := serializer.GetAccount()
+	result := &StateDiffAccount{
+		Balance:  (*hexutil.Big)(new(big.Int).Set(acc.GetBalance())),
+		Nonce:    hexutil.Uint64(acc.GetNonce()),
+		CodeHash: common.BytesToHash(emptyCodeHash),
+	}
+	if pa := account.GetProgramAccount(acc); pa != nil {
+		result.CodeHash = common.BytesToHash(pa.GetCodeHash())
+		result.storageRoot = pa.GetStorageRoot()
+	}
+	if ak, ok := acc.(account.AccountWithKey); ok && ak.GetKey() != nil {
+		keyType := ak.GetKey().Type()
+		result.AccountKeyType = &keyType
+	}
+	return result, nil
+}
+
+// decodeDiffSlot decodes a storage slot value of a trie or a snapshot.
+func decodeDiffSlot(data []byte) common.Hash {
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	_, content, _, err := rlp.Split(data)
+	if err != nil {
+		return common.Hash{}
+	}
+	return common.BytesToHash(content)
+}
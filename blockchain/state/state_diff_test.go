@@ -0,0 +1,114 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStates(t *testing.T) {
+	var (
+		dbm      = database.NewMemoryDBManager()
+		db       = NewDatabase(dbm)
+		eoa      = common.HexToAddress("0xaaaa")
+		removed  = common.HexToAddress("0xbbbb")
+		contract = common.HexToAddress("0xc0de")
+		created  = common.HexToAddress("0xdddd")
+	)
+	s, _ := New(common.Hash{}, db, nil, nil)
+	s.AddBalance(eoa, big.NewInt(10))
+	for _, addr := range []common.Address{contract, removed} {
+		s.CreateSmartContractAccount(addr, params.CodeFormatEVM, params.Rules{})
+		s.SetCode(addr, []byte{0x60, 0x01, 0x60, 0x00, 0x55})
+		for i := byte(1); i <= 3; i++ {
+			s.SetState(addr, common.Hash{i}, common.Hash{0xff, i})
+		}
+	}
+	parentRoot, err := s.Commit(false)
+	require.NoError(t, err)
+	require.NoError(t, db.TrieDB().Commit(parentRoot, false, 0))
+
+	snaps, err := snapshot.New(dbm, db.TrieDB(), 16, parentRoot, false, true, false)
+	require.NoError(t, err)
+
+	s, _ = New(parentRoot, db, snaps, nil)
+	s.AddBalance(eoa, big.NewInt(1))
+	s.SetNonce(eoa, 1)
+	s.AddBalance(created, big.NewInt(7))
+	s.SetState(contract, common.Hash{1}, common.Hash{0xee})
+	s.SetState(contract, common.Hash{2}, common.Hash{})
+	s.SetState(contract, common.Hash{4}, common.Hash{0x44})
+	s.SelfDestruct(removed)
+	s.Finalise(true, false) // As done after each transaction, recording the destruction
+	root, err := s.Commit(true)
+	require.NoError(t, err)
+
+	have, err := DiffStates(db, snaps, parentRoot, root)
+	require.NoError(t, err)
+	assert.True(t, have.Snapshot)
+
+	want, err := DiffStates(db, nil, parentRoot, root)
+	require.NoError(t, err)
+	assert.False(t, want.Snapshot)
+	have.Snapshot = false
+	assert.Equal(t, want, have)
+	assert.Len(t, have.Accounts, 4)
+
+	eoaDiff := have.Accounts[crypto.Keccak256Hash(eoa[:])]
+	require.NotNil(t, eoaDiff)
+	assert.Equal(t, big.NewInt(10), eoaDiff.Old.Balance.ToInt())
+	assert.Equal(t, big.NewInt(11), eoaDiff.New.Balance.ToInt())
+	assert.Equal(t, uint64(1), uint64(eoaDiff.New.Nonce))
+	assert.Equal(t, accountkey.AccountKeyTypeLegacy, *eoaDiff.New.AccountKeyType)
+	assert.Empty(t, eoaDiff.Storage)
+
+	createdDiff := have.Accounts[crypto.Keccak256Hash(created[:])]
+	require.NotNil(t, createdDiff)
+	assert.Nil(t, createdDiff.Old)
+	assert.Equal(t, big.NewInt(7), createdDiff.New.Balance.ToInt())
+
+	contractDiff := have.Accounts[crypto.Keccak256Hash(contract[:])]
+	require.NotNil(t, contractDiff)
+	assert.Equal(t, contractDiff.Old.CodeHash, contractDiff.New.CodeHash)
+	assert.Equal(t, map[common.Hash]StorageDiff{
+		crypto.Keccak256Hash(common.Hash{1}.Bytes()): {Old: common.Hash{0xff, 1}, New: common.Hash{0xee}},
+		crypto.Keccak256Hash(common.Hash{2}.Bytes()): {Old: common.Hash{0xff, 2}},
+		crypto.Keccak256Hash(common.Hash{4}.Bytes()): {New: common.Hash{0x44}},
+	}, contractDiff.Storage)
+
+	removedDiff := have.Accounts[crypto.Keccak256Hash(removed[:])]
+	require.NotNil(t, removedDiff)
+	assert.NotNil(t, removedDiff.Old)
+	assert.Nil(t, removedDiff.New)
+	assert.Len(t, removedDiff.Storage, 3)
+
+	// The states of unrelated layers are compared through the tries
+	reverse, err := DiffStates(db, snaps, root, parentRoot)
+	require.NoError(t, err)
+	assert.False(t, reverse.Snapshot)
+	assert.Len(t, reverse.Accounts, 4)
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/kerrors"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/statedb"
 )
 
 var emptyCodeHash = crypto.Keccak256(nil)
@@ -167,7 +168,12 @@ func (s *stateObject) touch() {
 }
 
 func (s *stateObject) openStorageTrie(hash common.ExtHash, db Database) (Trie, error) {
-	return db.OpenStorageTrie(hash, s.db.trieOpts)
+	opts := &statedb.TrieOpts{}
+	if s.db.trieOpts != nil {
+		*opts = *s.db.trieOpts
+	}
+	opts.Owner = s.addrHash
+	return db.OpenStorageTrie(hash, opts)
 }
 
 func (s *stateObject) getStorageTrie(db Database) Trie {
@@ -322,6 +328,22 @@ func (s *stateObject) IsProgramAccount() bool {
 	return account.GetProgramAccount(s.account) != nil
 }
 
+// hasStorage returns true if the account has any storage slot, either committed
+// to its storage root or dirty in the current block.
+func (s *stateObject) hasStorage() bool {
+	for _, value := range s.dirtyStorage {
+		if value != (common.Hash{}) {
+			return true
+		}
+	}
+	acc := account.GetProgramAccount(s.account)
+	if acc == nil {
+		return false
+	}
+	root := acc.GetStorageRoot()
+	return !common.EmptyExtHash(root) && root.Unextend() != emptyRoot
+}
+
 func (s *stateObject) GetKey() accountkey.AccountKey {
 	if ak := account.GetAccountWithKey(s.account); ak != nil {
 		return ak.GetKey()
@@ -364,8 +386,8 @@ func (s *stateObject) updateStorageTrie(db Database) Trie {
 			v, _ = rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
 			s.setError(tr.TryUpdate(key[:], v))
 		}
-		// If state snapshotting is active, cache the data til commit
-		if s.db.snap != nil {
+		// If state snapshotting or history is active, cache the data til commit
+		if s.db.trackDiffs() {
 			if storage == nil {
 				// Retrieve the old storage map, if available, create a new one otherwise
 				if storage = s.db.snapStorage[s.addrHash]; storage == nil {
@@ -502,6 +524,9 @@ func (s *stateObject) Code(db Database) []byte {
 	if err != nil {
 		s.setError(fmt.Errorf("can't load code hash %x: %v", s.CodeHash(), err))
 	}
+	if s.db.witness != nil {
+		s.db.witness.AddCode(code)
+	}
 	s.code = code
 	return code
 }
@@ -516,6 +541,10 @@ func (s *stateObject) CodeSize(db Database) int {
 	if bytes.Equal(s.CodeHash(), emptyCodeHash) {
 		return 0
 	}
+	// A stateless execution needs the code to know its size
+	if s.db.witness != nil {
+		return len(s.Code(db))
+	}
 	size, err := db.ContractCodeSize(common.BytesToHash(s.CodeHash()))
 	if err != nil {
 		s.setError(fmt.Errorf("can't load code size %x: %v", s.CodeHash(), err))
@@ -21,6 +21,7 @@
 package state
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"sort"
@@ -36,6 +37,7 @@ import (
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
 )
 
@@ -63,6 +65,9 @@ type StateDB struct {
 	db       Database
 	trie     Trie
 	trieOpts *statedb.TrieOpts
+	witness  *Witness // Records the state read, if not nil
+
+	accesses *TxAccesses // Records the state accessed for the parallel executor, if not nil
 
 	snaps         *snapshot.Tree
 	snap          snapshot.Snapshot
@@ -70,6 +75,11 @@ type StateDB struct {
 	snapAccounts  map[common.Hash][]byte
 	snapStorage   map[common.Hash]map[common.Hash][]byte
 
+	// If captureHistory is set, the changes of the state are collected as for
+	// the snapshot, and Commit turns them into the change set of the block.
+	captureHistory bool
+	historyDiff    *database.StateHistoryDiff
+
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects             map[common.Address]*stateObject
 	stateObjectsDirty        map[common.Address]struct{}
@@ -153,6 +163,23 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree, opts *statedb.Trie
 	return sdb, nil
 }
 
+// NewWithWitness creates a new state from a given trie, recording every trie
+// node and contract code read into the given witness. Snapshots are not used,
+// since the reads they serve would not be recorded.
+func NewWithWitness(root common.Hash, db Database, witness *Witness) (*StateDB, error) {
+	sdb, err := New(root, db, nil, &statedb.TrieOpts{Witness: witness})
+	if err != nil {
+		return nil, err
+	}
+	sdb.witness = witness
+	return sdb, nil
+}
+
+// Witness returns the witness recording the state read, or nil.
+func (s *StateDB) Witness() *Witness {
+	return s.witness
+}
+
 // RLockGCCachedNode locks the GC lock of CachedNode.
 func (s *StateDB) LockGCCachedNode() {
 	s.db.RLockGCCachedNode()
@@ -329,6 +356,9 @@ func (s *StateDB) GetCodeHash(addr common.Address) common.Hash {
 
 // GetState retrieves a value from the given account's storage trie.
 func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
+	if s.accesses != nil {
+		s.accesses.readSlot(addr, hash)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetState(s.db, hash)
@@ -338,6 +368,9 @@ func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 
 // GetCommittedState retrieves a value from the given account's committed storage trie.
 func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	if s.accesses != nil {
+		s.accesses.readSlot(addr, hash)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetCommittedState(s.db, hash)
@@ -426,7 +459,7 @@ func (s *StateDB) HasSelfDestructed(addr common.Address) bool {
 
 // AddBalance adds amount to the account associated with addr.
 func (s *StateDB) AddBalance(addr common.Address, amount *big.Int) {
-	stateObject := s.GetOrNewStateObject(addr)
+	stateObject := s.getOrNewBalanceObject(addr)
 	if stateObject != nil {
 		stateObject.AddBalance(amount)
 	}
@@ -434,7 +467,7 @@ func (s *StateDB) AddBalance(addr common.Address, amount *big.Int) {
 
 // SubBalance subtracts amount from the account associated with addr.
 func (s *StateDB) SubBalance(addr common.Address, amount *big.Int) {
-	stateObject := s.GetOrNewStateObject(addr)
+	stateObject := s.getOrNewBalanceObject(addr)
 	if stateObject != nil {
 		stateObject.SubBalance(amount)
 	}
@@ -472,6 +505,9 @@ func (s *StateDB) SetCode(addr common.Address, code []byte) error {
 }
 
 func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
+	if s.accesses != nil {
+		s.accesses.readSlot(addr, key)
+	}
 	stateObject := s.GetOrNewSmartContract(addr)
 	if stateObject != nil {
 		stateObject.SetState(s.db, key, value)
@@ -481,6 +517,9 @@ func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
 // SetStorage replaces the entire storage for the specified account with given
 // storage. This function should only be used for debugging.
 func (s *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	if s.accesses != nil {
+		s.accesses.fakeStorage = true
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetStorage(storage)
@@ -586,11 +625,11 @@ func (s *StateDB) updateStateObject(stateObject *stateObject) {
 		snapshotData = data
 	}
 
-	// If state snapshotting is active, cache the data til commit. Note, this
+	// If state snapshotting or history is active, cache the data til commit. Note, this
 	// update mechanism is not symmetric to the deletion, because whereas it is
 	// enough to track account updates at commit time, deletions need tracking
 	// at transaction boundary level to ensure we capture state clearing.
-	if s.snap != nil {
+	if s.trackDiffs() {
 		s.snapAccounts[stateObject.addrHash] = snapshotData
 	}
 }
@@ -621,6 +660,9 @@ func (s *StateDB) getStateObject(addr common.Address) *stateObject {
 // flag set. This is needed by the state journal to revert to the correct s-
 // destructed object instead of wiping all knowledge about the state object.
 func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
+	if s.accesses != nil {
+		s.accesses.readAccount(addr)
+	}
 	// First, check stateObjects if there is "live" object.
 	if obj := s.stateObjects[addr]; obj != nil {
 		return obj
@@ -699,7 +741,7 @@ func (s *StateDB) createObject(addr common.Address) (newobj, prev *stateObject)
 	prev = s.getDeletedStateObject(addr) // Note, prev might have been deleted, we need that!
 
 	var prevdestruct bool
-	if s.snap != nil && prev != nil {
+	if s.trackDiffs() && prev != nil {
 		_, prevdestruct = s.snapDestructs[prev.addrHash]
 		if !prevdestruct {
 			s.snapDestructs[prev.addrHash] = struct{}{}
@@ -735,7 +777,7 @@ func (s *StateDB) createObjectWithMap(addr common.Address, accountType account.A
 	prev = s.getDeletedStateObject(addr) // Note, prev might have been deleted, we need that!
 
 	var prevdestruct bool
-	if s.snap != nil && prev != nil {
+	if s.trackDiffs() && prev != nil {
 		_, prevdestruct = s.snapDestructs[prev.addrHash]
 		if !prevdestruct {
 			s.snapDestructs[prev.addrHash] = struct{}{}
@@ -803,6 +845,64 @@ func (s *StateDB) CreateSmartContractAccountWithKey(addr common.Address, humanRe
 	}
 }
 
+// SetCodeToEOA sets the code of an externally owned account as EIP-7702 does.
+// Since only a smart contract account can hold code in Klaytn, an EOA is
+// converted in place into a smart contract account keeping its key, nonce,
+// balance and human-readable flag. An empty code clears the delegation of a
+// delegated account; it turns back into an EOA only if it has no storage, and
+// otherwise stays a smart contract account with empty code so that its storage
+// is kept. An empty code does nothing to a non-program account.
+func (s *StateDB) SetCodeToEOA(addr common.Address, code []byte, r params.Rules) error {
+	stateObject := s.GetOrNewStateObject(addr)
+	if stateObject == nil {
+		return nil
+	}
+	if !stateObject.IsProgramAccount() {
+		if len(code) == 0 {
+			return nil
+		}
+		if err := s.convertAccount(stateObject, account.SmartContractAccountType, r); err != nil {
+			return err
+		}
+		return stateObject.SetCode(crypto.Keccak256Hash(code), code)
+	}
+	_, delegated := types.ParseDelegation(stateObject.Code(s.db))
+	if err := stateObject.SetCode(crypto.Keccak256Hash(code), code); err != nil {
+		return err
+	}
+	if len(code) != 0 || !delegated || stateObject.hasStorage() {
+		return nil
+	}
+	// Clear the delegation
+	return s.convertAccount(stateObject, account.ExternallyOwnedAccountType, r)
+}
+
+// convertAccount converts the account of the given state object into the given
+// type in place, keeping its nonce, balance, human-readable flag and key. Unlike
+// creating an account, the storage and the created flag of the object are left
+// as they are, and the previous account is journaled to be reverted.
+func (s *StateDB) convertAccount(stateObject *stateObject, accountType account.AccountType, r params.Rules) error {
+	values := map[account.AccountValueKeyType]interface{}{
+		account.AccountValueKeyNonce:         stateObject.account.GetNonce(),
+		account.AccountValueKeyBalance:       stateObject.account.GetBalance(),
+		account.AccountValueKeyHumanReadable: stateObject.account.GetHumanReadable(),
+		account.AccountValueKeyAccountKey:    stateObject.GetKey(),
+	}
+	if accountType == account.SmartContractAccountType {
+		values[account.AccountValueKeyCodeInfo] = params.NewCodeInfoWithRules(params.CodeFormatEVM, r)
+	}
+	acc, err := account.NewAccountWithMap(accountType, values)
+	if err != nil {
+		return err
+	}
+	s.journal.append(accountChange{
+		account: &stateObject.address,
+		prev:    stateObject.account,
+	})
+	stateObject.account = acc
+	return nil
+}
+
 func (s *StateDB) ForEachStorage(addr common.Address, cb func(key, value common.Hash) bool) {
 	so := s.getStateObject(addr)
 	if so == nil {
@@ -830,15 +930,16 @@ func (s *StateDB) ForEachStorage(addr common.Address, cb func(key, value common.
 func (s *StateDB) Copy() *StateDB {
 	// Copy all the basic fields, initialize the memory ones
 	state := &StateDB{
-		db:                s.db,
-		trie:              s.db.CopyTrie(s.trie),
-		stateObjects:      make(map[common.Address]*stateObject, len(s.journal.dirties)),
-		stateObjectsDirty: make(map[common.Address]struct{}, len(s.journal.dirties)),
-		refund:            s.refund,
-		logs:              make(map[common.Hash][]*types.Log, len(s.logs)),
-		logSize:           s.logSize,
-		preimages:         make(map[common.Hash][]byte),
-		journal:           newJournal(),
+		db:                       s.db,
+		trie:                     s.db.CopyTrie(s.trie),
+		stateObjects:             make(map[common.Address]*stateObject, len(s.journal.dirties)),
+		stateObjectsDirty:        make(map[common.Address]struct{}, len(s.journal.dirties)),
+		stateObjectsDirtyStorage: make(map[common.Address]struct{}, len(s.stateObjectsDirtyStorage)),
+		refund:                   s.refund,
+		logs:                     make(map[common.Hash][]*types.Log, len(s.logs)),
+		logSize:                  s.logSize,
+		preimages:                make(map[common.Hash][]byte),
+		journal:                  newJournal(),
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range s.journal.dirties {
@@ -861,6 +962,10 @@ func (s *StateDB) Copy() *StateDB {
 		}
 	}
 
+	for addr := range s.stateObjectsDirtyStorage {
+		state.stateObjectsDirtyStorage[addr] = struct{}{}
+	}
+
 	deepCopyLogs(s, state)
 
 	for hash, preimage := range s.preimages {
@@ -874,7 +979,8 @@ func (s *StateDB) Copy() *StateDB {
 	// to not blow up if we ever decide copy it in the middle of a transaction
 	state.accessList = s.accessList.Copy()
 	state.transientStorage = s.transientStorage.Copy()
-	if s.snaps != nil {
+	state.captureHistory = s.captureHistory
+	if s.snaps != nil || s.captureHistory {
 		// In order for the miner to be able to use and make additions
 		// to the snapshot tree, we need to copy that aswell.
 		// Otherwise, any block mined by ourselves will cause gaps in the tree,
@@ -961,11 +1067,11 @@ func (stateDB *StateDB) Finalise(deleteEmptyObjects bool, setStorageRoot bool) {
 		if so.selfDestructed || (deleteEmptyObjects && so.empty()) {
 			stateDB.deleteStateObject(so)
 
-			// If state snapshotting is active, also mark the destruction there.
+			// If state snapshotting or history is active, also mark the destruction there.
 			// Note, we can't do this only at the end of a block because multiple
 			// transactions within the same block might self destruct and then
 			// ressurrect an account; but the snapshotter needs both events.
-			if stateDB.snap != nil {
+			if stateDB.trackDiffs() {
 				stateDB.snapDestructs[so.addrHash] = struct{}{} // We need to maintain account deletions explicitly (will remain set indefinitely)
 				delete(stateDB.snapAccounts, so.addrHash)       // Clear out any previously updated account data (may be recreated via a ressurrect)
 				delete(stateDB.snapStorage, so.addrHash)        // Clear out any previously updated storage data (may be recreated via a ressurrect)
@@ -1083,6 +1189,10 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 		return nil
 	})
 
+	if s.captureHistory {
+		s.historyDiff = newStateHistoryDiff(s.snapDestructs, s.snapAccounts, s.snapStorage)
+	}
+
 	// If snapshotting is enabled, update the snapshot tree with this new version
 	if s.snap != nil {
 		if EnabledExpensive {
@@ -1103,10 +1213,74 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 		}
 		s.snap, s.snapDestructs, s.snapAccounts, s.snapStorage = nil, nil, nil, nil
 	}
+	if s.captureHistory {
+		s.snapDestructs = make(map[common.Hash]struct{})
+		s.snapAccounts = make(map[common.Hash][]byte)
+		s.snapStorage = make(map[common.Hash]map[common.Hash][]byte)
+	}
 
 	return root, err
 }
 
+// trackDiffs returns true if the changes of the state are collected, for the
+// snapshot or for the state history.
+func (s *StateDB) trackDiffs() bool {
+	return s.snap != nil || s.captureHistory
+}
+
+// CaptureHistory makes the next Commit record the change set of the state,
+// which is then returned by HistoryDiff.
+func (s *StateDB) CaptureHistory() {
+	if s.captureHistory {
+		return
+	}
+	s.captureHistory = true
+	if s.snap == nil {
+		s.snapDestructs = make(map[common.Hash]struct{})
+		s.snapAccounts = make(map[common.Hash][]byte)
+		s.snapStorage = make(map[common.Hash]map[common.Hash][]byte)
+	}
+}
+
+// HistoryDiff returns the change set recorded by the last Commit, or nil if
+// the state history is not captured.
+func (s *StateDB) HistoryDiff() *database.StateHistoryDiff {
+	return s.historyDiff
+}
+
+// newStateHistoryDiff returns the change set of the collected changes, in the
+// order of the hashes. The accounts destructed and not recreated are deleted.
+func newStateHistoryDiff(destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) *database.StateHistoryDiff {
+	diff := new(database.StateHistoryDiff)
+	for accountHash := range destructs {
+		diff.Destructs = append(diff.Destructs, accountHash)
+		if _, ok := accounts[accountHash]; !ok {
+			diff.Accounts = append(diff.Accounts, database.StateHistoryAccount{Hash: accountHash})
+		}
+	}
+	for accountHash, data := range accounts {
+		diff.Accounts = append(diff.Accounts, database.StateHistoryAccount{Hash: accountHash, Data: data})
+	}
+	for accountHash, slots := range storage {
+		for slotHash, value := range slots {
+			diff.Storage = append(diff.Storage, database.StateHistoryStorage{Account: accountHash, Slot: slotHash, Value: value})
+		}
+	}
+	sort.Slice(diff.Destructs, func(i, j int) bool {
+		return bytes.Compare(diff.Destructs[i][:], diff.Destructs[j][:]) < 0
+	})
+	sort.Slice(diff.Accounts, func(i, j int) bool {
+		return bytes.Compare(diff.Accounts[i].Hash[:], diff.Accounts[j].Hash[:]) < 0
+	})
+	sort.Slice(diff.Storage, func(i, j int) bool {
+		if c := bytes.Compare(diff.Storage[i].Account[:], diff.Storage[j].Account[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(diff.Storage[i].Slot[:], diff.Storage[j].Slot[:]) < 0
+	})
+	return diff
+}
+
 // GetTxHash returns the hash of current running transaction.
 func (s *StateDB) GetTxHash() common.Hash {
 	return s.thash
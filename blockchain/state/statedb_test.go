@@ -33,6 +33,7 @@ import (
 	"testing/quick"
 
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
@@ -832,3 +833,79 @@ func TestStateDBTransientStorage(t *testing.T) {
 		t.Fatalf("transient storage mismatch: have %x, want %x", got, value)
 	}
 }
+
+func TestSetCodeToEOA(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	rules := params.Rules{IsPrague: true}
+
+	addr := common.HexToAddress("0x7702")
+	state.CreateEOA(addr, true, accountkey.NewAccountKeyLegacy())
+	state.SetNonce(addr, 5)
+	state.AddBalance(addr, big.NewInt(100))
+
+	check := func(isProgram bool, code []byte) {
+		assert.Equal(t, isProgram, state.IsProgramAccount(addr))
+		assert.Equal(t, code, state.GetCode(addr))
+		assert.Equal(t, uint64(5), state.GetNonce(addr))
+		assert.Equal(t, big.NewInt(100), state.GetBalance(addr))
+		assert.True(t, state.GetKey(addr).Type().IsLegacyAccountKey())
+		assert.True(t, state.getStateObject(addr).account.GetHumanReadable())
+	}
+
+	// An empty code does nothing to an EOA.
+	assert.NoError(t, state.SetCodeToEOA(addr, nil, rules))
+	check(false, nil)
+
+	// A delegation converts the EOA into a program account.
+	delegation := types.AddressToDelegation(common.HexToAddress("0xdead"))
+	assert.NoError(t, state.SetCodeToEOA(addr, delegation, rules))
+	check(true, delegation)
+
+	// Clearing the delegation converts it back into an EOA.
+	snapshot := state.Snapshot()
+	assert.NoError(t, state.SetCodeToEOA(addr, nil, rules))
+	check(false, nil)
+
+	state.RevertToSnapshot(snapshot)
+	check(true, delegation)
+
+	// An EOA is converted in place, so it is not regarded as created in the transaction.
+	state.Finalise(true, true)
+	assert.NoError(t, state.SetCodeToEOA(addr, nil, rules))
+	assert.NoError(t, state.SetCodeToEOA(addr, delegation, rules))
+	assert.False(t, state.getStateObject(addr).created)
+}
+
+func TestSetCodeToEOAKeepStorage(t *testing.T) {
+	db := NewDatabase(database.NewMemoryDBManager())
+	state, _ := New(common.Hash{}, db, nil, nil)
+	rules := params.Rules{IsPrague: true}
+
+	addr := common.HexToAddress("0x7702")
+	key, value := common.HexToHash("0x1"), common.HexToHash("0x2a")
+	delegation := types.AddressToDelegation(common.HexToAddress("0xdead"))
+	state.CreateEOA(addr, false, accountkey.NewAccountKeyLegacy())
+	state.SetNonce(addr, 1)
+	assert.NoError(t, state.SetCodeToEOA(addr, delegation, rules))
+	state.SetState(addr, key, value)
+
+	root, err := state.Commit(true)
+	assert.NoError(t, err)
+	state, _ = New(root, db, nil, nil)
+
+	// Clearing the delegation of an account with storage keeps it as a program account with empty code.
+	assert.NoError(t, state.SetCodeToEOA(addr, nil, rules))
+	assert.True(t, state.IsProgramAccount(addr))
+	assert.Equal(t, 0, state.GetCodeSize(addr))
+	assert.Equal(t, value, state.GetState(addr, key))
+
+	root, err = state.Commit(true)
+	assert.NoError(t, err)
+	state, _ = New(root, db, nil, nil)
+	assert.Equal(t, value, state.GetState(addr, key))
+
+	// The storage is still accessible when the account is delegated again.
+	assert.NoError(t, state.SetCodeToEOA(addr, delegation, rules))
+	assert.Equal(t, delegation, state.GetCode(addr))
+	assert.Equal(t, value, state.GetState(addr, key))
+}
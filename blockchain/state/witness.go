@@ -0,0 +1,171 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// Witness is the part of the chain and of the state read by the execution of a
+// block: the parent header, the ancestors read by BLOCKHASH, the trie nodes and
+// the contract codes. It is enough to execute the block again without the
+// state, see NewWithWitness for its recording.
+type Witness struct {
+	Headers []*types.Header           // Parent header first, then the ancestors read
+	Codes   map[string]struct{}       // Contract codes read
+	State   map[common.ExtHash][]byte // Trie nodes read, keyed by their extended hash
+
+	lock sync.Mutex
+}
+
+// NewWitness returns an empty witness of the child block of the given header.
+func NewWitness(parent *types.Header) *Witness {
+	return &Witness{
+		Headers: []*types.Header{parent},
+		Codes:   make(map[string]struct{}),
+		State:   make(map[common.ExtHash][]byte),
+	}
+}
+
+// Parent returns the header of the parent block.
+func (w *Witness) Parent() *types.Header {
+	if len(w.Headers) == 0 {
+		return nil
+	}
+	return w.Headers[0]
+}
+
+// AddHeader records an ancestor header read by the execution.
+func (w *Witness) AddHeader(header *types.Header) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	hash := header.Hash()
+	for _, h := range w.Headers {
+		if h.Hash() == hash {
+			return
+		}
+	}
+	w.Headers = append(w.Headers, header)
+}
+
+// AddCode records a contract code read by the execution.
+func (w *Witness) AddCode(code []byte) {
+	if len(code) == 0 {
+		return
+	}
+	w.lock.Lock()
+	w.Codes[string(code)] = struct{}{}
+	w.lock.Unlock()
+}
+
+// AddTrieNode records a trie node read by the execution.
+func (w *Witness) AddTrieNode(hash common.ExtHash, enc []byte) {
+	w.lock.Lock()
+	if _, ok := w.State[hash]; !ok {
+		w.State[hash] = common.CopyBytes(enc)
+	}
+	w.lock.Unlock()
+}
+
+// MakeDB returns a memory database holding the trie nodes and the contract
+// codes of the witness, after checking them against their hashes.
+func (w *Witness) MakeDB() (database.DBManager, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	db := database.NewMemoryDBManager()
+	for hash, enc := range w.State {
+		if err := statedb.VerifyNodeHash(hash, enc); err != nil {
+			return nil, err
+		}
+		db.WriteTrieNode(hash, enc)
+	}
+	for code := range w.Codes {
+		db.WriteCode(crypto.Keccak256Hash([]byte(code)), []byte(code))
+	}
+	return db, nil
+}
+
+// extWitness is the JSON encoding of a witness. The trie nodes are given with
+// their database key, since an extended hash can't be derived from the node.
+type extWitness struct {
+	Headers []*types.Header `json:"headers"`
+	Codes   []hexutil.Bytes `json:"codes"`
+	State   []witnessNode   `json:"state"`
+}
+
+type witnessNode struct {
+	Key  hexutil.Bytes `json:"key"`
+	Node hexutil.Bytes `json:"node"`
+}
+
+// MarshalJSON encodes the witness, with the codes and nodes sorted.
+func (w *Witness) MarshalJSON() ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	ext := extWitness{
+		Headers: w.Headers,
+		Codes:   make([]hexutil.Bytes, 0, len(w.Codes)),
+		State:   make([]witnessNode, 0, len(w.State)),
+	}
+	for code := range w.Codes {
+		ext.Codes = append(ext.Codes, []byte(code))
+	}
+	sort.Slice(ext.Codes, func(i, j int) bool { return bytes.Compare(ext.Codes[i], ext.Codes[j]) < 0 })
+	for hash, enc := range w.State {
+		ext.State = append(ext.State, witnessNode{Key: database.TrieNodeKey(hash), Node: enc})
+	}
+	sort.Slice(ext.State, func(i, j int) bool { return bytes.Compare(ext.State[i].Key, ext.State[j].Key) < 0 })
+	return json.Marshal(&ext)
+}
+
+// UnmarshalJSON decodes a witness.
+func (w *Witness) UnmarshalJSON(input []byte) error {
+	var ext extWitness
+	if err := json.Unmarshal(input, &ext); err != nil {
+		return err
+	}
+	if len(ext.Headers) == 0 {
+		return errors.New("witness without parent header")
+	}
+	w.Headers = ext.Headers
+	w.Codes = make(map[string]struct{}, len(ext.Codes))
+	for _, code := range ext.Codes {
+		w.Codes[string(code)] = struct{}{}
+	}
+	w.State = make(map[common.ExtHash][]byte, len(ext.State))
+	for _, node := range ext.State {
+		if len(node.Key) != common.HashLength && len(node.Key) != common.ExtHashLength {
+			return errors.New("invalid witness node key")
+		}
+		w.State[common.BytesToExtHash(node.Key)] = node.Node
+	}
+	return nil
+}
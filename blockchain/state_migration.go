@@ -32,6 +32,7 @@ import (
 	"github.com/klaytn/klaytn/blockchain/state"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/mclock"
+	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
@@ -315,6 +316,9 @@ func (bc *BlockChain) PrepareStateMigration() error {
 	if bc.db.ReadPruningEnabled() {
 		return errors.New("state migration not supported with live pruning enabled")
 	}
+	if bc.stateCache.TrieDB().Scheme() == statedb.PathScheme {
+		return errors.New("state migration not supported with the path trie node scheme")
+	}
 
 	if bc.db.InMigration() || bc.prepareStateMigration {
 		return errors.New("migration already started")
@@ -364,6 +368,9 @@ func (bc *BlockChain) StartStateMigration(number uint64, root common.Hash) error
 	if bc.db.InMigration() {
 		return errors.New("migration already started")
 	}
+	if bc.stateCache.TrieDB().Scheme() == statedb.PathScheme {
+		return errors.New("state migration not supported with the path trie node scheme")
+	}
 
 	for _, f := range migrationPrerequisites {
 		if err := f(number); err != nil {
@@ -700,7 +707,7 @@ func prepareContractWarmUp(block *types.Block, db state.Database, contractAddr c
 	if err != nil {
 		return common.ExtHash{}, nil, err
 	}
-	storageTrie, err := db.OpenStorageTrie(storageTrieRoot, nil)
+	storageTrie, err := db.OpenStorageTrie(storageTrieRoot, &statedb.TrieOpts{Owner: crypto.Keccak256Hash(contractAddr[:])})
 	if err != nil {
 		return common.ExtHash{}, nil, err
 	}
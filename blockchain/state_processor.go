@@ -21,11 +21,14 @@
 package blockchain
 
 import (
+	"math/big"
 	"time"
 
 	"github.com/klaytn/klaytn/blockchain/state"
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/consensus"
 	"github.com/klaytn/klaytn/params"
 )
@@ -35,9 +38,12 @@ import (
 //
 // StateProcessor implements Processor.
 type StateProcessor struct {
-	config *params.ChainConfig // Chain configuration options
-	bc     *BlockChain         // Canonical block chain
-	engine consensus.Engine    // Consensus engine used for block rewards
+	config *params.ChainConfig   // Chain configuration options
+	bc     consensus.ChainReader // Canonical block chain
+	engine consensus.Engine      // Consensus engine used for block rewards
+
+	parallel      bool // Executes the transactions of a block in parallel
+	parallelCheck bool // Executes the transactions of a block with both executors and compares the results
 }
 
 // ProcessStats includes the time statistics regarding StateProcessor.Process.
@@ -50,9 +56,11 @@ type ProcessStats struct {
 // NewStateProcessor initialises a new StateProcessor.
 func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consensus.Engine) *StateProcessor {
 	return &StateProcessor{
-		config: config,
-		bc:     bc,
-		engine: engine,
+		config:        config,
+		bc:            bc,
+		engine:        engine,
+		parallel:      bc.cacheConfig.ParallelExecution,
+		parallelCheck: bc.cacheConfig.ParallelExecutionCheck,
 	}
 }
 
@@ -65,7 +73,7 @@ func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consen
 func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, []*vm.InternalTxTrace, ProcessStats, error) {
 	var (
 		receipts         types.Receipts
-		usedGas          = new(uint64)
+		usedGas          uint64
 		header           = block.Header()
 		allLogs          []*types.Log
 		internalTxTraces []*vm.InternalTxTrace
@@ -75,17 +83,40 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	// Extract author from the header
 	author, _ := p.bc.Engine().Author(header) // Ignore error, we're past header validation
 
+	// Record the ancestors read by the transactions if a witness is recorded
+	var chain ChainContext = p.bc
+	if witness := statedb.Witness(); witness != nil {
+		chain = &witnessChainContext{ChainContext: chain, witness: witness}
+	}
+
 	processStats.BeforeApplyTxs = time.Now()
-	// Iterate over and process the individual transactions
-	for i, tx := range block.Transactions() {
-		statedb.SetTxContext(tx.Hash(), block.Hash(), i)
-		receipt, internalTxTrace, err := p.bc.ApplyTransaction(p.config, &author, statedb, header, tx, usedGas, &cfg)
-		if err != nil {
-			return nil, nil, 0, nil, processStats, err
+	if err := ProcessParentBlockHash(p.config, header, statedb); err != nil {
+		return nil, nil, 0, nil, processStats, err
+	}
+	var err error
+	switch {
+	case p.parallelCheck && canExecuteInParallel(block, statedb, &cfg):
+		// The sequential results are kept, the parallel ones are only compared
+		parState := statedb.Copy()
+		parReceipts, parGas, parErr := p.applyTransactionsParallel(chain, block, parState, &author, &cfg)
+		receipts, usedGas, internalTxTraces, err = p.applyTransactions(chain, block, statedb, &author, &cfg)
+		if err == nil && parErr == nil {
+			checkParallelResult(block, statedb, parState, receipts, parReceipts, usedGas, parGas)
+		} else if (err == nil) != (parErr == nil) {
+			logger.Error("Parallel execution error mismatch", "number", block.Number(), "hash", block.Hash(), "err", err, "parallelErr", parErr)
+			parallelMismatchMeter.Mark(1)
 		}
-		receipts = append(receipts, receipt)
+	case p.parallel && canExecuteInParallel(block, statedb, &cfg):
+		receipts, usedGas, err = p.applyTransactionsParallel(chain, block, statedb, &author, &cfg)
+		internalTxTraces = make([]*vm.InternalTxTrace, len(receipts))
+	default:
+		receipts, usedGas, internalTxTraces, err = p.applyTransactions(chain, block, statedb, &author, &cfg)
+	}
+	if err != nil {
+		return nil, nil, 0, nil, processStats, err
+	}
+	for _, receipt := range receipts {
 		allLogs = append(allLogs, receipt.Logs...)
-		internalTxTraces = append(internalTxTraces, internalTxTrace)
 	}
 	processStats.AfterApplyTxs = time.Now()
 
@@ -95,5 +126,51 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	}
 	processStats.AfterFinalize = time.Now()
 
-	return receipts, allLogs, *usedGas, internalTxTraces, processStats, nil
+	return receipts, allLogs, usedGas, internalTxTraces, processStats, nil
+}
+
+// applyTransactions executes the transactions of a block one after another.
+func (p *StateProcessor) applyTransactions(chain ChainContext, block *types.Block, statedb *state.StateDB, author *common.Address, cfg *vm.Config) (types.Receipts, uint64, []*vm.InternalTxTrace, error) {
+	var (
+		receipts         types.Receipts
+		internalTxTraces []*vm.InternalTxTrace
+		usedGas          = new(uint64)
+		header           = block.Header()
+	)
+	for i, tx := range block.Transactions() {
+		statedb.SetTxContext(tx.Hash(), block.Hash(), i)
+		receipt, internalTxTrace, err := applyTransaction(chain, p.config, author, statedb, header, tx, usedGas, cfg)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		receipts = append(receipts, receipt)
+		internalTxTraces = append(internalTxTraces, internalTxTrace)
+	}
+	return receipts, *usedGas, internalTxTraces, nil
+}
+
+// ProcessParentBlockHash stores the parent block hash in the history storage
+// contract as EIP-2935 does, so that the hashes older than the BLOCKHASH window
+// can be read from the state. It must be called before the transactions of a
+// block are applied. The contract is installed at the first block it is missing
+// since the prague fork. The storage is written directly instead of calling the
+// contract from the system address, which results in the same state.
+func ProcessParentBlockHash(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) error {
+	if header.Number.Sign() == 0 || !config.IsPragueForkEnabled(header.Number) {
+		return nil
+	}
+	addr := params.HistoryStorageAddress
+	if statedb.GetCodeSize(addr) == 0 {
+		// An existing balance is kept when the account is converted.
+		if !statedb.IsProgramAccount(addr) {
+			statedb.CreateSmartContractAccountWithKey(addr, false, accountkey.NewAccountKeyFail(), params.CodeFormatEVM, config.Rules(header.Number))
+		}
+		if err := statedb.SetCode(addr, params.HistoryStorageCode); err != nil {
+			return err
+		}
+	}
+	slot := new(big.Int).SetUint64((header.Number.Uint64() - 1) % params.HistoryServeWindow)
+	statedb.SetState(addr, common.BigToHash(slot), header.ParentHash)
+	statedb.Finalise(true, true)
+	return nil
 }
@@ -0,0 +1,66 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessParentBlockHash(t *testing.T) {
+	var (
+		addr   = params.HistoryStorageAddress
+		window = params.HistoryServeWindow
+		config = params.TestChainConfig.Copy()
+	)
+	config.PragueCompatibleBlock = big.NewInt(10)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+
+	// A balance sent to the address before the fork is kept
+	statedb.AddBalance(addr, big.NewInt(7))
+
+	header := func(num uint64) *types.Header {
+		return &types.Header{Number: new(big.Int).SetUint64(num), ParentHash: common.BigToHash(new(big.Int).SetUint64(num - 1))}
+	}
+	slot := func(num uint64) common.Hash {
+		return common.BigToHash(new(big.Int).SetUint64(num % window))
+	}
+
+	// Nothing happens before the fork
+	assert.NoError(t, ProcessParentBlockHash(config, header(9), statedb))
+	assert.False(t, statedb.IsProgramAccount(addr))
+	assert.Equal(t, common.Hash{}, statedb.GetState(addr, slot(8)))
+
+	// The contract is installed at the fork block
+	assert.NoError(t, ProcessParentBlockHash(config, header(10), statedb))
+	assert.True(t, statedb.IsProgramAccount(addr))
+	assert.Equal(t, params.HistoryStorageCode, statedb.GetCode(addr))
+	assert.Equal(t, big.NewInt(7), statedb.GetBalance(addr))
+	assert.Equal(t, header(10).ParentHash, statedb.GetState(addr, slot(9)))
+
+	// The ring buffer overwrites the oldest hash
+	assert.NoError(t, ProcessParentBlockHash(config, header(window+10), statedb))
+	assert.Equal(t, slot(9), slot(window+9))
+	assert.Equal(t, header(window+10).ParentHash, statedb.GetState(addr, slot(9)))
+}
@@ -94,7 +94,7 @@ type Message interface {
 
 	GasPrice() *big.Int
 
-	// For TxTypeEthereumDynamicFee
+	// For TxTypeEthereumDynamicFee and TxTypeEthereumSetCode
 	GasTipCap() *big.Int
 	GasFeeCap() *big.Int
 	EffectiveGasTip(baseFee *big.Int) *big.Int
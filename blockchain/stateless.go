@@ -0,0 +1,147 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/params"
+)
+
+var (
+	errStatelessChain  = errors.New("not available in a stateless execution")
+	errStatelessEngine = errors.New("consensus engine can't finalize a block from a witness")
+)
+
+// witnessChainContext records the headers read from a chain into a witness.
+type witnessChainContext struct {
+	ChainContext
+	witness *state.Witness
+}
+
+func (c *witnessChainContext) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header := c.ChainContext.GetHeader(hash, number)
+	if header != nil {
+		c.witness.AddHeader(header)
+	}
+	return header
+}
+
+// statelessChain serves the headers of a witness in place of the chain. The
+// headers are indexed by their hash, so a header can't be forged.
+type statelessChain struct {
+	config  *params.ChainConfig
+	engine  consensus.Engine
+	parent  *types.Header
+	headers map[common.Hash]*types.Header
+}
+
+func newStatelessChain(config *params.ChainConfig, engine consensus.Engine, witness *state.Witness) *statelessChain {
+	chain := &statelessChain{
+		config:  config,
+		engine:  engine,
+		parent:  witness.Parent(),
+		headers: make(map[common.Hash]*types.Header, len(witness.Headers)),
+	}
+	for _, header := range witness.Headers {
+		chain.headers[header.Hash()] = header
+	}
+	return chain
+}
+
+func (c *statelessChain) Config() *params.ChainConfig  { return c.config }
+func (c *statelessChain) Engine() consensus.Engine     { return c.engine }
+func (c *statelessChain) CurrentHeader() *types.Header { return c.parent }
+func (c *statelessChain) CurrentBlock() *types.Block   { return nil }
+
+func (c *statelessChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+func (c *statelessChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.headers[hash]
+}
+
+// GetHeaderByNumber returns the ancestor of the given number, which is only
+// known if the headers down to it are in the witness.
+func (c *statelessChain) GetHeaderByNumber(number uint64) *types.Header {
+	for header := c.parent; header != nil; header = c.headers[header.ParentHash] {
+		if n := header.Number.Uint64(); n == number {
+			return header
+		} else if n < number {
+			break
+		}
+	}
+	return nil
+}
+
+func (c *statelessChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return nil
+}
+
+func (c *statelessChain) State() (*state.StateDB, error) {
+	return nil, errStatelessChain
+}
+
+func (c *statelessChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	return nil, errStatelessChain
+}
+
+// ExecuteStateless executes a block without the state and the chain, which are
+// replaced by the witness of the block recorded by another node. It returns an
+// error if the resulting state root or receipts differ from the header ones.
+//
+// The consensus engine must be able to finalize the block from the headers of
+// the witness only. Istanbul can't, as it reads the staking information and the
+// governance parameters which aren't recorded in the witness.
+func ExecuteStateless(config *params.ChainConfig, engine consensus.Engine, block *types.Block, witness *state.Witness) error {
+	if _, ok := engine.(consensus.Istanbul); ok {
+		return errStatelessEngine
+	}
+	parent := witness.Parent()
+	if parent == nil || parent.Hash() != block.ParentHash() {
+		return errors.New("witness is not of the parent block")
+	}
+	db, err := witness.MakeDB()
+	if err != nil {
+		return fmt.Errorf("invalid witness: %v", err)
+	}
+	statedb, err := state.New(parent.Root, state.NewDatabase(db), nil, nil)
+	if err != nil {
+		return err
+	}
+	processor := &StateProcessor{config: config, bc: newStatelessChain(config, engine, witness), engine: engine}
+	receipts, _, usedGas, _, _, err := processor.Process(block, statedb, vm.Config{})
+	if err != nil {
+		return err
+	}
+	validator := &BlockValidator{config: config, engine: engine}
+	if err := validator.ValidateState(block, nil, statedb, receipts, usedGas); err != nil {
+		return err
+	}
+	// A node missing from the witness is only reported by the state
+	return statedb.Error()
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteStateless(t *testing.T) {
+	t.Run("hash", func(t *testing.T) { testExecuteStateless(t, false) })
+	t.Run("live pruning", func(t *testing.T) { testExecuteStateless(t, true) })
+}
+
+func testExecuteStateless(t *testing.T, pruning bool) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		hasher  = common.HexToAddress("0x1000") // stores the hash of the block 3 blocks ago in a new slot
+		cleaner = common.HexToAddress("0x2000") // deletes the slot 1
+		sizer   = common.HexToAddress("0x3000") // stores the code size of the hasher
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000)},
+				hasher: {
+					Code: []byte{
+						byte(vm.PUSH1), 3, byte(vm.NUMBER), byte(vm.SUB), byte(vm.BLOCKHASH),
+						byte(vm.NUMBER), byte(vm.SSTORE),
+					},
+					Balance: common.Big0,
+				},
+				cleaner: {
+					Code:    []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 1, byte(vm.SSTORE)},
+					Storage: map[common.Hash]common.Hash{{1}: {1}, {2}: {2}},
+					Balance: common.Big0,
+				},
+				sizer: {
+					Code:    []byte{byte(vm.PUSH2), 0x10, 0x00, byte(vm.EXTCODESIZE), byte(vm.PUSH1), 0, byte(vm.SSTORE)},
+					Balance: common.Big0,
+				},
+			},
+		}
+		gendb   = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.LatestSignerForChainID(gspec.Config.ChainID)
+		engine  = gxhash.NewFaker()
+	)
+	cacheConfig := &CacheConfig{
+		ArchiveMode:         true,
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+	}
+	// The blocks are generated one by one, for BLOCKHASH to find the ancestors
+	genchain, err := NewBlockChain(gendb, cacheConfig, gspec.Config, engine, vm.Config{})
+	require.NoError(t, err)
+	defer genchain.Stop()
+	blocks := types.Blocks{genesis}
+	for i := 0; i < 4; i++ {
+		generated, _ := GenerateChain(gspec.Config, blocks[i], engine, gendb, 1, func(_ int, block *BlockGen) {
+			send := func(to common.Address) {
+				tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), to, big.NewInt(1), 100000, nil, nil), signer, key)
+				require.NoError(t, err)
+				block.AddTxWithChain(genchain, tx)
+			}
+			send(hasher)
+			send(common.Address{byte(i + 1)})
+			switch i {
+			case 1:
+				send(cleaner)
+			case 2:
+				send(sizer)
+			}
+		})
+		_, err := genchain.InsertChain(generated)
+		require.NoError(t, err)
+		blocks = append(blocks, generated...)
+	}
+	blocks = blocks[1:]
+
+	db := database.NewMemoryDBManager()
+	if pruning {
+		db.WritePruningEnabled()
+	}
+	gspec.MustCommit(db)
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, engine, vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	for _, block := range blocks {
+		witness, err := chain.ExecutionWitness(block)
+		require.NoError(t, err, "block %d", block.NumberU64())
+
+		// The witness is served by the debug API in JSON
+		enc, err := json.Marshal(witness)
+		require.NoError(t, err)
+		decoded := new(state.Witness)
+		require.NoError(t, json.Unmarshal(enc, decoded))
+		assert.Equal(t, len(witness.State), len(decoded.State))
+		if block.NumberU64() >= 3 {
+			assert.Equal(t, 2, len(decoded.Headers)) // The parent, and the grandparent read by BLOCKHASH
+		}
+		assert.NoError(t, ExecuteStateless(gspec.Config, engine, block, decoded), "block %d", block.NumberU64())
+	}
+	block := blocks[2]
+	witness, err := chain.ExecutionWitness(block)
+	require.NoError(t, err)
+	assert.Contains(t, witness.Codes, string(gspec.Alloc[hasher].Code)) // Read by EXTCODESIZE
+	if pruning {
+		var extended bool
+		for hash := range witness.State {
+			extended = extended || !hash.IsZeroExtended()
+		}
+		assert.True(t, extended, "no storage trie node with extended hash")
+	}
+
+	// A witness of another block is refused
+	assert.Error(t, ExecuteStateless(gspec.Config, engine, blocks[3], witness))
+
+	// A missing or forged trie node is detected
+	for hash, enc := range witness.State {
+		delete(witness.State, hash)
+		assert.Error(t, ExecuteStateless(gspec.Config, engine, block, witness))
+
+		forged := common.CopyBytes(enc)
+		forged[len(forged)-1]++
+		witness.State[hash] = forged
+		assert.Error(t, ExecuteStateless(gspec.Config, engine, block, witness))
+		witness.State[hash] = enc
+	}
+	assert.NoError(t, ExecuteStateless(gspec.Config, engine, block, witness))
+
+	// A missing code is detected
+	delete(witness.Codes, string(gspec.Alloc[hasher].Code))
+	assert.Error(t, ExecuteStateless(gspec.Config, engine, block, witness))
+}
+
+// statelessIstanbul is an engine of the istanbul type, which can't finalize a block from a witness.
+type statelessIstanbul struct {
+	consensus.Engine
+}
+
+func (e *statelessIstanbul) Start(chain consensus.ChainReader, currentBlock func() *types.Block, hasBadBlock func(hash common.Hash) bool) error {
+	return nil
+}
+func (e *statelessIstanbul) Stop() error                          { return nil }
+func (e *statelessIstanbul) SetChain(chain consensus.ChainReader) {}
+func (e *statelessIstanbul) UpdateParam(num uint64) error         { return nil }
+
+func TestExecuteStatelessIstanbul(t *testing.T) {
+	var (
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		db      = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(db)
+		engine  = gxhash.NewFaker()
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, nil)
+	witness := state.NewWitness(genesis.Header())
+
+	assert.Equal(t, errStatelessEngine, ExecuteStateless(gspec.Config, &statelessIstanbul{engine}, blocks[0], witness))
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/klaytn/klaytn/common/hexutil"
 	contracts "github.com/klaytn/klaytn/contracts/system_contracts"
 	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/params"
 )
 
 var (
@@ -48,6 +49,8 @@ var (
 	// Some system contracts are allocated at special addresses.
 	AddressBookAddr = common.HexToAddress("0x0000000000000000000000000000000000000400") // TODO: replace contracts/reward/contract/utils.go
 	RegistryAddr    = common.HexToAddress("0x0000000000000000000000000000000000000401")
+	// The history storage contract (EIP-2935) is installed by the protocol since the prague fork.
+	HistoryStorageAddr = params.HistoryStorageAddress
 	// The following addresses are only used for testing.
 	Kip113ProxyAddrMock = common.HexToAddress("0x0000000000000000000000000000000000000402")
 	Kip113LogicAddrMock = common.HexToAddress("0x0000000000000000000000000000000000000403")
@@ -60,8 +63,13 @@ var (
 
 	ERC1967ProxyCode = hexutil.MustDecode("0x" + contracts.ERC1967ProxyBinRuntime)
 
+	HistoryStorageCode = params.HistoryStorageCode
+
 	// Errors
 	ErrRegistryNotInstalled = errors.New("Registry contract not installed")
 	ErrKip113BadResult      = errors.New("KIP113 call returned bad data")
 	ErrKip113BadPop         = errors.New("KIP113 PoP verification failed")
+
+	ErrHistoryStorageNotInstalled = errors.New("history storage contract not installed")
+	ErrHistoryStorageBadResult    = errors.New("history storage call returned bad data")
 )
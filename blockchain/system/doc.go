@@ -21,5 +21,6 @@ Package system deals with system contracts in Klaytn.
 System contracts are smart contracts that affects the protocol.
 
 - Registry: Stores the canonical system contract addresses.
+- HistoryStorage: Serves the recent block hashes from the state (EIP-2935).
 
 */
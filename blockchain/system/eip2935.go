@@ -0,0 +1,50 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/klaytn/klaytn"
+	"github.com/klaytn/klaytn/accounts/abi/bind"
+	"github.com/klaytn/klaytn/common"
+)
+
+// ReadHistoryBlockHash returns the hash of the target block served by the
+// history storage contract (EIP-2935) at the given block. Only the hashes of the
+// HistoryServeWindow blocks before the given block are served, and the call is
+// reverted otherwise.
+func ReadHistoryBlockHash(backend bind.ContractCaller, target uint64, num *big.Int) (common.Hash, error) {
+	code, err := backend.CodeAt(context.Background(), HistoryStorageAddr, num)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if code == nil {
+		return common.Hash{}, ErrHistoryStorageNotInstalled
+	}
+
+	msg := klaytn.CallMsg{To: &HistoryStorageAddr, Data: lpad32(target).Bytes()}
+	ret, err := backend.CallContract(context.Background(), msg, num)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(ret) != common.HashLength {
+		return common.Hash{}, ErrHistoryStorageBadResult
+	}
+	return common.BytesToHash(ret), nil
+}
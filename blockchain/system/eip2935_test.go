@@ -0,0 +1,76 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/accounts/abi/bind/backends"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadHistoryBlockHash(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlWarn)
+
+	config := params.AllGxhashProtocolChanges.Copy()
+	config.IstanbulCompatibleBlock = common.Big0
+	config.LondonCompatibleBlock = common.Big0
+	config.EthTxTypeCompatibleBlock = common.Big0
+	config.MagmaCompatibleBlock = common.Big0
+	config.KoreCompatibleBlock = common.Big0
+	config.ShanghaiCompatibleBlock = common.Big0
+	config.CancunCompatibleBlock = common.Big0
+	config.DragonCompatibleBlock = common.Big0
+	config.PragueCompatibleBlock = big.NewInt(2)
+	config.Governance = params.GetDefaultGovernanceConfig()
+	backend := backends.NewSimulatedBackendWithDatabase(database.NewMemoryDBManager(), blockchain.GenesisAlloc{}, config)
+	defer backend.Close()
+
+	// Not installed before the prague fork
+	backend.Commit()
+	_, err := ReadHistoryBlockHash(backend, 0, nil)
+	assert.Equal(t, ErrHistoryStorageNotInstalled, err)
+
+	// Installed at the prague fork block, serving the parent block hashes since then
+	for i := 0; i < 3; i++ {
+		backend.Commit()
+	}
+	chain := backend.BlockChain()
+	assert.Equal(t, uint64(4), chain.CurrentBlock().NumberU64())
+	for num := uint64(1); num < 4; num++ {
+		hash, err := ReadHistoryBlockHash(backend, num, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, chain.GetHeaderByNumber(num).Hash(), hash)
+	}
+
+	// The hash before the fork block is not stored
+	hash, err := ReadHistoryBlockHash(backend, 0, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	// The current and future blocks are not served
+	_, err = ReadHistoryBlockHash(backend, 4, nil)
+	assert.NotNil(t, err)
+	_, err = ReadHistoryBlockHash(backend, 5, nil)
+	assert.NotNil(t, err)
+}
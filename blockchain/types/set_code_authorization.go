@@ -0,0 +1,194 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+)
+
+// SetCodeAuthorizationMagic is the prefix of the hash signed by an authority of EIP-7702.
+const SetCodeAuthorizationMagic byte = 0x05
+
+// DelegationPrefix is the prefix of the code of an account delegating its
+// execution to another address, followed by the 20 bytes of the address.
+var DelegationPrefix = []byte{0xef, 0x01, 0x00}
+
+var (
+	ErrEmptyAuthorizationList = errors.New("set code transaction with an empty authorization list")
+	errInvalidAuthorizationV  = errors.New("invalid y parity of the authorization")
+)
+
+// SetCodeAuthorization is an authorization of EIP-7702 signed by an authority,
+// the owner of an account, to delegate the execution of the account to the
+// code of Address. A ChainID of zero authorizes on any chain.
+type SetCodeAuthorization struct {
+	ChainID *big.Int
+	Address common.Address
+	Nonce   uint64
+	V       uint8
+	R       *big.Int
+	S       *big.Int
+}
+
+// setCodeAuthorizationJSON is the JSON representation of SetCodeAuthorization.
+type setCodeAuthorizationJSON struct {
+	ChainID *hexutil.Big    `json:"chainId"`
+	Address *common.Address `json:"address"`
+	Nonce   *hexutil.Uint64 `json:"nonce"`
+	V       *hexutil.Uint64 `json:"yParity"`
+	R       *hexutil.Big    `json:"r"`
+	S       *hexutil.Big    `json:"s"`
+}
+
+// SignSetCode returns the authorization signed by the given private key.
+func SignSetCode(prv *ecdsa.PrivateKey, auth SetCodeAuthorization) (SetCodeAuthorization, error) {
+	h := auth.SigHash()
+	sig, err := crypto.Sign(h[:], prv)
+	if err != nil {
+		return SetCodeAuthorization{}, err
+	}
+	r, s, _ := decodeSignature(sig)
+	return SetCodeAuthorization{
+		ChainID: auth.ChainID,
+		Address: auth.Address,
+		Nonce:   auth.Nonce,
+		V:       sig[crypto.RecoveryIDOffset],
+		R:       r,
+		S:       s,
+	}, nil
+}
+
+// SigHash returns the hash to be signed by the authority.
+func (a *SetCodeAuthorization) SigHash() common.Hash {
+	chainID := a.ChainID
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	return prefixedRlpHash(SetCodeAuthorizationMagic, []interface{}{chainID, a.Address, a.Nonce})
+}
+
+// Authority returns the address of the signer of the authorization.
+func (a *SetCodeAuthorization) Authority() (common.Address, error) {
+	if a.V > 1 {
+		return common.Address{}, errInvalidAuthorizationV
+	}
+	if a.R == nil || a.S == nil {
+		return common.Address{}, ErrInvalidSig
+	}
+	return recoverPlain(a.SigHash(), a.R, a.S, big.NewInt(int64(a.V)+27), true)
+}
+
+func (a *SetCodeAuthorization) equal(b *SetCodeAuthorization) bool {
+	return bigEqual(a.ChainID, b.ChainID) &&
+		a.Address == b.Address &&
+		a.Nonce == b.Nonce &&
+		a.V == b.V &&
+		bigEqual(a.R, b.R) &&
+		bigEqual(a.S, b.S)
+}
+
+func (a SetCodeAuthorization) MarshalJSON() ([]byte, error) {
+	nonce, v := hexutil.Uint64(a.Nonce), hexutil.Uint64(a.V)
+	return json.Marshal(setCodeAuthorizationJSON{
+		ChainID: (*hexutil.Big)(a.ChainID),
+		Address: &a.Address,
+		Nonce:   &nonce,
+		V:       &v,
+		R:       (*hexutil.Big)(a.R),
+		S:       (*hexutil.Big)(a.S),
+	})
+}
+
+func (a *SetCodeAuthorization) UnmarshalJSON(input []byte) error {
+	var dec setCodeAuthorizationJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.ChainID == nil || dec.Address == nil || dec.Nonce == nil || dec.V == nil || dec.R == nil || dec.S == nil {
+		return errors.New("missing required field of SetCodeAuthorization")
+	}
+	if *dec.V > 1 {
+		return errInvalidAuthorizationV
+	}
+	a.ChainID = (*big.Int)(dec.ChainID)
+	a.Address = *dec.Address
+	a.Nonce = uint64(*dec.Nonce)
+	a.V = uint8(*dec.V)
+	a.R = (*big.Int)(dec.R)
+	a.S = (*big.Int)(dec.S)
+	return nil
+}
+
+func equalAuthorizationList(a, b []SetCodeAuthorization) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].equal(&b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// copyAuthorizationList returns a deep copy of the authorization list.
+func copyAuthorizationList(auths []SetCodeAuthorization) []SetCodeAuthorization {
+	cpy := make([]SetCodeAuthorization, len(auths))
+	for i, auth := range auths {
+		cpy[i] = auth
+		cpy[i].ChainID = copyBigInt(auth.ChainID)
+		cpy[i].R = copyBigInt(auth.R)
+		cpy[i].S = copyBigInt(auth.S)
+	}
+	return cpy
+}
+
+func bigEqual(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
+
+func copyBigInt(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(x)
+}
+
+// ParseDelegation returns the address delegated to by the given code, and
+// false if the code is not a delegation designator.
+func ParseDelegation(code []byte) (common.Address, bool) {
+	if len(code) != len(DelegationPrefix)+common.AddressLength || !bytes.HasPrefix(code, DelegationPrefix) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(code[len(DelegationPrefix):]), true
+}
+
+// AddressToDelegation returns the delegation designator of the given address.
+func AddressToDelegation(addr common.Address) []byte {
+	return append(common.CopyBytes(DelegationPrefix), addr.Bytes()...)
+}
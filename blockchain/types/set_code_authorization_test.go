@@ -0,0 +1,81 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCodeAuthorization(t *testing.T) {
+	auth, err := SignSetCode(key, SetCodeAuthorization{
+		ChainID: big.NewInt(2),
+		Address: to,
+		Nonce:   nonce,
+	})
+	assert.NoError(t, err)
+
+	authority, err := auth.Authority()
+	assert.NoError(t, err)
+	assert.Equal(t, from, authority)
+
+	// The authority changes if any of the signed fields changes.
+	modified := auth
+	modified.Nonce++
+	authority, err = modified.Authority()
+	assert.NoError(t, err)
+	assert.NotEqual(t, from, authority)
+
+	modified = auth
+	modified.V = 2
+	_, err = modified.Authority()
+	assert.Equal(t, errInvalidAuthorizationV, err)
+
+	// JSON roundtrip
+	b, err := json.Marshal(auth)
+	assert.NoError(t, err)
+	var dec SetCodeAuthorization
+	assert.NoError(t, json.Unmarshal(b, &dec))
+	assert.True(t, auth.equal(&dec))
+	assert.Error(t, json.Unmarshal([]byte(`{"chainId":"0x2"}`), &dec))
+}
+
+func TestParseDelegation(t *testing.T) {
+	addr := common.HexToAddress("0x7702")
+
+	code := AddressToDelegation(addr)
+	assert.Equal(t, 23, len(code))
+	assert.Equal(t, DelegationPrefix, code[:3])
+
+	parsed, ok := ParseDelegation(code)
+	assert.True(t, ok)
+	assert.Equal(t, addr, parsed)
+
+	for _, code := range [][]byte{
+		nil,
+		DelegationPrefix,
+		append(common.CopyBytes(code), 0x00),
+		append([]byte{0xef, 0x01, 0x01}, addr.Bytes()...),
+	} {
+		_, ok := ParseDelegation(code)
+		assert.False(t, ok)
+	}
+}
@@ -281,8 +281,7 @@ func (tx *Transaction) setDecoded(inner TxInternalData, size int) {
 func (tx *Transaction) Gas() uint64        { return tx.data.GetGasLimit() }
 func (tx *Transaction) GasPrice() *big.Int { return new(big.Int).Set(tx.data.GetPrice()) }
 func (tx *Transaction) GasTipCap() *big.Int {
-	if tx.Type() == TxTypeEthereumDynamicFee {
-		te := tx.GetTxInternalData().(TxInternalDataBaseFee)
+	if te, ok := tx.GetTxInternalData().(TxInternalDataBaseFee); ok {
 		return te.GetGasTipCap()
 	}
 
@@ -290,8 +289,7 @@ func (tx *Transaction) GasTipCap() *big.Int {
 }
 
 func (tx *Transaction) GasFeeCap() *big.Int {
-	if tx.Type() == TxTypeEthereumDynamicFee {
-		te := tx.GetTxInternalData().(TxInternalDataBaseFee)
+	if te, ok := tx.GetTxInternalData().(TxInternalDataBaseFee); ok {
 		return te.GetGasFeeCap()
 	}
 
@@ -300,8 +298,7 @@ func (tx *Transaction) GasFeeCap() *big.Int {
 
 // This function is disabled because klaytn has no gas tip
 func (tx *Transaction) EffectiveGasTip(baseFee *big.Int) *big.Int {
-	if tx.Type() == TxTypeEthereumDynamicFee {
-		te := tx.GetTxInternalData().(TxInternalDataBaseFee)
+	if te, ok := tx.GetTxInternalData().(TxInternalDataBaseFee); ok {
 		return math.BigMin(te.GetGasTipCap(), new(big.Int).Sub(te.GetGasFeeCap(), baseFee))
 	}
 	return tx.GasPrice()
@@ -312,8 +309,7 @@ func (tx *Transaction) EffectiveGasPrice(header *Header) *big.Int {
 		return header.BaseFee
 	}
 	// Only enters if Magma is not enabled. If Magma is enabled, it will return BaseFee in the above if statement.
-	if tx.Type() == TxTypeEthereumDynamicFee {
-		te := tx.GetTxInternalData().(TxInternalDataBaseFee)
+	if te, ok := tx.GetTxInternalData().(TxInternalDataBaseFee); ok {
 		return te.GetGasFeeCap()
 	}
 	return tx.GasPrice()
@@ -327,6 +323,14 @@ func (tx *Transaction) AccessList() AccessList {
 	return nil
 }
 
+// AuthorizationList returns the authorization list of EIP-7702 if the transaction is a set code transaction.
+func (tx *Transaction) AuthorizationList() []SetCodeAuthorization {
+	if te, ok := tx.GetTxInternalData().(TxInternalDataSetCode); ok {
+		return te.GetAuthorizationList()
+	}
+	return nil
+}
+
 func (tx *Transaction) Value() *big.Int { return new(big.Int).Set(tx.data.GetAmount()) }
 func (tx *Transaction) Nonce() uint64   { return tx.data.GetAccountNonce() }
 func (tx *Transaction) CheckNonce() bool {
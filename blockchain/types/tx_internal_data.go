@@ -25,6 +25,8 @@ import (
 
 	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/kerrors"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
 )
@@ -43,6 +45,7 @@ const (
 	//   <base type>, <fee-delegated type>, and <fee-delegated type with a fee ratio>
 	// If types other than <base type> are not useful, they are declared with underscore(_).
 	// Each base type is self-descriptive.
+	// The base type of TxTypeFeeDelegatedSetCode is the Ethereum typed TxTypeEthereumSetCode.
 	TxTypeLegacyTransaction, _, _ TxType = iota << SubTxTypeBits, iota<<SubTxTypeBits + 1, iota<<SubTxTypeBits + 2
 	TxTypeValueTransfer, TxTypeFeeDelegatedValueTransfer, TxTypeFeeDelegatedValueTransferWithRatio
 	TxTypeValueTransferMemo, TxTypeFeeDelegatedValueTransferMemo, TxTypeFeeDelegatedValueTransferMemoWithRatio
@@ -53,10 +56,12 @@ const (
 	TxTypeCancel, TxTypeFeeDelegatedCancel, TxTypeFeeDelegatedCancelWithRatio
 	TxTypeBatch, _, _
 	TxTypeChainDataAnchoring, TxTypeFeeDelegatedChainDataAnchoring, TxTypeFeeDelegatedChainDataAnchoringWithRatio
+	_, TxTypeFeeDelegatedSetCode, _
 	TxTypeKlaytnLast, _, _
 	TxTypeEthereumAccessList = TxType(0x7801)
 	TxTypeEthereumDynamicFee = TxType(0x7802)
-	TxTypeEthereumLast       = TxType(0x7803)
+	TxTypeEthereumSetCode    = TxType(0x7804)
+	TxTypeEthereumLast       = TxType(0x7805)
 )
 
 type TxValueKeyType uint
@@ -81,6 +86,7 @@ const (
 	TxValueKeyChainID
 	TxValueKeyGasTipCap
 	TxValueKeyGasFeeCap
+	TxValueKeyAuthorizationList
 )
 
 type TxTypeMask uint8
@@ -116,6 +122,7 @@ var (
 	errValueKeyChainIDInvalid            = errors.New("ChainID must be a type of ChainID")
 	errValueKeyGasTipCapMustBigInt       = errors.New("GasTipCap must be a type of *big.Int")
 	errValueKeyGasFeeCapMustBigInt       = errors.New("GasFeeCap must be a type of *big.Int")
+	errValueKeyAuthorizationListInvalid  = errors.New("AuthorizationList must be a type of []SetCodeAuthorization")
 
 	ErrTxTypeNotSupported         = errors.New("transaction type not supported")
 	ErrSenderPubkeyNotSupported   = errors.New("SenderPubkey is not supported for this signer")
@@ -162,6 +169,8 @@ func (t TxValueKeyType) String() string {
 		return "TxValueKeyGasTipCap"
 	case TxValueKeyGasFeeCap:
 		return "TxValueKeyGasFeeCap"
+	case TxValueKeyAuthorizationList:
+		return "TxValueKeyAuthorizationList"
 	}
 
 	return "UndefinedTxValueKeyType"
@@ -217,10 +226,14 @@ func (t TxType) String() string {
 		return "TxTypeFeeDelegatedChainDataAnchoring"
 	case TxTypeFeeDelegatedChainDataAnchoringWithRatio:
 		return "TxTypeFeeDelegatedChainDataAnchoringWithRatio"
+	case TxTypeFeeDelegatedSetCode:
+		return "TxTypeFeeDelegatedSetCode"
 	case TxTypeEthereumAccessList:
 		return "TxTypeEthereumAccessList"
 	case TxTypeEthereumDynamicFee:
 		return "TxTypeEthereumDynamicFee"
+	case TxTypeEthereumSetCode:
+		return "TxTypeEthereumSetCode"
 	}
 
 	return "UndefinedTxType"
@@ -266,6 +279,16 @@ func (t TxType) IsChainDataAnchoring() bool {
 	return (t &^ ((1 << SubTxTypeBits) - 1)) == TxTypeChainDataAnchoring
 }
 
+// IsDynamicFeeTransaction returns true if the tx type has the fee fields of EIP-1559 instead of the gas price.
+func (t TxType) IsDynamicFeeTransaction() bool {
+	return t == TxTypeEthereumDynamicFee || t == TxTypeEthereumSetCode
+}
+
+// IsSetCodeTransaction returns true if the tx type sets the code of accounts by EIP-7702 authorizations.
+func (t TxType) IsSetCodeTransaction() bool {
+	return t == TxTypeEthereumSetCode || t == TxTypeFeeDelegatedSetCode
+}
+
 type FeeRatio uint8
 
 // FeeRatio is valid where it is [1,99].
@@ -404,12 +427,18 @@ type TxInternalDataBaseFee interface {
 	GetGasFeeCap() *big.Int
 }
 
+// TxInternalDataSetCode has a function related to EIP-7702 set code transaction.
+type TxInternalDataSetCode interface {
+	GetAuthorizationList() []SetCodeAuthorization
+}
+
 // Since we cannot access the package `blockchain/vm` directly, an interface `VM` is introduced.
 // TODO-Klaytn-Refactoring: Transaction and related data structures should be a new package.
 type VM interface {
 	Create(caller ContractRef, code []byte, gas uint64, value *big.Int, codeFormat params.CodeFormat) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error)
 	CreateWithAddress(caller ContractRef, code []byte, gas uint64, value *big.Int, contractAddr common.Address, humanReadable bool, codeFormat params.CodeFormat) ([]byte, common.Address, uint64, error)
 	Call(caller ContractRef, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, leftOverGas uint64, err error)
+	ApplyAuthorizations(auths []SetCodeAuthorization)
 }
 
 // Since we cannot access the package `blockchain/state` directly, an interface `StateDB` is introduced.
@@ -475,10 +504,14 @@ func NewTxInternalData(t TxType) (TxInternalData, error) {
 		return newTxInternalDataFeeDelegatedChainDataAnchoring(), nil
 	case TxTypeFeeDelegatedChainDataAnchoringWithRatio:
 		return newTxInternalDataFeeDelegatedChainDataAnchoringWithRatio(), nil
+	case TxTypeFeeDelegatedSetCode:
+		return newTxInternalDataFeeDelegatedSetCode(), nil
 	case TxTypeEthereumAccessList:
 		return newTxInternalDataEthereumAccessList(), nil
 	case TxTypeEthereumDynamicFee:
 		return newTxInternalDataEthereumDynamicFee(), nil
+	case TxTypeEthereumSetCode:
+		return newTxInternalDataEthereumSetCode(), nil
 	}
 
 	return nil, errUndefinedTxType
@@ -532,10 +565,14 @@ func NewTxInternalDataWithMap(t TxType, values map[TxValueKeyType]interface{}) (
 		return newTxInternalDataFeeDelegatedChainDataAnchoringWithMap(values)
 	case TxTypeFeeDelegatedChainDataAnchoringWithRatio:
 		return newTxInternalDataFeeDelegatedChainDataAnchoringWithRatioWithMap(values)
+	case TxTypeFeeDelegatedSetCode:
+		return newTxInternalDataFeeDelegatedSetCodeWithMap(values)
 	case TxTypeEthereumAccessList:
 		return newTxInternalDataEthereumAccessListWithMap(values)
 	case TxTypeEthereumDynamicFee:
 		return newTxInternalDataEthereumDynamicFeeWithMap(values)
+	case TxTypeEthereumSetCode:
+		return newTxInternalDataEthereumSetCodeWithMap(values)
 	}
 
 	return nil, errUndefinedTxType
@@ -631,6 +668,31 @@ func IntrinsicGas(data []byte, accessList AccessList, contractCreation bool, r p
 	return gasPayloadWithGas, nil
 }
 
+// IntrinsicGasAuthorizations adds the gas of the authorizations of EIP-7702 to
+// the given gas. Each authorization is charged as creating an account, and
+// refunded in part if the authority exists.
+func IntrinsicGasAuthorizations(gas uint64, auths []SetCodeAuthorization) (uint64, error) {
+	if (math.MaxUint64-gas)/params.CallNewAccountGas < uint64(len(auths)) {
+		return 0, ErrGasUintOverflow
+	}
+	return gas + uint64(len(auths))*params.CallNewAccountGas, nil
+}
+
+// validateSetCode returns an error if a set code transaction is sent before
+// the prague fork or without any authorization.
+func validateSetCode(recipient common.Address, auths []SetCodeAuthorization, currentBlockNumber uint64) error {
+	if !fork.Rules(new(big.Int).SetUint64(currentBlockNumber)).IsPrague {
+		return ErrTxTypeNotSupported
+	}
+	if len(auths) == 0 {
+		return ErrEmptyAuthorizationList
+	}
+	if common.IsPrecompiledContractAddress(recipient) {
+		return kerrors.ErrPrecompiledContractAddress
+	}
+	return nil
+}
+
 // CalcFeeWithRatio returns feePayer's fee and sender's fee based on feeRatio.
 // For example, if fee = 100 and feeRatio = 30, feePayer = 30 and feeSender = 70.
 func CalcFeeWithRatio(feeRatio FeeRatio, fee *big.Int) (*big.Int, *big.Int) {
@@ -0,0 +1,448 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto/sha3"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// TxInternalDataFeeDelegatedSetCode represents a fee-delegated set code transaction of EIP-7702.
+// It sets the code of the authorities of AuthorizationList to the delegation
+// designators of the authorized addresses before calling Recipient.
+type TxInternalDataFeeDelegatedSetCode struct {
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    common.Address
+	Amount       *big.Int
+	From         common.Address
+	Payload      []byte
+
+	AuthorizationList []SetCodeAuthorization
+
+	TxSignatures
+
+	FeePayer           common.Address
+	FeePayerSignatures TxSignatures
+
+	// This is only used when marshaling to JSON.
+	Hash *common.Hash `json:"hash" rlp:"-"`
+}
+
+type TxInternalDataFeeDelegatedSetCodeJSON struct {
+	Type               TxType                 `json:"typeInt"`
+	TypeStr            string                 `json:"type"`
+	AccountNonce       hexutil.Uint64         `json:"nonce"`
+	Price              *hexutil.Big           `json:"gasPrice"`
+	GasLimit           hexutil.Uint64         `json:"gas"`
+	Recipient          common.Address         `json:"to"`
+	Amount             *hexutil.Big           `json:"value"`
+	From               common.Address         `json:"from"`
+	Payload            hexutil.Bytes          `json:"input"`
+	AuthorizationList  []SetCodeAuthorization `json:"authorizationList"`
+	TxSignatures       TxSignaturesJSON       `json:"signatures"`
+	FeePayer           common.Address         `json:"feePayer"`
+	FeePayerSignatures TxSignaturesJSON       `json:"feePayerSignatures"`
+	Hash               *common.Hash           `json:"hash"`
+}
+
+func newTxInternalDataFeeDelegatedSetCode() *TxInternalDataFeeDelegatedSetCode {
+	h := common.Hash{}
+	return &TxInternalDataFeeDelegatedSetCode{
+		Price:  new(big.Int),
+		Amount: new(big.Int),
+		Hash:   &h,
+	}
+}
+
+func newTxInternalDataFeeDelegatedSetCodeWithMap(values map[TxValueKeyType]interface{}) (*TxInternalDataFeeDelegatedSetCode, error) {
+	t := newTxInternalDataFeeDelegatedSetCode()
+
+	if v, ok := values[TxValueKeyNonce].(uint64); ok {
+		t.AccountNonce = v
+		delete(values, TxValueKeyNonce)
+	} else {
+		return nil, errValueKeyNonceMustUint64
+	}
+
+	if v, ok := values[TxValueKeyGasPrice].(*big.Int); ok {
+		t.Price.Set(v)
+		delete(values, TxValueKeyGasPrice)
+	} else {
+		return nil, errValueKeyGasPriceMustBigInt
+	}
+
+	if v, ok := values[TxValueKeyGasLimit].(uint64); ok {
+		t.GasLimit = v
+		delete(values, TxValueKeyGasLimit)
+	} else {
+		return nil, errValueKeyGasLimitMustUint64
+	}
+
+	if v, ok := values[TxValueKeyTo].(common.Address); ok {
+		t.Recipient = v
+		delete(values, TxValueKeyTo)
+	} else {
+		return nil, errValueKeyToMustAddress
+	}
+
+	if v, ok := values[TxValueKeyAmount].(*big.Int); ok {
+		t.Amount.Set(v)
+		delete(values, TxValueKeyAmount)
+	} else {
+		return nil, errValueKeyAmountMustBigInt
+	}
+
+	if v, ok := values[TxValueKeyFrom].(common.Address); ok {
+		t.From = v
+		delete(values, TxValueKeyFrom)
+	} else {
+		return nil, errValueKeyFromMustAddress
+	}
+
+	if v, ok := values[TxValueKeyData].([]byte); ok {
+		t.Payload = v
+		delete(values, TxValueKeyData)
+	} else {
+		return nil, errValueKeyDataMustByteSlice
+	}
+
+	if v, ok := values[TxValueKeyAuthorizationList].([]SetCodeAuthorization); ok {
+		t.AuthorizationList = copyAuthorizationList(v)
+		delete(values, TxValueKeyAuthorizationList)
+	} else {
+		return nil, errValueKeyAuthorizationListInvalid
+	}
+
+	if v, ok := values[TxValueKeyFeePayer].(common.Address); ok {
+		t.FeePayer = v
+		delete(values, TxValueKeyFeePayer)
+	} else {
+		return nil, errValueKeyFeePayerMustAddress
+	}
+
+	if len(values) != 0 {
+		for k := range values {
+			logger.Warn("unnecessary key", k.String())
+		}
+		return nil, errUndefinedKeyRemains
+	}
+
+	return t, nil
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) Type() TxType {
+	return TxTypeFeeDelegatedSetCode
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetRoleTypeForValidation() accountkey.RoleType {
+	return accountkey.RoleTransaction
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) Equal(a TxInternalData) bool {
+	ta, ok := a.(*TxInternalDataFeeDelegatedSetCode)
+	if !ok {
+		return false
+	}
+
+	return t.AccountNonce == ta.AccountNonce &&
+		t.Price.Cmp(ta.Price) == 0 &&
+		t.GasLimit == ta.GasLimit &&
+		t.Recipient == ta.Recipient &&
+		t.Amount.Cmp(ta.Amount) == 0 &&
+		t.From == ta.From &&
+		bytes.Equal(t.Payload, ta.Payload) &&
+		equalAuthorizationList(t.AuthorizationList, ta.AuthorizationList) &&
+		t.TxSignatures.equal(ta.TxSignatures) &&
+		t.FeePayer == ta.FeePayer &&
+		t.FeePayerSignatures.equal(ta.FeePayerSignatures)
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) IsLegacyTransaction() bool {
+	return false
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetPayload() []byte {
+	return t.Payload
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetAuthorizationList() []SetCodeAuthorization {
+	return t.AuthorizationList
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetAccountNonce() uint64 {
+	return t.AccountNonce
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetPrice() *big.Int {
+	return new(big.Int).Set(t.Price)
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetGasLimit() uint64 {
+	return t.GasLimit
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetRecipient() *common.Address {
+	if t.Recipient == (common.Address{}) {
+		return nil
+	}
+
+	to := common.Address(t.Recipient)
+	return &to
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetAmount() *big.Int {
+	return new(big.Int).Set(t.Amount)
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetFrom() common.Address {
+	return t.From
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetHash() *common.Hash {
+	return t.Hash
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetFeePayer() common.Address {
+	return t.FeePayer
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) GetFeePayerRawSignatureValues() TxSignatures {
+	return t.FeePayerSignatures.RawSignatureValues()
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) SetHash(h *common.Hash) {
+	t.Hash = h
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) SetSignature(s TxSignatures) {
+	t.TxSignatures = s
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) SetFeePayerSignatures(s TxSignatures) {
+	t.FeePayerSignatures = s
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) RecoverFeePayerPubkey(txhash common.Hash, homestead bool, vfunc func(*big.Int) *big.Int) ([]*ecdsa.PublicKey, error) {
+	return t.FeePayerSignatures.RecoverPubkey(txhash, homestead, vfunc)
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) String() string {
+	ser := newTxInternalDataSerializerWithValues(t)
+	tx := Transaction{data: t}
+	enc, _ := rlp.EncodeToBytes(ser)
+	return fmt.Sprintf(`
+	TX(%x)
+	Type:          %s
+	From:          %s
+	To:            %s
+	Nonce:         %v
+	GasPrice:      %#x
+	GasLimit:      %#x
+	Value:         %#x
+	Data:          %x
+	AuthList:      %v
+	Signature:     %s
+	FeePayer:      %s
+	FeePayerSig:   %s
+	Hex:           %x
+`,
+		tx.Hash(),
+		t.Type().String(),
+		t.From.String(),
+		t.Recipient.String(),
+		t.AccountNonce,
+		t.Price,
+		t.GasLimit,
+		t.Amount,
+		common.Bytes2Hex(t.Payload),
+		t.AuthorizationList,
+		t.TxSignatures.string(),
+		t.FeePayer.String(),
+		t.FeePayerSignatures.string(),
+		enc)
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) IntrinsicGas(currentBlockNumber uint64) (uint64, error) {
+	gas := params.TxGasContractExecution + params.TxGasFeeDelegated
+
+	gasPayloadWithGas, err := IntrinsicGasPayload(gas, t.Payload, false, *fork.Rules(big.NewInt(int64(currentBlockNumber))))
+	if err != nil {
+		return 0, err
+	}
+
+	return IntrinsicGasAuthorizations(gasPayloadWithGas, t.AuthorizationList)
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) SerializeForSignToBytes() []byte {
+	b, _ := rlp.EncodeToBytes(struct {
+		Txtype       TxType
+		AccountNonce uint64
+		Price        *big.Int
+		GasLimit     uint64
+		Recipient    common.Address
+		Amount       *big.Int
+		From         common.Address
+		Payload      []byte
+		AuthList     []SetCodeAuthorization
+	}{
+		t.Type(),
+		t.AccountNonce,
+		t.Price,
+		t.GasLimit,
+		t.Recipient,
+		t.Amount,
+		t.From,
+		t.Payload,
+		t.AuthorizationList,
+	})
+
+	return b
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) SerializeForSign() []interface{} {
+	return []interface{}{
+		t.Type(),
+		t.AccountNonce,
+		t.Price,
+		t.GasLimit,
+		t.Recipient,
+		t.Amount,
+		t.From,
+		t.Payload,
+		t.AuthorizationList,
+	}
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) SenderTxHash() common.Hash {
+	hw := sha3.NewKeccak256()
+	rlp.Encode(hw, t.Type())
+	rlp.Encode(hw, []interface{}{
+		t.AccountNonce,
+		t.Price,
+		t.GasLimit,
+		t.Recipient,
+		t.Amount,
+		t.From,
+		t.Payload,
+		t.AuthorizationList,
+		t.TxSignatures,
+	})
+
+	h := common.Hash{}
+
+	hw.Sum(h[:0])
+
+	return h
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) Validate(stateDB StateDB, currentBlockNumber uint64) error {
+	if err := validateSetCode(t.Recipient, t.AuthorizationList, currentBlockNumber); err != nil {
+		return err
+	}
+	return t.ValidateMutableValue(stateDB, currentBlockNumber)
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) ValidateMutableValue(stateDB StateDB, currentBlockNumber uint64) error {
+	// The recipient is not required to be a program account since it may be
+	// delegated by one of the authorizations of this transaction.
+	return nil
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) Execute(sender ContractRef, vm VM, stateDB StateDB, currentBlockNumber uint64, gas uint64, value *big.Int) (ret []byte, usedGas uint64, err error) {
+	///////////////////////////////////////////////////////
+	// OpcodeComputationCostLimit: The below code is commented and will be usd for debugging purposes.
+	//start := time.Now()
+	//defer func() {
+	//	elapsed := time.Since(start)
+	//	logger.Debug("[TxInternalDataFeeDelegatedSetCode] EVM execution done", "elapsed", elapsed)
+	//}()
+	///////////////////////////////////////////////////////
+	stateDB.IncNonce(sender.Address())
+	vm.ApplyAuthorizations(t.AuthorizationList)
+	return vm.Call(sender, t.Recipient, t.Payload, gas, value)
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) MakeRPCOutput() map[string]interface{} {
+	return map[string]interface{}{
+		"typeInt":            t.Type(),
+		"type":               t.Type().String(),
+		"gas":                hexutil.Uint64(t.GasLimit),
+		"gasPrice":           (*hexutil.Big)(t.Price),
+		"input":              hexutil.Bytes(t.Payload),
+		"nonce":              hexutil.Uint64(t.AccountNonce),
+		"to":                 t.Recipient,
+		"value":              (*hexutil.Big)(t.Amount),
+		"authorizationList":  t.AuthorizationList,
+		"signatures":         t.TxSignatures.ToJSON(),
+		"feePayer":           t.FeePayer,
+		"feePayerSignatures": t.FeePayerSignatures.ToJSON(),
+	}
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(TxInternalDataFeeDelegatedSetCodeJSON{
+		t.Type(),
+		t.Type().String(),
+		(hexutil.Uint64)(t.AccountNonce),
+		(*hexutil.Big)(t.Price),
+		(hexutil.Uint64)(t.GasLimit),
+		t.Recipient,
+		(*hexutil.Big)(t.Amount),
+		t.From,
+		t.Payload,
+		t.AuthorizationList,
+		t.TxSignatures.ToJSON(),
+		t.FeePayer,
+		t.FeePayerSignatures.ToJSON(),
+		t.Hash,
+	})
+}
+
+func (t *TxInternalDataFeeDelegatedSetCode) UnmarshalJSON(b []byte) error {
+	js := &TxInternalDataFeeDelegatedSetCodeJSON{}
+	if err := json.Unmarshal(b, js); err != nil {
+		return err
+	}
+
+	t.AccountNonce = uint64(js.AccountNonce)
+	t.Price = (*big.Int)(js.Price)
+	t.GasLimit = uint64(js.GasLimit)
+	t.Recipient = js.Recipient
+	t.Amount = (*big.Int)(js.Amount)
+	t.From = js.From
+	t.Payload = js.Payload
+	t.AuthorizationList = js.AuthorizationList
+	t.TxSignatures = js.TxSignatures.ToTxSignatures()
+	t.FeePayer = js.FeePayer
+	t.FeePayerSignatures = js.FeePayerSignatures.ToTxSignatures()
+	t.Hash = js.Hash
+
+	return nil
+}
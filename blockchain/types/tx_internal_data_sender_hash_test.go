@@ -58,6 +58,8 @@ func TestTransactionSenderTxHash(t *testing.T) {
 		{"FeeDelegatedCancelWithRatio", genFeeDelegatedCancelWithRatioTransaction()},
 		{"AccessList", genAccessListTransaction()},
 		{"DynamicFee", genDynamicFeeTransaction()},
+		{"SetCode", genSetCodeTransaction()},
+		{"FeeDelegatedSetCode", genFeeDelegatedSetCodeTransaction()},
 	}
 
 	testcases := []struct {
@@ -464,6 +466,52 @@ func testTransactionSenderTxHash(t *testing.T, tx TxInternalData) {
 
 		h := common.Hash{}
 
+		hw.Sum(h[:0])
+		senderTxHash := rawTx.GetTxInternalData().SenderTxHash()
+		assert.Equal(t, h, senderTxHash)
+
+	case *TxInternalDataEthereumSetCode:
+		hw := sha3.NewKeccak256()
+		rlp.Encode(hw, byte(rawTx.Type()))
+		rlp.Encode(hw, []interface{}{
+			v.ChainID,
+			v.AccountNonce,
+			v.GasTipCap,
+			v.GasFeeCap,
+			v.GasLimit,
+			v.Recipient,
+			v.Amount,
+			v.Payload,
+			v.AccessList,
+			v.AuthorizationList,
+			v.V,
+			v.R,
+			v.S,
+		})
+
+		h := common.Hash{}
+
+		hw.Sum(h[:0])
+		senderTxHash := rawTx.GetTxInternalData().SenderTxHash()
+		assert.Equal(t, h, senderTxHash)
+
+	case *TxInternalDataFeeDelegatedSetCode:
+		hw := sha3.NewKeccak256()
+		rlp.Encode(hw, rawTx.Type())
+		rlp.Encode(hw, []interface{}{
+			v.AccountNonce,
+			v.Price,
+			v.GasLimit,
+			v.Recipient,
+			v.Amount,
+			v.From,
+			v.Payload,
+			v.AuthorizationList,
+			v.TxSignatures,
+		})
+
+		h := common.Hash{}
+
 		hw.Sum(h[:0])
 		senderTxHash := rawTx.GetTxInternalData().SenderTxHash()
 		assert.Equal(t, h, senderTxHash)
@@ -73,6 +73,8 @@ func TestTransactionSerialization(t *testing.T) {
 		{"FeeDelegatedCancelWithRatio", genFeeDelegatedCancelWithRatioTransaction()},
 		{"AccessList", genAccessListTransaction()},
 		{"DynamicFee", genDynamicFeeTransaction()},
+		{"SetCode", genSetCodeTransaction()},
+		{"FeeDelegatedSetCode", genFeeDelegatedSetCodeTransaction()},
 	}
 
 	testcases := []struct {
@@ -286,6 +288,59 @@ func genDynamicFeeTransaction() TxInternalData {
 	return tx
 }
 
+func genAuthorizationList() []SetCodeAuthorization {
+	auth, err := SignSetCode(key, SetCodeAuthorization{
+		ChainID: big.NewInt(2),
+		Address: to,
+		Nonce:   nonce,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return []SetCodeAuthorization{auth}
+}
+
+func genSetCodeTransaction() TxInternalData {
+	tx, err := NewTxInternalDataWithMap(TxTypeEthereumSetCode, map[TxValueKeyType]interface{}{
+		TxValueKeyNonce:             nonce,
+		TxValueKeyTo:                &to,
+		TxValueKeyAmount:            amount,
+		TxValueKeyGasLimit:          gasLimit,
+		TxValueKeyGasFeeCap:         gasFeeCap,
+		TxValueKeyGasTipCap:         gasTipCap,
+		TxValueKeyData:              []byte("1234"),
+		TxValueKeyAccessList:        accesses,
+		TxValueKeyAuthorizationList: genAuthorizationList(),
+		TxValueKeyChainID:           big.NewInt(2),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return tx
+}
+
+func genFeeDelegatedSetCodeTransaction() TxInternalData {
+	d, err := NewTxInternalDataWithMap(TxTypeFeeDelegatedSetCode, map[TxValueKeyType]interface{}{
+		TxValueKeyNonce:             nonce,
+		TxValueKeyTo:                to,
+		TxValueKeyAmount:            amount,
+		TxValueKeyGasLimit:          gasLimit,
+		TxValueKeyGasPrice:          gasPrice,
+		TxValueKeyFrom:              from,
+		TxValueKeyData:              []byte("1234"),
+		TxValueKeyAuthorizationList: genAuthorizationList(),
+		TxValueKeyFeePayer:          feePayer,
+	})
+	if err != nil {
+		// Since we do not have testing.T here, call panic() instead of t.Fatal().
+		panic(err)
+	}
+
+	return d
+}
+
 func genValueTransferTransaction() TxInternalData {
 	d, err := NewTxInternalDataWithMap(TxTypeValueTransfer, map[TxValueKeyType]interface{}{
 		TxValueKeyNonce:    nonce,
@@ -20,6 +20,36 @@
 
 package vm
 
+import (
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/klaytn/klaytn/common"
+)
+
+// codeAnalysisCacheSize is the number of code analyses kept in the cache shared
+// by all the executions, so that hot contracts are not analyzed on every call.
+const codeAnalysisCacheSize = 1024
+
+var codeAnalysisCache, _ = lru.New(codeAnalysisCacheSize)
+
+// codeAnalysis is the result of the analysis of a code. It is immutable, so it
+// is shared by the executions of the code.
+type codeAnalysis struct {
+	bitmap  bitvec             // JUMPDEST analysis
+	fusions []superinstruction // Superinstruction starting at each pc, nil if there is none
+}
+
+// cachedCodeAnalysis returns the analysis of the code with the hash from the
+// cache, or analyzes the code and caches it.
+func cachedCodeAnalysis(hash common.Hash, code []byte) *codeAnalysis {
+	if cached, ok := codeAnalysisCache.Get(hash); ok {
+		return cached.(*codeAnalysis)
+	}
+	analysis := &codeAnalysis{bitmap: codeBitmap(code)}
+	analysis.fusions = codeFusions(code, analysis.bitmap)
+	codeAnalysisCache.Add(hash, analysis)
+	return analysis
+}
+
 // bitvec is a bit vector which maps bytes in a program.
 // An unset bit means the byte is an opcode, a set bit means
 // it's data (i.e. argument of PUSHxx).
@@ -0,0 +1,271 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+)
+
+// The classes of opcodes reported by the ComputationCostTracer.
+const (
+	opClassArithmetic  = "arithmetic"
+	opClassComparison  = "comparison"
+	opClassCrypto      = "crypto"
+	opClassEnvironment = "environment"
+	opClassBlock       = "block"
+	opClassStack       = "stack"
+	opClassMemory      = "memory"
+	opClassStorage     = "storage"
+	opClassFlow        = "flow"
+	opClassLog         = "log"
+	opClassSystem      = "system"
+	opClassPrecompiled = "precompiled"
+	opClassOther       = "other"
+)
+
+// opcodeClass returns the class of the opcode.
+func opcodeClass(op OpCode) string {
+	switch {
+	case op == STOP || op == JUMP || op == JUMPI || op == PC || op == JUMPDEST || (op >= RJUMP && op <= JUMPF):
+		return opClassFlow
+	case op == POP || (op >= PUSH0 && op <= SWAP16) || (op >= DUPN && op <= EXCHANGE):
+		return opClassStack
+	case op == MLOAD || op == MSTORE || op == MSTORE8 || op == MSIZE || op == MCOPY:
+		return opClassMemory
+	case op == SLOAD || op == SSTORE || op == TLOAD || op == TSTORE:
+		return opClassStorage
+	case op == GAS || (op >= ADDRESS && op <= 0x3f) || (op >= DATALOAD && op <= DATACOPY):
+		return opClassEnvironment
+	case op < LT:
+		return opClassArithmetic
+	case op >= LT && op < SHA3:
+		return opClassComparison
+	case op == SHA3:
+		return opClassCrypto
+	case op >= COINBASE && op <= 0x4f:
+		return opClassBlock
+	case op >= LOG0 && op <= LOG4:
+		return opClassLog
+	case op == EOFCREATE || op == RETURNCONTRACT || (op >= CREATE && op != INVALID):
+		return opClassSystem
+	default:
+		return opClassOther
+	}
+}
+
+// ComputationCostFrame is the computation cost of a call frame. The frames are
+// listed in the order of the calls.
+type ComputationCostFrame struct {
+	Type                 string         `json:"type"`
+	From                 common.Address `json:"from"`
+	To                   common.Address `json:"to"`
+	Depth                int            `json:"depth"`
+	ComputationCost      uint64         `json:"computationCost"`      // cost of the frame itself
+	TotalComputationCost uint64         `json:"totalComputationCost"` // cost including the calls of the frame
+}
+
+// OpcodeClassCost is the computation cost of the opcodes of a class.
+type OpcodeClassCost struct {
+	Class           string `json:"class"`
+	Count           uint64 `json:"count"`
+	ComputationCost uint64 `json:"computationCost"`
+}
+
+// ComputationCostBreakdown is the result of the ComputationCostTracer. The
+// opcode classes are sorted by computation cost in descending order.
+type ComputationCostBreakdown struct {
+	ComputationCost uint64                 `json:"computationCost"`
+	Frames          []ComputationCostFrame `json:"frames"`
+	OpcodeClasses   []OpcodeClassCost      `json:"opcodeClasses"`
+}
+
+type opcodeCost struct {
+	count           uint64
+	computationCost uint64
+}
+
+// computationCostFrame is a call frame being executed.
+type computationCostFrame struct {
+	index     int    // index in the frames of the result
+	ccStart   uint64 // computation cost spent before entering the frame
+	ccSteps   uint64 // computation cost of the opcodes executed in the frame
+	ccCalls   uint64 // computation cost of the calls of the frame
+	lastClass string // class of the opcode executed last in the frame
+}
+
+// ComputationCostTracer is a tracer breaking down the opcode computation cost
+// of an execution per call frame and per opcode class. The computation cost of
+// a precompiled contract is reported as the cost of its frame.
+type ComputationCostTracer struct {
+	env    *EVM
+	stack  []*computationCostFrame
+	frames []ComputationCostFrame
+
+	opcodes     map[OpCode]*opcodeCost
+	classes     map[string]*OpcodeClassCost
+	precompiled uint64 // computation cost of the precompiled contracts
+}
+
+// NewComputationCostTracer returns a new ComputationCostTracer.
+func NewComputationCostTracer() *ComputationCostTracer {
+	return &ComputationCostTracer{
+		opcodes: make(map[OpCode]*opcodeCost),
+		classes: make(map[string]*OpcodeClassCost),
+	}
+}
+
+func (t *ComputationCostTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *ComputationCostTracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *ComputationCostTracer) CaptureStart(env *EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	typ := CALL
+	if create {
+		typ = CREATE
+	}
+	t.enter(typ, from, to)
+}
+
+func (t *ComputationCostTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	t.exit()
+}
+
+func (t *ComputationCostTracer) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.enter(typ, from, to)
+}
+
+func (t *ComputationCostTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.exit()
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *ComputationCostTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *ScopeContext, depth int, err error) {
+	if len(t.stack) == 0 {
+		return
+	}
+	// The computation cost of the opcode is what the EVM actually spent, which
+	// is zero if the opcode failed before it was charged.
+	ccBefore := safeSub(env.Config.ComputationCostLimit, ccLeft)
+	ccOpcode = safeSub(env.GetOpCodeComputationCost(), ccBefore)
+
+	frame := t.stack[len(t.stack)-1]
+	frame.ccSteps += ccOpcode
+	frame.lastClass = opcodeClass(op)
+	t.addClass(frame.lastClass, 1, ccOpcode)
+
+	if t.opcodes[op] == nil {
+		t.opcodes[op] = new(opcodeCost)
+	}
+	t.opcodes[op].count++
+	t.opcodes[op].computationCost += ccOpcode
+}
+
+// CaptureFault implements the Tracer interface. The failed opcode was already
+// captured by CaptureState.
+func (t *ComputationCostTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *ScopeContext, depth int, err error) {
+}
+
+func (t *ComputationCostTracer) enter(typ OpCode, from, to common.Address) {
+	frame := &computationCostFrame{index: len(t.frames)}
+	if t.env != nil {
+		frame.ccStart = t.env.GetOpCodeComputationCost()
+	}
+	t.stack = append(t.stack, frame)
+	t.frames = append(t.frames, ComputationCostFrame{Type: typ.String(), From: from, To: to, Depth: len(t.stack) - 1})
+}
+
+// exit determines the computation cost of the frame and pops it.
+func (t *ComputationCostTracer) exit() {
+	if len(t.stack) == 0 {
+		return
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+
+	var total uint64
+	if t.env != nil {
+		total = safeSub(t.env.GetOpCodeComputationCost(), frame.ccStart)
+	}
+	// The cost not spent by the opcodes and the calls is of a precompiled
+	// contract if the frame executed no opcode.
+	if rest := safeSub(total, frame.ccSteps+frame.ccCalls); rest > 0 {
+		if frame.lastClass == "" {
+			t.precompiled += rest
+			t.addClass(opClassPrecompiled, 1, rest)
+		} else {
+			t.addClass(frame.lastClass, 0, rest)
+		}
+	}
+	t.frames[frame.index].TotalComputationCost = total
+	t.frames[frame.index].ComputationCost = safeSub(total, frame.ccCalls)
+	if len(t.stack) > 0 {
+		t.stack[len(t.stack)-1].ccCalls += total
+	}
+}
+
+func (t *ComputationCostTracer) addClass(class string, count, computationCost uint64) {
+	if t.classes[class] == nil {
+		t.classes[class] = &OpcodeClassCost{Class: class}
+	}
+	t.classes[class].Count += count
+	t.classes[class].ComputationCost += computationCost
+}
+
+// GetResult returns the computation cost broken down per call frame and per
+// opcode class.
+func (t *ComputationCostTracer) GetResult() *ComputationCostBreakdown {
+	result := &ComputationCostBreakdown{
+		Frames:        t.frames,
+		OpcodeClasses: make([]OpcodeClassCost, 0, len(t.classes)),
+	}
+	if len(t.frames) > 0 {
+		result.ComputationCost = t.frames[0].TotalComputationCost
+	}
+	for _, class := range t.classes {
+		result.OpcodeClasses = append(result.OpcodeClasses, *class)
+	}
+	sort.Slice(result.OpcodeClasses, func(i, j int) bool {
+		a, b := result.OpcodeClasses[i], result.OpcodeClasses[j]
+		if a.ComputationCost != b.ComputationCost {
+			return a.ComputationCost > b.ComputationCost
+		}
+		return a.Class < b.Class
+	})
+	return result
+}
+
+// ComputationCostUnder returns the computation cost of the traced execution
+// with the opcodes priced by the computation cost table under the rules. The
+// same opcodes are assumed to be executed, and the cost of the precompiled
+// contracts and of the opcodes missing in the table is not changed.
+func (t *ComputationCostTracer) ComputationCostUnder(rules params.Rules) uint64 {
+	jt := instructionSet(rules)
+	computationCost := t.precompiled
+	for op, cost := range t.opcodes {
+		if operation := jt[op]; operation != nil {
+			computationCost += cost.count * operation.computationCost
+		} else {
+			computationCost += cost.computationCost
+		}
+	}
+	return computationCost
+}
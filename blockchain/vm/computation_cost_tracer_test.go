@@ -0,0 +1,99 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputationCostTracer(t *testing.T) {
+	var (
+		tracer = NewComputationCostTracer()
+		evm    = newProfileTestEVM(t, tracer)
+		sender = common.HexToAddress("0x1000")
+		caller = common.HexToAddress("0xaaaa")
+		callee = common.HexToAddress("0xbbbb")
+		sha256 = common.BytesToAddress([]byte{2})
+	)
+	// The caller calls the callee storing a word, and then sha256 with 32 bytes.
+	deployTestCode(evm, caller, []byte{
+		byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH2), 0xbb, 0xbb, byte(PUSH3), 0x01, 0x86, 0xa0, byte(CALL), byte(POP),
+		byte(PUSH1), 32, byte(PUSH1), 0, byte(PUSH1), 32, byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH1), 2, byte(GAS), byte(CALL), byte(POP), byte(STOP),
+	})
+	deployTestCode(evm, callee, []byte{byte(PUSH1), 1, byte(PUSH1), 0, byte(SSTORE), byte(STOP)})
+
+	_, _, err := evm.Call(AccountRef(sender), caller, nil, 1000000, new(big.Int))
+	require.NoError(t, err)
+	result := tracer.GetResult()
+	assert.Equal(t, evm.GetOpCodeComputationCost(), result.ComputationCost)
+
+	require.Len(t, result.Frames, 3)
+	root, store, precompiled := result.Frames[0], result.Frames[1], result.Frames[2]
+	assert.Equal(t, ComputationCostFrame{
+		Type: "CALL", From: sender, To: caller, Depth: 0,
+		ComputationCost: root.ComputationCost, TotalComputationCost: result.ComputationCost,
+	}, root)
+	assert.Equal(t, root.TotalComputationCost, root.ComputationCost+store.TotalComputationCost+precompiled.TotalComputationCost)
+	assert.Equal(t, "CALL", store.Type)
+	assert.Equal(t, callee, store.To)
+	assert.Equal(t, 1, store.Depth)
+	assert.Equal(t, store.ComputationCost, store.TotalComputationCost)
+	assert.Equal(t, sha256, precompiled.To)
+	assert.NotZero(t, precompiled.ComputationCost)
+
+	classes := make(map[string]OpcodeClassCost)
+	var classCost uint64
+	for _, class := range result.OpcodeClasses {
+		classes[class.Class] = class
+		classCost += class.ComputationCost
+	}
+	assert.Equal(t, result.ComputationCost, classCost)
+	assert.Equal(t, OpcodeClassCost{opClassStorage, 1, params.SstoreComputationCostCancun}, classes[opClassStorage])
+	assert.Equal(t, OpcodeClassCost{opClassPrecompiled, 1, precompiled.ComputationCost}, classes[opClassPrecompiled])
+	assert.Equal(t, uint64(2), classes[opClassSystem].Count)
+	assert.Equal(t, uint64(2), classes[opClassFlow].Count)
+	for i := 1; i < len(result.OpcodeClasses); i++ {
+		assert.GreaterOrEqual(t, result.OpcodeClasses[i-1].ComputationCost, result.OpcodeClasses[i].ComputationCost)
+	}
+
+	// Only SSTORE is priced differently before Cancun.
+	assert.Equal(t, result.ComputationCost, tracer.ComputationCostUnder(evm.chainRules))
+	assert.Equal(t, result.ComputationCost+params.SstoreComputationCost-params.SstoreComputationCostCancun,
+		tracer.ComputationCostUnder(params.Rules{IsIstanbul: true, IsLondon: true, IsKore: true, IsShanghai: true}))
+}
+
+func TestOpcodeClass(t *testing.T) {
+	for op, class := range map[OpCode]string{
+		STOP: opClassFlow, ADD: opClassArithmetic, SIGNEXTEND: opClassArithmetic, LT: opClassComparison,
+		SAR: opClassComparison, SHA3: opClassCrypto, ADDRESS: opClassEnvironment, GAS: opClassEnvironment,
+		COINBASE: opClassBlock, BLOBBASEFEE: opClassBlock, POP: opClassStack, PUSH0: opClassStack,
+		PUSH32: opClassStack, DUP16: opClassStack, SWAP1: opClassStack, EXCHANGE: opClassStack,
+		MCOPY: opClassMemory, TSTORE: opClassStorage, JUMPDEST: opClassFlow, RJUMPV: opClassFlow,
+		DATACOPY: opClassEnvironment, LOG4: opClassLog, CALL: opClassSystem, EOFCREATE: opClassSystem,
+		SELFDESTRUCT: opClassSystem, INVALID: opClassOther, 0x0c: opClassArithmetic,
+	} {
+		assert.Equal(t, class, opcodeClass(op), op.String())
+	}
+}
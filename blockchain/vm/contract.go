@@ -52,14 +52,20 @@ type Contract struct {
 	caller          types.ContractRef
 	self            types.ContractRef
 
-	jumpdests map[common.Hash]bitvec // Aggregated result of JUMPDEST analysis.
-	analysis  bitvec                 // Locally cached result of JUMPDEST analysis
+	jumpdests map[common.Hash]*codeAnalysis // Aggregated result of code analysis.
+	analysis  *codeAnalysis                 // Locally cached result of code analysis
 
 	Code     []byte
 	CodeHash common.Hash
 	CodeAddr *common.Address
 	Input    []byte
 
+	// Container is the EOF container of the code, which is nil for legacy code.
+	// While running an EOF contract, Code is the code section being executed.
+	Container   *Container
+	codeSection uint16
+	returnStack []returnContext // Return addresses of CALLF
+
 	Gas   uint64
 	value *big.Int
 }
@@ -72,7 +78,7 @@ func NewContract(caller types.ContractRef, object types.ContractRef, value *big.
 		// Reuse JUMPDEST analysis from parent context if available.
 		c.jumpdests = parent.jumpdests
 	} else {
-		c.jumpdests = make(map[common.Hash]bitvec)
+		c.jumpdests = make(map[common.Hash]*codeAnalysis)
 	}
 
 	// Gas should be a pointer so it can safely be reduced through the run
@@ -101,9 +107,14 @@ func (c *Contract) validJumpdest(dest *uint256.Int) bool {
 // isCode returns true if the provided PC location is an actual opcode, as
 // opposed to a data-segment following a PUSHN operation.
 func (c *Contract) isCode(udest uint64) bool {
+	return c.analyze().bitmap.codeSegment(udest)
+}
+
+// analyze returns the analysis of the code.
+func (c *Contract) analyze() *codeAnalysis {
 	// Do we already have an analysis laying around?
 	if c.analysis != nil {
-		return c.analysis.codeSegment(udest)
+		return c.analysis
 	}
 	// Do we have a contract hash already?
 	// If we do have a hash, that means it's a 'regular' contract. For regular
@@ -112,23 +123,34 @@ func (c *Contract) isCode(udest uint64) bool {
 		// Does parent context have the analysis?
 		analysis, exist := c.jumpdests[c.CodeHash]
 		if !exist {
-			// Do the analysis and save in parent context
-			// We do not need to store it in c.analysis
-			analysis = codeBitmap(c.Code)
+			// Get the analysis shared by the executions of the code, and save
+			// it in parent context
+			analysis = cachedCodeAnalysis(c.CodeHash, c.Code)
 			c.jumpdests[c.CodeHash] = analysis
 		}
 		// Also stash it in current contract for faster access
 		c.analysis = analysis
-		return analysis.codeSegment(udest)
+		return analysis
 	}
 	// We don't have the code hash, most likely a piece of initcode not already
 	// in state trie. In that case, we do an analysis, and save it locally, so
 	// we don't have to recalculate it for every JUMP instruction in the execution
 	// However, we don't save it within the parent context
-	if c.analysis == nil {
-		c.analysis = codeBitmap(c.Code)
-	}
-	return c.analysis.codeSegment(udest)
+	c.analysis = &codeAnalysis{bitmap: codeBitmap(c.Code)}
+	return c.analysis
+}
+
+// returnContext is a return address of CALLF.
+type returnContext struct {
+	section uint16
+	pc      uint64
+}
+
+// setCodeSection switches the code to be executed to the given code section
+// of the EOF container.
+func (c *Contract) setCodeSection(section uint16) {
+	c.codeSection = section
+	c.Code = c.Container.codeSections[section]
 }
 
 // AsDelegate sets the contract to be a delegate call and returns the current
@@ -202,4 +224,5 @@ func (c *Contract) SetCodeOptionalHash(addr *common.Address, codeAndHash *codeAn
 	c.Code = codeAndHash.code
 	c.CodeHash = codeAndHash.hash
 	c.CodeAddr = addr
+	c.Container = codeAndHash.container
 }
@@ -36,8 +36,10 @@ import (
 	"github.com/klaytn/klaytn/common/math"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/crypto/blake2b"
+	"github.com/klaytn/klaytn/crypto/bls12381"
 	"github.com/klaytn/klaytn/crypto/bn256"
 	"github.com/klaytn/klaytn/crypto/kzg4844"
+	"github.com/klaytn/klaytn/crypto/secp256r1"
 	"github.com/klaytn/klaytn/kerrors"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/params"
@@ -136,7 +138,60 @@ var PrecompiledContractsCancun = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{3, 255}): &validateSender{},
 }
 
+// PrecompiledContractsPrague contains the default set of pre-compiled Klaytn
+// contracts based on Ethereum Prague.
+var PrecompiledContractsPrague = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{1}):      &ecrecover{},
+	common.BytesToAddress([]byte{2}):      &sha256hash{},
+	common.BytesToAddress([]byte{3}):      &ripemd160hash{},
+	common.BytesToAddress([]byte{4}):      &dataCopy{},
+	common.BytesToAddress([]byte{5}):      &bigModExp{eip2565: true},
+	common.BytesToAddress([]byte{6}):      &bn256AddIstanbul{},
+	common.BytesToAddress([]byte{7}):      &bn256ScalarMulIstanbul{},
+	common.BytesToAddress([]byte{8}):      &bn256PairingIstanbul{},
+	common.BytesToAddress([]byte{9}):      &blake2F{},
+	common.BytesToAddress([]byte{0x0a}):   &kzgPointEvaluation{},
+	common.BytesToAddress([]byte{0x0b}):   &bls12381G1Add{},
+	common.BytesToAddress([]byte{0x0c}):   &bls12381G1MultiExp{},
+	common.BytesToAddress([]byte{0x0d}):   &bls12381G2Add{},
+	common.BytesToAddress([]byte{0x0e}):   &bls12381G2MultiExp{},
+	common.BytesToAddress([]byte{0x0f}):   &bls12381Pairing{},
+	common.BytesToAddress([]byte{0x10}):   &bls12381MapG1{},
+	common.BytesToAddress([]byte{0x11}):   &bls12381MapG2{},
+	common.BytesToAddress([]byte{3, 253}): &vmLog{},
+	common.BytesToAddress([]byte{3, 254}): &feePayer{},
+	common.BytesToAddress([]byte{3, 255}): &validateSender{},
+}
+
+// PrecompiledContractsOsaka contains the default set of pre-compiled Klaytn
+// contracts based on Ethereum Osaka.
+var PrecompiledContractsOsaka = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{1}):      &ecrecover{},
+	common.BytesToAddress([]byte{2}):      &sha256hash{},
+	common.BytesToAddress([]byte{3}):      &ripemd160hash{},
+	common.BytesToAddress([]byte{4}):      &dataCopy{},
+	common.BytesToAddress([]byte{5}):      &bigModExp{eip2565: true},
+	common.BytesToAddress([]byte{6}):      &bn256AddIstanbul{},
+	common.BytesToAddress([]byte{7}):      &bn256ScalarMulIstanbul{},
+	common.BytesToAddress([]byte{8}):      &bn256PairingIstanbul{},
+	common.BytesToAddress([]byte{9}):      &blake2F{},
+	common.BytesToAddress([]byte{0x0a}):   &kzgPointEvaluation{},
+	common.BytesToAddress([]byte{0x0b}):   &bls12381G1Add{},
+	common.BytesToAddress([]byte{0x0c}):   &bls12381G1MultiExp{},
+	common.BytesToAddress([]byte{0x0d}):   &bls12381G2Add{},
+	common.BytesToAddress([]byte{0x0e}):   &bls12381G2MultiExp{},
+	common.BytesToAddress([]byte{0x0f}):   &bls12381Pairing{},
+	common.BytesToAddress([]byte{0x10}):   &bls12381MapG1{},
+	common.BytesToAddress([]byte{0x11}):   &bls12381MapG2{},
+	common.BytesToAddress([]byte{1, 0}):   &p256Verify{},
+	common.BytesToAddress([]byte{3, 253}): &vmLog{},
+	common.BytesToAddress([]byte{3, 254}): &feePayer{},
+	common.BytesToAddress([]byte{3, 255}): &validateSender{},
+}
+
 var (
+	PrecompiledAddressOsaka       []common.Address
+	PrecompiledAddressPrague      []common.Address
 	PrecompiledAddressCancun      []common.Address
 	PrecompiledAddressIstanbul    []common.Address
 	PrecompiledAddressesByzantium []common.Address
@@ -152,12 +207,22 @@ func init() {
 	for k := range PrecompiledContractsCancun {
 		PrecompiledAddressCancun = append(PrecompiledAddressCancun, k)
 	}
+	for k := range PrecompiledContractsPrague {
+		PrecompiledAddressPrague = append(PrecompiledAddressPrague, k)
+	}
+	for k := range PrecompiledContractsOsaka {
+		PrecompiledAddressOsaka = append(PrecompiledAddressOsaka, k)
+	}
 }
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
 	var precompiledContractAddrs []common.Address
 	switch {
+	case rules.IsOsaka:
+		precompiledContractAddrs = PrecompiledAddressOsaka
+	case rules.IsPrague:
+		precompiledContractAddrs = PrecompiledAddressPrague
 	case rules.IsCancun:
 		precompiledContractAddrs = PrecompiledAddressCancun
 	case rules.IsIstanbul:
@@ -166,6 +231,11 @@ func ActivePrecompiles(rules params.Rules) []common.Address {
 		precompiledContractAddrs = PrecompiledAddressesByzantium
 	}
 
+	// The custom precompiled contracts of a service chain are appended to a copy.
+	if len(rules.CustomPrecompiles) > 0 {
+		precompiledContractAddrs = append(append([]common.Address{}, precompiledContractAddrs...), rules.CustomPrecompiles...)
+	}
+
 	// After istanbulCompatible hf, need to support for vmversion0 contracts, too.
 	// VmVersion0 contracts are deployed before istanbulCompatible and they use byzantiumCompatible precompiled contracts.
 	// VmVersion0 contracts are the contracts deployed before istanbulCompatible hf.
@@ -728,6 +798,368 @@ func kZGToVersionedHash(kzg kzg4844.Commitment) common.Hash {
 	return h
 }
 
+var (
+	errBLS12381InvalidInputLength          = errors.New("invalid input length")
+	errBLS12381InvalidFieldElementTopBytes = errors.New("invalid field element top bytes")
+	errBLS12381G1PointSubgroup             = errors.New("g1 point is not on correct subgroup")
+	errBLS12381G2PointSubgroup             = errors.New("g2 point is not on correct subgroup")
+)
+
+// bls12381MultiExpCost returns the gas and computation cost of a multi-scalar
+// multiplication of k pairs, discounted as the EIP-2537 table for the group.
+func bls12381MultiExpCost(k int, mulGas, mulComputationCost uint64, discountTable []uint64) (uint64, uint64) {
+	if k == 0 {
+		return 0, 0
+	}
+	discount := discountTable[len(discountTable)-1]
+	if k < len(discountTable) {
+		discount = discountTable[k-1]
+	}
+	return uint64(k) * mulGas * discount / 1000, uint64(k) * mulComputationCost * discount / 1000
+}
+
+// bls12381G1Add implements EIP-2537 G1Add precompile.
+type bls12381G1Add struct{}
+
+// GetRequiredGasAndComputationCost returns the gas required to execute the pre-compiled contract
+// and the computation cost of the precompiled contract.
+func (c *bls12381G1Add) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381G1AddGas, params.Bls12381G1AddComputationCost
+}
+
+func (c *bls12381G1Add) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G1Add precompile.
+	// > G1 addition call expects `256` bytes as an input that is interpreted as byte concatenation of two G1 points (`128` bytes each).
+	// > Output is an encoding of addition operation result - single G1 point (`128` bytes).
+	if len(input) != 256 {
+		return nil, errBLS12381InvalidInputLength
+	}
+	g := bls12381.NewG1()
+
+	// Decode G1 point p_0, which is not required to be in the subgroup
+	p0, err := g.DecodePoint(input[:128])
+	if err != nil {
+		return nil, err
+	}
+	// Decode G1 point p_1
+	p1, err := g.DecodePoint(input[128:])
+	if err != nil {
+		return nil, err
+	}
+	r := g.New()
+	g.Add(r, p0, p1)
+	return g.EncodePoint(r), nil
+}
+
+// bls12381G1MultiExp implements EIP-2537 G1MSM precompile.
+type bls12381G1MultiExp struct{}
+
+// GetRequiredGasAndComputationCost returns the gas required to execute the pre-compiled contract
+// and the computation cost of the precompiled contract.
+func (c *bls12381G1MultiExp) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	// Calculate G1 point, scalar value pair length
+	k := len(input) / 160
+	return bls12381MultiExpCost(k, params.Bls12381G1MulGas, params.Bls12381G1MulComputationCost, params.Bls12381G1MultiExpDiscountTable[:])
+}
+
+func (c *bls12381G1MultiExp) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G1MSM precompile.
+	// G1 multi-scalar multiplication call expects `160*k` bytes as an input that is interpreted as byte concatenation of `k` slices each of them being a byte concatenation of encoding of G1 point (`128` bytes) and encoding of a scalar value (`32` bytes).
+	// Output is an encoding of multi-scalar multiplication operation result - single G1 point (`128` bytes).
+	k := len(input) / 160
+	if len(input) == 0 || len(input)%160 != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+	points := make([]*bls12381.PointG1, k)
+	scalars := make([]*big.Int, k)
+	g := bls12381.NewG1()
+
+	// Decode point scalar pairs
+	for i := 0; i < k; i++ {
+		off := 160 * i
+		t0, t1, t2 := off, off+128, off+160
+		p, err := g.DecodePoint(input[t0:t1])
+		if err != nil {
+			return nil, err
+		}
+		if !g.InCorrectSubgroup(p) {
+			return nil, errBLS12381G1PointSubgroup
+		}
+		points[i] = p
+		scalars[i] = new(big.Int).SetBytes(input[t1:t2])
+	}
+	r := g.New()
+	if k == 1 {
+		g.MulScalar(r, points[0], scalars[0])
+	} else if _, err := g.MultiExp(r, points, scalars); err != nil {
+		return nil, err
+	}
+	return g.EncodePoint(r), nil
+}
+
+// bls12381G2Add implements EIP-2537 G2Add precompile.
+type bls12381G2Add struct{}
+
+// GetRequiredGasAndComputationCost returns the gas required to execute the pre-compiled contract
+// and the computation cost of the precompiled contract.
+func (c *bls12381G2Add) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381G2AddGas, params.Bls12381G2AddComputationCost
+}
+
+func (c *bls12381G2Add) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G2Add precompile.
+	// > G2 addition call expects `512` bytes as an input that is interpreted as byte concatenation of two G2 points (`256` bytes each).
+	// > Output is an encoding of addition operation result - single G2 point (`256` bytes).
+	if len(input) != 512 {
+		return nil, errBLS12381InvalidInputLength
+	}
+	g := bls12381.NewG2()
+
+	// Decode G2 point p_0, which is not required to be in the subgroup
+	p0, err := g.DecodePoint(input[:256])
+	if err != nil {
+		return nil, err
+	}
+	// Decode G2 point p_1
+	p1, err := g.DecodePoint(input[256:])
+	if err != nil {
+		return nil, err
+	}
+	r := g.New()
+	g.Add(r, p0, p1)
+	return g.EncodePoint(r), nil
+}
+
+// bls12381G2MultiExp implements EIP-2537 G2MSM precompile.
+type bls12381G2MultiExp struct{}
+
+// GetRequiredGasAndComputationCost returns the gas required to execute the pre-compiled contract
+// and the computation cost of the precompiled contract.
+func (c *bls12381G2MultiExp) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	// Calculate G2 point, scalar value pair length
+	k := len(input) / 288
+	return bls12381MultiExpCost(k, params.Bls12381G2MulGas, params.Bls12381G2MulComputationCost, params.Bls12381G2MultiExpDiscountTable[:])
+}
+
+func (c *bls12381G2MultiExp) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G2MSM precompile logic
+	// > G2 multi-scalar multiplication call expects `288*k` bytes as an input that is interpreted as byte concatenation of `k` slices each of them being a byte concatenation of encoding of G2 point (`256` bytes) and encoding of a scalar value (`32` bytes).
+	// > Output is an encoding of multi-scalar multiplication operation result - single G2 point (`256` bytes).
+	k := len(input) / 288
+	if len(input) == 0 || len(input)%288 != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+	points := make([]*bls12381.PointG2, k)
+	scalars := make([]*big.Int, k)
+	g := bls12381.NewG2()
+
+	// Decode point scalar pairs
+	for i := 0; i < k; i++ {
+		off := 288 * i
+		t0, t1, t2 := off, off+256, off+288
+		p, err := g.DecodePoint(input[t0:t1])
+		if err != nil {
+			return nil, err
+		}
+		if !g.InCorrectSubgroup(p) {
+			return nil, errBLS12381G2PointSubgroup
+		}
+		points[i] = p
+		scalars[i] = new(big.Int).SetBytes(input[t1:t2])
+	}
+	r := g.New()
+	if k == 1 {
+		g.MulScalar(r, points[0], scalars[0])
+	} else if _, err := g.MultiExp(r, points, scalars); err != nil {
+		return nil, err
+	}
+	return g.EncodePoint(r), nil
+}
+
+// bls12381Pairing implements EIP-2537 Pairing precompile.
+type bls12381Pairing struct{}
+
+// GetRequiredGasAndComputationCost returns the gas required to execute the pre-compiled contract
+// and the computation cost of the precompiled contract.
+func (c *bls12381Pairing) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	k := uint64(len(input) / 384)
+	return params.Bls12381PairingBaseGas + k*params.Bls12381PairingPerPairGas,
+		params.Bls12381PairingBaseComputationCost + k*params.Bls12381PairingPerPairComputationCost
+}
+
+func (c *bls12381Pairing) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 Pairing precompile logic.
+	// > Pairing call expects `384*k` bytes as an inputs that is interpreted as byte concatenation of `k` slices. Each slice has the following structure:
+	// > - `128` bytes of G1 point encoding
+	// > - `256` bytes of G2 point encoding
+	// > Output is a `32` bytes where last single byte is `0x01` if pairing result is equal to multiplicative identity in a pairing target field and `0x00` otherwise
+	// > (which is equivalent of Big Endian encoding of Solidity values `uint256(1)` and `uin256(0)` respectively).
+	k := len(input) / 384
+	if len(input) == 0 || len(input)%384 != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	// Initialize BLS12-381 pairing engine
+	e := bls12381.NewPairingEngine()
+	g1, g2 := e.G1, e.G2
+
+	// Decode pairs
+	for i := 0; i < k; i++ {
+		off := 384 * i
+		t0, t1, t2 := off, off+128, off+384
+
+		// Decode G1 point
+		p1, err := g1.DecodePoint(input[t0:t1])
+		if err != nil {
+			return nil, err
+		}
+		// Decode G2 point
+		p2, err := g2.DecodePoint(input[t1:t2])
+		if err != nil {
+			return nil, err
+		}
+
+		// 'point is on curve' check already done,
+		// Here we need to apply subgroup checks.
+		if !g1.InCorrectSubgroup(p1) {
+			return nil, errBLS12381G1PointSubgroup
+		}
+		if !g2.InCorrectSubgroup(p2) {
+			return nil, errBLS12381G2PointSubgroup
+		}
+
+		// Update pairing engine with G1 and G2 points
+		e.AddPair(p1, p2)
+	}
+	// Prepare 32 byte output
+	out := make([]byte, 32)
+
+	// Compute pairing and set the result
+	if e.Check() {
+		out[31] = 1
+	}
+	return out, nil
+}
+
+// decodeBLS12381FieldElement decodes BLS12-381 elliptic curve field element.
+// Removes top 16 bytes of 64 byte input.
+func decodeBLS12381FieldElement(in []byte) ([]byte, error) {
+	if len(in) != 64 {
+		return nil, errors.New("invalid field element length")
+	}
+	// check top bytes
+	for i := 0; i < 16; i++ {
+		if in[i] != byte(0x00) {
+			return nil, errBLS12381InvalidFieldElementTopBytes
+		}
+	}
+	out := make([]byte, 48)
+	copy(out[:], in[16:])
+	return out, nil
+}
+
+// bls12381MapG1 implements EIP-2537 MapG1 precompile.
+type bls12381MapG1 struct{}
+
+// GetRequiredGasAndComputationCost returns the gas required to execute the pre-compiled contract
+// and the computation cost of the precompiled contract.
+func (c *bls12381MapG1) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381MapG1Gas, params.Bls12381MapG1ComputationCost
+}
+
+func (c *bls12381MapG1) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 Map_To_G1 precompile.
+	// > Field-to-curve call expects `64` bytes an an input that is interpreted as a an element of the base field.
+	// > Output of this call is `128` bytes and is G1 point following respective encoding rules.
+	if len(input) != 64 {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	// Decode input field element
+	fe, err := decodeBLS12381FieldElement(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute mapping
+	g := bls12381.NewG1()
+	r, err := g.MapToCurve(fe)
+	if err != nil {
+		return nil, err
+	}
+	return g.EncodePoint(r), nil
+}
+
+// bls12381MapG2 implements EIP-2537 MapG2 precompile.
+type bls12381MapG2 struct{}
+
+// GetRequiredGasAndComputationCost returns the gas required to execute the pre-compiled contract
+// and the computation cost of the precompiled contract.
+func (c *bls12381MapG2) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381MapG2Gas, params.Bls12381MapG2ComputationCost
+}
+
+func (c *bls12381MapG2) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 Map_FP2_To_G2 precompile logic.
+	// > Field-to-curve call expects `128` bytes an an input that is interpreted as a an element of the quadratic extension field.
+	// > Output of this call is `256` bytes and is G2 point following respective encoding rules.
+	if len(input) != 128 {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	// Decode input field element
+	fe := make([]byte, 96)
+	c0, err := decodeBLS12381FieldElement(input[:64])
+	if err != nil {
+		return nil, err
+	}
+	copy(fe[48:], c0)
+	c1, err := decodeBLS12381FieldElement(input[64:])
+	if err != nil {
+		return nil, err
+	}
+	copy(fe[:48], c1)
+
+	// Compute mapping
+	g := bls12381.NewG2()
+	r, err := g.MapToCurve(fe)
+	if err != nil {
+		return nil, err
+	}
+	return g.EncodePoint(r), nil
+}
+
+// p256Verify implements the secp256r1 signature verification precompile of RIP-7212.
+type p256Verify struct{}
+
+const p256VerifyInputLength = 160
+
+// GetRequiredGasAndComputationCost returns the gas required to execute the pre-compiled contract
+// and the computation cost of the precompiled contract.
+func (c *p256Verify) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.P256VerifyGas, params.P256VerifyComputationCost
+}
+
+// Run verifies the signature (r, s) of the hash by the public key (x, y), given
+// as 32 bytes each. It returns 1 in 32 bytes if the signature is valid, and
+// nothing otherwise, including when the input is malformed.
+func (c *p256Verify) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	if len(input) != p256VerifyInputLength {
+		return nil, nil
+	}
+	var (
+		hash = input[:32]
+		r    = new(big.Int).SetBytes(input[32:64])
+		s    = new(big.Int).SetBytes(input[64:96])
+		x    = new(big.Int).SetBytes(input[96:128])
+		y    = new(big.Int).SetBytes(input[128:160])
+	)
+	if !secp256r1.Verify(hash, r, s, x, y) {
+		return nil, nil
+	}
+	return common.LeftPadBytes(common.Big1.Bytes(), 32), nil
+}
+
 // vmLog implemented as a native contract.
 type vmLog struct{}
 
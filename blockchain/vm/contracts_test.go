@@ -59,17 +59,24 @@ type precompiledFailureTest struct {
 // allPrecompiles does not map to the actual set of precompiles, as it also contains
 // repriced versions of precompiles at certain slots
 var allPrecompiles = map[common.Address]PrecompiledContract{
-	common.BytesToAddress([]byte{1}):    &ecrecover{},
-	common.BytesToAddress([]byte{2}):    &sha256hash{},
-	common.BytesToAddress([]byte{3}):    &ripemd160hash{},
-	common.BytesToAddress([]byte{4}):    &dataCopy{},
-	common.BytesToAddress([]byte{5}):    &bigModExp{eip2565: false},
-	common.BytesToAddress([]byte{0xf5}): &bigModExp{eip2565: true},
-	common.BytesToAddress([]byte{6}):    &bn256AddIstanbul{},
-	common.BytesToAddress([]byte{7}):    &bn256ScalarMulIstanbul{},
-	common.BytesToAddress([]byte{8}):    &bn256PairingIstanbul{},
-	common.BytesToAddress([]byte{9}):    &blake2F{},
-	// TODO-klaytn import bls-signature precompiled contracts
+	common.BytesToAddress([]byte{1}):      &ecrecover{},
+	common.BytesToAddress([]byte{2}):      &sha256hash{},
+	common.BytesToAddress([]byte{3}):      &ripemd160hash{},
+	common.BytesToAddress([]byte{4}):      &dataCopy{},
+	common.BytesToAddress([]byte{5}):      &bigModExp{eip2565: false},
+	common.BytesToAddress([]byte{0xf5}):   &bigModExp{eip2565: true},
+	common.BytesToAddress([]byte{6}):      &bn256AddIstanbul{},
+	common.BytesToAddress([]byte{7}):      &bn256ScalarMulIstanbul{},
+	common.BytesToAddress([]byte{8}):      &bn256PairingIstanbul{},
+	common.BytesToAddress([]byte{9}):      &blake2F{},
+	common.BytesToAddress([]byte{0xb}):    &bls12381G1Add{},
+	common.BytesToAddress([]byte{0xc}):    &bls12381G1MultiExp{},
+	common.BytesToAddress([]byte{0xd}):    &bls12381G2Add{},
+	common.BytesToAddress([]byte{0xe}):    &bls12381G2MultiExp{},
+	common.BytesToAddress([]byte{0xf}):    &bls12381Pairing{},
+	common.BytesToAddress([]byte{0x10}):   &bls12381MapG1{},
+	common.BytesToAddress([]byte{0x11}):   &bls12381MapG2{},
+	common.BytesToAddress([]byte{1, 0}):   &p256Verify{},
 	common.BytesToAddress([]byte{0xa}):    &kzgPointEvaluation{},
 	common.BytesToAddress([]byte{3, 253}): &vmLog{},
 	common.BytesToAddress([]byte{3, 254}): &feePayer{},
@@ -257,6 +264,124 @@ func TestPrecompileBlake2FMalformedInput(t *testing.T) { testJsonFail("blake2F",
 func TestPrecompiledPointEvaluation(t *testing.T)      { testJson("pointEvaluation", "a", t) }
 func BenchmarkPrecompiledPointEvaluation(b *testing.B) { benchJson("pointEvaluation", "a", b) }
 
+// Tests the sample inputs from the BLS12-381 precompiles of EIP-2537.
+func TestPrecompiledBLS12381G1Add(t *testing.T)      { testJson("blsG1Add", "0b", t) }
+func TestPrecompiledBLS12381G1Mul(t *testing.T)      { testJson("blsG1Mul", "0c", t) }
+func TestPrecompiledBLS12381G1MultiExp(t *testing.T) { testJson("blsG1MultiExp", "0c", t) }
+func TestPrecompiledBLS12381G2Add(t *testing.T)      { testJson("blsG2Add", "0d", t) }
+func TestPrecompiledBLS12381G2Mul(t *testing.T)      { testJson("blsG2Mul", "0e", t) }
+func TestPrecompiledBLS12381G2MultiExp(t *testing.T) { testJson("blsG2MultiExp", "0e", t) }
+func TestPrecompiledBLS12381Pairing(t *testing.T)    { testJson("blsPairing", "0f", t) }
+func TestPrecompiledBLS12381MapG1(t *testing.T)      { testJson("blsMapG1", "10", t) }
+func TestPrecompiledBLS12381MapG2(t *testing.T)      { testJson("blsMapG2", "11", t) }
+
+func BenchmarkPrecompiledBLS12381G1Add(b *testing.B)      { benchJson("blsG1Add", "0b", b) }
+func BenchmarkPrecompiledBLS12381G1MultiExp(b *testing.B) { benchJson("blsG1MultiExp", "0c", b) }
+func BenchmarkPrecompiledBLS12381G2Add(b *testing.B)      { benchJson("blsG2Add", "0d", b) }
+func BenchmarkPrecompiledBLS12381G2MultiExp(b *testing.B) { benchJson("blsG2MultiExp", "0e", b) }
+func BenchmarkPrecompiledBLS12381Pairing(b *testing.B)    { benchJson("blsPairing", "0f", b) }
+func BenchmarkPrecompiledBLS12381MapG1(b *testing.B)      { benchJson("blsMapG1", "10", b) }
+func BenchmarkPrecompiledBLS12381MapG2(b *testing.B)      { benchJson("blsMapG2", "11", b) }
+
+// Failure tests
+func TestPrecompiledBLS12381G1AddFail(t *testing.T)      { testJsonFail("blsG1Add", "0b", t) }
+func TestPrecompiledBLS12381G1MulFail(t *testing.T)      { testJsonFail("blsG1Mul", "0c", t) }
+func TestPrecompiledBLS12381G1MultiExpFail(t *testing.T) { testJsonFail("blsG1MultiExp", "0c", t) }
+func TestPrecompiledBLS12381G2AddFail(t *testing.T)      { testJsonFail("blsG2Add", "0d", t) }
+func TestPrecompiledBLS12381G2MulFail(t *testing.T)      { testJsonFail("blsG2Mul", "0e", t) }
+func TestPrecompiledBLS12381G2MultiExpFail(t *testing.T) { testJsonFail("blsG2MultiExp", "0e", t) }
+func TestPrecompiledBLS12381PairingFail(t *testing.T)    { testJsonFail("blsPairing", "0f", t) }
+func TestPrecompiledBLS12381MapG1Fail(t *testing.T)      { testJsonFail("blsMapG1", "10", t) }
+func TestPrecompiledBLS12381MapG2Fail(t *testing.T)      { testJsonFail("blsMapG2", "11", t) }
+
+// Tests the gas of the BLS12-381 precompiles, which follows EIP-2537 including
+// the discounts of the multi-scalar multiplications.
+func TestPrecompiledBLS12381Gas(t *testing.T) {
+	for name, addr := range map[string]string{
+		"blsG1Add": "0b", "blsG1Mul": "0c", "blsG1MultiExp": "0c", "blsG2Add": "0d", "blsG2Mul": "0e",
+		"blsG2MultiExp": "0e", "blsPairing": "0f", "blsMapG1": "10", "blsMapG2": "11",
+	} {
+		tests, err := loadJson(name)
+		require.NoError(t, err)
+		p := allPrecompiles[common.HexToAddress(addr)]
+		for _, test := range tests {
+			gas, computationCost := p.GetRequiredGasAndComputationCost(common.Hex2Bytes(test.Input))
+			assert.Equal(t, test.Gas, gas, test.Name)
+			assert.NotZero(t, computationCost, test.Name)
+		}
+	}
+}
+
+// Tests that the BLS12-381 precompiles are only active after the prague fork.
+func TestPrecompiledBLS12381Activation(t *testing.T) {
+	config := &params.ChainConfig{
+		IstanbulCompatibleBlock: big.NewInt(0), LondonCompatibleBlock: big.NewInt(0), EthTxTypeCompatibleBlock: big.NewInt(0),
+		MagmaCompatibleBlock: big.NewInt(0), KoreCompatibleBlock: big.NewInt(0), ShanghaiCompatibleBlock: big.NewInt(0),
+		CancunCompatibleBlock: big.NewInt(0), DragonCompatibleBlock: big.NewInt(0), PragueCompatibleBlock: big.NewInt(10),
+	}
+	stateDb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	for _, tc := range []struct {
+		number int64
+		active bool
+	}{{9, false}, {10, true}} {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(tc.number)}, TxContext{}, stateDb, config, &Config{})
+		active := ActivePrecompiles(evm.chainRules)
+		for i := byte(0x0b); i <= 0x11; i++ {
+			addr := common.BytesToAddress([]byte{i})
+			_, ok := evm.GetPrecompiledContractMap(addr)[addr]
+			assert.Equal(t, tc.active, ok, "block %d, address %x", tc.number, i)
+			// 0x0b is listed before as well for the validateSender of the contracts deployed before istanbul
+			if i != 0x0b {
+				assert.Equal(t, tc.active, containsAddress(active, addr), "block %d, address %x", tc.number, i)
+			}
+		}
+	}
+}
+
+// Tests the sample inputs of the secp256r1 signature verification of RIP-7212,
+// which returns nothing for an invalid signature or input.
+func TestPrecompiledP256Verify(t *testing.T)      { testJson("p256Verify", "100", t) }
+func BenchmarkPrecompiledP256Verify(b *testing.B) { benchJson("p256Verify", "100", b) }
+
+// Tests that the secp256r1 signature verification is only active after the
+// osaka fork, with a constant cost.
+func TestPrecompiledP256VerifyActivation(t *testing.T) {
+	config := &params.ChainConfig{
+		IstanbulCompatibleBlock: big.NewInt(0), LondonCompatibleBlock: big.NewInt(0), EthTxTypeCompatibleBlock: big.NewInt(0),
+		MagmaCompatibleBlock: big.NewInt(0), KoreCompatibleBlock: big.NewInt(0), ShanghaiCompatibleBlock: big.NewInt(0),
+		CancunCompatibleBlock: big.NewInt(0), DragonCompatibleBlock: big.NewInt(0), PragueCompatibleBlock: big.NewInt(0),
+		OsakaCompatibleBlock: big.NewInt(10),
+	}
+	addr := common.BytesToAddress([]byte{1, 0})
+	stateDb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	for _, tc := range []struct {
+		number int64
+		active bool
+	}{{9, false}, {10, true}} {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(tc.number)}, TxContext{}, stateDb, config, &Config{})
+		_, ok := evm.GetPrecompiledContractMap(addr)[addr]
+		assert.Equal(t, tc.active, ok, "block %d", tc.number)
+		assert.Equal(t, tc.active, containsAddress(ActivePrecompiles(evm.chainRules), addr), "block %d", tc.number)
+	}
+
+	tests, err := loadJson("p256Verify")
+	require.NoError(t, err)
+	for _, test := range tests {
+		gas, computationCost := allPrecompiles[addr].GetRequiredGasAndComputationCost(common.Hex2Bytes(test.Input))
+		assert.Equal(t, params.P256VerifyGas, gas, test.Name)
+		assert.Equal(t, uint64(params.P256VerifyComputationCost), computationCost, test.Name)
+	}
+}
+
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
 // Tests the sample inputs of the vmLog
 func TestPrecompiledVmLog(t *testing.T)      { testJson("vmLog", "3fd", t) }
 func BenchmarkPrecompiledVmLog(b *testing.B) { benchJson("vmLog", "3fd", b) }
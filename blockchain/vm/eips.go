@@ -55,6 +55,8 @@ func EnableEIP(eipNum int, jt *JumpTable) error {
 		enable1344(jt)
 	case 1153:
 		enable1153(jt)
+	case 7702:
+		enable7702(jt)
 	default:
 		return fmt.Errorf("undefined eip %d", eipNum)
 	}
@@ -360,6 +362,197 @@ func enable1052(jt *JumpTable) {
 	}
 }
 
+// enable7702 applies EIP-7702 (Set EOA account code) to the gas of the call
+// opcodes, which additionally charge the access of the delegated address.
+func enable7702(jt *JumpTable) {
+	jt[CALL].dynamicGas = gasCallEIP7702
+	jt[CALLCODE].dynamicGas = gasCallCodeEIP7702
+	jt[STATICCALL].dynamicGas = gasStaticCallEIP7702
+	jt[DELEGATECALL].dynamicGas = gasDelegateCallEIP7702
+}
+
+// enable3540 makes EXTCODESIZE, EXTCODECOPY and EXTCODEHASH of legacy code see
+// the code of an EOF contract as the EOF magic "EF00".
+func enable3540(jt *JumpTable) {
+	jt[EXTCODESIZE].execute = opExtCodeSizeEOF
+	jt[EXTCODECOPY].execute = opExtCodeCopyEOF
+	jt[EXTCODEHASH].execute = opExtCodeHashEOF
+}
+
+// enableEOF turns a legacy jump table into the one of EOF code:
+// - EIP-4200 Static relative jumps: RJUMP, RJUMPI and RJUMPV
+// - EIP-4750 Functions: CALLF and RETF
+// - EIP-6206 JUMPF and non-returning functions
+// - EIP-663 DUPN, SWAPN and EXCHANGE
+// - EIP-7480 Data section access: DATALOAD, DATALOADN, DATASIZE and DATACOPY
+// - EIP-7620 EOF contract creation: EOFCREATE and RETURNCONTRACT
+// - EIP-7069 Revamped CALL instructions: EXTCALL, EXTDELEGATECALL, EXTSTATICCALL and RETURNDATALOAD
+// The instructions which observe code or gas and the legacy jumps, calls and
+// creations are undefined in EOF code.
+func enableEOF(jt *JumpTable) {
+	for _, op := range []OpCode{
+		CALLCODE, SELFDESTRUCT, JUMP, JUMPI, PC, CREATE, CREATE2, CODESIZE, CODECOPY,
+		EXTCODESIZE, EXTCODECOPY, EXTCODEHASH, GAS, CALL, DELEGATECALL, STATICCALL,
+	} {
+		jt[op] = nil
+	}
+	jt[RETURNDATACOPY] = &operation{
+		execute:         opReturnDataCopyEOF,
+		constantGas:     GasFastestStep,
+		dynamicGas:      gasReturnDataCopy,
+		minStack:        minStack(3, 0),
+		maxStack:        maxStack(3, 0),
+		memorySize:      memoryReturnDataCopy,
+		computationCost: params.ReturnDataCopyComputationCost,
+	}
+	jt[RJUMP] = &operation{
+		execute:         opRjump,
+		constantGas:     GasQuickStep,
+		minStack:        minStack(0, 0),
+		maxStack:        maxStack(0, 0),
+		computationCost: params.RjumpComputationCost,
+	}
+	jt[RJUMPI] = &operation{
+		execute:         opRjumpi,
+		constantGas:     params.RjumpiGas,
+		minStack:        minStack(1, 0),
+		maxStack:        maxStack(1, 0),
+		computationCost: params.RjumpiComputationCost,
+	}
+	jt[RJUMPV] = &operation{
+		execute:         opRjumpv,
+		constantGas:     params.RjumpiGas,
+		minStack:        minStack(1, 0),
+		maxStack:        maxStack(1, 0),
+		computationCost: params.RjumpvComputationCost,
+	}
+	// The stack heights of the following are checked by the validation and the
+	// execution with the type section.
+	jt[CALLF] = &operation{
+		execute:         opCallf,
+		constantGas:     GasFastStep,
+		minStack:        minStack(0, 0),
+		maxStack:        maxStack(0, 0),
+		computationCost: params.CallfComputationCost,
+	}
+	jt[RETF] = &operation{
+		execute:         opRetf,
+		constantGas:     GasFastestStep,
+		minStack:        minStack(0, 0),
+		maxStack:        maxStack(0, 0),
+		computationCost: params.RetfComputationCost,
+	}
+	jt[JUMPF] = &operation{
+		execute:         opJumpf,
+		constantGas:     GasFastStep,
+		minStack:        minStack(0, 0),
+		maxStack:        maxStack(0, 0),
+		computationCost: params.JumpfComputationCost,
+	}
+	jt[DUPN] = &operation{
+		execute:         opDupN,
+		constantGas:     GasFastestStep,
+		minStack:        minStack(0, 1),
+		maxStack:        maxStack(0, 1),
+		computationCost: params.DupNComputationCost,
+	}
+	jt[SWAPN] = &operation{
+		execute:         opSwapN,
+		constantGas:     GasFastestStep,
+		minStack:        minStack(0, 0),
+		maxStack:        maxStack(0, 0),
+		computationCost: params.SwapNComputationCost,
+	}
+	jt[EXCHANGE] = &operation{
+		execute:         opExchange,
+		constantGas:     GasFastestStep,
+		minStack:        minStack(0, 0),
+		maxStack:        maxStack(0, 0),
+		computationCost: params.ExchangeComputationCost,
+	}
+	jt[DATALOAD] = &operation{
+		execute:         opDataLoad,
+		constantGas:     params.DataLoadGas,
+		minStack:        minStack(1, 1),
+		maxStack:        maxStack(1, 1),
+		computationCost: params.DataLoadComputationCost,
+	}
+	jt[DATALOADN] = &operation{
+		execute:         opDataLoadN,
+		constantGas:     GasFastestStep,
+		minStack:        minStack(0, 1),
+		maxStack:        maxStack(0, 1),
+		computationCost: params.DataLoadNComputationCost,
+	}
+	jt[DATASIZE] = &operation{
+		execute:         opDataSize,
+		constantGas:     GasQuickStep,
+		minStack:        minStack(0, 1),
+		maxStack:        maxStack(0, 1),
+		computationCost: params.DataSizeComputationCost,
+	}
+	jt[DATACOPY] = &operation{
+		execute:         opDataCopy,
+		constantGas:     GasFastestStep,
+		dynamicGas:      gasDataCopy,
+		minStack:        minStack(3, 0),
+		maxStack:        maxStack(3, 0),
+		memorySize:      memoryDataCopy,
+		computationCost: params.DataCopyComputationCost,
+	}
+	jt[EOFCREATE] = &operation{
+		execute:         opEOFCreate,
+		constantGas:     params.CreateGas,
+		dynamicGas:      gasEOFCreate,
+		minStack:        minStack(4, 1),
+		maxStack:        maxStack(4, 1),
+		memorySize:      memoryEOFCreate,
+		computationCost: params.EofCreateComputationCost,
+	}
+	jt[RETURNCONTRACT] = &operation{
+		execute:         opReturnContract,
+		dynamicGas:      gasReturnContract,
+		minStack:        minStack(2, 0),
+		maxStack:        maxStack(2, 0),
+		memorySize:      memoryReturnContract,
+		computationCost: params.ReturnContractComputationCost,
+	}
+	jt[RETURNDATALOAD] = &operation{
+		execute:         opReturnDataLoad,
+		constantGas:     GasFastestStep,
+		minStack:        minStack(1, 1),
+		maxStack:        maxStack(1, 1),
+		computationCost: params.ReturnDataLoadComputationCost,
+	}
+	jt[EXTCALL] = &operation{
+		execute:         opExtCall,
+		constantGas:     params.WarmStorageReadCostEIP2929,
+		dynamicGas:      gasExtCall,
+		minStack:        minStack(4, 1),
+		maxStack:        maxStack(4, 1),
+		memorySize:      memoryExtCall,
+		computationCost: params.ExtCallComputationCost,
+	}
+	jt[EXTDELEGATECALL] = &operation{
+		execute:         opExtDelegateCall,
+		constantGas:     params.WarmStorageReadCostEIP2929,
+		dynamicGas:      gasExtDelegateCall,
+		minStack:        minStack(3, 1),
+		maxStack:        maxStack(3, 1),
+		memorySize:      memoryExtCall,
+		computationCost: params.ExtDelegateCallComputationCost,
+	}
+	jt[EXTSTATICCALL] = &operation{
+		execute:         opExtStaticCall,
+		constantGas:     params.WarmStorageReadCostEIP2929,
+		dynamicGas:      gasExtStaticCall,
+		minStack:        minStack(3, 1),
+		maxStack:        maxStack(3, 1),
+		memorySize:      memoryExtCall,
+		computationCost: params.ExtStaticCallComputationCost,
+	}
+}
+
 // As the cpu performance has been improved a lot, and as the storage size has increased a lot
 // recalculated the computation cost of some opcodes
 func enableCancunComputationCostModification(jt *JumpTable) {
@@ -0,0 +1,317 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klaytn/klaytn/crypto"
+)
+
+const (
+	offsetVersion   = 2
+	offsetTypesKind = 3
+	offsetCodeKind  = 6
+
+	kindTypes     = 1
+	kindCode      = 2
+	kindContainer = 3
+	kindData      = 0xff
+
+	eof1Version = 1
+
+	maxInputItems        = 127
+	maxOutputItems       = 127
+	maxStackHeight       = 1023
+	maxCodeSections      = 1024
+	maxContainerSections = 256
+	nonReturningFunction = 0x80
+)
+
+var (
+	eofMagic     = []byte{0xef, 0x00}
+	eofMagicHash = crypto.Keccak256Hash(eofMagic)
+
+	errInvalidMagic              = errors.New("invalid magic")
+	errUnknownVersion            = errors.New("unknown version")
+	errMissingTypeHeader         = errors.New("missing type header")
+	errInvalidTypeSize           = errors.New("invalid type section size")
+	errMissingCodeHeader         = errors.New("missing code header")
+	errInvalidCodeSize           = errors.New("invalid code size")
+	errInvalidContainerSize      = errors.New("invalid container size")
+	errTooManyContainerSections  = errors.New("too many container sections")
+	errMissingDataHeader         = errors.New("missing data header")
+	errMissingTerminator         = errors.New("missing header terminator")
+	errTooManyInputs             = errors.New("invalid type content, too many inputs")
+	errTooManyOutputs            = errors.New("invalid type content, too many outputs")
+	errInvalidSection0Type       = errors.New("invalid section 0 type, input and output should be zero and non-returning (0x80)")
+	errTooLargeMaxStackIncrease  = errors.New("invalid type content, max stack increase exceeds limit")
+	errInvalidContainerSectionNo = errors.New("invalid number of container sections")
+)
+
+// hasEOFMagic returns true if the code starts with the EOF magic bytes.
+func hasEOFMagic(code []byte) bool {
+	return len(code) >= len(eofMagic) && bytes.Equal(eofMagic, code[:len(eofMagic)])
+}
+
+// Container is an EVM Object Format (EOF) container of EIP-3540, which
+// consists of the code sections, the subcontainers to be created by
+// EOFCREATE or deployed by RETURNCONTRACT, and the data section.
+type Container struct {
+	types             []*functionMetadata
+	codeSections      [][]byte
+	subContainers     []*Container
+	subContainerCodes [][]byte
+	data              []byte
+	dataSize          int // declared size of the data section, which may exceed len(data)
+}
+
+// functionMetadata is an entry of the type section describing the stack
+// usage of a code section (EIP-4750).
+type functionMetadata struct {
+	inputs           uint8
+	outputs          uint8
+	maxStackIncrease uint16
+}
+
+// nonReturning returns true if the code section never returns to its caller.
+func (meta *functionMetadata) nonReturning() bool {
+	return meta.outputs == nonReturningFunction
+}
+
+// MarshalBinary encodes the container in the EOF format.
+func (c *Container) MarshalBinary() []byte {
+	// Build the header.
+	b := make([]byte, 0, 2*len(c.data))
+	b = append(b, eofMagic...)
+	b = append(b, eof1Version)
+	b = append(b, kindTypes)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.types)*4))
+	b = append(b, kindCode)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.codeSections)))
+	for _, code := range c.codeSections {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(code)))
+	}
+	if len(c.subContainers) > 0 {
+		b = append(b, kindContainer)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(c.subContainers)))
+		for _, code := range c.subContainerCodes {
+			b = binary.BigEndian.AppendUint32(b, uint32(len(code)))
+		}
+	}
+	b = append(b, kindData)
+	b = binary.BigEndian.AppendUint16(b, uint16(c.dataSize))
+	b = append(b, 0) // terminator
+
+	// Write the body.
+	for _, ty := range c.types {
+		b = append(b, ty.inputs, ty.outputs)
+		b = binary.BigEndian.AppendUint16(b, ty.maxStackIncrease)
+	}
+	for _, code := range c.codeSections {
+		b = append(b, code...)
+	}
+	for _, code := range c.subContainerCodes {
+		b = append(b, code...)
+	}
+	return append(b, c.data...)
+}
+
+// UnmarshalBinary decodes the EOF container in b, whose data section must be
+// complete.
+func (c *Container) UnmarshalBinary(b []byte) error {
+	size, err := c.unmarshal(b, false)
+	if err != nil {
+		return err
+	}
+	if size != len(b) {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidContainerSize, len(b), size)
+	}
+	return nil
+}
+
+// unmarshal decodes the EOF container at the beginning of b and returns its
+// size declared by the header. If truncated is true, the data section may be
+// shorter than declared, which is allowed for a subcontainer to be deployed
+// with the auxiliary data of RETURNCONTRACT.
+func (c *Container) unmarshal(b []byte, truncated bool) (int, error) {
+	if !hasEOFMagic(b) {
+		return 0, fmt.Errorf("%w: want %x", errInvalidMagic, eofMagic)
+	}
+	if len(b) < 14 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if b[offsetVersion] != eof1Version {
+		return 0, fmt.Errorf("%w: have %d, want %d", errUnknownVersion, b[offsetVersion], eof1Version)
+	}
+
+	// Parse the type section header.
+	if b[offsetTypesKind] != kindTypes {
+		return 0, fmt.Errorf("%w: found section kind %x instead", errMissingTypeHeader, b[offsetTypesKind])
+	}
+	typesSize := int(binary.BigEndian.Uint16(b[offsetTypesKind+1:]))
+	if typesSize < 4 || typesSize%4 != 0 || typesSize/4 > maxCodeSections {
+		return 0, fmt.Errorf("%w: type section size must be a multiple of 4 up to %d, have %d", errInvalidTypeSize, 4*maxCodeSections, typesSize)
+	}
+
+	// Parse the code section header.
+	if b[offsetCodeKind] != kindCode {
+		return 0, fmt.Errorf("%w: found section kind %x instead", errMissingCodeHeader, b[offsetCodeKind])
+	}
+	codeSizes, err := parseSectionSizes(b, offsetCodeKind+1, 2)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errInvalidCodeSize, err)
+	}
+	if len(codeSizes) != typesSize/4 {
+		return 0, fmt.Errorf("%w: mismatch of code sections count and type signatures, types %d, code %d", errInvalidCodeSize, typesSize/4, len(codeSizes))
+	}
+	offset := offsetCodeKind + 3 + 2*len(codeSizes)
+
+	// Parse the optional container section header.
+	var containerSizes []int
+	if offset < len(b) && b[offset] == kindContainer {
+		if containerSizes, err = parseSectionSizes(b, offset+1, 4); err != nil {
+			return 0, fmt.Errorf("%w: %v", errInvalidContainerSectionNo, err)
+		}
+		if len(containerSizes) > maxContainerSections {
+			return 0, fmt.Errorf("%w: have %d", errTooManyContainerSections, len(containerSizes))
+		}
+		offset += 3 + 4*len(containerSizes)
+	}
+
+	// Parse the data section header and the terminator.
+	if offset+3 >= len(b) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if b[offset] != kindData {
+		return 0, fmt.Errorf("%w: found section kind %x instead", errMissingDataHeader, b[offset])
+	}
+	dataSize := int(binary.BigEndian.Uint16(b[offset+1:]))
+	offset += 3
+	if b[offset] != 0 {
+		return 0, fmt.Errorf("%w: have %x", errMissingTerminator, b[offset])
+	}
+	offset++
+
+	// Check the body size except the data section which may be truncated.
+	bodySize := typesSize
+	for _, size := range codeSizes {
+		bodySize += size
+	}
+	for _, size := range containerSizes {
+		bodySize += size
+	}
+	if len(b) < offset+bodySize || (!truncated && len(b) < offset+bodySize+dataSize) {
+		return 0, fmt.Errorf("%w: have %d, want %d", errInvalidContainerSize, len(b), offset+bodySize+dataSize)
+	}
+
+	// Parse the type section.
+	types := make([]*functionMetadata, typesSize/4)
+	for i := range types {
+		sig := &functionMetadata{
+			inputs:           b[offset+i*4],
+			outputs:          b[offset+i*4+1],
+			maxStackIncrease: binary.BigEndian.Uint16(b[offset+i*4+2:]),
+		}
+		if sig.inputs > maxInputItems {
+			return 0, fmt.Errorf("%w for section %d: have %d", errTooManyInputs, i, sig.inputs)
+		}
+		if sig.outputs > maxOutputItems && !sig.nonReturning() {
+			return 0, fmt.Errorf("%w for section %d: have %d", errTooManyOutputs, i, sig.outputs)
+		}
+		if int(sig.inputs)+int(sig.maxStackIncrease) > maxStackHeight {
+			return 0, fmt.Errorf("%w for section %d: have %d", errTooLargeMaxStackIncrease, i, sig.maxStackIncrease)
+		}
+		types[i] = sig
+	}
+	if types[0].inputs != 0 || !types[0].nonReturning() {
+		return 0, fmt.Errorf("%w: have %d, %d", errInvalidSection0Type, types[0].inputs, types[0].outputs)
+	}
+	offset += typesSize
+
+	// Parse the code sections.
+	codeSections := make([][]byte, len(codeSizes))
+	for i, size := range codeSizes {
+		codeSections[i] = b[offset : offset+size]
+		offset += size
+	}
+
+	// Parse the subcontainers, whose data sections may be truncated.
+	var (
+		subContainers     []*Container
+		subContainerCodes [][]byte
+	)
+	for i, size := range containerSizes {
+		code := b[offset : offset+size]
+		sub := new(Container)
+		subSize, err := sub.unmarshal(code, true)
+		if err != nil {
+			return 0, fmt.Errorf("subcontainer %d: %w", i, err)
+		}
+		if subSize < size {
+			return 0, fmt.Errorf("%w: subcontainer %d has %d trailing bytes", errInvalidContainerSize, i, size-subSize)
+		}
+		subContainers = append(subContainers, sub)
+		subContainerCodes = append(subContainerCodes, code)
+		offset += size
+	}
+
+	// Parse the data section.
+	end := offset + dataSize
+	if end > len(b) {
+		end = len(b)
+	}
+	c.types = types
+	c.codeSections = codeSections
+	c.subContainers = subContainers
+	c.subContainerCodes = subContainerCodes
+	c.data = b[offset:end]
+	c.dataSize = dataSize
+	return offset + dataSize, nil
+}
+
+// parseSectionSizes parses the number of sections and their sizes of the
+// given byte width at idx in the header.
+func parseSectionSizes(b []byte, idx int, width int) ([]int, error) {
+	if idx+2 > len(b) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	count := int(binary.BigEndian.Uint16(b[idx:]))
+	if count == 0 {
+		return nil, errors.New("no sections")
+	}
+	idx += 2
+	if idx+count*width > len(b) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	sizes := make([]int, count)
+	for i := range sizes {
+		if width == 2 {
+			sizes[i] = int(binary.BigEndian.Uint16(b[idx+i*width:]))
+		} else {
+			sizes[i] = int(binary.BigEndian.Uint32(b[idx+i*width:]))
+		}
+		if sizes[i] == 0 {
+			return nil, fmt.Errorf("section %d is empty", i)
+		}
+	}
+	return sizes, nil
+}
@@ -0,0 +1,407 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/holiman/uint256"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/klaytn/klaytn/params"
+)
+
+// opRjump implements RJUMP of EIP-4200, which jumps by the signed offset
+// relative to the next instruction.
+func opRjump(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.evm.Cancelled() {
+		return nil, errStopToken
+	}
+	offset := int64(int16(binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:])))
+	*pc = uint64(int64(*pc+3)+offset) - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
+
+func opRjumpi(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	cond := scope.Stack.pop()
+	if cond.IsZero() {
+		*pc += 2
+		return nil, nil
+	}
+	return opRjump(pc, interpreter, scope)
+}
+
+func opRjumpv(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.evm.Cancelled() {
+		return nil, errStopToken
+	}
+	var (
+		code  = scope.Contract.Code
+		count = uint64(code[*pc+1]) + 1
+		idx   = scope.Stack.pop()
+	)
+	if idx, overflow := idx.Uint64WithOverflow(); !overflow && idx < count {
+		offset := int64(int16(binary.BigEndian.Uint16(code[*pc+2+2*idx:])))
+		*pc = uint64(int64(*pc+2+2*count)+offset) - 1 // pc will be increased by the interpreter loop
+		return nil, nil
+	}
+	// Fall through to the next instruction if the index is out of the table.
+	*pc += 1 + 2*count
+	return nil, nil
+}
+
+// opCallf implements CALLF of EIP-4750, which calls a code section with the
+// return address pushed to the return stack.
+func opCallf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		contract = scope.Contract
+		section  = binary.BigEndian.Uint16(contract.Code[*pc+1:])
+		typ      = contract.Container.types[section]
+	)
+	if scope.Stack.len()+int(typ.maxStackIncrease) > int(params.StackLimit) {
+		return nil, ErrEOFStackOverflow
+	}
+	if len(contract.returnStack) >= int(params.StackLimit) {
+		return nil, ErrEOFReturnStackExceeded
+	}
+	contract.returnStack = append(contract.returnStack, returnContext{
+		section: contract.codeSection,
+		pc:      *pc + 3,
+	})
+	contract.setCodeSection(section)
+	*pc = math.MaxUint64 // pc will be increased to 0 by the interpreter loop
+	return nil, nil
+}
+
+func opRetf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		contract = scope.Contract
+		last     = contract.returnStack[len(contract.returnStack)-1]
+	)
+	contract.returnStack = contract.returnStack[:len(contract.returnStack)-1]
+	contract.setCodeSection(last.section)
+	*pc = last.pc - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
+
+// opJumpf implements JUMPF of EIP-6206, which jumps to a code section
+// without changing the return stack.
+func opJumpf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		contract = scope.Contract
+		section  = binary.BigEndian.Uint16(contract.Code[*pc+1:])
+		typ      = contract.Container.types[section]
+	)
+	if scope.Stack.len()+int(typ.maxStackIncrease) > int(params.StackLimit) {
+		return nil, ErrEOFStackOverflow
+	}
+	contract.setCodeSection(section)
+	*pc = math.MaxUint64 // pc will be increased to 0 by the interpreter loop
+	return nil, nil
+}
+
+// opDupN, opSwapN and opExchange implement the stack instructions of
+// EIP-663 whose operands are given by the immediate.
+func opDupN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	n := int(scope.Contract.Code[*pc+1]) + 1
+	scope.Stack.dup(n)
+	*pc += 1
+	return nil, nil
+}
+
+func opSwapN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	n := int(scope.Contract.Code[*pc+1]) + 1
+	scope.Stack.swap(n + 1)
+	*pc += 1
+	return nil, nil
+}
+
+func opExchange(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		imm = scope.Contract.Code[*pc+1]
+		n   = int(imm>>4) + 1
+		m   = int(imm&0x0f) + 1
+		a   = scope.Stack.Back(n)
+		b   = scope.Stack.Back(n + m)
+	)
+	*a, *b = *b, *a
+	*pc += 1
+	return nil, nil
+}
+
+// opDataLoad, opDataLoadN, opDataSize and opDataCopy implement the data
+// section access of EIP-7480.
+func opDataLoad(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	offset := scope.Stack.peek()
+	offset64, overflow := offset.Uint64WithOverflow()
+	if overflow {
+		offset64 = math.MaxUint64
+	}
+	offset.SetBytes(getData(scope.Contract.Container.data, offset64, 32))
+	return nil, nil
+}
+
+func opDataLoadN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	offset := uint64(binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:]))
+	scope.Stack.push(new(uint256.Int).SetBytes(getData(scope.Contract.Container.data, offset, 32)))
+	*pc += 2
+	return nil, nil
+}
+
+func opDataSize(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	scope.Stack.push(new(uint256.Int).SetUint64(uint64(len(scope.Contract.Container.data))))
+	return nil, nil
+}
+
+func opDataCopy(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		memOffset = scope.Stack.pop()
+		offset    = scope.Stack.pop()
+		size      = scope.Stack.pop()
+	)
+	offset64, overflow := offset.Uint64WithOverflow()
+	if overflow {
+		offset64 = math.MaxUint64
+	}
+	scope.Memory.Set(memOffset.Uint64(), size.Uint64(), getData(scope.Contract.Container.data, offset64, size.Uint64()))
+	return nil, nil
+}
+
+// opReturnDataLoad implements RETURNDATALOAD of EIP-7069, which reads the
+// return data padded with zeros.
+func opReturnDataLoad(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	offset := scope.Stack.peek()
+	offset64, overflow := offset.Uint64WithOverflow()
+	if overflow {
+		offset64 = math.MaxUint64
+	}
+	offset.SetBytes(getData(interpreter.returnData, offset64, 32))
+	return nil, nil
+}
+
+// opReturnDataCopyEOF is RETURNDATACOPY in EOF code, which pads the return
+// data with zeros instead of failing on an out-of-bounds read (EIP-7069).
+func opReturnDataCopyEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		memOffset = scope.Stack.pop()
+		offset    = scope.Stack.pop()
+		size      = scope.Stack.pop()
+	)
+	offset64, overflow := offset.Uint64WithOverflow()
+	if overflow {
+		offset64 = math.MaxUint64
+	}
+	scope.Memory.Set(memOffset.Uint64(), size.Uint64(), getData(interpreter.returnData, offset64, size.Uint64()))
+	return nil, nil
+}
+
+// opEOFCreate implements EOFCREATE of EIP-7620, which creates a contract from
+// a subcontainer with the given input as the calldata of its initcode.
+func opEOFCreate(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.readOnly {
+		return nil, ErrWriteProtection
+	}
+	var (
+		idx               = scope.Contract.Code[*pc+1]
+		value             = scope.Stack.pop()
+		salt              = scope.Stack.pop()
+		inOffset, inSize  = scope.Stack.pop(), scope.Stack.pop()
+		input             = scope.Memory.GetCopy(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+		initContainer     = scope.Contract.Container.subContainers[idx]
+		initContainerCode = scope.Contract.Container.subContainerCodes[idx]
+	)
+	*pc += 1
+
+	// Charge hashing the initcode container, whose size is given by the
+	// immediate not by the stack.
+	if !scope.Contract.UseGas(params.Sha3WordGas * toWordSize(uint64(len(initContainerCode)))) {
+		return nil, kerrors.ErrOutOfGas
+	}
+	gas := scope.Contract.Gas
+	gas -= gas / 64
+	scope.Contract.UseGas(gas)
+
+	// reuse size int for stackvalue
+	stackvalue := inSize
+	bigVal := common.Big0
+	if !value.IsZero() {
+		bigVal = value.ToBig()
+	}
+	res, addr, returnGas, suberr := interpreter.evm.EOFCreate(scope.Contract, initContainer, initContainerCode, input, gas, bigVal, &salt)
+	if suberr != nil {
+		stackvalue.Clear()
+	} else {
+		stackvalue.SetBytes(addr.Bytes())
+	}
+	scope.Stack.push(&stackvalue)
+	scope.Contract.Gas += returnGas
+
+	if suberr == ErrExecutionReverted {
+		interpreter.returnData = res // set REVERT data to return data buffer
+		return res, nil
+	}
+	interpreter.returnData = nil // clear dirty return data buffer
+	return nil, nil
+}
+
+// opReturnContract implements RETURNCONTRACT of EIP-7620, which ends the
+// initcode and returns a subcontainer with the auxiliary data appended to its
+// data section as the code to be deployed.
+func opReturnContract(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		idx          = scope.Contract.Code[*pc+1]
+		offset, size = scope.Stack.pop(), scope.Stack.pop()
+		aux          = scope.Memory.GetPtr(int64(offset.Uint64()), int64(size.Uint64()))
+		deploy       = *scope.Contract.Container.subContainers[idx]
+	)
+	dataSize := len(deploy.data) + len(aux)
+	if dataSize < deploy.dataSize || dataSize > math.MaxUint16 {
+		return nil, ErrInvalidEOFAuxDataSize
+	}
+	data := make([]byte, 0, dataSize)
+	deploy.data = append(append(data, deploy.data...), aux...)
+	deploy.dataSize = dataSize
+	return deploy.MarshalBinary(), errStopToken
+}
+
+// opExtCall, opExtDelegateCall and opExtStaticCall implement the EXTCALL
+// family of EIP-7069, which gives all but the retained gas to the callee and
+// pushes 0 on success, 1 on revert or a light failure, and 2 on failure.
+func opExtCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	addr, inOffset, inSize, value := scope.Stack.pop(), scope.Stack.pop(), scope.Stack.pop(), scope.Stack.pop()
+	if interpreter.readOnly && !value.IsZero() {
+		return nil, ErrWriteProtection
+	}
+	return extCall(EXTCALL, interpreter, scope, &addr, &inOffset, &inSize, &value)
+}
+
+func opExtDelegateCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	addr, inOffset, inSize := scope.Stack.pop(), scope.Stack.pop(), scope.Stack.pop()
+	return extCall(EXTDELEGATECALL, interpreter, scope, &addr, &inOffset, &inSize, new(uint256.Int))
+}
+
+func opExtStaticCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	addr, inOffset, inSize := scope.Stack.pop(), scope.Stack.pop(), scope.Stack.pop()
+	return extCall(EXTSTATICCALL, interpreter, scope, &addr, &inOffset, &inSize, new(uint256.Int))
+}
+
+func extCall(typ OpCode, interpreter *EVMInterpreter, scope *ScopeContext, addr, inOffset, inSize, value *uint256.Int) ([]byte, error) {
+	if addr.BitLen() > 160 {
+		return nil, ErrInvalidEOFCallAddress
+	}
+	var (
+		evm      = interpreter.evm
+		contract = scope.Contract
+		toAddr   = common.Address(addr.Bytes20())
+		args     = scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+		bigVal   = common.Big0
+		retained = contract.Gas / 64
+		gas      uint64
+		ret      []byte
+		err      error
+		status   = new(uint256.Int)
+	)
+	if !value.IsZero() {
+		bigVal = value.ToBig()
+	}
+	if retained < params.ExtCallMinRetainedGas {
+		retained = params.ExtCallMinRetainedGas
+	}
+	if contract.Gas > retained {
+		gas = contract.Gas - retained
+	}
+
+	switch {
+	case gas < params.ExtCallMinCalleeGas,
+		evm.depth > int(params.CallCreateDepth),
+		bigVal.Sign() != 0 && !evm.Context.CanTransfer(evm.StateDB, contract.Address(), bigVal),
+		typ == EXTDELEGATECALL && !hasEOFMagic(evm.resolveCode(toAddr)):
+		// A light failure keeps the gas of the caller without calling.
+		status.SetOne()
+	default:
+		contract.UseGas(gas)
+		var returnGas uint64
+		switch typ {
+		case EXTCALL:
+			ret, returnGas, err = evm.Call(contract, toAddr, args, gas, bigVal)
+		case EXTDELEGATECALL:
+			ret, returnGas, err = evm.DelegateCall(contract, toAddr, args, gas)
+		case EXTSTATICCALL:
+			ret, returnGas, err = evm.StaticCall(contract, toAddr, args, gas)
+		}
+		contract.Gas += returnGas
+
+		switch err {
+		case nil:
+		case ErrExecutionReverted, ErrDepth, ErrInsufficientBalance:
+			status.SetOne()
+		default:
+			status.SetUint64(2)
+		}
+	}
+	scope.Stack.push(status)
+	interpreter.returnData = ret
+	return ret, nil
+}
+
+// opExtCodeSizeEOF, opExtCodeCopyEOF and opExtCodeHashEOF are the legacy
+// instructions which see the code of an EOF contract as the EOF magic only,
+// so that legacy contracts cannot introspect EOF code (EIP-3540).
+func opExtCodeSizeEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	slot := scope.Stack.peek()
+	code := interpreter.evm.StateDB.GetCode(slot.Bytes20())
+	if hasEOFMagic(code) {
+		code = eofMagic
+	}
+	slot.SetUint64(uint64(len(code)))
+	return nil, nil
+}
+
+func opExtCodeCopyEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		stack      = scope.Stack
+		a          = stack.pop()
+		memOffset  = stack.pop()
+		codeOffset = stack.pop()
+		length     = stack.pop()
+	)
+	uint64CodeOffset, overflow := codeOffset.Uint64WithOverflow()
+	if overflow {
+		uint64CodeOffset = math.MaxUint64
+	}
+	code := interpreter.evm.StateDB.GetCode(a.Bytes20())
+	if hasEOFMagic(code) {
+		code = eofMagic
+	}
+	scope.Memory.Set(memOffset.Uint64(), length.Uint64(), getData(code, uint64CodeOffset, length.Uint64()))
+	return nil, nil
+}
+
+func opExtCodeHashEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	slot := scope.Stack.peek()
+	address := common.Address(slot.Bytes20())
+	switch {
+	case interpreter.evm.StateDB.Empty(address):
+		slot.Clear()
+	case hasEOFMagic(interpreter.evm.StateDB.GetCode(address)):
+		slot.SetBytes(eofMagicHash.Bytes())
+	default:
+		slot.SetBytes(interpreter.evm.StateDB.GetCodeHash(address).Bytes())
+	}
+	return nil, nil
+}
@@ -0,0 +1,335 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeContainer builds an EOF container whose data section is complete.
+func makeContainer(types []*functionMetadata, codeSections [][]byte, subContainers []*Container, data []byte) *Container {
+	c := &Container{
+		types:         types,
+		codeSections:  codeSections,
+		subContainers: subContainers,
+		data:          data,
+		dataSize:      len(data),
+	}
+	for _, sub := range subContainers {
+		c.subContainerCodes = append(c.subContainerCodes, sub.MarshalBinary())
+	}
+	return c
+}
+
+// nonReturning is the type of a non-returning code section using the given stack.
+func nonReturning(maxStackIncrease uint16) *functionMetadata {
+	return &functionMetadata{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: maxStackIncrease}
+}
+
+func TestEOFMarshaling(t *testing.T) {
+	sub := makeContainer([]*functionMetadata{nonReturning(0)}, [][]byte{{byte(STOP)}}, nil, []byte{})
+	for i, c := range []*Container{
+		makeContainer([]*functionMetadata{nonReturning(0)}, [][]byte{{byte(STOP)}}, nil, []byte{}),
+		makeContainer(
+			[]*functionMetadata{nonReturning(1), {inputs: 2, outputs: 3, maxStackIncrease: 4}},
+			[][]byte{{byte(PUSH0), byte(STOP)}, {byte(INVALID)}},
+			nil,
+			[]byte{0x01, 0x02, 0x03},
+		),
+		makeContainer([]*functionMetadata{nonReturning(0)}, [][]byte{{byte(STOP)}}, []*Container{sub, sub}, []byte{0xff}),
+	} {
+		b := c.MarshalBinary()
+		var got Container
+		require.NoError(t, got.UnmarshalBinary(b), "test %d", i)
+		assert.True(t, reflect.DeepEqual(c, &got), "test %d: have %+v, want %+v", i, got, c)
+		assert.Equal(t, b, got.MarshalBinary(), "test %d", i)
+	}
+
+	// A subcontainer may have a truncated data section.
+	truncated := makeContainer([]*functionMetadata{nonReturning(0)}, [][]byte{{byte(STOP)}}, nil, []byte{0x01})
+	truncated.dataSize = 3
+	c := makeContainer([]*functionMetadata{nonReturning(0)}, [][]byte{{byte(STOP)}}, []*Container{truncated}, []byte{})
+	var got Container
+	require.NoError(t, got.UnmarshalBinary(c.MarshalBinary()))
+	assert.Equal(t, []byte{0x01}, got.subContainers[0].data)
+	assert.Equal(t, 3, got.subContainers[0].dataSize)
+
+	// But the top level container may not.
+	assert.ErrorIs(t, got.UnmarshalBinary(truncated.MarshalBinary()), errInvalidContainerSize)
+}
+
+func TestEOFUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		code string
+		err  error
+	}{
+		{"ef", errInvalidMagic},
+		{"ef000101000402000100", io.ErrUnexpectedEOF},
+		{"ef00020100040200010001ff0000000080000000", errUnknownVersion},
+		{"ef00010200040200010001ff0000000080000000", errMissingTypeHeader},
+		{"ef00010100030200010001ff0000000080000000", errInvalidTypeSize},
+		{"ef00010100040300010001ff0000000080000000", errMissingCodeHeader},
+		{"ef00010100040200010000ff0000000080000000", errInvalidCodeSize},
+		{"ef00010100080200010001ff000000008000000080000000", errInvalidCodeSize},
+		{"ef00010100040200010001040000000080000000", errMissingDataHeader},
+		{"ef00010100040200010001ff0000010080000000", errMissingTerminator},
+		{"ef00010100040200010001ff0000000180000000", errInvalidSection0Type},
+		{"ef00010100040200010001ff0000000000000000", errInvalidSection0Type},
+		{"ef000101000802000200010001ff000000008000008080000000fe", errTooManyInputs},
+		{"ef000101000802000200010001ff000000008000000081000000fe", errTooManyOutputs},
+		{"ef00010100040200010001ff0000000080040000", errTooLargeMaxStackIncrease},
+		{"ef00010100040200010001ff0001000080000000", errInvalidContainerSize},
+		{"ef00010100040200010001ff000000008000000000", errInvalidContainerSize},
+		{"ef00010100040200010001030000ff0000000080000000", errInvalidContainerSectionNo},
+	}
+	for i, tt := range tests {
+		var c Container
+		err := c.UnmarshalBinary(hexutil.MustDecode("0x" + tt.code))
+		assert.ErrorIs(t, err, tt.err, "test %d", i)
+	}
+}
+
+// eofTestConfig enables all the hardforks up to osaka.
+var eofTestConfig = &params.ChainConfig{
+	ChainID:                  big.NewInt(1),
+	IstanbulCompatibleBlock:  big.NewInt(0),
+	LondonCompatibleBlock:    big.NewInt(0),
+	EthTxTypeCompatibleBlock: big.NewInt(0),
+	MagmaCompatibleBlock:     big.NewInt(0),
+	KoreCompatibleBlock:      big.NewInt(0),
+	ShanghaiCompatibleBlock:  big.NewInt(0),
+	CancunCompatibleBlock:    big.NewInt(0),
+	DragonCompatibleBlock:    big.NewInt(0),
+	PragueCompatibleBlock:    big.NewInt(0),
+	OsakaCompatibleBlock:     big.NewInt(0),
+}
+
+func newEOFTestEVM(t *testing.T, config *params.ChainConfig) *EVM {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	require.NoError(t, err)
+	blockCtx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	return NewEVM(blockCtx, TxContext{}, statedb, config, &Config{})
+}
+
+func deployTestCode(evm *EVM, addr common.Address, code []byte) {
+	evm.StateDB.CreateSmartContractAccount(addr, params.CodeFormatEVM, evm.chainRules)
+	evm.StateDB.SetCode(addr, code)
+}
+
+// The runtime container returns the double of the 32-byte word in its data
+// section, which is appended by the initcode container from its calldata.
+var (
+	eofTestRuntime = func() *Container {
+		c := makeContainer(
+			[]*functionMetadata{nonReturning(2), {inputs: 1, outputs: 1, maxStackIncrease: 1}},
+			[][]byte{
+				{
+					byte(DATALOADN), 0, 0,
+					byte(CALLF), 0, 1,
+					byte(PUSH0), byte(MSTORE),
+					byte(PUSH1), 32, byte(PUSH0), byte(RETURN),
+				},
+				{byte(DUP1), byte(ADD), byte(RETF)},
+			},
+			nil,
+			[]byte{},
+		)
+		c.dataSize = 32
+		return c
+	}()
+	eofTestInitcode = makeContainer(
+		[]*functionMetadata{nonReturning(3)},
+		[][]byte{{
+			byte(PUSH1), 32, byte(PUSH0), byte(PUSH0), byte(CALLDATACOPY),
+			byte(PUSH1), 32, byte(PUSH0), byte(RETURNCONTRACT), 0,
+		}},
+		[]*Container{eofTestRuntime},
+		[]byte{},
+	)
+)
+
+func TestEOFCreationTransaction(t *testing.T) {
+	var (
+		evm    = newEOFTestEVM(t, eofTestConfig)
+		sender = common.HexToAddress("0x1000")
+		input  = common.LeftPadBytes([]byte{21}, 32)
+	)
+	require.NoError(t, eofTestInitcode.ValidateCode(evm.interpreter.eofTable, true))
+
+	code := append(eofTestInitcode.MarshalBinary(), input...)
+	_, addr, _, err := evm.Create(AccountRef(sender), code, 1000000, new(big.Int), params.CodeFormatEVM)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.CreateAddress(sender, 0), addr)
+
+	// The deployed container has the auxiliary data in its data section.
+	deployed := *eofTestRuntime
+	deployed.data, deployed.dataSize = input, len(input)
+	assert.Equal(t, deployed.MarshalBinary(), evm.StateDB.GetCode(addr))
+
+	ret, _, err := evm.Call(AccountRef(sender), addr, nil, 100000, new(big.Int))
+	require.NoError(t, err)
+	assert.Equal(t, common.LeftPadBytes([]byte{42}, 32), ret)
+
+	// Legacy code sees the EOF code as the magic only.
+	legacy := common.HexToAddress("0x2000")
+	deployTestCode(evm, legacy, append(append([]byte{byte(PUSH20)}, addr.Bytes()...),
+		byte(EXTCODESIZE), byte(PUSH0), byte(MSTORE), byte(PUSH1), 32, byte(PUSH0), byte(RETURN)))
+	ret, _, err = evm.Call(AccountRef(sender), legacy, nil, 100000, new(big.Int))
+	require.NoError(t, err)
+	assert.Equal(t, common.LeftPadBytes([]byte{2}, 32), ret)
+
+	// An invalid container consumes no gas but the nonce of the sender.
+	invalid := eofTestInitcode.MarshalBinary()
+	invalid[len(invalid)-1] = byte(STOP)
+	_, _, leftOverGas, err := evm.Create(AccountRef(sender), invalid, 1000000, new(big.Int), params.CodeFormatEVM)
+	assert.ErrorIs(t, err, ErrInvalidEOFInitcode)
+	assert.Equal(t, uint64(1000000), leftOverGas)
+	assert.Equal(t, uint64(2), evm.StateDB.GetNonce(sender))
+}
+
+func TestEOFCreate(t *testing.T) {
+	var (
+		evm     = newEOFTestEVM(t, eofTestConfig)
+		factory = common.HexToAddress("0x1000")
+		input   = common.LeftPadBytes([]byte{100}, 32)
+	)
+	container := makeContainer(
+		[]*functionMetadata{nonReturning(4)},
+		[][]byte{{
+			byte(PUSH1), 32, byte(PUSH0), byte(PUSH0), byte(CALLDATACOPY),
+			byte(PUSH1), 32, byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(EOFCREATE), 0,
+			byte(PUSH0), byte(MSTORE), byte(PUSH1), 32, byte(PUSH0), byte(RETURN),
+		}},
+		[]*Container{eofTestInitcode},
+		[]byte{},
+	)
+	require.NoError(t, container.ValidateCode(evm.interpreter.eofTable, false))
+	deployTestCode(evm, factory, container.MarshalBinary())
+
+	ret, _, err := evm.Call(AccountRef(common.Address{}), factory, input, 1000000, new(big.Int))
+	require.NoError(t, err)
+	addr := crypto.CreateAddress2(factory, common.Hash{}, crypto.Keccak256(eofTestInitcode.MarshalBinary()))
+	assert.Equal(t, common.LeftPadBytes(addr.Bytes(), 32), ret)
+
+	ret, _, err = evm.Call(AccountRef(common.Address{}), addr, nil, 100000, new(big.Int))
+	require.NoError(t, err)
+	assert.Equal(t, common.LeftPadBytes([]byte{200}, 32), ret)
+}
+
+func TestEOFExtCall(t *testing.T) {
+	evm := newEOFTestEVM(t, eofTestConfig)
+	targets := []struct {
+		code   []byte
+		status byte
+	}{
+		{[]byte{byte(STOP)}, 0},
+		{[]byte{byte(PUSH0), byte(PUSH0), byte(REVERT)}, 1},
+		{[]byte{byte(INVALID)}, 2},
+	}
+	for i, target := range targets {
+		var (
+			callee = common.BigToAddress(big.NewInt(int64(0x2000 + i)))
+			caller = common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+		)
+		deployTestCode(evm, callee, target.code)
+		code := append([]byte{byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(PUSH20)}, callee.Bytes()...)
+		code = append(code, byte(EXTCALL), byte(PUSH0), byte(MSTORE), byte(PUSH1), 32, byte(PUSH0), byte(RETURN))
+		container := makeContainer([]*functionMetadata{nonReturning(4)}, [][]byte{code}, nil, []byte{})
+		require.NoError(t, container.ValidateCode(evm.interpreter.eofTable, false))
+		deployTestCode(evm, caller, container.MarshalBinary())
+
+		ret, _, err := evm.Call(AccountRef(common.Address{}), caller, nil, 100000, new(big.Int))
+		require.NoError(t, err, "test %d", i)
+		assert.Equal(t, common.LeftPadBytes([]byte{target.status}, 32), ret, "test %d", i)
+
+		// A light failure without enough gas for the callee.
+		ret, _, err = evm.Call(AccountRef(common.Address{}), caller, nil, 7000, new(big.Int))
+		require.NoError(t, err, "test %d", i)
+		assert.Equal(t, common.LeftPadBytes([]byte{1}, 32), ret, "test %d", i)
+	}
+}
+
+func TestEOFBeforeOsaka(t *testing.T) {
+	config := *eofTestConfig
+	config.OsakaCompatibleBlock = nil
+	var (
+		evm  = newEOFTestEVM(t, &config)
+		addr = common.HexToAddress("0x1000")
+	)
+	deployTestCode(evm, addr, eofTestRuntime.MarshalBinary())
+	_, _, err := evm.Call(AccountRef(common.Address{}), addr, nil, 100000, new(big.Int))
+	assert.Error(t, err)
+
+	// The creation with EOF initcode runs it as legacy code.
+	_, _, _, err = evm.Create(AccountRef(common.Address{}), eofTestInitcode.MarshalBinary(), 100000, new(big.Int), params.CodeFormatEVM)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidEOFInitcode))
+}
+
+// TestEOFUnvalidatedCode tests that the code deployed before EIP-3541 with the
+// EOF magic, which is not a valid container, runs as legacy code.
+func TestEOFUnvalidatedCode(t *testing.T) {
+	evm := newEOFTestEVM(t, eofTestConfig)
+	for i, code := range [][]byte{
+		{byte(RJUMP)},                             // truncated immediate
+		{byte(CALLF), 0, 5, byte(STOP)},           // missing code section
+		{byte(JUMPF), 0, 5},                       // missing code section
+		{byte(DATALOADN), 0xff, 0xff, byte(STOP)}, // out of the data section
+	} {
+		container := makeContainer([]*functionMetadata{nonReturning(1)}, [][]byte{code}, nil, []byte{})
+		require.NoError(t, new(Container).UnmarshalBinary(container.MarshalBinary()), "test %d", i)
+		require.Error(t, container.ValidateCode(evm.interpreter.eofTable, false), "test %d", i)
+
+		addr := common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+		deployTestCode(evm, addr, container.MarshalBinary())
+		_, _, err := evm.Call(AccountRef(common.Address{}), addr, nil, 100000, new(big.Int))
+		assert.EqualError(t, err, "invalid opcode 0xef", "test %d", i)
+	}
+}
+
+// TestEOFContainerCache tests that a cached container is validated again with
+// another instruction table.
+func TestEOFContainerCache(t *testing.T) {
+	evm := newEOFTestEVM(t, eofTestConfig)
+	container := makeContainer([]*functionMetadata{nonReturning(1)}, [][]byte{{byte(PUSH0), byte(POP), byte(STOP)}}, nil, []byte{})
+	code := container.MarshalBinary()
+	hash := crypto.Keccak256Hash(code)
+
+	jt := *evm.interpreter.eofTable
+	jt[PUSH0] = nil
+
+	assert.NotNil(t, validatedContainer(hash, code, evm.interpreter.eofTable))
+	assert.Nil(t, validatedContainer(hash, code, &jt))
+	assert.NotNil(t, validatedContainer(hash, code, evm.interpreter.eofTable))
+}
@@ -0,0 +1,428 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+)
+
+var (
+	errUndefinedInstruction      = errors.New("undefined instruction")
+	errTruncatedImmediate        = errors.New("truncated immediate")
+	errInvalidSectionArgument    = errors.New("invalid section argument")
+	errInvalidCallArgument       = errors.New("callf into non-returning section")
+	errInvalidDataloadNArgument  = errors.New("invalid dataloadN argument")
+	errInvalidJumpDest           = errors.New("invalid jump destination")
+	errInvalidBackwardJump       = errors.New("invalid backward jump")
+	errInvalidOutputs            = errors.New("invalid number of outputs")
+	errInvalidMaxStackIncrease   = errors.New("invalid max stack increase")
+	errInvalidCodeTermination    = errors.New("invalid code termination")
+	errInvalidNonReturning       = errors.New("invalid non-returning flag")
+	errInvalidJumpfTarget        = errors.New("invalid jumpf target")
+	errInvalidSubcontainerIndex  = errors.New("invalid subcontainer index")
+	errTruncatedEOFCreateData    = errors.New("eofcreate of a subcontainer with truncated data")
+	errOrphanedSubcontainer      = errors.New("subcontainer not referenced at all")
+	errIncompatibleContainerKind = errors.New("incompatible container kind")
+	errStackUnderflow            = errors.New("stack underflow")
+	errStackOverflow             = errors.New("stack overflow")
+	errUnreachableCode           = errors.New("unreachable code")
+)
+
+// The kinds of references of a subcontainer, which must not be mixed.
+const (
+	refByEOFCreate = iota + 1
+	refByReturnContract
+)
+
+// immediates holds the sizes of the immediate arguments of the EOF
+// instructions. The size of RJUMPV depends on its first immediate.
+var immediates [256]int
+
+func init() {
+	for op := PUSH1; op <= PUSH32; op++ {
+		immediates[op] = int(op-PUSH1) + 1
+	}
+	for _, op := range []OpCode{RJUMP, RJUMPI, CALLF, JUMPF, DATALOADN} {
+		immediates[op] = 2
+	}
+	for _, op := range []OpCode{RJUMPV, DUPN, SWAPN, EXCHANGE, EOFCREATE, RETURNCONTRACT} {
+		immediates[op] = 1
+	}
+}
+
+// isTerminal returns true if the instruction ends the execution of a code
+// section without falling through to the next instruction.
+func isTerminal(op OpCode) bool {
+	switch op {
+	case STOP, RETURN, REVERT, INVALID, RETF, JUMPF, RETURNCONTRACT:
+		return true
+	}
+	return false
+}
+
+// validationResult is what a code section refers to in its container.
+type validationResult struct {
+	sections      []int       // code sections called by CALLF or JUMPF
+	subContainers map[int]int // subcontainers and the kinds of their references
+}
+
+// ValidateCode validates the code sections reachable from the first one and
+// the referenced subcontainers recursively against the given jump table
+// (EIP-3670, EIP-4200, EIP-4750, EIP-5450). An initcode container may only
+// end with RETURNCONTRACT, while a runtime container may not use it.
+func (c *Container) ValidateCode(jt *JumpTable, isInitCode bool) error {
+	var (
+		visited = make([]bool, len(c.codeSections))
+		refs    = make([]int, len(c.subContainers))
+		queue   = []int{0}
+	)
+	visited[0] = true
+	for len(queue) > 0 {
+		section := queue[0]
+		queue = queue[1:]
+		res, err := validateCode(c.codeSections[section], section, c, jt, isInitCode)
+		if err != nil {
+			return fmt.Errorf("code section %d: %w", section, err)
+		}
+		for _, s := range res.sections {
+			if !visited[s] {
+				visited[s] = true
+				queue = append(queue, s)
+			}
+		}
+		for i, kind := range res.subContainers {
+			if refs[i] != 0 && refs[i] != kind {
+				return fmt.Errorf("%w: subcontainer %d is referenced by both EOFCREATE and RETURNCONTRACT", errIncompatibleContainerKind, i)
+			}
+			refs[i] = kind
+		}
+	}
+	for section, ok := range visited {
+		if !ok {
+			return fmt.Errorf("%w: code section %d is never called", errUnreachableCode, section)
+		}
+	}
+	for i, sub := range c.subContainers {
+		var err error
+		switch refs[i] {
+		case refByEOFCreate:
+			if len(sub.data) < sub.dataSize {
+				return fmt.Errorf("%w: subcontainer %d", errTruncatedEOFCreateData, i)
+			}
+			err = sub.ValidateCode(jt, true)
+		case refByReturnContract:
+			err = sub.ValidateCode(jt, false)
+		default:
+			return fmt.Errorf("%w: subcontainer %d", errOrphanedSubcontainer, i)
+		}
+		if err != nil {
+			return fmt.Errorf("subcontainer %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// eofContainerCacheSize is the number of validated containers of deployed code
+// kept in the cache shared by all the executions.
+const eofContainerCacheSize = 1024
+
+var eofContainerCache, _ = lru.New(eofContainerCacheSize)
+
+// eofContainerKey is the key of a validated container in the cache. Since the
+// validity of a code depends on the instructions defined at the fork, the
+// instruction table validated with is a part of the key.
+type eofContainerKey struct {
+	hash common.Hash
+	jt   *JumpTable
+}
+
+// validatedContainer returns the container of the deployed code with the hash,
+// or nil if the code is not a valid runtime container. The code is validated
+// on its execution since the code deployed before EIP-3541 may start with the
+// EOF magic without having been validated. The result is cached by the hash
+// and the instruction table.
+func validatedContainer(hash common.Hash, code []byte, jt *JumpTable) *Container {
+	key := eofContainerKey{hash: hash, jt: jt}
+	if hash != (common.Hash{}) {
+		if cached, ok := eofContainerCache.Get(key); ok {
+			return cached.(*Container)
+		}
+	}
+	container := new(Container)
+	if err := container.UnmarshalBinary(code); err != nil {
+		container = nil
+	} else if err := container.ValidateCode(jt, false); err != nil {
+		logger.Trace("invalid EOF code", "hash", hash, "err", err)
+		container = nil
+	}
+	if hash != (common.Hash{}) {
+		eofContainerCache.Add(key, container)
+	}
+	return container
+}
+
+// validateCode validates the instructions, their immediate arguments and the
+// stack usage of a code section.
+func validateCode(code []byte, section int, container *Container, jt *JumpTable, isInitCode bool) (*validationResult, error) {
+	var (
+		op        OpCode
+		res       = &validationResult{subContainers: make(map[int]int)}
+		immediate = make([]bool, len(code))
+		dests     []int
+		returning bool
+	)
+	for i := 0; i < len(code); {
+		op = OpCode(code[i])
+		if jt[op] == nil && op != INVALID {
+			return nil, fmt.Errorf("%w: op %s at pos %d", errUndefinedInstruction, op, i)
+		}
+		size := immediates[op]
+		if op == RJUMPV && i+1 < len(code) {
+			size += 2 * (int(code[i+1]) + 1)
+		}
+		if i+size >= len(code) {
+			return nil, fmt.Errorf("%w: op %s at pos %d", errTruncatedImmediate, op, i)
+		}
+		switch op {
+		case RJUMP, RJUMPI:
+			dests = append(dests, i+3+int(int16(binary.BigEndian.Uint16(code[i+1:]))))
+		case RJUMPV:
+			for j := 0; j < int(code[i+1])+1; j++ {
+				dests = append(dests, i+1+size+int(int16(binary.BigEndian.Uint16(code[i+2+2*j:]))))
+			}
+		case CALLF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(container.types) {
+				return nil, fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(container.types)-1, i)
+			}
+			if container.types[arg].nonReturning() {
+				return nil, fmt.Errorf("%w: section %d at pos %d", errInvalidCallArgument, arg, i)
+			}
+			res.sections = append(res.sections, arg)
+		case RETF:
+			if container.types[section].nonReturning() {
+				return nil, fmt.Errorf("%w: RETF in non-returning section at pos %d", errInvalidNonReturning, i)
+			}
+			returning = true
+		case JUMPF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(container.types) {
+				return nil, fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(container.types)-1, i)
+			}
+			if !container.types[arg].nonReturning() {
+				if container.types[section].nonReturning() {
+					return nil, fmt.Errorf("%w: returning section %d from non-returning section at pos %d", errInvalidJumpfTarget, arg, i)
+				}
+				if container.types[section].outputs < container.types[arg].outputs {
+					return nil, fmt.Errorf("%w: section %d has more outputs at pos %d", errInvalidJumpfTarget, arg, i)
+				}
+				returning = true
+			}
+			res.sections = append(res.sections, arg)
+		case DATALOADN:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg+32 > container.dataSize {
+				return nil, fmt.Errorf("%w: arg %d, data size %d, pos %d", errInvalidDataloadNArgument, arg, container.dataSize, i)
+			}
+		case EOFCREATE, RETURNCONTRACT:
+			arg := int(code[i+1])
+			if arg >= len(container.subContainers) {
+				return nil, fmt.Errorf("%w: arg %d, pos %d", errInvalidSubcontainerIndex, arg, i)
+			}
+			kind := refByEOFCreate
+			if op == RETURNCONTRACT {
+				if !isInitCode {
+					return nil, fmt.Errorf("%w: RETURNCONTRACT in runtime code at pos %d", errIncompatibleContainerKind, i)
+				}
+				kind = refByReturnContract
+			}
+			if prev, ok := res.subContainers[arg]; ok && prev != kind {
+				return nil, fmt.Errorf("%w: subcontainer %d is referenced by both EOFCREATE and RETURNCONTRACT", errIncompatibleContainerKind, arg)
+			}
+			res.subContainers[arg] = kind
+		case STOP, RETURN:
+			if isInitCode {
+				return nil, fmt.Errorf("%w: %s in initcode at pos %d", errIncompatibleContainerKind, op, i)
+			}
+		}
+		for j := i + 1; j <= i+size; j++ {
+			immediate[j] = true
+		}
+		i += size + 1
+	}
+	// The code must not fall through its end.
+	if !isTerminal(op) && op != RJUMP {
+		return nil, fmt.Errorf("%w: end with %s", errInvalidCodeTermination, op)
+	}
+	for _, dest := range dests {
+		if dest < 0 || dest >= len(code) || immediate[dest] {
+			return nil, fmt.Errorf("%w: %d", errInvalidJumpDest, dest)
+		}
+	}
+	if !returning && !container.types[section].nonReturning() {
+		return nil, fmt.Errorf("%w: returning section without RETF or JUMPF to a returning section", errInvalidNonReturning)
+	}
+	height, err := validateControlFlow(code, section, container.types, jt)
+	if err != nil {
+		return nil, err
+	}
+	if want := int(container.types[section].inputs) + int(container.types[section].maxStackIncrease); height != want {
+		return nil, fmt.Errorf("%w: computed %d, declared %d", errInvalidMaxStackIncrease, height-int(container.types[section].inputs), container.types[section].maxStackIncrease)
+	}
+	return res, nil
+}
+
+// stackBounds is the range of the stack heights at an instruction.
+type stackBounds struct {
+	min, max int
+}
+
+// validateControlFlow computes the stack height range of every instruction
+// in a single forward pass and returns the maximum stack height (EIP-5450).
+// Every instruction must be reached by a forward jump or by falling through,
+// and a backward jump must keep the stack height of its destination.
+func validateControlFlow(code []byte, section int, metadata []*functionMetadata, jt *JumpTable) (int, error) {
+	var (
+		heights   = make([]*stackBounds, len(code))
+		maxHeight = int(metadata[section].inputs)
+		limit     = int(params.StackLimit)
+	)
+	heights[0] = &stackBounds{maxHeight, maxHeight}
+
+	// visit records the stack heights for a successor of the instruction at pos.
+	visit := func(pos, dest int, h stackBounds) error {
+		if dest > pos {
+			if cur := heights[dest]; cur == nil {
+				heights[dest] = &stackBounds{h.min, h.max}
+			} else {
+				cur.min = minInt(cur.min, h.min)
+				cur.max = maxInt(cur.max, h.max)
+			}
+			return nil
+		}
+		if cur := heights[dest]; cur == nil || *cur != h {
+			return fmt.Errorf("%w: from %d to %d", errInvalidBackwardJump, pos, dest)
+		}
+		return nil
+	}
+
+	for pos := 0; pos < len(code); {
+		op := OpCode(code[pos])
+		cur := heights[pos]
+		if cur == nil {
+			return 0, fmt.Errorf("%w: pos %d", errUnreachableCode, pos)
+		}
+		size := immediates[op]
+		if op == RJUMPV {
+			size += 2 * (int(code[pos+1]) + 1)
+		}
+		var pops, pushes int
+		switch op {
+		case CALLF, JUMPF:
+			target := metadata[binary.BigEndian.Uint16(code[pos+1:])]
+			if cur.min < int(target.inputs) {
+				return 0, fmt.Errorf("%w: %s at pos %d", errStackUnderflow, op, pos)
+			}
+			if cur.max+int(target.maxStackIncrease) > limit {
+				return 0, fmt.Errorf("%w: %s at pos %d", errStackOverflow, op, pos)
+			}
+			if op == CALLF {
+				pops, pushes = int(target.inputs), int(target.outputs)
+			} else if !target.nonReturning() {
+				want := int(metadata[section].outputs) + int(target.inputs) - int(target.outputs)
+				if cur.min != want || cur.max != want {
+					return 0, fmt.Errorf("%w: JUMPF at pos %d with stack height [%d, %d], want %d", errInvalidOutputs, pos, cur.min, cur.max, want)
+				}
+			}
+		case RETF:
+			want := int(metadata[section].outputs)
+			if cur.min != want || cur.max != want {
+				return 0, fmt.Errorf("%w: RETF at pos %d with stack height [%d, %d], want %d", errInvalidOutputs, pos, cur.min, cur.max, want)
+			}
+		case DUPN:
+			pops = int(code[pos+1]) + 1
+			pushes = pops + 1
+		case SWAPN:
+			pops = int(code[pos+1]) + 2
+			pushes = pops
+		case EXCHANGE:
+			pops = int(code[pos+1]>>4) + int(code[pos+1]&0x0f) + 3
+			pushes = pops
+		case INVALID:
+		default:
+			pops = jt[op].minStack
+			pushes = limit + pops - jt[op].maxStack
+		}
+		if cur.min < pops {
+			return 0, fmt.Errorf("%w: %s at pos %d", errStackUnderflow, op, pos)
+		}
+		next := stackBounds{cur.min - pops + pushes, cur.max - pops + pushes}
+		if maxHeight = maxInt(maxHeight, next.max); maxHeight > maxStackHeight {
+			return 0, fmt.Errorf("%w: %s at pos %d", errStackOverflow, op, pos)
+		}
+
+		end := pos + size + 1
+		switch {
+		case op == RJUMP:
+			if err := visit(pos, end+int(int16(binary.BigEndian.Uint16(code[pos+1:]))), next); err != nil {
+				return 0, err
+			}
+		case op == RJUMPI:
+			if err := visit(pos, end, next); err != nil {
+				return 0, err
+			}
+			if err := visit(pos, end+int(int16(binary.BigEndian.Uint16(code[pos+1:]))), next); err != nil {
+				return 0, err
+			}
+		case op == RJUMPV:
+			if err := visit(pos, end, next); err != nil {
+				return 0, err
+			}
+			for i := 0; i < int(code[pos+1])+1; i++ {
+				if err := visit(pos, end+int(int16(binary.BigEndian.Uint16(code[pos+2+2*i:]))), next); err != nil {
+					return 0, err
+				}
+			}
+		case !isTerminal(op):
+			if err := visit(pos, end, next); err != nil {
+				return 0, err
+			}
+		}
+		pos = end
+	}
+	return maxHeight, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
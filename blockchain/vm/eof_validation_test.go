@@ -0,0 +1,245 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCode(t *testing.T) {
+	jt := newOsakaEOFInstructionSet()
+	runtime := makeContainer([]*functionMetadata{nonReturning(0)}, [][]byte{{byte(STOP)}}, nil, []byte{})
+	truncated := makeContainer([]*functionMetadata{nonReturning(0)}, [][]byte{{byte(STOP)}}, nil, []byte{})
+	truncated.dataSize = 32
+	initcode := makeContainer([]*functionMetadata{nonReturning(2)}, [][]byte{{
+		byte(PUSH0), byte(PUSH0), byte(RETURNCONTRACT), 0,
+	}}, []*Container{runtime}, []byte{})
+
+	tests := []struct {
+		types      []*functionMetadata
+		code       [][]byte
+		subs       []*Container
+		data       []byte
+		isInitCode bool
+		err        error
+	}{
+		// Valid code.
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(STOP)}},
+		},
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(PUSH1), 1, byte(RJUMPI), 0, 1, byte(INVALID), byte(STOP)}},
+		},
+		{
+			// A loop keeping the stack height.
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(PUSH0), byte(RJUMPI), 0xff, 0xfc, byte(STOP)}},
+		},
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code: [][]byte{{
+				byte(PUSH0), byte(RJUMPV), 1, 0, 4, 0, 5,
+				byte(PUSH0), byte(RJUMP), 0, 1,
+				byte(PUSH0), byte(STOP),
+			}},
+		},
+		{
+			types: []*functionMetadata{nonReturning(2), {inputs: 1, outputs: 2, maxStackIncrease: 1}},
+			code: [][]byte{
+				{byte(PUSH0), byte(CALLF), 0, 1, byte(STOP)},
+				{byte(DUP1), byte(RETF)},
+			},
+		},
+		{
+			types: []*functionMetadata{nonReturning(0), nonReturning(0)},
+			code:  [][]byte{{byte(JUMPF), 0, 1}, {byte(STOP)}},
+		},
+		{
+			types: []*functionMetadata{nonReturning(3)},
+			code:  [][]byte{{byte(PUSH0), byte(DUPN), 0, byte(SWAPN), 0, byte(PUSH0), byte(EXCHANGE), 0x00, byte(STOP)}},
+		},
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(DATALOADN), 0, 1, byte(STOP)}},
+			data:  make([]byte, 33),
+		},
+		{
+			types: []*functionMetadata{nonReturning(4)},
+			code:  [][]byte{{byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(EOFCREATE), 0, byte(STOP)}},
+			subs:  []*Container{initcode},
+		},
+		{
+			types:      []*functionMetadata{nonReturning(2)},
+			code:       [][]byte{{byte(PUSH0), byte(PUSH0), byte(RETURNCONTRACT), 0}},
+			subs:       []*Container{truncated},
+			isInitCode: true,
+		},
+		// Invalid instructions and immediates.
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(PUSH0), byte(JUMP)}},
+			err:   errUndefinedInstruction,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(SELFDESTRUCT)}},
+			err:   errUndefinedInstruction,
+		},
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(PUSH2), 0}},
+			err:   errTruncatedImmediate,
+		},
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(PUSH0)}},
+			err:   errInvalidCodeTermination,
+		},
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(PUSH1), 1, byte(RJUMPI), 0xff, 0xfc, byte(STOP)}},
+			err:   errInvalidJumpDest,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(RJUMP), 0, 1, byte(STOP)}},
+			err:   errInvalidJumpDest,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(CALLF), 0, 1, byte(STOP)}},
+			err:   errInvalidSectionArgument,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0), nonReturning(0)},
+			code:  [][]byte{{byte(CALLF), 0, 1, byte(STOP)}, {byte(STOP)}},
+			err:   errInvalidCallArgument,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(RETF)}},
+			err:   errInvalidNonReturning,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0), {inputs: 0, outputs: 0, maxStackIncrease: 0}},
+			code:  [][]byte{{byte(CALLF), 0, 1, byte(STOP)}, {byte(STOP)}},
+			err:   errInvalidNonReturning,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0), {inputs: 0, outputs: 0, maxStackIncrease: 0}},
+			code:  [][]byte{{byte(JUMPF), 0, 1}, {byte(RETF)}},
+			err:   errInvalidJumpfTarget,
+		},
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(DATALOADN), 0, 1, byte(STOP)}},
+			data:  make([]byte, 32),
+			err:   errInvalidDataloadNArgument,
+		},
+		// Invalid stack usage.
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(POP), byte(STOP)}},
+			err:   errStackUnderflow,
+		},
+		{
+			types: []*functionMetadata{nonReturning(2)},
+			code:  [][]byte{{byte(PUSH0), byte(PUSH0), byte(STOP)}, {byte(STOP)}},
+			err:   errUnreachableCode,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(RJUMP), 0, 1, byte(INVALID), byte(STOP)}},
+			err:   errUnreachableCode,
+		},
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(STOP), byte(STOP)}},
+			err:   errUnreachableCode,
+		},
+		{
+			types: []*functionMetadata{nonReturning(1)},
+			code:  [][]byte{{byte(PUSH0), byte(RJUMP), 0xff, 0xfc}},
+			err:   errInvalidBackwardJump,
+		},
+		{
+			types: []*functionMetadata{nonReturning(2)},
+			code:  [][]byte{{byte(PUSH0), byte(STOP)}},
+			err:   errInvalidMaxStackIncrease,
+		},
+		{
+			types: []*functionMetadata{nonReturning(1), {inputs: 0, outputs: 1, maxStackIncrease: 2}},
+			code:  [][]byte{{byte(CALLF), 0, 1, byte(STOP)}, {byte(PUSH0), byte(PUSH0), byte(RETF)}},
+			err:   errInvalidOutputs,
+		},
+		// Invalid subcontainers.
+		{
+			types: []*functionMetadata{nonReturning(0)},
+			code:  [][]byte{{byte(STOP)}},
+			subs:  []*Container{runtime},
+			err:   errOrphanedSubcontainer,
+		},
+		{
+			types: []*functionMetadata{nonReturning(4)},
+			code:  [][]byte{{byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(EOFCREATE), 1, byte(STOP)}},
+			subs:  []*Container{initcode},
+			err:   errInvalidSubcontainerIndex,
+		},
+		{
+			types: []*functionMetadata{nonReturning(4)},
+			code:  [][]byte{{byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(EOFCREATE), 0, byte(STOP)}},
+			subs:  []*Container{truncated},
+			err:   errTruncatedEOFCreateData,
+		},
+		{
+			// The runtime container created by EOFCREATE must be initcode.
+			types: []*functionMetadata{nonReturning(4)},
+			code:  [][]byte{{byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(EOFCREATE), 0, byte(STOP)}},
+			subs:  []*Container{runtime},
+			err:   errIncompatibleContainerKind,
+		},
+		{
+			types: []*functionMetadata{nonReturning(2)},
+			code:  [][]byte{{byte(PUSH0), byte(PUSH0), byte(RETURNCONTRACT), 0}},
+			subs:  []*Container{runtime},
+			err:   errIncompatibleContainerKind,
+		},
+		{
+			types:      []*functionMetadata{nonReturning(0)},
+			code:       [][]byte{{byte(STOP)}},
+			isInitCode: true,
+			err:        errIncompatibleContainerKind,
+		},
+	}
+	for i, tt := range tests {
+		data := tt.data
+		if data == nil {
+			data = []byte{}
+		}
+		c := makeContainer(tt.types, tt.code, tt.subs, data)
+		err := c.ValidateCode(&jt, tt.isInitCode)
+		if tt.err == nil {
+			assert.NoError(t, err, "test %d", i)
+		} else {
+			assert.ErrorIs(t, err, tt.err, "test %d", i)
+		}
+	}
+}
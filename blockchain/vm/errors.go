@@ -35,6 +35,13 @@ var (
 	ErrOpcodeComputationCostLimitReached = errors.New("reached the opcode computation cost limit")
 	ErrFailedOnSetCode                   = errors.New("failed on setting code to an account")
 
+	// EIP-7702 authorization errors, which skip the authorization only
+	ErrAuthorizationWrongChainID       = errors.New("EIP-7702 authorization chain ID mismatch")
+	ErrAuthorizationNonceOverflow      = errors.New("EIP-7702 authorization nonce > 64 bit")
+	ErrAuthorizationDestinationHasCode = errors.New("EIP-7702 authorization destination has code")
+	ErrAuthorizationNotLegacyKey       = errors.New("EIP-7702 authorization destination has a non-legacy account key")
+	ErrAuthorizationNonceMismatch      = errors.New("EIP-7702 authorization nonce does not match current account nonce")
+
 	// EVM internal errors
 	ErrWriteProtection       = errors.New("evm: write protection")
 	ErrReturnDataOutOfBounds = errors.New("evm: return data out of bounds")
@@ -43,6 +50,13 @@ var (
 	ErrInvalidJump           = errors.New("evm: invalid jump destination")
 	ErrInvalidCode           = errors.New("invalid code: must not begin with 0xef")
 
+	// EOF execution errors
+	ErrInvalidEOFInitcode     = errors.New("evm: invalid eof initcode")
+	ErrInvalidEOFCallAddress  = errors.New("evm: invalid call address, the upper 12 bytes must be zero")
+	ErrInvalidEOFAuxDataSize  = errors.New("evm: invalid data size of the deploy container")
+	ErrEOFStackOverflow       = errors.New("evm: stack limit reached")
+	ErrEOFReturnStackExceeded = errors.New("evm: return stack limit reached")
+
 	// errStopToken is an internal token indicating interpreter loop termination,
 	// never returned to outside callers.
 	errStopToken = errors.New("stop token")
@@ -87,6 +87,11 @@ func run(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
 			return ret, err
 		}
 	}
+	// The code which is not a valid container runs as legacy code and fails.
+	// The container of initcode has been validated by its creation.
+	if evm.chainRules.IsOsaka && contract.Container == nil && hasEOFMagic(contract.Code) {
+		contract.Container = validatedContainer(contract.CodeHash, contract.Code, evm.interpreter.eofTable)
+	}
 	return evm.interpreter.Run(contract, input)
 }
 
@@ -158,6 +163,11 @@ type EVM struct {
 
 	// opcodeComputationCostSum is the sum of computation cost of opcodes.
 	opcodeComputationCostSum uint64
+
+	// customPrecompiles are the custom precompiled contracts activated at the block,
+	// and precompiles is the precompiled contract map of the block including them.
+	customPrecompiles map[common.Address]PrecompiledContract
+	precompiles       map[common.Address]PrecompiledContract
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -171,6 +181,7 @@ func NewEVM(blockCtx BlockContext, txCtx TxContext, statedb StateDB, chainConfig
 		chainConfig: chainConfig,
 		chainRules:  chainConfig.Rules(blockCtx.BlockNumber),
 	}
+	evm.customPrecompiles = activeCustomPrecompiles(chainConfig, blockCtx.BlockNumber)
 
 	if vmConfig.RunningEVM != nil {
 		vmConfig.RunningEVM <- evm
@@ -301,7 +312,7 @@ func (evm *EVM) Call(caller types.ContractRef, addr common.Address, input []byte
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
 		contract := NewContract(caller, to, value, gas)
-		contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
+		contract.SetCallCode(&addr, evm.resolveCodeHash(addr), evm.resolveCode(addr))
 		ret, err = run(evm, contract, input)
 		gas = contract.Gas
 	}
@@ -363,7 +374,7 @@ func (evm *EVM) CallCode(caller types.ContractRef, addr common.Address, input []
 	// Initialise a new contract and set the code that is to be used by the EVM.
 	// The contract is a scoped environment for this execution context only.
 	contract := NewContract(caller, to, value, gas)
-	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
+	contract.SetCallCode(&addr, evm.resolveCodeHash(addr), evm.resolveCode(addr))
 
 	ret, err = run(evm, contract, input)
 	if err != nil {
@@ -413,7 +424,7 @@ func (evm *EVM) DelegateCall(caller types.ContractRef, addr common.Address, inpu
 
 	// Initialise a new contract and make initialise the delegate values
 	contract := NewContract(caller, to, nil, gas).AsDelegate()
-	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
+	contract.SetCallCode(&addr, evm.resolveCodeHash(addr), evm.resolveCode(addr))
 
 	ret, err = run(evm, contract, input)
 	if err != nil {
@@ -466,7 +477,7 @@ func (evm *EVM) StaticCall(caller types.ContractRef, addr common.Address, input
 	// Initialise a new contract and set the code that is to be used by the EVM.
 	// The contract is a scoped environment for this execution context only.
 	contract := NewContract(caller, to, new(big.Int), gas)
-	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
+	contract.SetCallCode(&addr, evm.resolveCodeHash(addr), evm.resolveCode(addr))
 
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
@@ -484,6 +495,10 @@ func (evm *EVM) StaticCall(caller types.ContractRef, addr common.Address, input
 type codeAndHash struct {
 	code []byte
 	hash common.Hash
+
+	// container is the parsed EOF initcode container and input is its calldata.
+	container *Container
+	input     []byte
 }
 
 func (c *codeAndHash) Hash() common.Hash {
@@ -507,6 +522,24 @@ func (evm *EVM) create(caller types.ContractRef, codeAndHash *codeAndHash, gas u
 	// Increasing nonce since a failed tx with one of following error will be loaded on a block.
 	evm.StateDB.IncNonce(caller.Address())
 
+	// A contract creation transaction may have an EOF initcode container followed
+	// by its calldata (EIP-7698). If the container is invalid, only the intrinsic
+	// gas is consumed.
+	if evm.chainRules.IsOsaka && evm.depth == 0 && codeAndHash.container == nil && hasEOFMagic(codeAndHash.code) {
+		container := new(Container)
+		size, err := container.unmarshal(codeAndHash.code, false)
+		if err == nil {
+			err = container.ValidateCode(evm.interpreter.eofTable, true)
+		}
+		if err != nil {
+			logger.Trace("invalid EOF initcode", "err", err)
+			return nil, common.Address{}, gas, ErrInvalidEOFInitcode
+		}
+		code := codeAndHash.code
+		codeAndHash.code, codeAndHash.hash = code[:size], common.Hash{}
+		codeAndHash.container, codeAndHash.input = container, code[size:]
+	}
+
 	// We add this to the access list _before_ taking a snapshot. Even if the creation fails,
 	// the access-list change should not be rolled back
 	if evm.chainRules.IsKore {
@@ -560,7 +593,7 @@ func (evm *EVM) create(caller types.ContractRef, codeAndHash *codeAndHash, gas u
 		}
 	}
 
-	ret, err = evm.interpreter.Run(contract, nil)
+	ret, err = evm.interpreter.Run(contract, codeAndHash.input)
 
 	// check whether the max code size has been exceeded
 	maxCodeSizeExceeded := len(ret) > params.MaxCodeSize
@@ -598,8 +631,9 @@ func (evm *EVM) create(caller types.ContractRef, codeAndHash *codeAndHash, gas u
 		err = ErrMaxCodeSizeExceeded // TODO-Klaytn-Issue615
 	}
 
-	// Reject code starting with 0xEF if EIP-3541 is enabled.
-	if err == nil && len(ret) >= 1 && ret[0] == 0xEF && evm.chainRules.IsKore {
+	// Reject code starting with 0xEF if EIP-3541 is enabled, except the EOF
+	// container returned by EOF initcode.
+	if err == nil && len(ret) >= 1 && ret[0] == 0xEF && evm.chainRules.IsKore && codeAndHash.container == nil {
 		err = ErrInvalidCode
 	}
 
@@ -631,6 +665,15 @@ func (evm *EVM) Create2(caller types.ContractRef, code []byte, gas uint64, endow
 	return evm.create(caller, codeAndHash, gas, endowment, contractAddr, CREATE2, false, codeFormat)
 }
 
+// EOFCreate creates a new contract from the EOF initcode container with the
+// input as its calldata (EIP-7620). Like Create2, the address is derived from
+// the salt and the hash of the initcode container.
+func (evm *EVM) EOFCreate(caller types.ContractRef, container *Container, code []byte, input []byte, gas uint64, endowment *big.Int, salt *uint256.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	codeAndHash := &codeAndHash{code: code, container: container, input: input}
+	contractAddr = crypto.CreateAddress2(caller.Address(), salt.Bytes32(), codeAndHash.Hash().Bytes())
+	return evm.create(caller, codeAndHash, gas, endowment, contractAddr, EOFCREATE, false, params.CodeFormatEVM)
+}
+
 // CreateWithAddress creates a new contract using code as deployment code with given address and humanReadable.
 func (evm *EVM) CreateWithAddress(caller types.ContractRef, code []byte, gas uint64, value *big.Int, contractAddr common.Address, humanReadable bool, codeFormat params.CodeFormat) ([]byte, common.Address, uint64, error) {
 	codeAndHash := &codeAndHash{code: code}
@@ -638,6 +681,80 @@ func (evm *EVM) CreateWithAddress(caller types.ContractRef, code []byte, gas uin
 	return evm.create(caller, codeAndHash, gas, value, contractAddr, CREATE, humanReadable, codeFormat)
 }
 
+// ApplyAuthorizations sets the code of the authorities to the delegation
+// designators of the authorized addresses by EIP-7702. An invalid
+// authorization is skipped without failing the transaction. Since an
+// authority is a Klaytn account, its key must be a legacy key which can be
+// derived from the signature of the authorization.
+func (evm *EVM) ApplyAuthorizations(auths []types.SetCodeAuthorization) {
+	for _, auth := range auths {
+		if err := evm.applyAuthorization(&auth); err != nil {
+			logger.Trace("skip an invalid authorization", "address", auth.Address, "nonce", auth.Nonce, "err", err)
+		}
+	}
+}
+
+func (evm *EVM) applyAuthorization(auth *types.SetCodeAuthorization) error {
+	if auth.ChainID != nil && auth.ChainID.Sign() != 0 && auth.ChainID.Cmp(evm.chainConfig.ChainID) != 0 {
+		return ErrAuthorizationWrongChainID
+	}
+	if auth.Nonce+1 < auth.Nonce {
+		return ErrAuthorizationNonceOverflow
+	}
+	authority, err := auth.Authority()
+	if err != nil {
+		return err
+	}
+	evm.StateDB.AddAddressToAccessList(authority)
+
+	if _, ok := types.ParseDelegation(evm.StateDB.GetCode(authority)); !ok && evm.StateDB.GetCodeSize(authority) != 0 {
+		return ErrAuthorizationDestinationHasCode
+	}
+	if !evm.StateDB.GetKey(authority).Type().IsLegacyAccountKey() {
+		return ErrAuthorizationNotLegacyKey
+	}
+	if evm.StateDB.GetNonce(authority) != auth.Nonce {
+		return ErrAuthorizationNonceMismatch
+	}
+
+	// The intrinsic gas of the authorization is charged as creating an account.
+	if evm.StateDB.Exist(authority) {
+		evm.StateDB.AddRefund(params.CallNewAccountGas - params.TxAuthTupleGas)
+	}
+	evm.StateDB.IncNonce(authority)
+	if auth.Address == (common.Address{}) {
+		// Clear the delegation of the authority.
+		return evm.StateDB.SetCodeToEOA(authority, nil, evm.chainRules)
+	}
+	return evm.StateDB.SetCodeToEOA(authority, types.AddressToDelegation(auth.Address), evm.chainRules)
+}
+
+// resolveCode returns the code of the given address. If the code is a
+// delegation designator of EIP-7702, the code of the delegated address is
+// returned instead.
+func (evm *EVM) resolveCode(addr common.Address) []byte {
+	code := evm.StateDB.GetCode(addr)
+	if !evm.chainRules.IsPrague {
+		return code
+	}
+	if target, ok := types.ParseDelegation(code); ok {
+		evm.StateDB.AddAddressToAccessList(target)
+		return evm.StateDB.GetCode(target)
+	}
+	return code
+}
+
+// resolveCodeHash returns the code hash of the given address following a
+// delegation designator of EIP-7702 like resolveCode.
+func (evm *EVM) resolveCodeHash(addr common.Address) common.Hash {
+	if evm.chainRules.IsPrague {
+		if target, ok := types.ParseDelegation(evm.StateDB.GetCode(addr)); ok {
+			return evm.StateDB.GetCodeHash(target)
+		}
+	}
+	return evm.StateDB.GetCodeHash(addr)
+}
+
 func (evm *EVM) GetPrecompiledContractMap(addr common.Address) map[common.Address]PrecompiledContract {
 	// VmVersion means that the contract uses the precompiled contract map at the deployment time.
 	// Also, it follows old map's gas price & computation cost.
@@ -647,10 +764,22 @@ func (evm *EVM) GetPrecompiledContractMap(addr common.Address) map[common.Addres
 	if vmVersion, ok := evm.StateDB.GetVmVersion(addr); ok && vmVersion == params.VmVersion0 {
 		// Without VmVersion0, precompiled contract address 0x09-0x0b won't work properly
 		// with the contracts deployed before istanbulHF
-		return PrecompiledContractsByzantium
+		return withCustomPrecompiles(PrecompiledContractsByzantium, evm.customPrecompiles)
+	}
+
+	if evm.precompiles == nil {
+		evm.precompiles = withCustomPrecompiles(evm.builtinPrecompiles(), evm.customPrecompiles)
 	}
+	return evm.precompiles
+}
 
+// builtinPrecompiles returns the built-in precompiled contract map of the chain rules.
+func (evm *EVM) builtinPrecompiles() map[common.Address]PrecompiledContract {
 	switch {
+	case evm.chainRules.IsOsaka:
+		return PrecompiledContractsOsaka
+	case evm.chainRules.IsPrague:
+		return PrecompiledContractsPrague
 	case evm.chainRules.IsCancun:
 		return PrecompiledContractsCancun
 	case evm.chainRules.IsKore:
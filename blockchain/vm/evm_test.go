@@ -1,13 +1,17 @@
 package vm
 
 import (
+	"crypto/ecdsa"
 	"errors"
 	"math"
 	"math/big"
 	"testing"
 
 	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/kerrors"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
@@ -132,3 +136,126 @@ func TestBn256GasCost(t *testing.T) {
 		{"0x008", bn256PairingInput, true, Block5, params.Bn256PairingBaseGasIstanbul + params.Bn256PairingPerPointGasIstanbul*uint64(len(bn256PairingInput)/192), bn256PairingOutput, nil},
 	})
 }
+
+func TestApplyAuthorizations(t *testing.T) {
+	var (
+		config = &params.ChainConfig{
+			ChainID:                 big.NewInt(1),
+			IstanbulCompatibleBlock: common.Big0,
+			LondonCompatibleBlock:   common.Big0,
+			KoreCompatibleBlock:     common.Big0,
+			ShanghaiCompatibleBlock: common.Big0,
+			CancunCompatibleBlock:   common.Big0,
+			PragueCompatibleBlock:   common.Big0,
+		}
+		rules = config.Rules(common.Big1)
+
+		callerAddr = common.BytesToAddress([]byte("caller"))
+		target     = common.BytesToAddress([]byte("target"))
+		// PUSH1 0x2a PUSH1 0 MSTORE PUSH1 0x20 PUSH1 0 RETURN
+		targetCode = common.Hex2Bytes("602a60005260206000f3")
+
+		key, _    = crypto.GenerateKey()
+		authority = crypto.PubkeyToAddress(key.PublicKey)
+		other, _  = crypto.GenerateKey()
+		otherAddr = crypto.PubkeyToAddress(other.PublicKey)
+	)
+
+	newEVM := func() *EVM {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+		statedb.CreateSmartContractAccount(target, params.CodeFormatEVM, rules)
+		statedb.SetCode(target, targetCode)
+		statedb.CreateEOA(authority, false, accountkey.NewAccountKeyLegacy())
+		statedb.AddBalance(authority, big.NewInt(100))
+		statedb.SetNonce(authority, 1)
+		statedb.CreateEOA(otherAddr, false, accountkey.NewAccountKeyPublicWithValue(&other.PublicKey))
+
+		blockCtx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: common.Big1,
+		}
+		return NewEVM(blockCtx, TxContext{}, statedb, config, &Config{})
+	}
+	sign := func(key *ecdsa.PrivateKey, chainID int64, addr common.Address, nonce uint64) types.SetCodeAuthorization {
+		auth, err := types.SignSetCode(key, types.SetCodeAuthorization{ChainID: big.NewInt(chainID), Address: addr, Nonce: nonce})
+		assert.NoError(t, err)
+		return auth
+	}
+
+	t.Run("delegate", func(t *testing.T) {
+		evm := newEVM()
+		evm.ApplyAuthorizations([]types.SetCodeAuthorization{sign(key, 1, target, 1)})
+
+		statedb := evm.StateDB
+		assert.Equal(t, types.AddressToDelegation(target), statedb.GetCode(authority))
+		assert.True(t, statedb.IsProgramAccount(authority))
+		assert.True(t, statedb.GetKey(authority).Type().IsLegacyAccountKey())
+		assert.Equal(t, uint64(2), statedb.GetNonce(authority))
+		assert.Equal(t, big.NewInt(100), statedb.GetBalance(authority))
+		assert.Equal(t, params.CallNewAccountGas-params.TxAuthTupleGas, statedb.GetRefund())
+		assert.True(t, statedb.AddressInAccessList(authority))
+
+		// A call to the authority executes the code of the delegated address.
+		ret, _, err := evm.Call(AccountRef(callerAddr), authority, nil, 100000, new(big.Int))
+		assert.NoError(t, err)
+		assert.Equal(t, common.LeftPadBytes([]byte{0x2a}, 32), ret)
+
+		// EXTCODESIZE and EXTCODEHASH operate on the delegation designator.
+		// PUSH20 authority EXTCODESIZE PUSH1 0 MSTORE PUSH20 authority EXTCODEHASH PUSH1 0x20 MSTORE PUSH1 0x40 PUSH1 0 RETURN
+		code := append(append([]byte{byte(PUSH20)}, authority.Bytes()...), byte(EXTCODESIZE), byte(PUSH1), 0, byte(MSTORE), byte(PUSH20))
+		code = append(append(code, authority.Bytes()...), byte(EXTCODEHASH), byte(PUSH1), 0x20, byte(MSTORE), byte(PUSH1), 0x40, byte(PUSH1), 0, byte(RETURN))
+		statedb.CreateSmartContractAccount(callerAddr, params.CodeFormatEVM, rules)
+		statedb.SetCode(callerAddr, code)
+		ret, _, err = evm.Call(AccountRef(callerAddr), callerAddr, nil, 100000, new(big.Int))
+		assert.NoError(t, err)
+		assert.Equal(t, common.LeftPadBytes([]byte{23}, 32), ret[:32])
+		assert.Equal(t, crypto.Keccak256(types.AddressToDelegation(target)), ret[32:])
+
+		// The delegation is cleared by an authorization of the zero address.
+		evm.ApplyAuthorizations([]types.SetCodeAuthorization{sign(key, 0, common.Address{}, 2)})
+		assert.Equal(t, 0, statedb.GetCodeSize(authority))
+		assert.Equal(t, uint64(3), statedb.GetNonce(authority))
+		assert.False(t, statedb.IsProgramAccount(authority))
+		assert.True(t, statedb.GetKey(authority).Type().IsLegacyAccountKey())
+		assert.Equal(t, big.NewInt(100), statedb.GetBalance(authority))
+	})
+
+	t.Run("delegate self-destructs", func(t *testing.T) {
+		evm := newEVM()
+		statedb := evm.StateDB.(*state.StateDB)
+
+		// PUSH20 caller SELFDESTRUCT
+		destroyer := common.BytesToAddress([]byte("destroyer"))
+		statedb.CreateSmartContractAccount(destroyer, params.CodeFormatEVM, rules)
+		statedb.SetCode(destroyer, append(append([]byte{byte(PUSH20)}, callerAddr.Bytes()...), byte(SELFDESTRUCT)))
+		statedb.Finalise(true, true)
+
+		// The authority converted in the same transaction is not deleted by SELFDESTRUCT.
+		evm.ApplyAuthorizations([]types.SetCodeAuthorization{sign(key, 1, destroyer, 1)})
+		_, _, err := evm.Call(AccountRef(callerAddr), authority, nil, 100000, new(big.Int))
+		assert.NoError(t, err)
+		statedb.Finalise(true, true)
+
+		assert.True(t, statedb.Exist(authority))
+		assert.Equal(t, uint64(2), statedb.GetNonce(authority))
+		assert.Equal(t, types.AddressToDelegation(destroyer), statedb.GetCode(authority))
+		assert.Equal(t, big.NewInt(100), statedb.GetBalance(callerAddr))
+	})
+
+	t.Run("skip invalid authorizations", func(t *testing.T) {
+		evm := newEVM()
+		evm.ApplyAuthorizations([]types.SetCodeAuthorization{
+			sign(key, 2, target, 1),              // wrong chain id
+			sign(key, 1, target, 0),              // wrong nonce
+			sign(other, 1, target, 0),            // non-legacy account key
+			sign(key, 1, target, math.MaxUint64), // nonce overflow
+		})
+		statedb := evm.StateDB
+		assert.Equal(t, 0, statedb.GetCodeSize(authority))
+		assert.False(t, statedb.IsProgramAccount(authority))
+		assert.Equal(t, uint64(1), statedb.GetNonce(authority))
+		assert.Equal(t, 0, statedb.GetCodeSize(otherAddr))
+		assert.Equal(t, uint64(0), statedb.GetRefund())
+	})
+}
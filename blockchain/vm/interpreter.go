@@ -53,6 +53,9 @@ type Config struct {
 	// Enables collecting and printing opcode execution time
 	EnableOpDebug bool
 
+	// Disables the superinstructions fusing common opcode sequences of legacy code
+	DisableSuperinstructions bool
+
 	// Prefetching is true if the EVM is used for prefetching.
 	Prefetching bool
 
@@ -89,6 +92,8 @@ type EVMInterpreter struct {
 
 	readOnly   bool   // Whether to throw on stateful modifications
 	returnData []byte // Last CALL's return data for subsequent reuse
+
+	eofTable *JumpTable // EVM instruction table of EOF code, nil if EOF is not enabled
 }
 
 // NewEVMInterpreter returns a new instance of the Interpreter.
@@ -98,21 +103,7 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	// we'll set the default jump table.
 	cfg := evm.Config
 	if cfg.JumpTable[STOP] == nil {
-		var jt JumpTable
-		switch {
-		case evm.chainRules.IsCancun:
-			jt = CancunInstructionSet
-		case evm.chainRules.IsShanghai:
-			jt = ShanghaiInstructionSet
-		case evm.chainRules.IsKore:
-			jt = KoreInstructionSet
-		case evm.chainRules.IsLondon:
-			jt = LondonInstructionSet
-		case evm.chainRules.IsIstanbul:
-			jt = IstanbulInstructionSet
-		default:
-			jt = ConstantinopleInstructionSet
-		}
+		jt := instructionSet(evm.chainRules)
 		for i, eip := range cfg.ExtraEips {
 			if err := EnableEIP(eip, &jt); err != nil {
 				// Disable it, so caller can check if it's activated or not
@@ -122,27 +113,58 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		}
 		cfg.JumpTable = jt
 	}
+	in := &EVMInterpreter{evm: evm, cfg: cfg}
+	if evm.chainRules.IsOsaka {
+		in.eofTable = &OsakaEOFInstructionSet
+	}
 
 	// When setting computation cost limit value, priority is given to the original value, override experimental value,
 	// and then the limit value specified for each hard fork. If the original value is not infinite or
 	// there is no override value, the next priority value is used.
 	// Cautious, the infinite value is only applicable for specific API calls. (e.g. call/estimateGas/estimateComputationGas)
 	if cfg.ComputationCostLimit == params.OpcodeComputationCostLimitInfinite {
-		return &EVMInterpreter{evm: evm, cfg: cfg}
+		return in
+	}
+	cfg.ComputationCostLimit = ComputationCostLimit(evm.chainRules)
+	return in
+}
+
+// instructionSet returns the instruction set of legacy code under the rules.
+func instructionSet(rules params.Rules) JumpTable {
+	switch {
+	case rules.IsOsaka:
+		return OsakaInstructionSet
+	case rules.IsPrague:
+		return PragueInstructionSet
+	case rules.IsCancun:
+		return CancunInstructionSet
+	case rules.IsShanghai:
+		return ShanghaiInstructionSet
+	case rules.IsKore:
+		return KoreInstructionSet
+	case rules.IsLondon:
+		return LondonInstructionSet
+	case rules.IsIstanbul:
+		return IstanbulInstructionSet
+	default:
+		return ConstantinopleInstructionSet
 	}
+}
+
+// ComputationCostLimit returns the limit of the opcode computation cost of a
+// transaction under the rules.
+func ComputationCostLimit(rules params.Rules) uint64 {
 	// Override the computation cost with an experiment value
 	if params.OpcodeComputationCostLimitOverride != 0 {
-		cfg.ComputationCostLimit = params.OpcodeComputationCostLimitOverride
-		return &EVMInterpreter{evm: evm, cfg: cfg}
+		return params.OpcodeComputationCostLimitOverride
 	}
 	// Set the opcode computation cost limit by the default value
 	switch {
-	case evm.chainRules.IsCancun:
-		cfg.ComputationCostLimit = uint64(params.OpcodeComputationCostLimitCancun)
+	case rules.IsCancun:
+		return uint64(params.OpcodeComputationCostLimitCancun)
 	default:
-		cfg.ComputationCostLimit = uint64(params.OpcodeComputationCostLimit)
+		return uint64(params.OpcodeComputationCostLimit)
 	}
-	return &EVMInterpreter{evm: evm, cfg: cfg}
 }
 
 // count values and execution time of the opcodes are collected until the node is turned off.
@@ -196,9 +218,25 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte) (ret []byte, err
 
 		// used for collecting opcode execution time
 		opExecStart time.Time
+
+		jt = &in.cfg.JumpTable // instruction table of the code
 	)
 	contract.Input = input
 
+	// EOF code starts with its first code section under the EOF instruction table.
+	if contract.Container != nil {
+		contract.setCodeSection(0)
+		jt = in.eofTable
+	}
+
+	// Superinstructions are executed unless each opcode is traced or timed.
+	// They are found by the analysis of the code cached with its hash.
+	var fusions []superinstruction
+	if !in.cfg.Debug && !in.cfg.EnableOpDebug && !in.cfg.DisableSuperinstructions &&
+		contract.Container == nil && contract.CodeHash != (common.Hash{}) {
+		fusions = contract.analyze().fusions
+	}
+
 	if in.cfg.Debug {
 		defer func() {
 			if err != nil {
@@ -224,10 +262,23 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte) (ret []byte, err
 			logged, pcCopy, gasCopy, ccLeftCopy = false, pc, contract.Gas, in.evm.Config.ComputationCostLimit-in.evm.opcodeComputationCostSum
 		}
 
+		if fusions != nil && pc < uint64(len(fusions)) && fusions[pc] != noFusion {
+			fused, ferr := in.runSuperinstruction(fusions[pc], &pc, jt, contract, stack)
+			if ferr == errStopToken {
+				res, err = nil, ferr
+				break
+			} else if ferr != nil {
+				return nil, ferr
+			}
+			if fused {
+				continue
+			}
+		}
+
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
 		op = contract.GetOp(pc)
-		operation := in.cfg.JumpTable[op]
+		operation := jt[op]
 		if operation == nil {
 			return nil, fmt.Errorf("invalid opcode 0x%x", int(op)) // TODO-Klaytn-Issue615
 		}
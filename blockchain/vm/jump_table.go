@@ -61,11 +61,34 @@ var (
 	KoreInstructionSet           = newKoreInstructionSet()
 	ShanghaiInstructionSet       = newShanghaiInstructionSet()
 	CancunInstructionSet         = newCancunInstructionSet()
+	PragueInstructionSet         = newPragueInstructionSet()
+	OsakaInstructionSet          = newOsakaInstructionSet()
+	OsakaEOFInstructionSet       = newOsakaEOFInstructionSet()
 )
 
 // JumpTable contains the EVM opcodes supported at a given fork.
 type JumpTable [256]*operation
 
+// newOsakaEOFInstructionSet returns the instructions of EOF code, which is
+// validated against the table before being deployed.
+func newOsakaEOFInstructionSet() JumpTable {
+	instructionSet := newOsakaInstructionSet()
+	enableEOF(&instructionSet) // EIP-7692 EVM Object Format (EOF)
+	return instructionSet
+}
+
+func newOsakaInstructionSet() JumpTable {
+	instructionSet := newPragueInstructionSet()
+	enable3540(&instructionSet) // EIP-3540 Hide EOF code from legacy code
+	return instructionSet
+}
+
+func newPragueInstructionSet() JumpTable {
+	instructionSet := newCancunInstructionSet()
+	enable7702(&instructionSet) // EIP-7702 Set EOA account code
+	return instructionSet
+}
+
 func newCancunInstructionSet() JumpTable {
 	instructionSet := newShanghaiInstructionSet()
 	enable4844(&instructionSet) // EIP-4844 BLOBHASH opcode
@@ -19,6 +19,7 @@ package vm
 import (
 	"errors"
 
+	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/math"
 	"github.com/klaytn/klaytn/kerrors"
@@ -192,11 +193,119 @@ func makeCallVariantGasCallEIP2929(oldCalculator gasFunc) gasFunc {
 	}
 }
 
+// makeCallVariantGasCallEIP7702 is makeCallVariantGasCallEIP2929 charging
+// the access of the delegated address additionally if the called address
+// has a delegation designator of EIP-7702.
+func makeCallVariantGasCallEIP7702(oldCalculator gasFunc) gasFunc {
+	return func(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		var (
+			total uint64 // total dynamic gas charged in advance
+			addr  = common.Address(stack.Back(1).Bytes20())
+		)
+		// Check slot presence in the access list
+		if !evm.StateDB.AddressInAccessList(addr) {
+			evm.StateDB.AddAddressToAccessList(addr)
+			// The WarmStorageReadCostEIP2929 (100) is already deducted in the form of a constant cost, so
+			// the cost to charge for cold access, if any, is Cold - Warm
+			coldCost := params.ColdAccountAccessCostEIP2929 - params.WarmStorageReadCostEIP2929
+			if !contract.UseGas(coldCost) {
+				return 0, kerrors.ErrOutOfGas
+			}
+			total += coldCost
+		}
+		// Charge the access of the delegated address if the code is a delegation designator.
+		if target, ok := types.ParseDelegation(evm.StateDB.GetCode(addr)); ok {
+			cost := params.WarmStorageReadCostEIP2929
+			if !evm.StateDB.AddressInAccessList(target) {
+				evm.StateDB.AddAddressToAccessList(target)
+				cost = params.ColdAccountAccessCostEIP2929
+			}
+			if !contract.UseGas(cost) {
+				return 0, kerrors.ErrOutOfGas
+			}
+			total += cost
+		}
+		// Now call the old calculator, which takes into account
+		// - create new account
+		// - transfer value
+		// - memory expansion
+		// - 63/64ths rule
+		gas, err := oldCalculator(evm, contract, stack, mem, memorySize)
+		if total == 0 || err != nil {
+			return gas, err
+		}
+		// As makeCallVariantGasCallEIP2929, add the charge back temporarily and
+		// return it as a part of the dynamic gas to be reported to tracers.
+		contract.Gas += total
+		var overflow bool
+		if total, overflow = math.SafeAdd(gas, total); overflow {
+			return 0, errGasUintOverflow
+		}
+		return total, nil
+	}
+}
+
+// makeExtCallGasFunc creates the dynamic gas function of the EXTCALL family of
+// EIP-7069, which charges the memory expansion, the cold access of the target
+// and the value transfer. Unlike the legacy calls, the gas given to the callee
+// is determined in the execution.
+func makeExtCallGasFunc(transfersValue bool) gasFunc {
+	return func(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		gas, err := memoryGasCost(mem, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		// An invalid address fails in the execution without being accessed.
+		if stack.Back(0).BitLen() > 160 {
+			return gas, nil
+		}
+		var (
+			addr     = common.Address(stack.Back(0).Bytes20())
+			overflow bool
+		)
+		if !evm.StateDB.AddressInAccessList(addr) {
+			evm.StateDB.AddAddressToAccessList(addr)
+			// The warm storage read cost is already charged as constantGas
+			if gas, overflow = math.SafeAdd(gas, params.ColdAccountAccessCostEIP2929-params.WarmStorageReadCostEIP2929); overflow {
+				return 0, errGasUintOverflow
+			}
+		}
+		// Charge the access of the delegated address if the code is a delegation designator.
+		if target, ok := types.ParseDelegation(evm.StateDB.GetCode(addr)); ok {
+			cost := params.WarmStorageReadCostEIP2929
+			if !evm.StateDB.AddressInAccessList(target) {
+				evm.StateDB.AddAddressToAccessList(target)
+				cost = params.ColdAccountAccessCostEIP2929
+			}
+			if gas, overflow = math.SafeAdd(gas, cost); overflow {
+				return 0, errGasUintOverflow
+			}
+		}
+		if transfersValue && stack.Back(3).Sign() != 0 {
+			cost := params.CallValueTransferGas
+			if evm.StateDB.Empty(addr) {
+				cost += params.CallNewAccountGas
+			}
+			if gas, overflow = math.SafeAdd(gas, cost); overflow {
+				return 0, errGasUintOverflow
+			}
+		}
+		return gas, nil
+	}
+}
+
 var (
 	gasCallEIP2929         = makeCallVariantGasCallEIP2929(gasCall)
 	gasDelegateCallEIP2929 = makeCallVariantGasCallEIP2929(gasDelegateCall)
 	gasStaticCallEIP2929   = makeCallVariantGasCallEIP2929(gasStaticCall)
 	gasCallCodeEIP2929     = makeCallVariantGasCallEIP2929(gasCallCode)
+	gasCallEIP7702         = makeCallVariantGasCallEIP7702(gasCall)
+	gasDelegateCallEIP7702 = makeCallVariantGasCallEIP7702(gasDelegateCall)
+	gasStaticCallEIP7702   = makeCallVariantGasCallEIP7702(gasStaticCall)
+	gasCallCodeEIP7702     = makeCallVariantGasCallEIP7702(gasCallCode)
+	gasExtCall             = makeExtCallGasFunc(true)
+	gasExtDelegateCall     = makeExtCallGasFunc(false)
+	gasExtStaticCall       = makeExtCallGasFunc(false)
 	gasSelfdestructEIP2929 = makeSelfdestructGasFn(true)
 	// gasSelfdestructEIP3529 implements the changes in EIP-3529 (no refunds)
 	gasSelfdestructEIP3529 = makeSelfdestructGasFn(false)
@@ -0,0 +1,261 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+)
+
+// Custom precompiled contracts let a service chain add native contracts without
+// modifying the built-in ones. An implementation is registered in the binary by
+// name with its version, and the chain config activates it at an address from a
+// block:
+//
+//	func init() {
+//		vm.RegisterPrecompiledContract("vrf", "1", &vrfVerify{})
+//	}
+//
+//	"precompiles": [{"name": "vrf", "address": "0x0000000000000000000000000000000000000300", "block": 0}]
+//
+// The address must be in the range reserved for precompiled contracts and must
+// not be used by a built-in one.
+var (
+	customPrecompiledContracts   = make(map[string]*customPrecompile)
+	customPrecompiledContractsMu sync.RWMutex
+)
+
+type customPrecompile struct {
+	contract PrecompiledContract
+	version  string
+}
+
+// RegisterPrecompiledContract registers the precompiled contract under the name.
+// The version identifies the implementation, and must be changed whenever its
+// results or costs change. A node refuses to execute a chain with another version
+// than the one the chain has been executed with since its activation.
+// It is supposed to be called in init, and panics if the name is registered twice.
+func RegisterPrecompiledContract(name, version string, p PrecompiledContract) {
+	customPrecompiledContractsMu.Lock()
+	defer customPrecompiledContractsMu.Unlock()
+
+	if p == nil {
+		panic("vm: RegisterPrecompiledContract of a nil contract " + name)
+	}
+	if version == "" {
+		panic("vm: RegisterPrecompiledContract without a version for " + name)
+	}
+	if _, exists := customPrecompiledContracts[name]; exists {
+		panic("vm: RegisterPrecompiledContract called twice for " + name)
+	}
+	customPrecompiledContracts[name] = &customPrecompile{contract: p, version: version}
+}
+
+// UnregisterPrecompiledContract removes the precompiled contract registered under
+// the name. It is used only for testing.
+func UnregisterPrecompiledContract(name string) {
+	customPrecompiledContractsMu.Lock()
+	defer customPrecompiledContractsMu.Unlock()
+
+	delete(customPrecompiledContracts, name)
+}
+
+// RegisteredPrecompiledContracts returns the sorted names of the registered
+// custom precompiled contracts.
+func RegisteredPrecompiledContracts() []string {
+	customPrecompiledContractsMu.RLock()
+	defer customPrecompiledContractsMu.RUnlock()
+
+	names := make([]string, 0, len(customPrecompiledContracts))
+	for name := range customPrecompiledContracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func registeredPrecompiledContract(name string) (*customPrecompile, bool) {
+	customPrecompiledContractsMu.RLock()
+	defer customPrecompiledContractsMu.RUnlock()
+
+	p, ok := customPrecompiledContracts[name]
+	return p, ok
+}
+
+// CustomPrecompiledContract implements PrecompiledContract with functions, so that
+// the gas and the computation cost can be hooked separately from the execution.
+type CustomPrecompiledContract struct {
+	GasFunc             func(input []byte) uint64
+	ComputationCostFunc func(input []byte) uint64
+	RunFunc             func(input []byte, contract *Contract, evm *EVM) ([]byte, error)
+}
+
+func (c *CustomPrecompiledContract) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	var gas, computationCost uint64
+	if c.GasFunc != nil {
+		gas = c.GasFunc(input)
+	}
+	if c.ComputationCostFunc != nil {
+		computationCost = c.ComputationCostFunc(input)
+	}
+	return gas, computationCost
+}
+
+func (c *CustomPrecompiledContract) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	return c.RunFunc(input, contract, evm)
+}
+
+// CheckPrecompiledContracts checks that the custom precompiled contracts of the chain
+// config are registered and placed at valid addresses. A node refuses to start with
+// the chain config on an error, since it would not execute the chain the same way.
+func CheckPrecompiledContracts(config *params.ChainConfig) error {
+	builtin := make(map[common.Address]bool)
+	for _, precompiles := range []map[common.Address]PrecompiledContract{
+		PrecompiledContractsByzantium,
+		PrecompiledContractsIstanbul,
+		PrecompiledContractsKore,
+		PrecompiledContractsCancun,
+		PrecompiledContractsPrague,
+		PrecompiledContractsOsaka,
+	} {
+		for addr := range precompiles {
+			builtin[addr] = true
+		}
+	}
+
+	var (
+		names     = make(map[string]bool)
+		addresses = make(map[common.Address]string)
+	)
+	for _, p := range config.Precompiles {
+		if _, ok := registeredPrecompiledContract(p.Name); !ok {
+			return fmt.Errorf("precompiled contract %q is not registered, registered: %v", p.Name, RegisteredPrecompiledContracts())
+		}
+		if names[p.Name] {
+			return fmt.Errorf("precompiled contract %q is configured twice", p.Name)
+		}
+		names[p.Name] = true
+		if p.Block == nil {
+			return fmt.Errorf("precompiled contract %q has no activation block", p.Name)
+		}
+		if !common.IsPrecompiledContractAddress(p.Address) {
+			return fmt.Errorf("precompiled contract %q at %v is out of the reserved address range", p.Name, p.Address.Hex())
+		}
+		if builtin[p.Address] {
+			return fmt.Errorf("precompiled contract %q at %v overrides a built-in one", p.Name, p.Address.Hex())
+		}
+		if other, exists := addresses[p.Address]; exists {
+			return fmt.Errorf("precompiled contracts %q and %q are at the same address %v", other, p.Name, p.Address.Hex())
+		}
+		addresses[p.Address] = p.Name
+	}
+	return nil
+}
+
+// PrecompileFingerprints returns the custom precompiled contracts of the chain
+// config with the versions of their registered implementations, sorted by name.
+func PrecompileFingerprints(config *params.ChainConfig) ([]*params.PrecompileFingerprint, error) {
+	if err := CheckPrecompiledContracts(config); err != nil {
+		return nil, err
+	}
+	fingerprints := make([]*params.PrecompileFingerprint, 0, len(config.Precompiles))
+	for _, p := range config.Precompiles {
+		registered, _ := registeredPrecompiledContract(p.Name)
+		fingerprints = append(fingerprints, &params.PrecompileFingerprint{
+			Name:    p.Name,
+			Address: p.Address,
+			Block:   new(big.Int).Set(p.Block),
+			Version: registered.version,
+		})
+	}
+	sort.Slice(fingerprints, func(i, j int) bool { return fingerprints[i].Name < fingerprints[j].Name })
+	return fingerprints, nil
+}
+
+// CheckPrecompileFingerprints checks that the custom precompiled contracts activated
+// at head are the same as the stored ones the chain has been executed with, down
+// to the versions of their implementations.
+func CheckPrecompileFingerprints(stored []*params.PrecompileFingerprint, config *params.ChainConfig, head *big.Int) error {
+	current, err := PrecompileFingerprints(config)
+	if err != nil {
+		return err
+	}
+	activated := func(fingerprints []*params.PrecompileFingerprint) map[string]*params.PrecompileFingerprint {
+		m := make(map[string]*params.PrecompileFingerprint)
+		for _, p := range fingerprints {
+			if p.IsActivated(head) {
+				m[p.Name] = p
+			}
+		}
+		return m
+	}
+	storedByName, currentByName := activated(stored), activated(current)
+	for _, s := range stored {
+		if storedByName[s.Name] != s {
+			continue
+		}
+		c, ok := currentByName[s.Name]
+		if !ok {
+			return fmt.Errorf("precompiled contract %v has been executed, but is not activated anymore", s)
+		}
+		if !s.Equal(c) {
+			return fmt.Errorf("precompiled contract %v has been executed, but is now %v", s, c)
+		}
+	}
+	for _, c := range current {
+		if currentByName[c.Name] == c && storedByName[c.Name] == nil {
+			return fmt.Errorf("precompiled contract %v is activated, but has not been executed", c)
+		}
+	}
+	return nil
+}
+
+// activeCustomPrecompiles returns the custom precompiled contracts activated at num.
+// The unregistered ones are ignored, which CheckPrecompiledContracts prevents.
+func activeCustomPrecompiles(config *params.ChainConfig, num *big.Int) map[common.Address]PrecompiledContract {
+	active := config.ActivePrecompiles(num)
+	if len(active) == 0 {
+		return nil
+	}
+	precompiles := make(map[common.Address]PrecompiledContract, len(active))
+	for _, p := range active {
+		if registered, ok := registeredPrecompiledContract(p.Name); ok {
+			precompiles[p.Address] = registered.contract
+		}
+	}
+	return precompiles
+}
+
+// withCustomPrecompiles returns the built-in precompiled contracts with the custom ones.
+func withCustomPrecompiles(builtin, custom map[common.Address]PrecompiledContract) map[common.Address]PrecompiledContract {
+	if len(custom) == 0 {
+		return builtin
+	}
+	precompiles := make(map[common.Address]PrecompiledContract, len(builtin)+len(custom))
+	for addr, p := range builtin {
+		precompiles[addr] = p
+	}
+	for addr, p := range custom {
+		precompiles[addr] = p
+	}
+	return precompiles
+}
@@ -0,0 +1,202 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerTestPrecompile registers a custom precompiled contract returning the
+// input twice, which costs 100 gas plus the input length.
+func registerTestPrecompile(t *testing.T, name string) {
+	registerTestPrecompileVersion(t, name, "1")
+}
+
+func registerTestPrecompileVersion(t *testing.T, name, version string) {
+	RegisterPrecompiledContract(name, version, &CustomPrecompiledContract{
+		GasFunc:             func(input []byte) uint64 { return 100 + uint64(len(input)) },
+		ComputationCostFunc: func(input []byte) uint64 { return 50 },
+		RunFunc: func(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+			return append(append([]byte{}, input...), input...), nil
+		},
+	})
+	t.Cleanup(func() { UnregisterPrecompiledContract(name) })
+}
+
+func TestCustomPrecompiledContract(t *testing.T) {
+	registerTestPrecompile(t, "double")
+	assert.Panics(t, func() { RegisterPrecompiledContract("double", "2", &CustomPrecompiledContract{}) })
+	assert.Panics(t, func() { RegisterPrecompiledContract("unversioned", "", &CustomPrecompiledContract{}) })
+	assert.Contains(t, RegisteredPrecompiledContracts(), "double")
+
+	var (
+		addr   = common.HexToAddress("0x0300")
+		sender = common.HexToAddress("0x1000")
+		input  = []byte{1, 2, 3}
+		config = *eofTestConfig
+	)
+	config.Precompiles = []*params.PrecompileConfig{{Name: "double", Address: addr, Block: big.NewInt(1)}}
+	require.NoError(t, CheckPrecompiledContracts(&config))
+
+	newEVM := func(number int64) *EVM {
+		statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+		require.NoError(t, err)
+		blockCtx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: big.NewInt(number),
+		}
+		return NewEVM(blockCtx, TxContext{}, statedb, &config, &Config{})
+	}
+
+	// Before the activation, the address is reserved.
+	evm := newEVM(0)
+	assert.NotContains(t, ActivePrecompiles(evm.chainRules), addr)
+	_, _, err := evm.Call(AccountRef(sender), addr, input, 10000, new(big.Int))
+	assert.ErrorIs(t, err, kerrors.ErrPrecompiledContractAddress)
+
+	evm = newEVM(1)
+	assert.Contains(t, ActivePrecompiles(evm.chainRules), addr)
+	assert.Len(t, ActivePrecompiles(evm.chainRules), len(ActivePrecompiles(eofTestConfig.Rules(big.NewInt(1))))+1)
+	ret, leftOverGas, err := evm.Call(AccountRef(sender), addr, input, 10000, new(big.Int))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3, 1, 2, 3}, ret)
+	assert.Equal(t, uint64(10000-103), leftOverGas)
+	assert.Equal(t, uint64(50), evm.GetOpCodeComputationCost())
+	// The built-in ones are not changed.
+	assert.NotContains(t, PrecompiledContractsOsaka, addr)
+	assert.Contains(t, evm.GetPrecompiledContractMap(sender), common.BytesToAddress([]byte{1}))
+
+	// Out of gas
+	_, _, err = evm.Call(AccountRef(sender), addr, input, 102, new(big.Int))
+	assert.ErrorIs(t, err, kerrors.ErrOutOfGas)
+}
+
+func TestCheckPrecompiledContracts(t *testing.T) {
+	registerTestPrecompile(t, "double")
+	registerTestPrecompile(t, "triple")
+
+	var (
+		addr    = common.HexToAddress("0x0300")
+		builtin = common.BytesToAddress([]byte{3, 255})
+		block   = big.NewInt(0)
+	)
+	testcases := []struct {
+		precompiles []*params.PrecompileConfig
+		expectErr   string
+	}{
+		{nil, ""},
+		{
+			[]*params.PrecompileConfig{{Name: "double", Address: addr, Block: block}, {Name: "triple", Address: common.HexToAddress("0x0301")}},
+			`precompiled contract "triple" has no activation block`,
+		},
+		{
+			[]*params.PrecompileConfig{{Name: "unknown", Address: addr, Block: block}},
+			`precompiled contract "unknown" is not registered, registered: [double triple]`,
+		},
+		{
+			[]*params.PrecompileConfig{{Name: "double", Address: addr, Block: block}, {Name: "double", Address: common.HexToAddress("0x0301"), Block: block}},
+			`precompiled contract "double" is configured twice`,
+		},
+		{
+			[]*params.PrecompileConfig{{Name: "double", Address: common.HexToAddress("0x0400"), Block: block}},
+			`precompiled contract "double" at 0x0000000000000000000000000000000000000400 is out of the reserved address range`,
+		},
+		{
+			[]*params.PrecompileConfig{{Name: "double", Address: builtin, Block: block}},
+			`precompiled contract "double" at ` + builtin.Hex() + ` overrides a built-in one`,
+		},
+		{
+			[]*params.PrecompileConfig{{Name: "double", Address: addr, Block: block}, {Name: "triple", Address: addr, Block: block}},
+			`precompiled contracts "double" and "triple" are at the same address 0x0000000000000000000000000000000000000300`,
+		},
+	}
+	for i, tc := range testcases {
+		err := CheckPrecompiledContracts(&params.ChainConfig{Precompiles: tc.precompiles})
+		if tc.expectErr == "" {
+			assert.NoError(t, err, "testcase %d", i)
+		} else {
+			assert.EqualError(t, err, tc.expectErr, "testcase %d", i)
+		}
+	}
+}
+
+func TestCheckPrecompileFingerprints(t *testing.T) {
+	registerTestPrecompile(t, "double")
+	registerTestPrecompileVersion(t, "triple", "2")
+
+	var (
+		addr   = common.HexToAddress("0x0300")
+		config = &params.ChainConfig{Precompiles: []*params.PrecompileConfig{
+			{Name: "triple", Address: common.HexToAddress("0x0301"), Block: big.NewInt(10)},
+			{Name: "double", Address: addr, Block: big.NewInt(0)},
+		}}
+	)
+	stored, err := PrecompileFingerprints(config)
+	require.NoError(t, err)
+	assert.Equal(t, []*params.PrecompileFingerprint{
+		{Name: "double", Address: addr, Block: big.NewInt(0), Version: "1"},
+		{Name: "triple", Address: common.HexToAddress("0x0301"), Block: big.NewInt(10), Version: "2"},
+	}, stored)
+
+	fingerprint := func(name, version string, address common.Address, block int64) *params.PrecompileFingerprint {
+		return &params.PrecompileFingerprint{Name: name, Address: address, Block: big.NewInt(block), Version: version}
+	}
+	testcases := []struct {
+		stored    []*params.PrecompileFingerprint
+		head      int64
+		expectErr string
+	}{
+		{stored, 100, ""},
+		// The ones not activated yet may be changed.
+		{[]*params.PrecompileFingerprint{stored[0], fingerprint("triple", "1", addr, 20)}, 9, ""},
+		{[]*params.PrecompileFingerprint{stored[0]}, 9, ""},
+		{
+			[]*params.PrecompileFingerprint{fingerprint("double", "0", addr, 0), stored[1]}, 0,
+			`precompiled contract "double" (version "0") at ` + addr.Hex() + ` from block 0 has been executed, but is now "double" (version "1") at ` + addr.Hex() + ` from block 0`,
+		},
+		{
+			[]*params.PrecompileFingerprint{fingerprint("double", "1", common.HexToAddress("0x0302"), 0), stored[1]}, 0,
+			`precompiled contract "double" (version "1") at 0x0000000000000000000000000000000000000302 from block 0 has been executed, but is now "double" (version "1") at ` + addr.Hex() + ` from block 0`,
+		},
+		{
+			[]*params.PrecompileFingerprint{stored[0], stored[1], fingerprint("quadruple", "1", common.HexToAddress("0x0302"), 5)}, 10,
+			`precompiled contract "quadruple" (version "1") at 0x0000000000000000000000000000000000000302 from block 5 has been executed, but is not activated anymore`,
+		},
+		{
+			[]*params.PrecompileFingerprint{stored[0]}, 10,
+			`precompiled contract "triple" (version "2") at 0x0000000000000000000000000000000000000301 from block 10 is activated, but has not been executed`,
+		},
+	}
+	for i, tc := range testcases {
+		err := CheckPrecompileFingerprints(tc.stored, config, big.NewInt(tc.head))
+		if tc.expectErr == "" {
+			assert.NoError(t, err, "testcase %d", i)
+		} else {
+			assert.EqualError(t, err, tc.expectErr, "testcase %d", i)
+		}
+	}
+}
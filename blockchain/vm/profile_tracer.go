@@ -0,0 +1,386 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/pprof/profile"
+	"github.com/klaytn/klaytn/common"
+)
+
+// precompiledOpName is the name under which the gas and the computation cost
+// of a call frame without any opcode, i.e. a precompiled contract, are reported.
+const precompiledOpName = "PRECOMPILED"
+
+// ProfileCost is the aggregated cost of the executed opcodes.
+type ProfileCost struct {
+	Count           uint64 `json:"count"`
+	Gas             uint64 `json:"gas"`
+	ComputationCost uint64 `json:"computationCost"`
+}
+
+func (c *ProfileCost) add(gas, computationCost uint64) {
+	c.Count++
+	c.Gas += gas
+	c.ComputationCost += computationCost
+}
+
+// OpcodeProfile is the cost of an opcode over all contracts.
+type OpcodeProfile struct {
+	Op string `json:"op"`
+	ProfileCost
+}
+
+// ContractProfile is the cost of the opcodes executed in the code of a contract.
+type ContractProfile struct {
+	Address common.Address `json:"address"`
+	ProfileCost
+}
+
+// PCProfile is the cost of the opcode at a program counter of a contract code.
+type PCProfile struct {
+	Address common.Address `json:"address"`
+	PC      uint64         `json:"pc"`
+	Op      string         `json:"op"`
+	ProfileCost
+}
+
+// ProfileResult is the result of the ProfileTracer. The entries are sorted by
+// gas in descending order. Pprof is a gzipped profile.proto, which can be
+// rendered by `go tool pprof`, e.g. as a flame graph.
+type ProfileResult struct {
+	Gas             uint64            `json:"gas"`
+	ComputationCost uint64            `json:"computationCost"`
+	Opcodes         []OpcodeProfile   `json:"opcodes"`
+	Contracts       []ContractProfile `json:"contracts"`
+	PCs             []PCProfile       `json:"pcs"`
+	Pprof           []byte            `json:"pprof"`
+}
+
+type pcKey struct {
+	address common.Address
+	pc      uint64
+}
+
+// profileStep is an executed opcode whose gas is not determined yet.
+type profileStep struct {
+	pc       uint64
+	op       string
+	gas      uint64 // gas left before the execution
+	ccOpcode uint64
+}
+
+// profileFrame is a call frame of the execution.
+type profileFrame struct {
+	address    common.Address // address of the executed code
+	pending    *profileStep   // last opcode executed in the frame
+	childGas   uint64         // gas used by the calls of the pending opcode
+	attributed uint64         // gas attributed to the opcodes and the calls of the frame
+	ccStart    uint64         // computation cost spent before entering the frame
+}
+
+// profileSample is the cost of the opcodes executed with the same call stack.
+type profileSample struct {
+	stack []pcKey // call stack from the root, the last one is the executing opcode
+	op    string
+	cost  ProfileCost
+}
+
+// ProfileTracer is a tracer aggregating the gas and the computation cost of
+// the executed opcodes per opcode, per contract and per program counter.
+//
+// The gas of an opcode is what the opcode actually consumed, i.e. the gas
+// forwarded to a call is attributed to the opcodes executed by the callee,
+// and the gas of a precompiled contract is attributed to the contract.
+type ProfileTracer struct {
+	env    *EVM
+	frames []*profileFrame
+
+	opcodes   map[string]*ProfileCost
+	contracts map[common.Address]*ProfileCost
+	pcs       map[pcKey]*PCProfile
+	samples   map[string]*profileSample
+
+	err       error
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// NewProfileTracer returns a new ProfileTracer.
+func NewProfileTracer() *ProfileTracer {
+	return &ProfileTracer{
+		opcodes:   make(map[string]*ProfileCost),
+		contracts: make(map[common.Address]*ProfileCost),
+		pcs:       make(map[pcKey]*PCProfile),
+		samples:   make(map[string]*profileSample),
+	}
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *ProfileTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+func (t *ProfileTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *ProfileTracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *ProfileTracer) CaptureStart(env *EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.enter(to)
+}
+
+func (t *ProfileTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	t.exit(gasUsed)
+}
+
+func (t *ProfileTracer) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.enter(to)
+}
+
+func (t *ProfileTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.exit(gasUsed)
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *ProfileTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *ScopeContext, depth int, err error) {
+	if t.err != nil || len(t.frames) == 0 {
+		return
+	}
+	// If tracing was interrupted, set the error and stop
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	// The gas consumed by the previous opcode is known now from the gas left.
+	if step := frame.pending; step != nil {
+		t.record(step, safeSub(step.gas, gas+frame.childGas))
+	}
+	frame.pending = &profileStep{pc: pc, op: op.String(), gas: gas, ccOpcode: ccOpcode}
+	frame.childGas = 0
+}
+
+// CaptureFault implements the Tracer interface. The failed opcode was already
+// captured by CaptureState and its gas is determined when the frame exits.
+func (t *ProfileTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *ScopeContext, depth int, err error) {
+}
+
+func (t *ProfileTracer) enter(address common.Address) {
+	frame := &profileFrame{address: address}
+	if t.env != nil {
+		frame.ccStart = t.env.GetOpCodeComputationCost()
+	}
+	t.frames = append(t.frames, frame)
+}
+
+// exit attributes the gas used by the frame which is not attributed yet to its
+// last opcode, and pops the frame.
+func (t *ProfileTracer) exit(gasUsed uint64) {
+	if len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	if t.err == nil {
+		if step := frame.pending; step != nil {
+			t.record(step, safeSub(gasUsed, frame.attributed))
+		} else {
+			// No opcode is executed, so the cost is of a precompiled contract.
+			var computationCost uint64
+			if t.env != nil {
+				computationCost = safeSub(t.env.GetOpCodeComputationCost(), frame.ccStart)
+			}
+			if gasUsed > 0 || computationCost > 0 {
+				t.record(&profileStep{op: precompiledOpName, ccOpcode: computationCost}, gasUsed)
+			}
+		}
+	}
+	t.frames = t.frames[:len(t.frames)-1]
+	if len(t.frames) > 0 {
+		parent := t.frames[len(t.frames)-1]
+		parent.childGas += gasUsed
+		parent.attributed += gasUsed
+	}
+}
+
+// record adds the cost of the step executed in the current frame.
+func (t *ProfileTracer) record(step *profileStep, gas uint64) {
+	frame := t.frames[len(t.frames)-1]
+	frame.attributed += gas
+
+	if t.opcodes[step.op] == nil {
+		t.opcodes[step.op] = new(ProfileCost)
+	}
+	t.opcodes[step.op].add(gas, step.ccOpcode)
+
+	if t.contracts[frame.address] == nil {
+		t.contracts[frame.address] = new(ProfileCost)
+	}
+	t.contracts[frame.address].add(gas, step.ccOpcode)
+
+	key := pcKey{frame.address, step.pc}
+	if t.pcs[key] == nil {
+		t.pcs[key] = &PCProfile{Address: frame.address, PC: step.pc, Op: step.op}
+	}
+	t.pcs[key].add(gas, step.ccOpcode)
+
+	// The callers are at the opcodes pending in the parent frames.
+	stack := make([]pcKey, 0, len(t.frames))
+	for _, f := range t.frames[:len(t.frames)-1] {
+		if f.pending != nil {
+			stack = append(stack, pcKey{f.address, f.pending.pc})
+		}
+	}
+	stack = append(stack, key)
+	var sb strings.Builder
+	for _, k := range stack {
+		fmt.Fprintf(&sb, "%x:%d;", k.address, k.pc)
+	}
+	sb.WriteString(step.op)
+	sample := t.samples[sb.String()]
+	if sample == nil {
+		sample = &profileSample{stack: stack, op: step.op}
+		t.samples[sb.String()] = sample
+	}
+	sample.cost.add(gas, step.ccOpcode)
+}
+
+// GetResult returns the aggregated costs and the pprof profile of them.
+func (t *ProfileTracer) GetResult() (*ProfileResult, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	result := &ProfileResult{
+		Opcodes:   make([]OpcodeProfile, 0, len(t.opcodes)),
+		Contracts: make([]ContractProfile, 0, len(t.contracts)),
+		PCs:       make([]PCProfile, 0, len(t.pcs)),
+	}
+	for op, cost := range t.opcodes {
+		result.Opcodes = append(result.Opcodes, OpcodeProfile{Op: op, ProfileCost: *cost})
+		result.Gas += cost.Gas
+		result.ComputationCost += cost.ComputationCost
+	}
+	sort.Slice(result.Opcodes, func(i, j int) bool {
+		a, b := result.Opcodes[i], result.Opcodes[j]
+		if a.Gas != b.Gas {
+			return a.Gas > b.Gas
+		}
+		return a.Op < b.Op
+	})
+	for address, cost := range t.contracts {
+		result.Contracts = append(result.Contracts, ContractProfile{Address: address, ProfileCost: *cost})
+	}
+	sort.Slice(result.Contracts, func(i, j int) bool {
+		a, b := result.Contracts[i], result.Contracts[j]
+		if a.Gas != b.Gas {
+			return a.Gas > b.Gas
+		}
+		return bytes.Compare(a.Address[:], b.Address[:]) < 0
+	})
+	for _, pc := range t.pcs {
+		result.PCs = append(result.PCs, *pc)
+	}
+	sort.Slice(result.PCs, func(i, j int) bool {
+		a, b := result.PCs[i], result.PCs[j]
+		if a.Gas != b.Gas {
+			return a.Gas > b.Gas
+		}
+		if c := bytes.Compare(a.Address[:], b.Address[:]); c != 0 {
+			return c < 0
+		}
+		return a.PC < b.PC
+	})
+
+	var buf bytes.Buffer
+	if err := t.Profile().Write(&buf); err != nil {
+		return nil, err
+	}
+	result.Pprof = buf.Bytes()
+	return result, nil
+}
+
+// Profile returns the pprof profile of the aggregated costs. A location is a
+// program counter of a contract code, whose function is the contract, and the
+// leaf of a sample is the executed opcode.
+func (t *ProfileTracer) Profile() *profile.Profile {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "count", Unit: "count"},
+			{Type: "gas", Unit: "gas"},
+			{Type: "computation_cost", Unit: "cost"},
+		},
+		DefaultSampleType: "gas",
+		PeriodType:        &profile.ValueType{Type: "gas", Unit: "gas"},
+		Period:            1,
+	}
+	functions := make(map[string]*profile.Function)
+	function := func(name string) *profile.Function {
+		if f, ok := functions[name]; ok {
+			return f
+		}
+		f := &profile.Function{ID: uint64(len(p.Function) + 1), Name: name, SystemName: name}
+		functions[name] = f
+		p.Function = append(p.Function, f)
+		return f
+	}
+	locations := make(map[string]*profile.Location)
+	location := func(key string, line profile.Line) *profile.Location {
+		if l, ok := locations[key]; ok {
+			return l
+		}
+		l := &profile.Location{ID: uint64(len(p.Location) + 1), Line: []profile.Line{line}}
+		locations[key] = l
+		p.Location = append(p.Location, l)
+		return l
+	}
+
+	// The samples are sorted to make the profile deterministic.
+	keys := make([]string, 0, len(t.samples))
+	for key := range t.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sample := t.samples[key]
+		// The locations of a pprof sample start from the leaf.
+		locs := []*profile.Location{location(sample.op, profile.Line{Function: function(sample.op)})}
+		for i := len(sample.stack) - 1; i >= 0; i-- {
+			k := sample.stack[i]
+			name := k.address.Hex()
+			locs = append(locs, location(fmt.Sprintf("%s:%d", name, k.pc), profile.Line{Function: function(name), Line: int64(k.pc)}))
+		}
+		p.Sample = append(p.Sample, &profile.Sample{
+			Location: locs,
+			Value:    []int64{int64(sample.cost.Count), int64(sample.cost.Gas), int64(sample.cost.ComputationCost)},
+		})
+	}
+	return p
+}
+
+func safeSub(a, b uint64) uint64 {
+	if a < b {
+		return 0
+	}
+	return a - b
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProfileTestEVM(t *testing.T, tracer Tracer) *EVM {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	require.NoError(t, err)
+	blockCtx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	return NewEVM(blockCtx, TxContext{}, statedb, eofTestConfig, &Config{Debug: true, Tracer: tracer})
+}
+
+func TestProfileTracer(t *testing.T) {
+	var (
+		tracer   = NewProfileTracer()
+		evm      = newProfileTestEVM(t, tracer)
+		sender   = common.HexToAddress("0x1000")
+		caller   = common.HexToAddress("0xaaaa")
+		callee   = common.HexToAddress("0xbbbb")
+		sha256   = common.BytesToAddress([]byte{2})
+		gasLimit = uint64(1000000)
+	)
+	// The caller calls the callee storing a word, and then sha256 with 32 bytes.
+	deployTestCode(evm, caller, []byte{
+		byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH2), 0xbb, 0xbb, byte(PUSH3), 0x01, 0x86, 0xa0,
+		byte(CALL), // pc 17
+		byte(POP),
+		byte(PUSH1), 32, byte(PUSH1), 0, byte(PUSH1), 32, byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH1), 2, byte(GAS),
+		byte(CALL), // pc 32
+		byte(POP), byte(STOP),
+	})
+	deployTestCode(evm, callee, []byte{
+		byte(PUSH1), 1, byte(PUSH1), 0,
+		byte(SSTORE), // pc 4
+		byte(STOP),
+	})
+
+	_, leftOverGas, err := evm.Call(AccountRef(sender), caller, nil, gasLimit, new(big.Int))
+	require.NoError(t, err)
+	result, err := tracer.GetResult()
+	require.NoError(t, err)
+
+	assert.Equal(t, gasLimit-leftOverGas, result.Gas)
+	assert.Equal(t, evm.GetOpCodeComputationCost(), result.ComputationCost)
+
+	var opcodeGas, contractGas, pcGas uint64
+	for _, op := range result.Opcodes {
+		opcodeGas += op.Gas
+	}
+	for _, contract := range result.Contracts {
+		contractGas += contract.Gas
+	}
+	for _, pc := range result.PCs {
+		pcGas += pc.Gas
+	}
+	assert.Equal(t, result.Gas, opcodeGas)
+	assert.Equal(t, result.Gas, contractGas)
+	assert.Equal(t, result.Gas, pcGas)
+	assert.Len(t, result.Contracts, 3)
+	for i := 1; i < len(result.PCs); i++ {
+		assert.GreaterOrEqual(t, result.PCs[i-1].Gas, result.PCs[i].Gas)
+	}
+
+	pcs := make(map[pcKey]PCProfile)
+	for _, pc := range result.PCs {
+		pcs[pcKey{pc.Address, pc.PC}] = pc
+	}
+	// The gas forwarded to the callee is not attributed to the calls.
+	call := pcs[pcKey{caller, 17}]
+	assert.Equal(t, "CALL", call.Op)
+	assert.Equal(t, uint64(1), call.Count)
+	assert.NotZero(t, call.Gas)
+	assert.Less(t, call.Gas, uint64(10000))
+	assert.Less(t, pcs[pcKey{caller, 32}].Gas, uint64(10000))
+	sstore := pcs[pcKey{callee, 4}]
+	assert.Equal(t, "SSTORE", sstore.Op)
+	assert.GreaterOrEqual(t, sstore.Gas, uint64(20000))
+	// sha256 of a word costs 60 + 12 gas.
+	precompiled := pcs[pcKey{sha256, 0}]
+	assert.Equal(t, precompiledOpName, precompiled.Op)
+	assert.Equal(t, uint64(72), precompiled.Gas)
+	assert.NotZero(t, precompiled.ComputationCost)
+
+	// The JSON result embeds the costs in the entries.
+	b, err := json.Marshal(result.Opcodes[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"op":"SSTORE","count":1,"gas":`+jsonUint(sstore.Gas)+`,"computationCost":`+jsonUint(sstore.ComputationCost)+`}`, string(b))
+
+	p, err := profile.ParseData(result.Pprof)
+	require.NoError(t, err)
+	require.NoError(t, p.CheckValid())
+	assert.Equal(t, "gas", p.DefaultSampleType)
+	var sampleGas int64
+	var sstoreStack []string
+	for _, sample := range p.Sample {
+		sampleGas += sample.Value[1]
+		if sample.Location[0].Line[0].Function.Name == "SSTORE" {
+			for _, loc := range sample.Location[1:] {
+				sstoreStack = append(sstoreStack, loc.Line[0].Function.Name+":"+jsonUint(uint64(loc.Line[0].Line)))
+			}
+		}
+	}
+	assert.Equal(t, int64(result.Gas), sampleGas)
+	assert.Equal(t, []string{callee.Hex() + ":4", caller.Hex() + ":17"}, sstoreStack)
+}
+
+func TestProfileTracerStop(t *testing.T) {
+	var (
+		tracer = NewProfileTracer()
+		evm    = newProfileTestEVM(t, tracer)
+		addr   = common.HexToAddress("0xaaaa")
+	)
+	deployTestCode(evm, addr, []byte{byte(PUSH1), 0, byte(POP), byte(STOP)})
+	stopErr := errors.New("execution timeout")
+	tracer.Stop(stopErr)
+
+	_, _, err := evm.Call(AccountRef(common.HexToAddress("0x1000")), addr, nil, 100000, new(big.Int))
+	require.NoError(t, err)
+	_, err = tracer.GetResult()
+	assert.ErrorIs(t, err, stopErr)
+}
+
+func jsonUint(v uint64) string {
+	return new(big.Int).SetUint64(v).String()
+}
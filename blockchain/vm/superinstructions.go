@@ -0,0 +1,152 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+// superinstruction is a sequence of two opcodes of legacy code executed in one
+// dispatch of the interpreter loop. It costs the sum of the gas and the
+// computation cost of the opcodes, so the result of an execution is the same as
+// with the opcodes executed separately.
+type superinstruction uint8
+
+const (
+	noFusion       superinstruction = iota
+	fusedPushJump                   // PUSH1 or PUSH2 of a valid jump destination, and JUMP
+	fusedPushJumpi                  // PUSH1 or PUSH2 of a valid jump destination, and JUMPI
+	fusedDupSwap                    // DUPn and SWAPm
+	fusedSwapPop                    // SWAPn and POP
+)
+
+// codeFusions returns the superinstruction starting at each pc of the code, or
+// nil if the code has none. The second opcode of a superinstruction is never a
+// JUMPDEST, so it is only executed after the first one.
+func codeFusions(code []byte, bitmap bitvec) []superinstruction {
+	var fusions []superinstruction
+	fuse := func(pc uint64, s superinstruction) {
+		if fusions == nil {
+			fusions = make([]superinstruction, len(code))
+		}
+		fusions[pc] = s
+	}
+	for pc := uint64(0); pc < uint64(len(code)); {
+		op := OpCode(code[pc])
+		switch {
+		case op == PUSH1 || op == PUSH2:
+			next := pc + uint64(op-PUSH1) + 2
+			if next >= uint64(len(code)) {
+				break
+			}
+			dest := uint64(code[pc+1])
+			if op == PUSH2 {
+				dest = dest<<8 | uint64(code[pc+2])
+			}
+			if dest >= uint64(len(code)) || OpCode(code[dest]) != JUMPDEST || !bitmap.codeSegment(dest) {
+				break
+			}
+			switch OpCode(code[next]) {
+			case JUMP:
+				fuse(pc, fusedPushJump)
+			case JUMPI:
+				fuse(pc, fusedPushJumpi)
+			}
+		case op >= DUP1 && op <= DUP16:
+			if pc+1 < uint64(len(code)) && OpCode(code[pc+1]) >= SWAP1 && OpCode(code[pc+1]) <= SWAP16 {
+				fuse(pc, fusedDupSwap)
+			}
+		case op >= SWAP1 && op <= SWAP16:
+			if pc+1 < uint64(len(code)) && OpCode(code[pc+1]) == POP {
+				fuse(pc, fusedSwapPop)
+			}
+		}
+		if op >= PUSH1 && op <= PUSH32 {
+			pc += uint64(op-PUSH1) + 2
+		} else {
+			pc++
+		}
+	}
+	return fusions
+}
+
+// runSuperinstruction executes the superinstruction at pc and moves pc to the
+// next opcode to execute. It returns false without any change if the opcodes
+// have to be executed separately, i.e. if the stack is not valid for them or
+// the gas is not enough, so that the failure occurs at the right opcode.
+func (in *EVMInterpreter) runSuperinstruction(s superinstruction, pc *uint64, jt *JumpTable, contract *Contract, stack *Stack) (bool, error) {
+	var (
+		code          = contract.Code
+		op1           = OpCode(code[*pc])
+		size1         = uint64(1) // size of the first opcode with its immediate data
+		first, second *operation
+		sLen          = stack.len()
+	)
+	if op1 == PUSH1 || op1 == PUSH2 {
+		size1 += uint64(op1 - PUSH1 + 1)
+	}
+	op2 := OpCode(code[*pc+size1])
+	first, second = jt[op1], jt[op2]
+	if first == nil || second == nil || first.dynamicGas != nil || second.dynamicGas != nil {
+		return false, nil
+	}
+
+	// Validate the stack for the opcodes in order.
+	if sLen < first.minStack || sLen > first.maxStack {
+		return false, nil
+	}
+	if s != fusedSwapPop {
+		sLen++ // PUSH and DUP push an item
+	}
+	if sLen < second.minStack || sLen > second.maxStack {
+		return false, nil
+	}
+
+	gas := first.constantGas + second.constantGas
+	if contract.Gas < gas {
+		return false, nil
+	}
+	contract.Gas -= gas
+	in.evm.opcodeComputationCostSum += first.computationCost + second.computationCost
+	if in.evm.opcodeComputationCostSum > in.evm.Config.ComputationCostLimit {
+		return true, ErrOpcodeComputationCostLimitReached
+	}
+
+	switch s {
+	case fusedPushJump, fusedPushJumpi:
+		if in.evm.Cancelled() {
+			return true, errStopToken
+		}
+		dest := uint64(code[*pc+1])
+		if op1 == PUSH2 {
+			dest = dest<<8 | uint64(code[*pc+2])
+		}
+		if s == fusedPushJumpi {
+			if cond := stack.pop(); cond.IsZero() {
+				*pc += size1 + 1
+				return true, nil
+			}
+		}
+		// The destination is validated by the analysis.
+		*pc = dest
+	case fusedDupSwap:
+		stack.dup(int(op1-DUP1) + 1)
+		stack.swap(int(op2-SWAP1) + 2)
+		*pc += 2
+	case fusedSwapPop:
+		stack.swap(int(op1-SWAP1) + 2)
+		stack.pop()
+		*pc += 2
+	}
+	return true, nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeFusions(t *testing.T) {
+	tests := []struct {
+		code     []byte
+		expected map[int]superinstruction
+	}{
+		{[]byte{byte(PUSH1), 3, byte(JUMP), byte(JUMPDEST)}, map[int]superinstruction{0: fusedPushJump}},
+		{[]byte{byte(PUSH2), 0, 4, byte(JUMPI), byte(JUMPDEST)}, map[int]superinstruction{0: fusedPushJumpi}},
+		{[]byte{byte(DUP2), byte(SWAP3), byte(SWAP1), byte(POP)}, map[int]superinstruction{0: fusedDupSwap, 2: fusedSwapPop}},
+		// The destination is not a JUMPDEST.
+		{[]byte{byte(PUSH1), 2, byte(JUMP), byte(JUMPDEST)}, nil},
+		// The destination is out of the code.
+		{[]byte{byte(PUSH1), 4, byte(JUMP), byte(JUMPDEST)}, nil},
+		// The destination is in the data of PUSH1.
+		{[]byte{byte(PUSH1), 4, byte(JUMP), byte(PUSH1), byte(JUMPDEST)}, nil},
+		// The opcodes are in the data of PUSH2.
+		{[]byte{byte(PUSH2), byte(DUP1), byte(SWAP1), byte(SWAP1), byte(POP)}, map[int]superinstruction{3: fusedSwapPop}},
+		// The code ends in the middle.
+		{[]byte{byte(PUSH1), 0, byte(DUP1)}, nil},
+		{[]byte{byte(PUSH2), 0}, nil},
+	}
+	for i, test := range tests {
+		fusions := codeFusions(test.code, codeBitmap(test.code))
+		if test.expected == nil {
+			assert.Nil(t, fusions, "test %d", i)
+			continue
+		}
+		require.Len(t, fusions, len(test.code), "test %d", i)
+		for pc, s := range fusions {
+			assert.Equal(t, test.expected[pc], s, "test %d pc %d", i, pc)
+		}
+	}
+}
+
+type superinstructionResult struct {
+	ret             []byte
+	leftOverGas     uint64
+	computationCost uint64
+	err             error
+}
+
+// runWithSuperinstructions runs the code with or without the superinstructions.
+func runWithSuperinstructions(t *testing.T, code []byte, gas uint64, disable bool) superinstructionResult {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	require.NoError(t, err)
+	blockCtx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	evm := NewEVM(blockCtx, TxContext{}, statedb, eofTestConfig, &Config{
+		ComputationCostLimit:     params.OpcodeComputationCostLimitInfinite,
+		DisableSuperinstructions: disable,
+	})
+	addr := common.HexToAddress("0xaaaa")
+	deployTestCode(evm, addr, code)
+	ret, leftOverGas, err := evm.Call(AccountRef(common.HexToAddress("0x1000")), addr, nil, gas, new(big.Int))
+	return superinstructionResult{ret, leftOverGas, evm.GetOpCodeComputationCost(), err}
+}
+
+// randomSuperinstructionCode returns a random code full of the opcodes fused by
+// superinstructions, which returns the top four items of the stack. The opcodes
+// are chosen not to underflow the stack if the code is executed straight.
+func randomSuperinstructionCode(r *rand.Rand) []byte {
+	var (
+		n      = 20 + r.Intn(60)
+		code   []byte
+		height int
+	)
+	for i := 0; i < 4; i++ {
+		code = append(code, byte(PUSH1), byte(r.Intn(256)))
+		height++
+	}
+	for len(code) < n {
+		switch r.Intn(10) {
+		case 0:
+			code = append(code, byte(PUSH1), byte(r.Intn(n+8)), byte(JUMP))
+		case 1:
+			if height > 0 {
+				code = append(code, byte(PUSH2), 0, byte(r.Intn(n+8)), byte(JUMPI))
+				height--
+			}
+		case 2, 3:
+			code = append(code, byte(JUMPDEST))
+		case 4, 5:
+			if d := 1 + r.Intn(4); height >= d {
+				code = append(code, byte(DUP1)+byte(d-1))
+				height++
+			}
+		case 6, 7:
+			if d := 1 + r.Intn(4); height > d {
+				code = append(code, byte(SWAP1)+byte(d-1))
+			}
+		case 8:
+			if height > 4 {
+				code = append(code, byte(POP))
+				height--
+			}
+		default:
+			if height > 4 {
+				code = append(code, byte(ADD))
+				height--
+			} else {
+				code = append(code, byte(PUSH1), byte(r.Intn(4)))
+				height++
+			}
+		}
+	}
+	for i := 0; i < 4; i++ {
+		code = append(code, byte(PUSH1), byte(32*i), byte(MSTORE))
+	}
+	return append(code, byte(PUSH1), 128, byte(PUSH1), 0, byte(RETURN))
+}
+
+// TestSuperinstructionsDifferential tests that the superinstructions do not
+// change the result of executions compared to the plain interpreter.
+func TestSuperinstructionsDifferential(t *testing.T) {
+	programs := [][]byte{
+		// A loop of the superinstructions counting down from 100.
+		{
+			byte(PUSH1), 100, byte(JUMPDEST), byte(PUSH1), 7, byte(JUMP), byte(INVALID), byte(JUMPDEST),
+			byte(PUSH1), 1, byte(SWAP1), byte(SUB), byte(DUP1), byte(DUP1), byte(SWAP1), byte(POP),
+			byte(SWAP1), byte(POP), byte(DUP1), byte(PUSH1), 2, byte(JUMPI),
+			byte(PUSH1), 0, byte(MSTORE), byte(PUSH1), 32, byte(PUSH1), 0, byte(RETURN),
+		},
+		// JUMPI not taken
+		{byte(PUSH1), 0, byte(PUSH1), 5, byte(JUMPI), byte(JUMPDEST), byte(STOP)},
+		// Stack underflow in the second opcode
+		{byte(PUSH1), 1, byte(SWAP1), byte(POP)},
+		{byte(PUSH1), 1, byte(DUP1), byte(SWAP2)},
+		{byte(PUSH1), 3, byte(JUMPI), byte(JUMPDEST)},
+		// Stack overflow
+		{byte(JUMPDEST), byte(PUSH1), 0, byte(PUSH1), 0, byte(JUMP)},
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		programs = append(programs, randomSuperinstructionCode(r))
+	}
+
+	for i, code := range programs {
+		fusions := codeFusions(code, codeBitmap(code))
+		for _, gas := range []uint64{5, 11, 14, 20, 50, 100000} {
+			expected := runWithSuperinstructions(t, code, gas, true)
+			actual := runWithSuperinstructions(t, code, gas, false)
+			assert.Equal(t, expected, actual, "program %d %x (fusions %v) with gas %d", i, code, fusions, gas)
+		}
+	}
+}
+
+func TestCodeAnalysisCache(t *testing.T) {
+	code := []byte{byte(PUSH1), 3, byte(JUMP), byte(JUMPDEST), byte(DUP1), byte(SWAP1), byte(STOP)}
+	hash := crypto.Keccak256Hash(code)
+	codeAnalysisCache.Remove(hash)
+
+	runWithSuperinstructions(t, code, 100000, false)
+	cached, ok := codeAnalysisCache.Get(hash)
+	require.True(t, ok)
+	analysis := cached.(*codeAnalysis)
+	assert.Equal(t, codeBitmap(code), analysis.bitmap)
+	assert.Equal(t, []superinstruction{fusedPushJump, 0, 0, 0, fusedDupSwap, 0, 0}, analysis.fusions)
+
+	// The analysis is shared by the later executions.
+	contract := NewContract(AccountRef(common.Address{}), AccountRef(common.Address{}), new(big.Int), 0)
+	contract.SetCallCode(&common.Address{}, hash, code)
+	assert.Same(t, analysis, contract.analyze())
+
+	// The analysis of initcode without the hash is not cached.
+	contract = NewContract(AccountRef(common.Address{}), AccountRef(common.Address{}), new(big.Int), 0)
+	contract.SetCallCode(&common.Address{}, common.Hash{}, code)
+	assert.NotSame(t, analysis, contract.analyze())
+	assert.Nil(t, contract.analyze().fusions)
+}
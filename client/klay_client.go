@@ -338,6 +338,21 @@ func (ec *Client) StorageAt(ctx context.Context, account common.Address, key com
 	return result, err
 }
 
+// GetMultiProof returns the Merkle-proof of the given accounts and storage keys,
+// merged in a single set of trie nodes. The block number can be nil, in which
+// case the proof is taken from the latest known block. The result can be checked
+// with VerifyMultiProof.
+func (ec *Client) GetMultiProof(ctx context.Context, queries []api.ProofQuery, blockNumber *big.Int) (*api.MultiProofResult, error) {
+	var result *api.MultiProofResult
+	if err := ec.c.CallContext(ctx, &result, "klay_getMultiProof", queries, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, klaytn.NotFound
+	}
+	return result, nil
+}
+
 // CodeAt returns the contract code of the given account.
 // The block number can be nil, in which case the code is taken from the latest known block.
 func (ec *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
@@ -0,0 +1,116 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/api"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+var emptyCodeHash = crypto.Keccak256Hash(nil)
+
+// VerifyMultiProof checks a result of GetMultiProof against the state root of
+// the given header. It returns an error if the proof of an account or a storage
+// key is missing or invalid, or if a returned value differs from the proven one.
+//
+// Only storage tries whose root is a plain 32-byte hash can be verified, which
+// excludes the storage tries written with live pruning.
+func VerifyMultiProof(header *types.Header, result *api.MultiProofResult) error {
+	if result.StateRoot != header.Root {
+		return fmt.Errorf("state root mismatch: have %x, want %x", result.StateRoot, header.Root)
+	}
+	if result.BlockHash != header.Hash() {
+		return fmt.Errorf("block hash mismatch: have %x, want %x", result.BlockHash, header.Hash())
+	}
+	nodes := make([][]byte, len(result.Nodes))
+	for i, node := range result.Nodes {
+		nodes[i] = node
+	}
+	proof := statedb.NewProofSetFromList(nodes)
+
+	for _, acc := range result.Accounts {
+		if err := verifyAccountProof(header.Root, &acc, proof); err != nil {
+			return fmt.Errorf("account %x: %v", acc.Address, err)
+		}
+	}
+	return nil
+}
+
+func verifyAccountProof(root common.Hash, acc *api.MultiProofAccount, proof *statedb.ProofSet) error {
+	leaf, err, _ := statedb.VerifyProof(root, crypto.Keccak256(acc.Address.Bytes()), proof)
+	if err != nil {
+		return err
+	}
+	var (
+		balance     = new(big.Int)
+		nonce       uint64
+		codeHash    = emptyCodeHash
+		storageRoot = types.EmptyRootHashOriginal
+	)
+	if len(leaf) > 0 {
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(leaf, serializer); err != nil {
+			return fmt.Errorf("invalid account: %v", err)
+		}
+		proven := serializer.GetAccount()
+		balance, nonce = proven.GetBalance(), proven.GetNonce()
+		if pa := account.GetProgramAccount(proven); pa != nil {
+			codeHash = common.BytesToHash(pa.GetCodeHash())
+			storageRoot = pa.GetStorageRoot().Unextend()
+		}
+	}
+	switch {
+	case acc.Balance == nil || acc.Balance.ToInt().Cmp(balance) != 0:
+		return fmt.Errorf("balance mismatch: have %v, want %v", acc.Balance, balance)
+	case uint64(acc.Nonce) != nonce:
+		return fmt.Errorf("nonce mismatch: have %d, want %d", acc.Nonce, nonce)
+	case acc.CodeHash != codeHash:
+		return fmt.Errorf("code hash mismatch: have %x, want %x", acc.CodeHash, codeHash)
+	case acc.StorageHash != storageRoot:
+		return fmt.Errorf("storage hash mismatch: have %x, want %x", acc.StorageHash, storageRoot)
+	}
+
+	for _, slot := range acc.Storage {
+		key := common.HexToHash(slot.Key)
+		value := new(big.Int)
+		if storageRoot != types.EmptyRootHashOriginal {
+			leaf, err, _ := statedb.VerifyProof(storageRoot, crypto.Keccak256(key.Bytes()), proof)
+			if err != nil {
+				return fmt.Errorf("storage key %x: %v", key, err)
+			}
+			if len(leaf) > 0 {
+				_, content, _, err := rlp.Split(leaf)
+				if err != nil {
+					return fmt.Errorf("storage key %x: invalid value: %v", key, err)
+				}
+				value.SetBytes(content)
+			}
+		}
+		if slot.Value == nil || slot.Value.ToInt().Cmp(value) != 0 {
+			return fmt.Errorf("storage key %x: value mismatch: have %v, want %v", key, slot.Value, value)
+		}
+	}
+	return nil
+}
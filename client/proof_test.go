@@ -0,0 +1,109 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klaytn/klaytn/api"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyMultiProof(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sdb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	require.NoError(t, err)
+	var queries []api.ProofQuery
+	for i := 1; i <= 100; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i)))
+		query := api.ProofQuery{Address: addr}
+		if i%10 == 0 {
+			sdb.CreateSmartContractAccount(addr, params.CodeFormatEVM, params.Rules{})
+			sdb.SetCode(addr, []byte{byte(i)})
+			for j := 0; j < 5; j++ {
+				sdb.SetState(addr, common.BigToHash(big.NewInt(int64(j))), common.BigToHash(big.NewInt(int64(i*j))))
+			}
+			// The slot 0 is empty, and the slot 5 is missing
+			query.StorageKeys = []string{"0x0", "0x1", "0x4", "0x5"}
+		}
+		sdb.SetNonce(addr, uint64(i))
+		sdb.AddBalance(addr, big.NewInt(int64(i)))
+		queries = append(queries, query)
+	}
+	queries = append(queries, api.ProofQuery{Address: common.HexToAddress("0xdead"), StorageKeys: []string{"0x1"}})
+	root, err := sdb.Commit(false)
+	require.NoError(t, err)
+	sdb, err = state.New(root, sdb.Database(), nil, nil)
+	require.NoError(t, err)
+	header := &types.Header{Number: big.NewInt(1), Root: root}
+
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), gomock.Any()).Return(sdb, header, nil).AnyTimes()
+	blockchainAPI := api.NewPublicBlockChainAPI(mockBackend)
+
+	result, err := blockchainAPI.GetMultiProof(context.Background(), queries, rpc.NewBlockNumberOrHashWithNumber(1))
+	require.NoError(t, err)
+	require.Len(t, result.Accounts, len(queries))
+	assert.Equal(t, big.NewInt(40), result.Accounts[9].Storage[2].Value.ToInt())
+	require.NoError(t, VerifyMultiProof(header, result))
+
+	// The proofs share the upper nodes of the account trie
+	var proofs int
+	for _, query := range queries[:10] {
+		single, err := blockchainAPI.GetMultiProof(context.Background(), []api.ProofQuery{{Address: query.Address}}, rpc.NewBlockNumberOrHashWithNumber(1))
+		require.NoError(t, err)
+		proofs += len(single.Nodes)
+	}
+	partial, err := blockchainAPI.GetMultiProof(context.Background(), queries[:10], rpc.NewBlockNumberOrHashWithNumber(1))
+	require.NoError(t, err)
+	assert.Less(t, len(partial.Nodes), proofs)
+
+	// Tampered results are rejected
+	tampered := *result
+	tampered.Accounts = append([]api.MultiProofAccount{}, result.Accounts...)
+	tampered.Accounts[3].Balance = (*hexutil.Big)(big.NewInt(1000))
+	assert.Error(t, VerifyMultiProof(header, &tampered))
+
+	tampered.Accounts = append([]api.MultiProofAccount{}, result.Accounts...)
+	tampered.Accounts[9].Storage = append([]api.MultiProofSlot{}, result.Accounts[9].Storage...)
+	tampered.Accounts[9].Storage[3].Value = (*hexutil.Big)(big.NewInt(1))
+	assert.Error(t, VerifyMultiProof(header, &tampered))
+
+	tampered.Accounts = result.Accounts
+	tampered.Nodes = result.Nodes[1:]
+	assert.Error(t, VerifyMultiProof(header, &tampered))
+
+	assert.Error(t, VerifyMultiProof(&types.Header{Number: big.NewInt(1), Root: common.HexToHash("0x1")}, result))
+
+	// Too many keys are refused
+	_, err = blockchainAPI.GetMultiProof(context.Background(), make([]api.ProofQuery, 10001), rpc.NewBlockNumberOrHashWithNumber(1))
+	assert.Error(t, err)
+}
@@ -184,6 +184,15 @@ func AllocateRegistry(storage map[common.Hash]common.Hash) Option {
 	}
 }
 
+func AllocateHistoryStorage() Option {
+	return func(genesis *blockchain.Genesis) {
+		genesis.Alloc[system.HistoryStorageAddr] = blockchain.GenesisAccount{
+			Code:    system.HistoryStorageCode,
+			Balance: big.NewInt(0),
+		}
+	}
+}
+
 func RegistryMock() Option {
 	return func(genesis *blockchain.Genesis) {
 		registryMockCode := system.RegistryMockCode
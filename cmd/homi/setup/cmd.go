@@ -131,6 +131,8 @@ var HomiFlags = []cli.Flag{
 	altsrc.NewInt64Flag(shanghaiCompatibleBlockNumberFlag),
 	altsrc.NewInt64Flag(cancunCompatibleBlockNumberFlag),
 	altsrc.NewInt64Flag(dragonCompatibleBlockNumberFlag),
+	altsrc.NewInt64Flag(pragueCompatibleBlockNumberFlag),
+	altsrc.NewInt64Flag(osakaCompatibleBlockNumberFlag),
 	altsrc.NewInt64Flag(kip103CompatibleBlockNumberFlag),
 	altsrc.NewStringFlag(kip103ContractAddressFlag),
 	altsrc.NewInt64Flag(randaoCompatibleBlockNumberFlag),
@@ -581,6 +583,15 @@ func allocateRegistry(ctx *cli.Context, genesisJson *blockchain.Genesis, owner c
 	allocationFunction(genesisJson)
 }
 
+func allocateHistoryStorage(ctx *cli.Context, genesisJson *blockchain.Genesis) {
+	if pragueCompatibleBlock := ctx.Int64(pragueCompatibleBlockNumberFlag.Name); pragueCompatibleBlock != 0 {
+		return
+	}
+
+	allocationFunction := genesis.AllocateHistoryStorage()
+	allocationFunction(genesisJson)
+}
+
 func useRegistryMock(ctx *cli.Context, genesisJson *blockchain.Genesis) {
 	if useMock := ctx.Bool(registryMockFlag.Name); !useMock {
 		return
@@ -771,6 +782,9 @@ func Gen(ctx *cli.Context) error {
 	useKip113Mock(ctx, genesisJson, kip113LogicAddr)
 	useRegistryMock(ctx, genesisJson)
 
+	// Prague hardfork related system contracts
+	allocateHistoryStorage(ctx, genesisJson)
+
 	genesisJson.Config.IstanbulCompatibleBlock = big.NewInt(ctx.Int64(istanbulCompatibleBlockNumberFlag.Name))
 	genesisJson.Config.LondonCompatibleBlock = big.NewInt(ctx.Int64(londonCompatibleBlockNumberFlag.Name))
 	genesisJson.Config.EthTxTypeCompatibleBlock = big.NewInt(ctx.Int64(ethTxTypeCompatibleBlockNumberFlag.Name))
@@ -779,6 +793,8 @@ func Gen(ctx *cli.Context) error {
 	genesisJson.Config.ShanghaiCompatibleBlock = big.NewInt(ctx.Int64(shanghaiCompatibleBlockNumberFlag.Name))
 	genesisJson.Config.CancunCompatibleBlock = big.NewInt(ctx.Int64(cancunCompatibleBlockNumberFlag.Name))
 	genesisJson.Config.DragonCompatibleBlock = big.NewInt(ctx.Int64(dragonCompatibleBlockNumberFlag.Name))
+	genesisJson.Config.PragueCompatibleBlock = big.NewInt(ctx.Int64(pragueCompatibleBlockNumberFlag.Name))
+	genesisJson.Config.OsakaCompatibleBlock = big.NewInt(ctx.Int64(osakaCompatibleBlockNumberFlag.Name))
 
 	// KIP103 hardfork is optional
 	genesisJson.Config.Kip103CompatibleBlock = big.NewInt(ctx.Int64(kip103CompatibleBlockNumberFlag.Name))
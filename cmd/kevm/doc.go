@@ -0,0 +1,30 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+kevm is the command-line tool to run and benchmark EVM bytecode with the Klaytn rules.
+
+Please try `kevm -h` to see commands and options list.
+
+Source Files
+
+Each file contains following contents
+ - main.go : Defines the common options and initializes the application
+ - runner.go : Implements the run command executing arbitrary bytecode
+ - staterunner.go : Implements the statetest command running the Ethereum GeneralStateTests
+ - transition.go : Implements the transition (t8n) command applying transactions on a prestate
+*/
+package main
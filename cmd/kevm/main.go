@@ -0,0 +1,115 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+
+	app = utils.NewApp(gitCommit, "the Klaytn EVM command line interface")
+)
+
+// Flags shared by the subcommands.
+var (
+	DebugFlag = &cli.BoolFlag{
+		Name:  "debug",
+		Usage: "Output full trace logs after the execution",
+	}
+	MachineFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Output trace logs in machine readable format (json) to stderr",
+	}
+	DisableMemoryFlag = &cli.BoolFlag{
+		Name:  "nomemory",
+		Usage: "Disable memory output of the trace logs",
+		Value: true,
+	}
+	DisableStackFlag = &cli.BoolFlag{
+		Name:  "nostack",
+		Usage: "Disable stack output of the trace logs",
+	}
+	DisableStorageFlag = &cli.BoolFlag{
+		Name:  "nostorage",
+		Usage: "Disable storage output of the trace logs",
+	}
+	ForkFlag = &cli.StringFlag{
+		Name:  "state.fork",
+		Usage: "Name of the hardfork with optional extra EIPs, e.g. Osaka or Prague+3855",
+		Value: "Osaka",
+	}
+	ChainIDFlag = &cli.Int64Flag{
+		Name:  "state.chainid",
+		Usage: "Chain ID to use",
+		Value: 1,
+	}
+)
+
+func init() {
+	app.Flags = []cli.Flag{
+		DebugFlag,
+		MachineFlag,
+		DisableMemoryFlag,
+		DisableStackFlag,
+		DisableStorageFlag,
+	}
+	app.Commands = []*cli.Command{
+		runCommand,
+		stateTestCommand,
+		transitionCommand,
+	}
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// logConfig returns the trace log configuration given by the flags.
+func logConfig(ctx *cli.Context) *vm.LogConfig {
+	return &vm.LogConfig{
+		DisableMemory:  ctx.Bool(DisableMemoryFlag.Name),
+		DisableStack:   ctx.Bool(DisableStackFlag.Name),
+		DisableStorage: ctx.Bool(DisableStorageFlag.Name),
+		Debug:          ctx.Bool(DebugFlag.Name),
+	}
+}
+
+// newTracer returns the tracer selected by the flags writing to w, or nil if
+// the execution is not traced. The struct logger is returned for --debug, so
+// that the logs can be printed after the execution.
+func newTracer(ctx *cli.Context, w io.Writer) vm.Tracer {
+	switch {
+	case ctx.Bool(MachineFlag.Name):
+		return vm.NewJSONLogger(logConfig(ctx), w)
+	case ctx.Bool(DebugFlag.Name):
+		return vm.NewStructLogger(logConfig(ctx))
+	default:
+		return nil
+	}
+}
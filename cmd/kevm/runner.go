@@ -0,0 +1,305 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	goruntime "runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/blockchain/vm/runtime"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	CodeFileFlag = &cli.StringFlag{
+		Name:  "codefile",
+		Usage: "File containing the EVM code in hex. If '-' is specified, the code is read from stdin",
+	}
+	CreateFlag = &cli.BoolFlag{
+		Name:  "create",
+		Usage: "Run the code as initcode of a contract creation",
+	}
+	GasFlag = &cli.Uint64Flag{
+		Name:  "gas",
+		Usage: "Gas limit for the execution",
+		Value: 10000000,
+	}
+	PriceFlag = &cli.StringFlag{
+		Name:  "price",
+		Usage: "Gas price of the execution in peb",
+		Value: "0",
+	}
+	ValueFlag = &cli.StringFlag{
+		Name:  "value",
+		Usage: "Value to transfer in peb",
+		Value: "0",
+	}
+	InputFlag = &cli.StringFlag{
+		Name:  "input",
+		Usage: "Input for the EVM in hex",
+	}
+	InputFileFlag = &cli.StringFlag{
+		Name:  "inputfile",
+		Usage: "File containing the input for the EVM in hex",
+	}
+	SenderFlag = &cli.StringFlag{
+		Name:  "sender",
+		Usage: "Address of the transaction sender",
+	}
+	ReceiverFlag = &cli.StringFlag{
+		Name:  "receiver",
+		Usage: "Address of the receiver, whose code in the prestate is run if no code is given",
+	}
+	PrestateFlag = &cli.StringFlag{
+		Name:  "prestate",
+		Usage: "JSON file with the prestate (genesis) config and alloc",
+	}
+	BenchFlag = &cli.BoolFlag{
+		Name:  "bench",
+		Usage: "Benchmark the execution",
+	}
+	StatDumpFlag = &cli.BoolFlag{
+		Name:  "statdump",
+		Usage: "Display stack and heap memory information",
+	}
+	DumpFlag = &cli.BoolFlag{
+		Name:  "dump",
+		Usage: "Dump the state after the run",
+	}
+)
+
+var runCommand = &cli.Command{
+	Action:    runCmd,
+	Name:      "run",
+	Usage:     "Run arbitrary EVM code",
+	ArgsUsage: "<code>",
+	Description: `The run command runs arbitrary EVM code given in hex as the argument, by --codefile
+or at --receiver in the prestate. The return value is printed to stdout in hex.`,
+	Flags: []cli.Flag{
+		CodeFileFlag,
+		CreateFlag,
+		GasFlag,
+		PriceFlag,
+		ValueFlag,
+		InputFlag,
+		InputFileFlag,
+		SenderFlag,
+		ReceiverFlag,
+		PrestateFlag,
+		ForkFlag,
+		BenchFlag,
+		StatDumpFlag,
+		DumpFlag,
+	},
+}
+
+// execStats holds the statistics of an execution.
+type execStats struct {
+	time           time.Duration // The execution time.
+	allocs         int64         // The number of heap allocations during execution.
+	bytesAllocated int64         // The cumulative number of bytes allocated during execution.
+}
+
+// timedExec runs the execution once, or repeatedly as a benchmark if bench is
+// true, and measures its statistics.
+func timedExec(bench bool, execFunc func() ([]byte, uint64, error)) (output []byte, gasLeft uint64, stats execStats, err error) {
+	if bench {
+		result := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				output, gasLeft, err = execFunc()
+			}
+		})
+		// Get the average execution time from the benchmarking result.
+		// There are other useful stats here that could be reported.
+		stats.time = time.Duration(result.NsPerOp())
+		stats.allocs = result.AllocsPerOp()
+		stats.bytesAllocated = result.AllocedBytesPerOp()
+	} else {
+		var memStatsBefore, memStatsAfter goruntime.MemStats
+		goruntime.ReadMemStats(&memStatsBefore)
+		startTime := time.Now()
+		output, gasLeft, err = execFunc()
+		stats.time = time.Since(startTime)
+		goruntime.ReadMemStats(&memStatsAfter)
+		stats.allocs = int64(memStatsAfter.Mallocs - memStatsBefore.Mallocs)
+		stats.bytesAllocated = int64(memStatsAfter.TotalAlloc - memStatsBefore.TotalAlloc)
+	}
+	return output, gasLeft, stats, err
+}
+
+// readHex reads the hex string in the file, or in stdin if the name is "-".
+func readHex(name string) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if name == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return common.FromHex(strings.TrimSpace(string(b))), nil
+}
+
+// parseBig parses a decimal or 0x-prefixed hexadecimal integer given by the flag.
+func parseBig(ctx *cli.Context, flag *cli.StringFlag) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(ctx.String(flag.Name), 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid --%s: %q", flag.Name, ctx.String(flag.Name))
+	}
+	return v, nil
+}
+
+func runCmd(ctx *cli.Context) error {
+	var (
+		genesis     = new(blockchain.Genesis)
+		chainConfig *params.ChainConfig
+		eips        []int
+		err         error
+	)
+	if name := ctx.String(PrestateFlag.Name); name != "" {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, genesis); err != nil {
+			return fmt.Errorf("invalid prestate %s: %v", name, err)
+		}
+	}
+	if genesis.Config != nil {
+		chainConfig = genesis.Config
+	} else if chainConfig, eips, err = tests.GetChainConfig(ctx.String(ForkFlag.Name)); err != nil {
+		return err
+	}
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), genesis.Alloc)
+
+	sender := common.BytesToAddress([]byte("sender"))
+	if ctx.IsSet(SenderFlag.Name) {
+		sender = common.HexToAddress(ctx.String(SenderFlag.Name))
+	}
+	receiver := common.BytesToAddress([]byte("receiver"))
+	if ctx.IsSet(ReceiverFlag.Name) {
+		receiver = common.HexToAddress(ctx.String(ReceiverFlag.Name))
+	}
+
+	// The code is given as the argument, by a file, or in the prestate.
+	var code []byte
+	switch {
+	case ctx.Args().Present():
+		code = common.FromHex(strings.TrimSpace(ctx.Args().First()))
+	case ctx.IsSet(CodeFileFlag.Name):
+		if code, err = readHex(ctx.String(CodeFileFlag.Name)); err != nil {
+			return fmt.Errorf("could not load code: %v", err)
+		}
+	case statedb.GetCodeSize(receiver) == 0:
+		return errors.New("no code given, specify it as the argument, by --codefile or in the prestate")
+	}
+	var input []byte
+	if ctx.IsSet(InputFileFlag.Name) {
+		if input, err = readHex(ctx.String(InputFileFlag.Name)); err != nil {
+			return fmt.Errorf("could not load input: %v", err)
+		}
+	} else {
+		input = common.FromHex(ctx.String(InputFlag.Name))
+	}
+
+	price, err := parseBig(ctx, PriceFlag)
+	if err != nil {
+		return err
+	}
+	value, err := parseBig(ctx, ValueFlag)
+	if err != nil {
+		return err
+	}
+	tracer := newTracer(ctx, os.Stderr)
+	runtimeConfig := &runtime.Config{
+		ChainConfig: chainConfig,
+		Origin:      sender,
+		State:       statedb,
+		BlockScore:  genesis.BlockScore,
+		BlockNumber: new(big.Int).SetUint64(genesis.Number),
+		Time:        new(big.Int).SetUint64(genesis.Timestamp),
+		GasLimit:    ctx.Uint64(GasFlag.Name),
+		GasPrice:    price,
+		Value:       value,
+		EVMConfig: vm.Config{
+			Debug:     tracer != nil,
+			Tracer:    tracer,
+			ExtraEips: eips,
+		},
+	}
+
+	var execFunc func() ([]byte, uint64, error)
+	if ctx.Bool(CreateFlag.Name) {
+		initcode := append(code, input...)
+		execFunc = func() ([]byte, uint64, error) {
+			output, _, gasLeft, err := runtime.Create(initcode, runtimeConfig)
+			return output, gasLeft, err
+		}
+	} else {
+		if len(code) > 0 {
+			if !statedb.IsProgramAccount(receiver) {
+				statedb.CreateSmartContractAccount(receiver, params.CodeFormatEVM, chainConfig.Rules(runtimeConfig.BlockNumber))
+			}
+			statedb.SetCode(receiver, code)
+		}
+		execFunc = func() ([]byte, uint64, error) {
+			return runtime.Call(receiver, input, runtimeConfig)
+		}
+	}
+	output, gasLeft, stats, err := timedExec(ctx.Bool(BenchFlag.Name), execFunc)
+
+	if ctx.Bool(DumpFlag.Name) {
+		statedb.Commit(true)
+		fmt.Println(string(statedb.Dump()))
+	}
+	if logger, ok := tracer.(*vm.StructLogger); ok {
+		fmt.Fprintln(os.Stderr, "#### TRACE ####")
+		vm.WriteTrace(os.Stderr, logger.StructLogs())
+		fmt.Fprintln(os.Stderr, "#### LOGS ####")
+		vm.WriteLogs(os.Stderr, statedb.Logs())
+	}
+	if ctx.Bool(BenchFlag.Name) || ctx.Bool(StatDumpFlag.Name) {
+		fmt.Fprintf(os.Stderr, `EVM gas used:    %d
+execution time:  %v
+allocations:     %d
+allocated bytes: %d
+`, runtimeConfig.GasLimit-gasLeft, stats.time, stats.allocs, stats.bytesAllocated)
+	}
+	fmt.Printf("0x%x\n", output)
+	if err != nil {
+		fmt.Printf(" error: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var StateTestForkFlag = &cli.StringFlag{
+	Name:  "statetest.fork",
+	Usage: "Only run the subtests of the given fork",
+}
+
+var stateTestCommand = &cli.Command{
+	Action:    stateTestCmd,
+	Name:      "statetest",
+	Usage:     "Execute the given state tests",
+	ArgsUsage: "<file>",
+	Description: `The statetest command runs the GeneralStateTests JSON files of Ethereum against the
+Klaytn rules. If no file is given, the file names are read from stdin line by line.
+The subtests of the forks unknown to Klaytn are skipped. The results are printed
+to stdout in JSON.`,
+	Flags: []cli.Flag{
+		StateTestForkFlag,
+		DumpFlag,
+	},
+}
+
+// StatetestResult contains the execution status after running a state test.
+type StatetestResult struct {
+	Name  string       `json:"name"`
+	Pass  bool         `json:"pass"`
+	Root  *common.Hash `json:"stateRoot,omitempty"`
+	Fork  string       `json:"fork"`
+	Index int          `json:"index"`
+	Error string       `json:"error,omitempty"`
+	State *state.Dump  `json:"state,omitempty"`
+}
+
+func stateTestCmd(ctx *cli.Context) error {
+	if ctx.Args().Present() {
+		return runStateTests(ctx, ctx.Args().First())
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if name := scanner.Text(); name != "" {
+			if err := runStateTests(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// runStateTests runs the state tests in the file and prints the results.
+func runStateTests(ctx *cli.Context, fname string) error {
+	src, err := os.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	var testsByName map[string]tests.StateTest
+	if err := json.Unmarshal(src, &testsByName); err != nil {
+		return fmt.Errorf("invalid state test %s: %v", fname, err)
+	}
+	names := make([]string, 0, len(testsByName))
+	for name := range testsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]StatetestResult, 0, len(testsByName))
+	for _, name := range names {
+		test := testsByName[name]
+		subtests := test.Subtests()
+		sort.Slice(subtests, func(i, j int) bool {
+			if subtests[i].Fork != subtests[j].Fork {
+				return subtests[i].Fork < subtests[j].Fork
+			}
+			return subtests[i].Index < subtests[j].Index
+		})
+		for _, subtest := range subtests {
+			if fork := ctx.String(StateTestForkFlag.Name); fork != "" && fork != subtest.Fork {
+				continue
+			}
+			tracer := newTracer(ctx, os.Stderr)
+			cfg := vm.Config{
+				Debug:                tracer != nil,
+				Tracer:               tracer,
+				ComputationCostLimit: params.OpcodeComputationCostLimitInfinite,
+			}
+			statedb, err := test.Run(subtest, cfg)
+			if errors.As(err, &tests.UnsupportedForkError{}) {
+				continue
+			}
+			result := StatetestResult{Name: name, Pass: err == nil, Fork: subtest.Fork, Index: subtest.Index}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			if statedb != nil {
+				root := statedb.IntermediateRoot(true)
+				result.Root = &root
+				if ctx.Bool(DumpFlag.Name) {
+					dump := statedb.RawDump()
+					result.State = &dump
+				}
+			}
+			if logger, ok := tracer.(*vm.StructLogger); ok {
+				vm.WriteTrace(os.Stderr, logger.StructLogs())
+			}
+			results = append(results, result)
+		}
+	}
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,373 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/math"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	InputAllocFlag = &cli.StringFlag{
+		Name:  "input.alloc",
+		Usage: "JSON file with the prestate alloc",
+		Value: "alloc.json",
+	}
+	InputEnvFlag = &cli.StringFlag{
+		Name:  "input.env",
+		Usage: "JSON file with the block environment",
+		Value: "env.json",
+	}
+	InputTxsFlag = &cli.StringFlag{
+		Name: "input.txs",
+		Usage: "JSON file with the signed transactions of any Klaytn type. A file with the .rlp " +
+			"extension holds the RLP encoded transaction list in hex instead",
+		Value: "txs.json",
+	}
+	OutputBasedirFlag = &cli.StringFlag{
+		Name:  "output.basedir",
+		Usage: "Directory of the output files and the trace logs",
+	}
+	OutputAllocFlag = &cli.StringFlag{
+		Name:  "output.alloc",
+		Usage: "File name of the post-state alloc, or stdout or stderr",
+		Value: "alloc.json",
+	}
+	OutputResultFlag = &cli.StringFlag{
+		Name:  "output.result",
+		Usage: "File name of the execution result, or stdout or stderr",
+		Value: "result.json",
+	}
+)
+
+var transitionCommand = &cli.Command{
+	Action:  transitionCmd,
+	Name:    "transition",
+	Aliases: []string{"t8n"},
+	Usage:   "Execute a state transition",
+	Description: `The transition command applies the transactions on the prestate alloc in the block
+environment, and outputs the post-state alloc and the execution result with the receipts.
+The transactions failing the validation are not applied but reported as rejected.
+The alloc does not hold the Klaytn account keys, which are kept only in the state root.
+With --json, the trace logs of the transactions are written to the files
+trace-<index>-<txhash>.jsonl in the output directory.`,
+	Flags: []cli.Flag{
+		InputAllocFlag,
+		InputEnvFlag,
+		InputTxsFlag,
+		OutputBasedirFlag,
+		OutputAllocFlag,
+		OutputResultFlag,
+		ForkFlag,
+		ChainIDFlag,
+	},
+}
+
+// stEnv is the block environment of a state transition.
+type stEnv struct {
+	Coinbase    common.Address                      `json:"currentCoinbase"`
+	BlockScore  *math.HexOrDecimal256               `json:"currentDifficulty"`
+	GasLimit    math.HexOrDecimal64                 `json:"currentGasLimit"`
+	Number      math.HexOrDecimal64                 `json:"currentNumber"`
+	Timestamp   math.HexOrDecimal64                 `json:"currentTimestamp"`
+	BaseFee     *math.HexOrDecimal256               `json:"currentBaseFee,omitempty"`
+	BlockHashes map[math.HexOrDecimal64]common.Hash `json:"blockHashes,omitempty"`
+}
+
+// rejectedTx is a transaction which is not applied.
+type rejectedTx struct {
+	Index int    `json:"index"`
+	Err   string `json:"error"`
+}
+
+// ExecutionResult contains the execution status after running a state transition.
+type ExecutionResult struct {
+	StateRoot   common.Hash         `json:"stateRoot"`
+	TxRoot      common.Hash         `json:"txRoot"`
+	ReceiptRoot common.Hash         `json:"receiptsRoot"`
+	LogsHash    common.Hash         `json:"logsHash"`
+	Bloom       types.Bloom         `json:"logsBloom"`
+	Receipts    types.Receipts      `json:"receipts"`
+	Rejected    []*rejectedTx       `json:"rejected,omitempty"`
+	GasUsed     math.HexOrDecimal64 `json:"gasUsed"`
+}
+
+func transitionCmd(ctx *cli.Context) error {
+	chainConfig, eips, err := tests.GetChainConfig(ctx.String(ForkFlag.Name))
+	if err != nil {
+		return err
+	}
+	config := *chainConfig
+	config.ChainID = big.NewInt(ctx.Int64(ChainIDFlag.Name))
+	blockchain.InitDeriveSha(&config)
+	if err := fork.SetHardForkBlockNumberConfig(&config); err != nil {
+		return err
+	}
+
+	var (
+		alloc blockchain.GenesisAlloc
+		env   stEnv
+		txs   types.Transactions
+	)
+	if err := readJSON(ctx.String(InputAllocFlag.Name), &alloc); err != nil {
+		return err
+	}
+	if err := readJSON(ctx.String(InputEnvFlag.Name), &env); err != nil {
+		return err
+	}
+	if name := ctx.String(InputTxsFlag.Name); strings.HasSuffix(name, ".rlp") {
+		b, err := readHex(name)
+		if err != nil {
+			return err
+		}
+		if err := rlp.DecodeBytes(b, &txs); err != nil {
+			return fmt.Errorf("invalid transactions %s: %v", name, err)
+		}
+	} else if err := readJSON(name, &txs); err != nil {
+		return err
+	}
+
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), alloc)
+	var getTracer func(i int, txHash common.Hash) (vm.Tracer, io.Closer, error)
+	if ctx.Bool(MachineFlag.Name) {
+		getTracer = func(i int, txHash common.Hash) (vm.Tracer, io.Closer, error) {
+			name := filepath.Join(ctx.String(OutputBasedirFlag.Name), fmt.Sprintf("trace-%d-%v.jsonl", i, txHash.Hex()))
+			f, err := os.Create(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			return vm.NewJSONLogger(logConfig(ctx), f), f, nil
+		}
+	}
+	result, err := env.apply(&config, eips, statedb, txs, getTracer)
+	if err != nil {
+		return err
+	}
+	return writeOutputs(ctx.String(OutputBasedirFlag.Name),
+		output{"alloc", ctx.String(OutputAllocFlag.Name), dumpAlloc(statedb)},
+		output{"result", ctx.String(OutputResultFlag.Name), result},
+	)
+}
+
+// apply applies the transactions on the statedb in the environment as the
+// block processing does, except that the invalid transactions are rejected
+// instead of failing the whole transition.
+func (env *stEnv) apply(config *params.ChainConfig, eips []int, statedb *state.StateDB, txs types.Transactions,
+	getTracer func(i int, txHash common.Hash) (vm.Tracer, io.Closer, error),
+) (*ExecutionResult, error) {
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(uint64(env.Number)),
+		Time:       new(big.Int).SetUint64(uint64(env.Timestamp)),
+		BlockScore: big.NewInt(1),
+		Rewardbase: env.Coinbase,
+	}
+	if env.BlockScore != nil {
+		header.BlockScore = (*big.Int)(env.BlockScore)
+	}
+	if env.BaseFee != nil {
+		header.BaseFee = (*big.Int)(env.BaseFee)
+	}
+	if env.Number > 0 {
+		header.ParentHash = env.BlockHashes[env.Number-1]
+	}
+	if err := blockchain.ProcessParentBlockHash(config, header, statedb); err != nil {
+		return nil, err
+	}
+
+	var (
+		blockNumber = header.Number.Uint64()
+		signer      = types.MakeSigner(config, header.Number)
+		included    types.Transactions
+		receipts    = types.Receipts{}
+		rejected    []*rejectedTx
+		gasUsed     uint64
+	)
+	for i, tx := range txs {
+		if err := tx.Validate(statedb, blockNumber); err != nil {
+			rejected = append(rejected, &rejectedTx{i, err.Error()})
+			continue
+		}
+		msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, blockNumber)
+		if err != nil {
+			rejected = append(rejected, &rejectedTx{i, err.Error()})
+			continue
+		}
+		vmConfig := vm.Config{ExtraEips: eips}
+		if getTracer != nil {
+			tracer, closer, err := getTracer(i, tx.Hash())
+			if err != nil {
+				return nil, err
+			}
+			defer closer.Close()
+			vmConfig.Debug, vmConfig.Tracer = true, tracer
+		}
+		statedb.SetTxContext(tx.Hash(), common.Hash{}, len(included))
+		blockContext := blockchain.NewEVMBlockContext(header, nil, &env.Coinbase)
+		blockContext.GetHash = env.getHash
+		blockContext.GasLimit = uint64(env.GasLimit)
+		evm := vm.NewEVM(blockContext, blockchain.NewEVMTxContext(msg, header), statedb, config, &vmConfig)
+
+		snapshot := statedb.Snapshot()
+		res, err := blockchain.ApplyMessage(evm, msg)
+		if err != nil {
+			statedb.RevertToSnapshot(snapshot)
+			rejected = append(rejected, &rejectedTx{i, err.Error()})
+			continue
+		}
+		statedb.Finalise(true, false)
+		gasUsed += res.UsedGas
+
+		receipt := types.NewReceipt(res.VmExecutionStatus, tx.Hash(), res.UsedGas)
+		msg.FillContractAddress(evm.Origin, receipt)
+		receipt.Logs = statedb.GetLogs(tx.Hash())
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		included = append(included, tx)
+		receipts = append(receipts, receipt)
+	}
+	root, err := statedb.Commit(true)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecutionResult{
+		StateRoot:   root,
+		TxRoot:      types.DeriveSha(included, header.Number),
+		ReceiptRoot: types.DeriveSha(receipts, header.Number),
+		LogsHash:    rlpHash(statedb.Logs()),
+		Bloom:       types.CreateBloom(receipts),
+		Receipts:    receipts,
+		Rejected:    rejected,
+		GasUsed:     math.HexOrDecimal64(gasUsed),
+	}, nil
+}
+
+// getHash returns the block hash given in the environment.
+func (env *stEnv) getHash(n uint64) common.Hash {
+	return env.BlockHashes[math.HexOrDecimal64(n)]
+}
+
+// dumpAlloc returns the accounts of the committed statedb as an alloc.
+func dumpAlloc(statedb *state.StateDB) blockchain.GenesisAlloc {
+	alloc := make(blockchain.GenesisAlloc)
+	for addr, dumped := range statedb.RawDump().Accounts {
+		balance, _ := new(big.Int).SetString(dumped.Balance, 10)
+		account := blockchain.GenesisAccount{
+			Code:    common.FromHex(dumped.Code),
+			Balance: balance,
+			Nonce:   dumped.Nonce,
+		}
+		if len(dumped.Storage) > 0 {
+			account.Storage = make(map[common.Hash]common.Hash, len(dumped.Storage))
+			for key, value := range dumped.Storage {
+				_, content, _, _ := rlp.Split(common.FromHex(value))
+				account.Storage[common.HexToHash(key)] = common.BytesToHash(content)
+			}
+		}
+		alloc[common.HexToAddress(addr)] = account
+	}
+	return alloc
+}
+
+func rlpHash(x interface{}) common.Hash {
+	b, _ := rlp.EncodeToBytes(x)
+	return common.BytesToHash(crypto.Keccak256(b))
+}
+
+// readJSON decodes the JSON file, or stdin if the name is "-".
+func readJSON(name string, v interface{}) error {
+	var (
+		b   []byte
+		err error
+	)
+	if name == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return nil
+}
+
+// output is an output of the state transition to be written to the named file.
+type output struct {
+	kind string
+	name string
+	v    interface{}
+}
+
+// writeOutputs writes the outputs to the named files in the output directory.
+// The outputs to stdout and stderr are combined in a JSON object keyed by the
+// kinds of the outputs.
+func writeOutputs(basedir string, outputs ...output) error {
+	var (
+		stdout = make(map[string]interface{})
+		stderr = make(map[string]interface{})
+	)
+	for _, out := range outputs {
+		switch out.name {
+		case "stdout":
+			stdout[out.kind] = out.v
+		case "stderr":
+			stderr[out.kind] = out.v
+		default:
+			b, err := json.MarshalIndent(out.v, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(basedir, out.name), b, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	for _, combined := range []struct {
+		w io.Writer
+		m map[string]interface{}
+	}{{os.Stdout, stdout}, {os.Stderr, stderr}} {
+		if len(combined.m) == 0 {
+			continue
+		}
+		b, err := json.MarshalIndent(combined.m, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(combined.w, string(b))
+	}
+	return nil
+}
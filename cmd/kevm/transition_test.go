@@ -0,0 +1,137 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/common/math"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransition(t *testing.T) {
+	chainConfig, _, err := tests.GetChainConfig("Osaka")
+	require.NoError(t, err)
+	config := *chainConfig
+	blockchain.InitDeriveSha(&config)
+	require.NoError(t, fork.SetHardForkBlockNumberConfig(&config))
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		receiver = common.HexToAddress("0x1000")
+		gasPrice = big.NewInt(25 * params.Ston)
+		signer   = types.LatestSignerForChainID(config.ChainID)
+		env      = &stEnv{
+			Coinbase: common.HexToAddress("0x2000"),
+			GasLimit: math.HexOrDecimal64(30000000),
+			Number:   math.HexOrDecimal64(1),
+			BaseFee:  (*math.HexOrDecimal256)(gasPrice),
+		}
+	)
+	signTx := func(typ types.TxType, values map[types.TxValueKeyType]interface{}) *types.Transaction {
+		tx, err := types.NewTransactionWithMap(typ, values)
+		require.NoError(t, err)
+		require.NoError(t, tx.SignWithKeys(signer, []*ecdsa.PrivateKey{key}))
+		return tx
+	}
+	// The initcode deploys the code 0x602a.
+	initcode := hexutil.MustDecode("0x61602a5f52600260" + "1ef3")
+	txs := types.Transactions{
+		signTx(types.TxTypeLegacyTransaction, map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:    uint64(0),
+			types.TxValueKeyTo:       receiver,
+			types.TxValueKeyAmount:   big.NewInt(1),
+			types.TxValueKeyGasLimit: uint64(21000),
+			types.TxValueKeyGasPrice: gasPrice,
+			types.TxValueKeyData:     []byte{},
+		}),
+		signTx(types.TxTypeValueTransfer, map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:    uint64(1),
+			types.TxValueKeyFrom:     sender,
+			types.TxValueKeyTo:       receiver,
+			types.TxValueKeyAmount:   big.NewInt(2),
+			types.TxValueKeyGasLimit: uint64(21000),
+			types.TxValueKeyGasPrice: gasPrice,
+		}),
+		// The nonce is too high.
+		signTx(types.TxTypeValueTransfer, map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:    uint64(5),
+			types.TxValueKeyFrom:     sender,
+			types.TxValueKeyTo:       receiver,
+			types.TxValueKeyAmount:   big.NewInt(4),
+			types.TxValueKeyGasLimit: uint64(21000),
+			types.TxValueKeyGasPrice: gasPrice,
+		}),
+		signTx(types.TxTypeSmartContractDeploy, map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:         uint64(2),
+			types.TxValueKeyFrom:          sender,
+			types.TxValueKeyTo:            (*common.Address)(nil),
+			types.TxValueKeyAmount:        new(big.Int),
+			types.TxValueKeyGasLimit:      uint64(100000),
+			types.TxValueKeyGasPrice:      gasPrice,
+			types.TxValueKeyHumanReadable: false,
+			types.TxValueKeyData:          initcode,
+			types.TxValueKeyCodeFormat:    params.CodeFormatEVM,
+		}),
+	}
+
+	// The transactions are given in JSON.
+	b, err := json.Marshal(txs)
+	require.NoError(t, err)
+	var decoded types.Transactions
+	require.NoError(t, json.Unmarshal(b, &decoded))
+
+	alloc := blockchain.GenesisAlloc{sender: {Balance: big.NewInt(params.KLAY)}}
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), alloc)
+	result, err := env.apply(&config, nil, statedb, decoded, nil)
+	require.NoError(t, err)
+
+	require.Len(t, result.Receipts, 3)
+	require.Len(t, result.Rejected, 1)
+	assert.Equal(t, 2, result.Rejected[0].Index)
+	var gasUsed uint64
+	for i, receipt := range result.Receipts {
+		assert.Equal(t, types.ReceiptStatusSuccessful, receipt.Status, "receipt %d", i)
+		gasUsed += receipt.GasUsed
+	}
+	assert.Equal(t, math.HexOrDecimal64(gasUsed), result.GasUsed)
+	assert.Equal(t, types.DeriveSha(types.Transactions{decoded[0], decoded[1], decoded[3]}, big.NewInt(1)), result.TxRoot)
+
+	post := dumpAlloc(statedb)
+	assert.Equal(t, big.NewInt(3), post[receiver].Balance)
+	assert.Equal(t, uint64(3), post[sender].Nonce)
+	contract := crypto.CreateAddress(sender, 2)
+	assert.Equal(t, contract, result.Receipts[2].ContractAddress)
+	assert.Equal(t, hexutil.MustDecode("0x602a"), post[contract].Code)
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice)
+	assert.Equal(t, new(big.Int).Sub(big.NewInt(params.KLAY), new(big.Int).Add(fee, big.NewInt(3))), post[sender].Balance)
+}
@@ -49,6 +49,13 @@ func init() {
 		// See utils/nodecmd/chaincmd.go:
 		nodecmd.InitCommand,
 		nodecmd.DumpGenesisCommand,
+		nodecmd.ImportCommand,
+		nodecmd.ExportCommand,
+		nodecmd.ImportReceiptsCommand,
+		nodecmd.ExportReceiptsCommand,
+		nodecmd.ImportPreimagesCommand,
+		nodecmd.ExportPreimagesCommand,
+		nodecmd.EraCommand,
 
 		// See utils/nodecmd/accountcmd.go
 		nodecmd.AccountCommand,
@@ -71,6 +78,9 @@ func init() {
 
 		// See utils/nodecmd/snapshot.go:
 		nodecmd.SnapshotCommand,
+
+		// See utils/nodecmd/dbcmd.go:
+		nodecmd.DBCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -22,22 +22,41 @@ package utils
 
 import (
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/node/cn"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/reward"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/era"
 )
 
 const (
 	importBatchSize = 2500
+
+	// preimageBatchSize is the number of preimages flushed to the database at once.
+	preimageBatchSize = 1024
 )
 
 var logger = log.NewModuleLogger(log.CMDUtils)
@@ -62,21 +81,46 @@ func StartNode(stack *node.Node) {
 	}()
 }
 
-func ImportChain(chain *blockchain.BlockChain, fn string) error {
-	// Watch for Ctrl-C while the import is running.
-	// If a signal is received, the import will stop at the next batch.
+// MakeChain creates a chain manager from the node configuration without
+// starting any networking service. It is used by the offline commands such as
+// import and export. The caller is responsible for stopping the returned chain
+// and closing the returned database.
+func MakeChain(stack *node.Node, cfg *KlayConfig) (*blockchain.BlockChain, database.DBManager) {
+	ctx := node.NewServiceContext(&cfg.Node, nil, nil, nil)
+	chainDB := cn.CreateDB(ctx, &cfg.CN, "chaindata")
+
+	setup, err := cn.SetupChain(ctx, &cfg.CN, chainDB)
+	if err != nil {
+		log.Fatalf("Can't create BlockChain: %v", err)
+	}
+	chain := setup.BlockChain
+	if setup.ParamSet.Policy() == uint64(istanbul.WeightedRandom) {
+		reward.NewStakingManager(chain, setup.Governance, chainDB)
+	}
+	if err := setup.Engine.CreateSnapshot(chain, chain.CurrentBlock().NumberU64(), chain.CurrentBlock().Hash(), nil); err != nil {
+		logger.Error("CreateSnapshot failed", "err", err)
+	}
+	if istBackend, ok := setup.Engine.(consensus.Istanbul); ok {
+		istBackend.SetChain(chain)
+	}
+
+	return chain, chainDB
+}
+
+// watchInterrupt starts watching for SIGINT and SIGTERM. The returned function
+// reports whether a signal has been received so that a long running command can
+// stop at a safe point. The release function must be called when done.
+func watchInterrupt(what string) (checkInterrupt func() bool, release func()) {
 	interrupt := make(chan os.Signal, 1)
 	stop := make(chan struct{})
 	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
-	defer signal.Stop(interrupt)
-	defer close(interrupt)
 	go func() {
 		if _, ok := <-interrupt; ok {
-			logger.Info("Interrupted during import, stopping at next batch")
+			logger.Info("Interrupted during " + what + ", stopping at next batch")
 		}
 		close(stop)
 	}()
-	checkInterrupt := func() bool {
+	checkInterrupt = func() bool {
 		select {
 		case <-stop:
 			return true
@@ -84,27 +128,83 @@ func ImportChain(chain *blockchain.BlockChain, fn string) error {
 			return false
 		}
 	}
+	release = func() {
+		signal.Stop(interrupt)
+		close(interrupt)
+	}
+	return checkInterrupt, release
+}
+
+// nopWriteCloser wraps an io.Writer with a no-op Close method.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// newCompressedWriter wraps the given writer with a compressor chosen by the
+// extension of the file name: ".gz" selects gzip and ".zst" selects zstd.
+// Otherwise the data is written as-is.
+func newCompressedWriter(w io.Writer, fn string) (io.WriteCloser, error) {
+	switch {
+	case strings.HasSuffix(fn, ".gz"):
+		return gzip.NewWriter(w), nil
+	case strings.HasSuffix(fn, ".zst"):
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+// newCompressedReader is the counterpart of newCompressedWriter.
+func newCompressedReader(r io.Reader, fn string) (io.ReadCloser, error) {
+	switch {
+	case strings.HasSuffix(fn, ".gz"):
+		return gzip.NewReader(r)
+	case strings.HasSuffix(fn, ".zst"):
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// ImportChain imports the blocks stored in the given file. Blocks already
+// present in the chain are skipped, so an interrupted import can be resumed by
+// running it again with the same file.
+func ImportChain(chain *blockchain.BlockChain, fn string) error {
+	// Watch for Ctrl-C while the import is running.
+	// If a signal is received, the import will stop at the next batch.
+	checkInterrupt, release := watchInterrupt("import")
+	defer release()
 
 	logger.Info("Importing blockchain", "file", fn)
 
-	// Open the file handle and potentially unwrap the gzip stream
+	// Open the file handle and potentially unwrap the compressed stream
 	fh, err := os.Open(fn)
 	if err != nil {
 		return err
 	}
 	defer fh.Close()
 
-	var reader io.Reader = fh
-	if strings.HasSuffix(fn, ".gz") {
-		if reader, err = gzip.NewReader(reader); err != nil {
-			return err
-		}
+	reader, err := newCompressedReader(fh, fn)
+	if err != nil {
+		return err
 	}
+	defer reader.Close()
 	stream := rlp.NewStream(reader, 0)
 
 	// Run actual the import.
-	blocks := make(types.Blocks, importBatchSize)
-	n := 0
+	var (
+		blocks   = make(types.Blocks, importBatchSize)
+		n        = 0
+		imported = 0
+		start    = time.Now()
+		reported = time.Now()
+	)
 	for batch := 0; ; batch++ {
 		// Load a batch of RLP blocks.
 		if checkInterrupt() {
@@ -131,6 +231,7 @@ func ImportChain(chain *blockchain.BlockChain, fn string) error {
 		}
 		// Import the batch.
 		if checkInterrupt() {
+			logger.Info("Import stopped, run the same command again to resume", "head", chain.CurrentBlock().NumberU64())
 			return fmt.Errorf("interrupted")
 		}
 		missing := missingBlocks(chain, blocks[:i])
@@ -141,7 +242,14 @@ func ImportChain(chain *blockchain.BlockChain, fn string) error {
 		if _, err := chain.InsertChain(missing); err != nil {
 			return fmt.Errorf("invalid block %d: %v", n, err)
 		}
+		imported += len(missing)
+		if time.Since(reported) >= log.StatsReportLimit {
+			logger.Info("Importing blocks", "imported", imported, "number", missing[len(missing)-1].NumberU64(),
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
 	}
+	logger.Info("Imported blockchain", "file", fn, "blocks", n, "imported", imported, "elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
 }
 
@@ -168,18 +276,19 @@ func missingBlocks(chain *blockchain.BlockChain, blocks []*types.Block) []*types
 func ExportChain(blockchain *blockchain.BlockChain, fn string) error {
 	logger.Info("Exporting blockchain", "file", fn)
 
-	// Open the file handle and potentially wrap with a gzip stream
+	// Open the file handle and potentially wrap with a compressed stream
 	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
 	if err != nil {
 		return err
 	}
 	defer fh.Close()
 
-	var writer io.Writer = fh
-	if strings.HasSuffix(fn, ".gz") {
-		writer = gzip.NewWriter(writer)
-		defer writer.(*gzip.Writer).Close()
+	writer, err := newCompressedWriter(fh, fn)
+	if err != nil {
+		return err
 	}
+	defer writer.Close()
+
 	// Iterate over the blocks and export them
 	if err := blockchain.Export(writer); err != nil {
 		return err
@@ -194,18 +303,19 @@ func ExportChain(blockchain *blockchain.BlockChain, fn string) error {
 func ExportAppendChain(blockchain *blockchain.BlockChain, fn string, first uint64, last uint64) error {
 	logger.Info("Exporting blockchain", "file", fn)
 
-	// Open the file handle and potentially wrap with a gzip stream
+	// Open the file handle and potentially wrap with a compressed stream
 	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
 	if err != nil {
 		return err
 	}
 	defer fh.Close()
 
-	var writer io.Writer = fh
-	if strings.HasSuffix(fn, ".gz") {
-		writer = gzip.NewWriter(writer)
-		defer writer.(*gzip.Writer).Close()
+	writer, err := newCompressedWriter(fh, fn)
+	if err != nil {
+		return err
 	}
+	defer writer.Close()
+
 	// Iterate over the blocks and export them
 	if err := blockchain.ExportN(writer, first, last); err != nil {
 		return err
@@ -214,77 +324,564 @@ func ExportAppendChain(blockchain *blockchain.BlockChain, fn string, first uint6
 	return nil
 }
 
-// TODO-Klaytn Commented out due to mismatched interface.
-//// ImportPreimages imports a batch of exported hash preimages into the database.
-//func ImportPreimages(db *database.LevelDB, fn string) error {
-//	logger.Info("Importing preimages", "file", fn)
-//
-//	// Open the file handle and potentially unwrap the gzip stream
-//	fh, err := os.Open(fn)
-//	if err != nil {
-//		return err
-//	}
-//	defer fh.Close()
-//
-//	var reader io.Reader = fh
-//	if strings.HasSuffix(fn, ".gz") {
-//		if reader, err = gzip.NewReader(reader); err != nil {
-//			return err
-//		}
-//	}
-//	stream := rlp.NewStream(reader, 0)
-//
-//	// Import the preimages in batches to prevent disk trashing
-//	preimages := make(map[common.Hash][]byte)
-//
-//	for {
-//		// Read the next entry and ensure it's not junk
-//		var blob []byte
-//
-//		if err := stream.Decode(&blob); err != nil {
-//			if err == io.EOF {
-//				break
-//			}
-//			return err
-//		}
-//		// Accumulate the preimages and flush when enough ws gathered
-//		preimages[crypto.Keccak256Hash(blob)] = common.CopyBytes(blob)
-//		if len(preimages) > 1024 {
-//			rawdb.WritePreimages(db, 0, preimages)
-//			preimages = make(map[common.Hash][]byte)
-//		}
-//	}
-//	// Flush the last batch preimage data
-//	if len(preimages) > 0 {
-//		rawdb.WritePreimages(db, 0, preimages)
-//	}
-//	return nil
-//}
-//
-//// ExportPreimages exports all known hash preimages into the specified file,
-//// truncating any data already present in the file.
-//func ExportPreimages(db *database.LevelDB, fn string) error {
-//	logger.Info("Exporting preimages", "file", fn)
-//
-//	// Open the file handle and potentially wrap with a gzip stream
-//	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
-//	if err != nil {
-//		return err
-//	}
-//	defer fh.Close()
-//
-//	var writer io.Writer = fh
-//	if strings.HasSuffix(fn, ".gz") {
-//		writer = gzip.NewWriter(writer)
-//		defer writer.(*gzip.Writer).Close()
-//	}
-//	// Iterate over the preimages and export them
-//	it := db.NewIteratorWithPrefix([]byte("secure-key-"))
-//	for it.Next() {
-//		if err := rlp.Encode(writer, it.Value()); err != nil {
-//			return err
-//		}
-//	}
-//	logger.Info("Exported preimages", "file", fn)
-//	return nil
-//}
+// SegmentFileName returns the name of the file holding the blocks in
+// [first, last] when an export is split into several files. The block range is
+// inserted in front of the extensions of fn, e.g. "chain.rlp.gz" becomes
+// "chain-0000000001-0000010000.rlp.gz".
+func SegmentFileName(fn string, first, last uint64) string {
+	dir, base := filepath.Split(fn)
+	name, ext := base, ""
+	if idx := strings.Index(base, "."); idx > 0 {
+		name, ext = base[:idx], base[idx:]
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%010d-%010d%s", name, first, last, ext))
+}
+
+// ExportChainSegments exports the blocks in [first, last] into files holding at
+// most segmentSize blocks each, named by SegmentFileName. A segment is written
+// to a temporary file and renamed once complete, so an interrupted export can
+// be resumed by running it again: segments already present are skipped.
+func ExportChainSegments(bc *blockchain.BlockChain, fn string, first, last, segmentSize uint64) error {
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	if segmentSize == 0 {
+		return errors.New("segment size must be positive")
+	}
+	checkInterrupt, release := watchInterrupt("export")
+	defer release()
+
+	start := time.Now()
+	for from := first; from <= last; from += segmentSize {
+		to := from + segmentSize - 1
+		if to > last || to < from {
+			to = last
+		}
+		segment := SegmentFileName(fn, from, to)
+		if _, err := os.Stat(segment); err == nil {
+			logger.Info("Skipping exported segment", "file", segment)
+			continue
+		}
+		if checkInterrupt() {
+			logger.Info("Export stopped, run the same command again to resume", "next", from)
+			return fmt.Errorf("interrupted")
+		}
+		if err := exportSegment(bc, segment, from, to); err != nil {
+			return err
+		}
+		logger.Info("Exported segment", "file", segment, "progress", fmt.Sprintf("%d/%d", to-first+1, last-first+1),
+			"elapsed", common.PrettyDuration(time.Since(start)))
+		if to == last {
+			break
+		}
+	}
+	return nil
+}
+
+// exportSegment writes the blocks in [first, last] into a temporary file and
+// moves it to fn when all blocks have been written.
+func exportSegment(bc *blockchain.BlockChain, fn string, first, last uint64) error {
+	tmp := fn + ".tmp"
+	fh, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	writer, err := newCompressedWriter(fh, fn)
+	if err != nil {
+		fh.Close()
+		return err
+	}
+	if err := bc.ExportN(writer, first, last); err != nil {
+		writer.Close()
+		fh.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		fh.Close()
+		return err
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
+
+// exportedReceipts is the format in which the receipts of a block are stored
+// by ExportReceipts.
+type exportedReceipts struct {
+	Number   uint64
+	Hash     common.Hash
+	Receipts []*types.ReceiptForStorage
+}
+
+// ExportReceipts exports the receipts of the canonical blocks in [first, last]
+// into the specified file, truncating any data already present in the file.
+func ExportReceipts(db database.DBManager, fn string, first, last uint64) error {
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	logger.Info("Exporting receipts", "file", fn, "first", first, "last", last)
+
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	writer, err := newCompressedWriter(fh, fn)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	start, reported := time.Now(), time.Now()
+	for nr := first; nr <= last; nr++ {
+		hash := db.ReadCanonicalHash(nr)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("export failed on #%d: canonical hash not found", nr)
+		}
+		receipts := db.ReadReceipts(hash, nr)
+		entry := exportedReceipts{Number: nr, Hash: hash, Receipts: make([]*types.ReceiptForStorage, len(receipts))}
+		for i, receipt := range receipts {
+			entry.Receipts[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		if err := rlp.Encode(writer, &entry); err != nil {
+			return err
+		}
+		if time.Since(reported) >= log.StatsReportLimit {
+			logger.Info("Exporting receipts", "exported", nr-first, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		if nr == last {
+			break
+		}
+	}
+	logger.Info("Exported receipts", "file", fn)
+	return nil
+}
+
+// ImportReceipts imports the receipts stored in the given file. The header of
+// each block must already be present and its receipts root must match the
+// imported receipts.
+func ImportReceipts(db database.DBManager, fn string) error {
+	checkInterrupt, release := watchInterrupt("receipts import")
+	defer release()
+
+	logger.Info("Importing receipts", "file", fn)
+
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	reader, err := newCompressedReader(fh, fn)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	stream := rlp.NewStream(reader, 0)
+
+	start, reported := time.Now(), time.Now()
+	for n := 0; ; n++ {
+		if checkInterrupt() {
+			return fmt.Errorf("interrupted")
+		}
+		var entry exportedReceipts
+		if err := stream.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("at entry %d: %v", n, err)
+		}
+		header := db.ReadHeader(entry.Hash, entry.Number)
+		if header == nil {
+			return fmt.Errorf("header #%d [%x…] not found", entry.Number, entry.Hash.Bytes()[:4])
+		}
+		receipts := make(types.Receipts, len(entry.Receipts))
+		for i, receipt := range entry.Receipts {
+			receipts[i] = (*types.Receipt)(receipt)
+		}
+		if root := types.DeriveSha(receipts, header.Number); root != header.ReceiptHash {
+			return fmt.Errorf("receipts root mismatch on #%d: have %x, want %x", entry.Number, root, header.ReceiptHash)
+		}
+		db.WriteReceipts(entry.Hash, entry.Number, receipts)
+
+		if time.Since(reported) >= log.StatsReportLimit {
+			logger.Info("Importing receipts", "imported", n, "number", entry.Number, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	logger.Info("Imported receipts", "file", fn, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// ImportPreimages imports a batch of exported hash preimages into the database.
+func ImportPreimages(db database.DBManager, fn string) error {
+	logger.Info("Importing preimages", "file", fn)
+
+	// Open the file handle and potentially unwrap the compressed stream
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	reader, err := newCompressedReader(fh, fn)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	stream := rlp.NewStream(reader, 0)
+
+	// Import the preimages in batches to prevent disk thrashing
+	preimages := make(map[common.Hash][]byte)
+
+	for {
+		// Read the next entry and ensure it's not junk
+		var blob []byte
+
+		if err := stream.Decode(&blob); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		// Accumulate the preimages and flush when enough ws gathered
+		preimages[crypto.Keccak256Hash(blob)] = common.CopyBytes(blob)
+		if len(preimages) > preimageBatchSize {
+			db.WritePreimages(0, preimages)
+			preimages = make(map[common.Hash][]byte)
+		}
+	}
+	// Flush the last batch preimage data
+	if len(preimages) > 0 {
+		db.WritePreimages(0, preimages)
+	}
+	logger.Info("Imported preimages", "file", fn)
+	return nil
+}
+
+// ExportPreimages exports all known hash preimages into the specified file,
+// truncating any data already present in the file.
+func ExportPreimages(db database.DBManager, fn string) error {
+	logger.Info("Exporting preimages", "file", fn)
+
+	// Open the file handle and potentially wrap with a compressed stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	writer, err := newCompressedWriter(fh, fn)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	// Iterate over the preimages and export them
+	it := db.NewPreimageIterator()
+	defer it.Release()
+	for it.Next() {
+		if err := rlp.Encode(writer, it.Value()); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	logger.Info("Exported preimages", "file", fn)
+	return nil
+}
+
+// eraChecksumsFile is the name of the file listing the sha256 checksums of the
+// era files stored in a directory.
+const eraChecksumsFile = "checksums.txt"
+
+// ExportHistory writes the blocks and receipts in [first, last] into era files
+// of the given network stored in dir. Every era file holds one epoch, so first
+// is rounded down to the beginning of its epoch. Epochs already exported are
+// skipped, so an interrupted export can be resumed by running it again. The
+// checksums of all era files are written into checksums.txt.
+func ExportHistory(db database.DBManager, dir, network string, first, last uint64) error {
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	checkInterrupt, release := watchInterrupt("era export")
+	defer release()
+
+	existing, err := eraFilesByEpoch(dir, network)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	for epoch := first / era.EpochSize; epoch*era.EpochSize <= last; epoch++ {
+		from, to := epoch*era.EpochSize, (epoch+1)*era.EpochSize-1
+		if to > last {
+			to = last
+		}
+		if fn, ok := existing[epoch]; ok {
+			if e, err := era.Open(fn); err == nil {
+				complete := e.Start() == from && e.Count() == to-from+1
+				e.Close()
+				if complete {
+					logger.Info("Skipping exported epoch", "epoch", epoch, "file", fn)
+					continue
+				}
+			}
+			if err := os.Remove(fn); err != nil {
+				return err
+			}
+		}
+		if checkInterrupt() {
+			logger.Info("Export stopped, run the same command again to resume", "epoch", epoch)
+			return fmt.Errorf("interrupted")
+		}
+		fn, err := exportEpoch(db, dir, network, epoch, from, to)
+		if err != nil {
+			return fmt.Errorf("epoch %d: %v", epoch, err)
+		}
+		logger.Info("Exported epoch", "epoch", epoch, "file", fn, "blocks", to-from+1, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return writeEraChecksums(dir, network)
+}
+
+// exportEpoch writes the blocks in [from, to] into an era file and returns its name.
+func exportEpoch(db database.DBManager, dir, network string, epoch, from, to uint64) (string, error) {
+	tmp := filepath.Join(dir, fmt.Sprintf("%s-%05d.tmp", network, epoch))
+	fh, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+
+	builder := era.NewBuilder(fh)
+	for nr := from; nr <= to; nr++ {
+		block := db.ReadBlockByNumber(nr)
+		if block == nil {
+			fh.Close()
+			return "", fmt.Errorf("block #%d not found", nr)
+		}
+		if err := builder.Add(block, db.ReadReceipts(block.Hash(), nr)); err != nil {
+			fh.Close()
+			return "", err
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		fh.Close()
+		return "", err
+	}
+	if err := fh.Close(); err != nil {
+		return "", err
+	}
+	fn := filepath.Join(dir, era.Filename(network, epoch, root))
+	return fn, os.Rename(tmp, fn)
+}
+
+// ImportHistory imports the era files of the given network stored in dir. The
+// files are checked against checksums.txt if present and against their own
+// accumulator. The headers are verified through the consensus engine and the
+// bodies and receipts are written without executing the blocks.
+func ImportHistory(chain *blockchain.BlockChain, dir, network string) error {
+	files, err := era.ReadDir(dir, network)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no era file of network %s in %s", network, dir)
+	}
+	if err := verifyEraChecksums(dir, files); err != nil {
+		return err
+	}
+	checkInterrupt, release := watchInterrupt("era import")
+	defer release()
+
+	start := time.Now()
+	for _, fn := range files {
+		if checkInterrupt() {
+			return fmt.Errorf("interrupted")
+		}
+		if err := importEra(chain, fn); err != nil {
+			return fmt.Errorf("%s: %v", fn, err)
+		}
+		logger.Info("Imported era file", "file", fn, "head", chain.CurrentHeader().Number, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+func importEra(chain *blockchain.BlockChain, fn string) error {
+	e, err := era.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+
+	if err := e.Verify(nil); err != nil {
+		return err
+	}
+	var (
+		blocks   = make(types.Blocks, 0, importBatchSize)
+		receipts = make([]types.Receipts, 0, importBatchSize)
+	)
+	flush := func() error {
+		if len(blocks) == 0 {
+			return nil
+		}
+		headers := make([]*types.Header, len(blocks))
+		for i, block := range blocks {
+			headers[i] = block.Header()
+		}
+		if _, err := chain.InsertHeaderChain(headers, 1); err != nil {
+			return err
+		}
+		if _, err := chain.InsertReceiptChain(blocks, receipts); err != nil {
+			return err
+		}
+		blocks, receipts = blocks[:0], receipts[:0]
+		return nil
+	}
+	for nr := e.Start(); nr < e.Start()+e.Count(); nr++ {
+		block, rs, err := e.GetBlockAndReceiptsByNumber(nr)
+		if err != nil {
+			return err
+		}
+		// The genesis block and blocks already present are not imported again
+		if nr == 0 || chain.HasBlock(block.Hash(), nr) {
+			continue
+		}
+		blocks, receipts = append(blocks, block), append(receipts, rs)
+		if len(blocks) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// VerifyHistory checks the era files of the given network stored in dir against
+// checksums.txt if present and checks every block hash against the canonical chain.
+func VerifyHistory(db database.DBManager, dir, network string) error {
+	files, err := era.ReadDir(dir, network)
+	if err != nil {
+		return err
+	}
+	if err := verifyEraChecksums(dir, files); err != nil {
+		return err
+	}
+	for _, fn := range files {
+		e, err := era.Open(fn)
+		if err != nil {
+			return fmt.Errorf("%s: %v", fn, err)
+		}
+		err = e.Verify(db)
+		e.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", fn, err)
+		}
+		logger.Info("Verified era file", "file", fn, "start", e.Start(), "count", e.Count())
+	}
+	logger.Info("Verified era files", "count", len(files))
+	return nil
+}
+
+// eraFilesByEpoch returns the era files of the given network stored in dir by their epoch.
+func eraFilesByEpoch(dir, network string) (map[uint64]string, error) {
+	files, err := era.ReadDir(dir, network)
+	if err != nil {
+		return nil, err
+	}
+	epochs := make(map[uint64]string, len(files))
+	for _, fn := range files {
+		var epoch uint64
+		name := strings.TrimPrefix(filepath.Base(fn), network+"-")
+		if _, err := fmt.Sscanf(name[:strings.Index(name, "-")], "%d", &epoch); err != nil {
+			return nil, err
+		}
+		epochs[epoch] = fn
+	}
+	return epochs, nil
+}
+
+// sha256File returns the hex encoded sha256 checksum of the given file.
+func sha256File(fn string) (string, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, fh); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeEraChecksums writes the checksums of the era files into checksums.txt.
+func writeEraChecksums(dir, network string) error {
+	files, err := era.ReadDir(dir, network)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	for _, fn := range files {
+		sum, err := sha256File(fn)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, sum+"  "+filepath.Base(fn))
+	}
+	return os.WriteFile(filepath.Join(dir, eraChecksumsFile), []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+}
+
+// verifyEraChecksums checks the given era files against checksums.txt. Nothing
+// is checked if checksums.txt does not exist.
+func verifyEraChecksums(dir string, files []string) error {
+	blob, err := os.ReadFile(filepath.Join(dir, eraChecksumsFile))
+	if os.IsNotExist(err) {
+		logger.Warn("No checksum file, skipping checksum verification", "dir", dir)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	checksums := make(map[string]string)
+	for _, line := range strings.Split(string(blob), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			checksums[fields[1]] = fields[0]
+		}
+	}
+	for _, fn := range files {
+		want, ok := checksums[filepath.Base(fn)]
+		if !ok {
+			return fmt.Errorf("no checksum for %s", fn)
+		}
+		have, err := sha256File(fn)
+		if err != nil {
+			return err
+		}
+		if have != want {
+			return fmt.Errorf("checksum mismatch for %s: have %s, want %s", fn, have, want)
+		}
+	}
+	return nil
+}
+
+// EraNetworkName returns the network name used in the era file names of the
+// chain with the given chain id.
+func EraNetworkName(chainID *big.Int) string {
+	switch {
+	case chainID == nil:
+		return "unknown"
+	case chainID.Uint64() == params.CypressNetworkId:
+		return "cypress"
+	case chainID.Uint64() == params.BaobabNetworkId:
+		return "baobab"
+	default:
+		return fmt.Sprintf("chain%d", chainID.Uint64())
+	}
+}
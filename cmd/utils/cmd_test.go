@@ -0,0 +1,164 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/era"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentFileName(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "chain-0000000001-0000010000.rlp.gz"), SegmentFileName(filepath.Join("out", "chain.rlp.gz"), 1, 10000))
+	assert.Equal(t, "chain-0000000000-0000000099", SegmentFileName("chain", 0, 99))
+}
+
+func TestCompressedStream(t *testing.T) {
+	data := bytes.Repeat([]byte("klaytn"), 1000)
+	for _, fn := range []string{"plain.rlp", "gzip.rlp.gz", "zstd.rlp.zst"} {
+		buf := new(bytes.Buffer)
+		w, err := newCompressedWriter(buf, fn)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		r, err := newCompressedReader(buf, fn)
+		require.NoError(t, err)
+		have, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		assert.Equal(t, data, have, fn)
+	}
+}
+
+func TestExportImportPreimages(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-preimages")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := database.NewMemoryDBManager()
+	preimages := make(map[common.Hash][]byte)
+	for i := 0; i < 3*preimageBatchSize; i++ {
+		blob := big.NewInt(int64(i)).Bytes()
+		preimages[crypto.Keccak256Hash(blob)] = blob
+	}
+	src.WritePreimages(0, preimages)
+
+	fn := filepath.Join(dir, "preimages.rlp.zst")
+	require.NoError(t, ExportPreimages(src, fn))
+
+	dst := database.NewMemoryDBManager()
+	require.NoError(t, ImportPreimages(dst, fn))
+	for hash, blob := range preimages {
+		assert.Equal(t, blob, dst.ReadPreimage(hash))
+	}
+}
+
+func TestExportImportReceipts(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-receipts")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	blockchain.InitDeriveSha(params.TestChainConfig)
+
+	src, dst := database.NewMemoryDBManager(), database.NewMemoryDBManager()
+	for i := uint64(0); i < 3; i++ {
+		receipts := types.Receipts{
+			&types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 21000 * (i + 1), Logs: []*types.Log{}},
+		}
+		receipts[0].Bloom = types.CreateBloom(receipts)
+		header := &types.Header{Number: new(big.Int).SetUint64(i), ReceiptHash: types.DeriveSha(receipts, new(big.Int).SetUint64(i))}
+
+		for _, db := range []database.DBManager{src, dst} {
+			db.WriteHeader(header)
+			db.WriteCanonicalHash(header.Hash(), i)
+		}
+		src.WriteReceipts(header.Hash(), i, receipts)
+	}
+
+	fn := filepath.Join(dir, "receipts.rlp.gz")
+	require.NoError(t, ExportReceipts(src, fn, 0, 2))
+	require.NoError(t, ImportReceipts(dst, fn))
+	for i := uint64(0); i < 3; i++ {
+		hash := src.ReadCanonicalHash(i)
+		have, want := dst.ReadReceipts(hash, i), src.ReadReceipts(hash, i)
+		require.Len(t, have, len(want))
+		assert.Equal(t, want[0].GasUsed, have[0].GasUsed)
+	}
+
+	// Receipts not matching the receipts root of the header must be rejected.
+	hash, wrong := src.ReadCanonicalHash(1), src.ReadReceipts(src.ReadCanonicalHash(2), 2)
+	entry := exportedReceipts{Number: 1, Hash: hash, Receipts: []*types.ReceiptForStorage{(*types.ReceiptForStorage)(wrong[0])}}
+	blob, err := rlp.EncodeToBytes(&entry)
+	require.NoError(t, err)
+	bad := filepath.Join(dir, "bad.rlp")
+	require.NoError(t, os.WriteFile(bad, blob, 0o600))
+	assert.Error(t, ImportReceipts(dst, bad))
+}
+
+func TestExportVerifyHistory(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-era")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	blockchain.InitDeriveSha(params.TestChainConfig)
+
+	db := database.NewMemoryDBManager()
+	var parent common.Hash
+	for i := uint64(0); i < 10; i++ {
+		header := &types.Header{ParentHash: parent, Number: new(big.Int).SetUint64(i), Time: new(big.Int).SetUint64(i)}
+		block := types.NewBlock(header, nil, nil)
+		db.WriteBlock(block)
+		db.WriteReceipts(block.Hash(), i, nil)
+		db.WriteCanonicalHash(block.Hash(), i)
+		parent = block.Hash()
+	}
+
+	require.NoError(t, ExportHistory(db, dir, "test", 0, 9))
+	require.NoError(t, VerifyHistory(db, dir, "test"))
+
+	// An export of the same range is a no-op.
+	require.NoError(t, ExportHistory(db, dir, "test", 0, 9))
+	files, err := era.ReadDir(dir, "test")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// A corrupted file must be detected by the checksums.
+	require.NoError(t, os.WriteFile(files[0], []byte("corrupted"), 0o644))
+	assert.Error(t, VerifyHistory(db, dir, "test"))
+}
+
+func TestEraNetworkName(t *testing.T) {
+	assert.Equal(t, "cypress", EraNetworkName(new(big.Int).SetUint64(params.CypressNetworkId)))
+	assert.Equal(t, "baobab", EraNetworkName(new(big.Int).SetUint64(params.BaobabNetworkId)))
+	assert.Equal(t, "chain1000", EraNetworkName(big.NewInt(1000)))
+}
@@ -600,6 +600,9 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 	cfg.TriesInMemory = ctx.Uint64(TriesInMemoryFlag.Name)
 	cfg.LivePruning = ctx.Bool(LivePruningFlag.Name)
 	cfg.LivePruningRetention = ctx.Uint64(LivePruningRetentionFlag.Name)
+	cfg.TrieNodeScheme = ctx.String(TrieNodeSchemeFlag.Name)
+	cfg.PathHistory = ctx.Uint64(PathHistoryFlag.Name)
+	cfg.StateHistory = ctx.Bool(StateHistoryFlag.Name)
 
 	if ctx.IsSet(CacheScaleFlag.Name) {
 		common.CacheScale = ctx.Int(CacheScaleFlag.Name)
@@ -634,6 +637,8 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 			Name)).ToValid(),
 		NumFetcherPrefetchWorker:  ctx.Int(NumFetcherPrefetchWorkerFlag.Name),
 		UseSnapshotForPrefetch:    ctx.Bool(UseSnapshotForPrefetchFlag.Name),
+		PoolPrefetchTxs:           ctx.Int(PoolPrefetchTxsFlag.Name),
+		PoolPrefetchCPUPercent:    ctx.Int(PoolPrefetchCPUPercentFlag.Name),
 		LocalCacheSizeMiB:         ctx.Int(TrieNodeCacheLimitFlag.Name),
 		FastCacheFileDir:          ctx.String(DataDirFlag.Name) + "/fastcache",
 		FastCacheSavePeriod:       ctx.Duration(TrieNodeCacheSavePeriodFlag.Name),
@@ -641,6 +646,9 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 		RedisClusterEnable:        ctx.Bool(TrieNodeCacheRedisClusterFlag.Name),
 		RedisPublishBlockEnable:   ctx.Bool(TrieNodeCacheRedisPublishBlockFlag.Name),
 		RedisSubscribeBlockEnable: ctx.Bool(TrieNodeCacheRedisSubscribeBlockFlag.Name),
+		WarmCacheFile:             ctx.String(DataDirFlag.Name) + "/triecache.warm",
+		WarmCacheMaxEntries:       ctx.Int(TrieNodeCacheWarmEntriesFlag.Name),
+		WarmCacheMaxSizeMiB:       ctx.Int(TrieNodeCacheWarmSizeFlag.Name),
 	}
 
 	if ctx.IsSet(VMEnableDebugFlag.Name) {
@@ -654,6 +662,8 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 	}
 	cfg.EnableInternalTxTracing = ctx.Bool(VMTraceInternalTxFlag.Name)
 	cfg.EnableOpDebug = ctx.Bool(VMOpDebugFlag.Name)
+	cfg.ParallelExecution = ctx.Bool(VMParallelExecFlag.Name)
+	cfg.ParallelExecutionCheck = ctx.Bool(VMParallelExecCheckFlag.Name)
 
 	cfg.AutoRestartFlag = ctx.Bool(AutoRestartFlag.Name)
 	cfg.RestartTimeOutFlag = ctx.Duration(RestartTimeOutFlag.Name)
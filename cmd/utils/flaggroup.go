@@ -199,6 +199,9 @@ var FlagGroups = []FlagGroup{
 			TriesInMemoryFlag,
 			LivePruningFlag,
 			LivePruningRetentionFlag,
+			TrieNodeSchemeFlag,
+			PathHistoryFlag,
+			StateHistoryFlag,
 		},
 	},
 	{
@@ -211,8 +214,12 @@ var FlagGroups = []FlagGroup{
 			TrieNodeCacheTypeFlag,
 			NumFetcherPrefetchWorkerFlag,
 			UseSnapshotForPrefetchFlag,
+			PoolPrefetchTxsFlag,
+			PoolPrefetchCPUPercentFlag,
 			TrieNodeCacheLimitFlag,
 			TrieNodeCacheSavePeriodFlag,
+			TrieNodeCacheWarmEntriesFlag,
+			TrieNodeCacheWarmSizeFlag,
 			TrieNodeCacheRedisEndpointsFlag,
 			TrieNodeCacheRedisClusterFlag,
 			TrieNodeCacheRedisPublishBlockFlag,
@@ -263,6 +270,8 @@ var FlagGroups = []FlagGroup{
 			VMLogTargetFlag,
 			VMTraceInternalTxFlag,
 			VMOpDebugFlag,
+			VMParallelExecFlag,
+			VMParallelExecCheckFlag,
 		},
 	},
 	{
@@ -352,6 +361,11 @@ var FlagGroups = []FlagGroup{
 			SnapshotFlag,
 			SnapshotCacheSizeFlag,
 			SnapshotAsyncGen,
+			ExportSegmentSizeFlag,
+			DBVerifyRepairFlag,
+			DBReshardShardsFlag,
+			DBReshardTypeFlag,
+			SnapshotExportChunkSizeFlag,
 			DocRootFlag,
 		},
 	},
@@ -501,6 +501,42 @@ var (
 		EnvVars:  []string{"KLAYTN_SNAPSHOT_BACKGROUND_GENERATION"},
 		Category: "MISC",
 	}
+	ExportSegmentSizeFlag = &cli.Uint64Flag{
+		Name:     "export.segment-size",
+		Usage:    "Number of blocks stored in each exported file (0 = export into a single file)",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_EXPORT_SEGMENT_SIZE"},
+		Category: "MISC",
+	}
+	DBVerifyRepairFlag = &cli.BoolFlag{
+		Name:     "db.repair",
+		Usage:    "Rewind the chain to the last consistent block if db verify finds a problem",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_REPAIR"},
+		Category: "MISC",
+	}
+	DBReshardShardsFlag = &cli.UintFlag{
+		Name:     "db.reshard.shards",
+		Usage:    "Number of shards of the state trie partitions after db reshard (0 = unchanged)",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_RESHARD_SHARDS"},
+		Category: "MISC",
+	}
+	DBReshardTypeFlag = &cli.StringFlag{
+		Name:     "db.reshard.dbtype",
+		Usage:    `Database type after db reshard ("LevelDB", "RocksDB", "BadgerDB", empty = unchanged)`,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_RESHARD_DBTYPE"},
+		Category: "MISC",
+	}
+	SnapshotExportChunkSizeFlag = &cli.Uint64Flag{
+		Name:     "snapshot.export.chunk-size",
+		Usage:    "Size limit of each chunk file written by snapshot export (in MiB)",
+		Value:    256,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_SNAPSHOT_EXPORT_CHUNK_SIZE"},
+		Category: "MISC",
+	}
 	TrieMemoryCacheSizeFlag = &cli.IntFlag{
 		Name:     "state.cache-size",
 		Usage:    "Size of in-memory cache of the global state (in MiB) to flush matured singleton trie nodes to disk",
@@ -540,6 +576,28 @@ var (
 		EnvVars:  []string{"KLAYTN_STATE_LIVE_PRUNING_RETENTION"},
 		Category: "STATE",
 	}
+	TrieNodeSchemeFlag = &cli.StringFlag{
+		Name:     "state.scheme",
+		Usage:    "Trie node scheme of a new database: hash, path (an existing database keeps its scheme)",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATE_SCHEME"},
+		Category: "STATE",
+	}
+	PathHistoryFlag = &cli.Uint64Flag{
+		Name:     "state.path-history",
+		Usage:    "Number of recent states kept with the path trie node scheme",
+		Value:    statedb.DefaultPathHistory,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATE_PATH_HISTORY"},
+		Category: "STATE",
+	}
+	StateHistoryFlag = &cli.BoolFlag{
+		Name:     "state.history",
+		Usage:    "Keep a flat index of the state changes of every block to serve historical state without archive tries (enable before the genesis block is written)",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATE_HISTORY"},
+		Category: "STATE",
+	}
 	CacheTypeFlag = &cli.IntFlag{
 		Name:     "cache.type",
 		Usage:    "Cache Type: 0=LRUCache, 1=LRUShardCache, 2=FIFOCache",
@@ -593,6 +651,22 @@ var (
 		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_USE_SNAPSHOT_FOR_PREFETCH"},
 		Category: "CACHE",
 	}
+	PoolPrefetchTxsFlag = &cli.IntFlag{
+		Name:     "statedb.cache.pool-prefetch-txs",
+		Usage:    "Number of pending transactions executed on the head state to prefetch the next block (0 = disabled)",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_POOL_PREFETCH_TXS"},
+		Category: "CACHE",
+	}
+	PoolPrefetchCPUPercentFlag = &cli.IntFlag{
+		Name:     "statedb.cache.pool-prefetch-cpu",
+		Usage:    "Maximum percentage of a CPU spent prefetching the pending transactions (1-100)",
+		Value:    25,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_POOL_PREFETCH_CPU"},
+		Category: "CACHE",
+	}
 	TrieNodeCacheRedisEndpointsFlag = &cli.StringSliceFlag{
 		Name:     "statedb.cache.redis.endpoints",
 		Usage:    "Set endpoints of redis trie node cache. More than one endpoints can be set",
@@ -637,6 +711,22 @@ var (
 		EnvVars:  []string{"KLAYTN_STATE_TRIE_CACHE_SAVE_PERIOD"},
 		Category: "CACHE",
 	}
+	TrieNodeCacheWarmEntriesFlag = &cli.IntFlag{
+		Name:     "state.trie-cache-warm-entries",
+		Usage:    "Number of recently accessed trie nodes saved on shutdown and restored in the trie cache on startup, 0 means disabled",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATE_TRIE_CACHE_WARM_ENTRIES"},
+		Category: "CACHE",
+	}
+	TrieNodeCacheWarmSizeFlag = &cli.IntFlag{
+		Name:     "state.trie-cache-warm-size",
+		Usage:    "Size limit (MiB) of the trie nodes saved on shutdown and restored in the trie cache on startup",
+		Value:    512,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATE_TRIE_CACHE_WARM_SIZE"},
+		Category: "CACHE",
+	}
 
 	SenderTxHashIndexingFlag = &cli.BoolFlag{
 		Name:     "sendertxhashindexing",
@@ -755,6 +845,20 @@ var (
 		EnvVars:  []string{"KLAYTN_VM_OPDEBUG"},
 		Category: "VIRTUAL MACHINE",
 	}
+	VMParallelExecFlag = &cli.BoolFlag{
+		Name:     "vm.parallel-exec",
+		Usage:    "Execute the transactions of a block in parallel with optimistic conflict detection (disabled while tracing)",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_VM_PARALLEL_EXEC"},
+		Category: "VIRTUAL MACHINE",
+	}
+	VMParallelExecCheckFlag = &cli.BoolFlag{
+		Name:     "vm.parallel-exec.check",
+		Usage:    "Execute the transactions of a block both in parallel and sequentially, and log the differences of the results (for testing)",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_VM_PARALLEL_EXEC_CHECK"},
+		Category: "VIRTUAL MACHINE",
+	}
 
 	// Logging and debug settings
 	MetricsEnabledFlag = &cli.BoolFlag{
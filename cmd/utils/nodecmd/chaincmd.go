@@ -23,17 +23,22 @@ package nodecmd
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/governance"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/urfave/cli/v2"
 )
 
@@ -69,6 +74,8 @@ var (
 			utils.RocksDBCacheIndexAndFilterFlag,
 			utils.OverwriteGenesisFlag,
 			utils.LivePruningFlag,
+			utils.TrieNodeSchemeFlag,
+			utils.StateHistoryFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -92,6 +99,91 @@ It expects the genesis file as argument.`,
 		Description: `
 The dumpgenesis command dumps the genesis block configuration in JSON format to stdout.`,
 	}
+
+	ImportCommand = &cli.Command{
+		Action:    utils.MigrateFlags(importChain),
+		Name:      "import",
+		Usage:     "Import a blockchain file",
+		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
+		Flags:     utils.SnapshotFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The import command imports blocks from RLP-encoded files. The input can be a single
+file or several files which are imported in the given order. Files ending with .gz
+or .zst are decompressed with gzip or zstd respectively.
+
+The header of every block is verified through the consensus engine before the block
+is executed. Blocks already present in the database are skipped, so an interrupted
+import is resumed by running the same command again.`,
+	}
+
+	ExportCommand = &cli.Command{
+		Action:    utils.MigrateFlags(exportChain),
+		Name:      "export",
+		Usage:     "Export blockchain into file",
+		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
+		Flags:     append(utils.SnapshotFlags, utils.ExportSegmentSizeFlag),
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+Requires a first argument of the file to write to.
+Optional second and third arguments control the first and
+last block to write. In this mode, the file will be appended
+if already existing. If the file ends with .gz or .zst, the output will
+be compressed with gzip or zstd respectively.
+
+If --export.segment-size is given, the blocks are written into several files
+holding that many blocks each, named <filename>-<first>-<last>.<ext>.
+Segments already present are skipped, so an interrupted export is resumed
+by running the same command again.`,
+	}
+
+	ImportReceiptsCommand = &cli.Command{
+		Action:    utils.MigrateFlags(importReceipts),
+		Name:      "import-receipts",
+		Usage:     "Import the receipts of blocks from an RLP stream",
+		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
+		Flags:     utils.SnapshotFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The import-receipts command imports the receipts exported by export-receipts.
+The headers of the blocks must already be in the database, and the receipts
+root of every header is checked before the receipts are written.`,
+	}
+
+	ExportReceiptsCommand = &cli.Command{
+		Action:    utils.MigrateFlags(exportReceipts),
+		Name:      "export-receipts",
+		Usage:     "Export the receipts of canonical blocks into an RLP stream",
+		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
+		Flags:     utils.SnapshotFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The export-receipts command exports the receipts of the canonical blocks in the
+given range, or of the whole chain if no range is given. If the file ends with
+.gz or .zst, the output will be compressed with gzip or zstd respectively.`,
+	}
+
+	ImportPreimagesCommand = &cli.Command{
+		Action:    utils.MigrateFlags(importPreimages),
+		Name:      "import-preimages",
+		Usage:     "Import the preimage database from an RLP stream",
+		ArgsUsage: "<datafile>",
+		Flags:     utils.SnapshotFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The import-preimages command imports hash preimages from an RLP encoded stream.`,
+	}
+
+	ExportPreimagesCommand = &cli.Command{
+		Action:    utils.MigrateFlags(exportPreimages),
+		Name:      "export-preimages",
+		Usage:     "Export the preimage database into an RLP stream",
+		ArgsUsage: "<dumpfile>",
+		Flags:     utils.SnapshotFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The export-preimages command exports hash preimages to an RLP encoded stream.`,
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -144,6 +236,8 @@ func initGenesis(ctx *cli.Context) error {
 	numStateTrieShards := ctx.Uint(utils.NumStateTrieShardsFlag.Name)
 	overwriteGenesis := ctx.Bool(utils.OverwriteGenesisFlag.Name)
 	livePruning := ctx.Bool(utils.LivePruningFlag.Name)
+	trieNodeScheme := ctx.String(utils.TrieNodeSchemeFlag.Name)
+	stateHistory := ctx.Bool(utils.StateHistoryFlag.Name)
 
 	dbtype := database.DBType(ctx.String(utils.DbTypeFlag.Name)).ToValid()
 	if len(dbtype) == 0 {
@@ -187,6 +281,20 @@ func initGenesis(ctx *cli.Context) error {
 		// Initialize DeriveSha implementation
 		blockchain.InitDeriveSha(genesis.Config)
 
+		// Write the trie node scheme to database before the genesis state
+		scheme, err := statedb.SetupTrieNodeScheme(chainDB, trieNodeScheme)
+		if err != nil {
+			logger.Crit("Failed to set up trie node scheme", "err", err)
+		}
+		if scheme == statedb.PathScheme && livePruning {
+			logger.Crit("Live pruning is not supported with the path trie node scheme")
+		}
+		// Write the state history flag to database, so that the genesis state is indexed
+		if stateHistory {
+			logger.Info("Writing state history flag to database")
+			chainDB.WriteStateHistoryEnabled()
+		}
+
 		_, hash, err := blockchain.SetupGenesisBlock(chainDB, genesis, params.UnusedNetworkId, false, overwriteGenesis)
 		if err != nil {
 			logger.Crit("Failed to write genesis block", "err", err)
@@ -211,6 +319,156 @@ func initGenesis(ctx *cli.Context) error {
 	return nil
 }
 
+func importChain(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	stack, cfg := utils.MakeConfigNode(ctx)
+
+	chain, chainDB := utils.MakeChain(stack, &cfg)
+	defer chainDB.Close()
+	defer chain.Stop()
+
+	start := time.Now()
+	for _, arg := range ctx.Args().Slice() {
+		if err := utils.ImportChain(chain, arg); err != nil {
+			return fmt.Errorf("import %s failed: %v", arg, err)
+		}
+	}
+	logger.Info("Import done", "head", chain.CurrentBlock().NumberU64(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func exportChain(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	stack, cfg := utils.MakeConfigNode(ctx)
+
+	chain, chainDB := utils.MakeChain(stack, &cfg)
+	defer chainDB.Close()
+	defer chain.Stop()
+
+	fp := ctx.Args().First()
+	first, last, err := parseBlockRange(ctx, chain.CurrentBlock().NumberU64())
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	switch {
+	case ctx.Uint64(utils.ExportSegmentSizeFlag.Name) > 0:
+		err = utils.ExportChainSegments(chain, fp, first, last, ctx.Uint64(utils.ExportSegmentSizeFlag.Name))
+	case ctx.Args().Len() < 3:
+		err = utils.ExportChain(chain, fp)
+	default:
+		err = utils.ExportAppendChain(chain, fp, first, last)
+	}
+	if err != nil {
+		return fmt.Errorf("export failed: %v", err)
+	}
+	logger.Info("Export done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func importReceipts(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	stack, cfg := utils.MakeConfigNode(ctx)
+
+	// The chain is created to set up the DeriveSha implementation used to
+	// check the receipts root of every block.
+	chain, chainDB := utils.MakeChain(stack, &cfg)
+	defer chainDB.Close()
+	defer chain.Stop()
+
+	for _, arg := range ctx.Args().Slice() {
+		if err := utils.ImportReceipts(chainDB, arg); err != nil {
+			return fmt.Errorf("import %s failed: %v", arg, err)
+		}
+	}
+	return nil
+}
+
+func exportReceipts(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	stack := MakeFullNode(ctx)
+
+	chainDB := stack.OpenDatabase(getConfig(ctx))
+	defer chainDB.Close()
+
+	head := chainDB.ReadHeaderNumber(chainDB.ReadHeadBlockHash())
+	if head == nil {
+		return errors.New("empty database")
+	}
+	first, last, err := parseBlockRange(ctx, *head)
+	if err != nil {
+		return err
+	}
+	return utils.ExportReceipts(chainDB, ctx.Args().First(), first, last)
+}
+
+func importPreimages(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	stack := MakeFullNode(ctx)
+
+	chainDB := stack.OpenDatabase(getConfig(ctx))
+	defer chainDB.Close()
+
+	start := time.Now()
+	if err := utils.ImportPreimages(chainDB, ctx.Args().First()); err != nil {
+		return fmt.Errorf("import failed: %v", err)
+	}
+	logger.Info("Import done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func exportPreimages(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	stack := MakeFullNode(ctx)
+
+	chainDB := stack.OpenDatabase(getConfig(ctx))
+	defer chainDB.Close()
+
+	start := time.Now()
+	if err := utils.ExportPreimages(chainDB, ctx.Args().First()); err != nil {
+		return fmt.Errorf("export failed: %v", err)
+	}
+	logger.Info("Export done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// parseBlockRange returns the block range given by the second and third
+// arguments. If they are omitted, the range from genesis to head is returned.
+func parseBlockRange(ctx *cli.Context, head uint64) (uint64, uint64, error) {
+	switch ctx.Args().Len() {
+	case 1:
+		return 0, head, nil
+	case 3:
+		first, ferr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		last, lerr := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+		if ferr != nil || lerr != nil {
+			return 0, 0, errors.New("export error in parsing parameters: block number not an integer")
+		}
+		if first > last {
+			return 0, 0, fmt.Errorf("export error: first (%d) is greater than last (%d)", first, last)
+		}
+		if last > head {
+			return 0, 0, fmt.Errorf("export error: last (%d) is greater than head (%d)", last, head)
+		}
+		return first, last, nil
+	default:
+		return 0, 0, errors.New("export requires a file name and an optional block range")
+	}
+}
+
 func dumpGenesis(ctx *cli.Context) error {
 	genesis := MakeGenesis(ctx)
 	if genesis == nil {
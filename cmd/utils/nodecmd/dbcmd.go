@@ -0,0 +1,247 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/urfave/cli/v2"
+)
+
+var DBCommand = &cli.Command{
+	Name:     "db",
+	Usage:    "A set of commands for the low level database",
+	Category: "DATABASE COMMANDS",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "backup",
+			Usage:     "Write a consistent copy of every database partition",
+			ArgsUsage: "<dir>",
+			Action:    utils.MigrateFlags(backupDB),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+klay db backup <dir>
+writes a point-in-time copy of every database partition of a stopped node
+into <dir>, which must not exist. The copies are made with the checkpoint
+facility of the database backend, and a MANIFEST.json records the head block
+and its state root.
+
+To back up a running node, call admin.backup("<dir>") through the console.`,
+		},
+		{
+			Name:      "restore",
+			Usage:     "Restore the database from a backup",
+			ArgsUsage: "<dir>",
+			Action:    utils.MigrateFlags(restoreDB),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+klay db restore <dir>
+restores the backup stored in <dir> into the chaindata directory of the node,
+which must be empty. The database flags must match the ones of the backed up
+node.`,
+		},
+		{
+			Name:      "verify",
+			Usage:     "Check the consistency of the chain database",
+			ArgsUsage: "[<blockNumFirst>]",
+			Action:    utils.MigrateFlags(verifyDB),
+			Flags:     append(utils.SnapshotFlags, utils.DBVerifyRepairFlag),
+			Description: `
+klay db verify [<first>]
+checks the blocks from <first>, or the genesis block, to the head across the
+database partitions: the continuity of the canonical chain, the presence of
+the headers, bodies and receipts, the tx lookup entries, and that the state
+root of the head block resolves. A report of the problems found is printed.
+
+With --db.repair, the chain is rewound to the last consistent block whose
+state is available, and the database is checked again.`,
+		},
+		{
+			Name:      "reshard",
+			Usage:     "Migrate database partitions to another number of shards or database type",
+			ArgsUsage: "[<partition> ...]",
+			Action:    utils.MigrateFlags(reshardDB),
+			Flags:     append(utils.SnapshotFlags, utils.DBReshardShardsFlag, utils.DBReshardTypeFlag),
+			Description: `
+klay db reshard [<partition> ...]
+migrates the given partitions (e.g. statetrie), or every partition if the
+database type changes, in place into --db.reshard.shards shards of
+--db.reshard.dbtype. The database flags must describe the current layout.
+Only the state trie partitions are sharded.
+
+The data is copied next to each partition and replaces it once complete. An
+interrupted resharding is resumed by running the same command again. After
+resharding, start the node with the new database flags.`,
+		},
+	},
+}
+
+func backupDB(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("this command requires a directory")
+	}
+	stack, cfg := utils.MakeConfigNode(ctx)
+
+	chain, chainDB := utils.MakeChain(stack, &cfg)
+	defer chainDB.Close()
+	defer chain.Stop()
+
+	start := time.Now()
+	manifest, err := chain.Backup(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("backup failed: %v", err)
+	}
+	logger.Info("Backup done", "number", manifest.BlockNumber, "hash", manifest.BlockHash, "root", manifest.StateRoot, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func restoreDB(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("this command requires a directory")
+	}
+	stack, _ := utils.MakeConfigNode(ctx)
+	dbc := getConfig(ctx)
+
+	manifest, err := database.ReadBackupManifest(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	switch {
+	case manifest.DBType != dbc.DBType:
+		return fmt.Errorf("database type mismatch: backup %s, configured %s", manifest.DBType, dbc.DBType)
+	case manifest.SingleDB != dbc.SingleDB:
+		return fmt.Errorf("single database mismatch: backup %v, configured %v", manifest.SingleDB, dbc.SingleDB)
+	case !manifest.SingleDB && manifest.NumStateTrieShards != dbc.NumStateTrieShards:
+		return fmt.Errorf("state trie shards mismatch: backup %d, configured %d", manifest.NumStateTrieShards, dbc.NumStateTrieShards)
+	}
+
+	start := time.Now()
+	if _, err := database.RestoreBackup(ctx.Args().First(), stack.ResolvePath(dbc.Dir)); err != nil {
+		return fmt.Errorf("restore failed: %v", err)
+	}
+	logger.Info("Restore done", "number", manifest.BlockNumber, "hash", manifest.BlockHash, "root", manifest.StateRoot, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func verifyDB(ctx *cli.Context) error {
+	var first uint64
+	switch ctx.Args().Len() {
+	case 0:
+	case 1:
+		var err error
+		if first, err = strconv.ParseUint(ctx.Args().First(), 10, 64); err != nil {
+			return fmt.Errorf("invalid block number: %v", err)
+		}
+	default:
+		return errors.New("this command takes an optional first block number")
+	}
+
+	stack, cfg := utils.MakeConfigNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+	report, err := utils.VerifyChainDB(db, first)
+	db.Close()
+	if err != nil {
+		return err
+	}
+	report.Print(os.Stdout)
+	if report.OK() {
+		logger.Info("No problem found in the chain database")
+		return nil
+	}
+	if !ctx.Bool(utils.DBVerifyRepairFlag.Name) {
+		return fmt.Errorf("%d problem(s) found, run with --%s to repair", report.NumProblems, utils.DBVerifyRepairFlag.Name)
+	}
+	if report.LastWithState < 0 {
+		return errors.New("no consistent block with available state to rewind to")
+	}
+
+	// Opening the chain already rewinds a head whose state is missing.
+	chain, chainDB := utils.MakeChain(stack, &cfg)
+	target := uint64(report.LastWithState)
+	if head := chain.CurrentBlock().NumberU64(); head > target {
+		logger.Warn("Rewinding the chain", "from", head, "to", target)
+		if err := chain.SetHead(target); err != nil {
+			chain.Stop()
+			chainDB.Close()
+			return fmt.Errorf("failed to rewind to #%d: %v", target, err)
+		}
+	}
+	chain.Stop()
+	report, err = utils.VerifyChainDB(chainDB, first)
+	chainDB.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Println("\nAfter repair:")
+	report.Print(os.Stdout)
+	if !report.OK() {
+		return fmt.Errorf("%d problem(s) remain after repair", report.NumProblems)
+	}
+	logger.Info("Repaired the chain database", "head", report.HeadBlock)
+	return nil
+}
+
+func reshardDB(ctx *cli.Context) error {
+	stack, _ := utils.MakeConfigNode(ctx)
+	dbc := getConfig(ctx)
+
+	numShards, dbType := ctx.Uint(utils.DBReshardShardsFlag.Name), dbc.DBType
+	if numShards == 0 {
+		numShards = dbc.NumStateTrieShards
+	}
+	if name := ctx.String(utils.DBReshardTypeFlag.Name); name != "" {
+		if dbType = database.DBType(name).ToValid(); dbType == "" {
+			return fmt.Errorf("invalid database type %q", name)
+		}
+	}
+	if numShards == dbc.NumStateTrieShards && dbType == dbc.DBType {
+		return errors.New("nothing to do, set --db.reshard.shards or --db.reshard.dbtype")
+	}
+
+	var entries []database.DBEntryType
+	switch {
+	case ctx.Args().Len() > 0:
+		for _, name := range ctx.Args().Slice() {
+			et, err := database.ParseDBEntryType(name)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, et)
+		}
+	case dbType != dbc.DBType:
+		entries = nil // every partition
+	default:
+		entries = []database.DBEntryType{database.StateTrieDB, database.StateTrieMigrationDB}
+	}
+
+	db := stack.OpenDatabase(dbc)
+	defer db.Close()
+
+	start := time.Now()
+	if err := db.Reshard(entries, dbType, numShards); err != nil {
+		return err
+	}
+	logger.Info("Resharding done", "dbType", dbType, "shards", numShards, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
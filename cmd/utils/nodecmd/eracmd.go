@@ -0,0 +1,136 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
+	"github.com/urfave/cli/v2"
+)
+
+var EraCommand = &cli.Command{
+	Name:     "era",
+	Usage:    "A set of commands for era archive files",
+	Category: "BLOCKCHAIN COMMANDS",
+	Description: `
+Era files hold the headers, bodies and receipts of one epoch of 8192 blocks
+together with an accumulator committing to the block hashes of the epoch.
+They are named <network>-<epoch>-<root>.era1, and the checksums of the files
+of a directory are listed in checksums.txt.
+
+Klaytn has no ancient store, so historical data distributed as era files is
+made available to a node by importing it.`,
+	Subcommands: []*cli.Command{
+		{
+			Name:      "export",
+			Usage:     "Export blocks and receipts into era files",
+			ArgsUsage: "<dir> [<blockNumFirst> <blockNumLast>]",
+			Action:    utils.MigrateFlags(exportEra),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+klay era export <dir> [<first> <last>]
+writes the blocks in the given range, or the whole chain, into era files in
+<dir>. The first block is rounded down to the beginning of its epoch. Epochs
+already exported are skipped, so an interrupted export is resumed by running
+the same command again.`,
+		},
+		{
+			Name:      "import",
+			Usage:     "Import blocks and receipts from era files",
+			ArgsUsage: "<dir>",
+			Action:    utils.MigrateFlags(importEra),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+klay era import <dir>
+verifies the era files in <dir> against checksums.txt and their accumulators,
+and imports them in order. The headers are verified by the consensus engine,
+and the bodies and receipts are written without executing the blocks.`,
+		},
+		{
+			Name:      "verify",
+			Usage:     "Verify era files against the local canonical chain",
+			ArgsUsage: "<dir>",
+			Action:    utils.MigrateFlags(verifyEra),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+klay era verify <dir>
+checks the era files in <dir> against checksums.txt, their accumulators and
+the canonical block hashes stored in the local database.`,
+		},
+	},
+}
+
+func exportEra(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	stack, cfg := utils.MakeConfigNode(ctx)
+
+	chain, chainDB := utils.MakeChain(stack, &cfg)
+	defer chainDB.Close()
+	defer chain.Stop()
+
+	first, last, err := parseBlockRange(ctx, chain.CurrentBlock().NumberU64())
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	network := utils.EraNetworkName(chain.Config().ChainID)
+	if err := utils.ExportHistory(chainDB, ctx.Args().First(), network, first, last); err != nil {
+		return fmt.Errorf("export failed: %v", err)
+	}
+	logger.Info("Export done", "network", network, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func importEra(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("this command requires a directory")
+	}
+	stack, cfg := utils.MakeConfigNode(ctx)
+
+	chain, chainDB := utils.MakeChain(stack, &cfg)
+	defer chainDB.Close()
+	defer chain.Stop()
+
+	start := time.Now()
+	network := utils.EraNetworkName(chain.Config().ChainID)
+	if err := utils.ImportHistory(chain, ctx.Args().First(), network); err != nil {
+		return fmt.Errorf("import failed: %v", err)
+	}
+	logger.Info("Import done", "network", network, "head", chain.CurrentHeader().Number, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func verifyEra(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("this command requires a directory")
+	}
+	stack := MakeFullNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+	defer db.Close()
+
+	config := db.ReadChainConfig(db.ReadCanonicalHash(0))
+	if config == nil {
+		return errors.New("chain config not found in the database")
+	}
+	return utils.VerifyHistory(db, ctx.Args().First(), utils.EraNetworkName(config.ChainID))
+}
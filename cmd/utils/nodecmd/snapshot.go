@@ -77,6 +77,33 @@ reading all nodes and logging the missing nodes.
 			Description: `
 klaytn statedb iterate-triedb
 Count the number of nodes in the state-trie db.
+`,
+		},
+		{
+			Name:      "export",
+			Usage:     "Export the state of the snapshot into checksummed chunk files",
+			ArgsUsage: "<dir> [<root>]",
+			Action:    utils.MigrateFlags(exportSnapshot),
+			Flags:     append(utils.SnapshotFlags, utils.SnapshotExportChunkSizeFlag),
+			Description: `
+klay snapshot export <dir> [<state-root>]
+will write all accounts, storage slots and codes of the state with the given
+root, or of the head block, into chunk files in <dir>. The manifest of the
+export records the header of the state and the checksum of every chunk.
+The state must be one of the recent blocks covered by the snapshot.
+`,
+		},
+		{
+			Name:      "import",
+			Usage:     "Rebuild the state trie from the chunk files of a snapshot export",
+			ArgsUsage: "<dir>",
+			Action:    utils.MigrateFlags(importSnapshot),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+klay snapshot import <dir>
+will verify the chunk files of the export in <dir> against their checksums,
+rebuild the state trie from them and check the resulting root against the
+header of the export.
 `,
 		},
 	},
@@ -313,3 +340,77 @@ func iterateTrie(ctx *cli.Context) error {
 	logger.Info("TrieDB Iterator finished", "total node count", cnt, "nil node count", nilCnt)
 	return nil
 }
+
+// exportSnapshot writes the state of the given root, or of the head block, into
+// chunk files.
+func exportSnapshot(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return errors.New("usage: klay snapshot export <dir> [<root>]")
+	}
+	stack := MakeFullNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+	head := db.ReadHeadBlockHash()
+	if head == (common.Hash{}) {
+		return errors.New("empty database")
+	}
+	headBlock := db.ReadBlockByHash(head)
+	if headBlock == nil {
+		return fmt.Errorf("head block missing: %v", head.String())
+	}
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), 256, headBlock.Root(), false, false, false)
+	if err != nil {
+		logger.Error("Failed to open snapshot tree", "err", err)
+		return err
+	}
+	header := headBlock.Header()
+	if ctx.NArg() == 2 {
+		root, err := parseRoot(ctx.Args().Get(1))
+		if err != nil {
+			logger.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+		// The snapshot only covers the recent blocks
+		for header != nil && header.Root != root {
+			if header.Number.Uint64() == 0 || headBlock.NumberU64()-header.Number.Uint64() >= 128 {
+				header = nil
+				break
+			}
+			header = db.ReadHeader(header.ParentHash, header.Number.Uint64()-1)
+		}
+		if header == nil {
+			return fmt.Errorf("no recent block with state root %x", root)
+		}
+	}
+	chunkSize := ctx.Uint64(utils.SnapshotExportChunkSizeFlag.Name) * 1024 * 1024
+	if _, err := snaptree.Export(header, ctx.Args().First(), chunkSize); err != nil {
+		logger.Error("Failed to export snapshot", "root", header.Root, "err", err)
+		return err
+	}
+	return nil
+}
+
+// importSnapshot rebuilds the state trie from the chunk files of an export.
+func importSnapshot(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("usage: klay snapshot import <dir>")
+	}
+	stack := MakeFullNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+
+	_, header, err := snapshot.ReadExportManifest(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	number := header.Number.Uint64()
+	if hash := db.ReadCanonicalHash(number); hash != (common.Hash{}) && hash != header.Hash() {
+		return fmt.Errorf("exported block %d (%x) is not the canonical block %x", number, header.Hash(), hash)
+	} else if hash == (common.Hash{}) {
+		logger.Warn("Exported block is not known by the local chain", "number", number, "hash", header.Hash())
+	}
+	if _, err := snapshot.Import(db, ctx.Args().First()); err != nil {
+		logger.Error("Failed to import snapshot", "err", err)
+		return err
+	}
+	logger.Info("Imported the state", "number", number, "root", header.Root)
+	return nil
+}
@@ -219,6 +219,9 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewUint64Flag(TriesInMemoryFlag),
 	altsrc.NewBoolFlag(LivePruningFlag),
 	altsrc.NewUint64Flag(LivePruningRetentionFlag),
+	altsrc.NewStringFlag(TrieNodeSchemeFlag),
+	altsrc.NewUint64Flag(PathHistoryFlag),
+	altsrc.NewBoolFlag(StateHistoryFlag),
 	altsrc.NewIntFlag(CacheTypeFlag),
 	altsrc.NewIntFlag(CacheScaleFlag),
 	altsrc.NewStringFlag(CacheUsageLevelFlag),
@@ -226,8 +229,12 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewStringFlag(TrieNodeCacheTypeFlag),
 	altsrc.NewIntFlag(NumFetcherPrefetchWorkerFlag),
 	altsrc.NewBoolFlag(UseSnapshotForPrefetchFlag),
+	altsrc.NewIntFlag(PoolPrefetchTxsFlag),
+	altsrc.NewIntFlag(PoolPrefetchCPUPercentFlag),
 	altsrc.NewIntFlag(TrieNodeCacheLimitFlag),
 	altsrc.NewDurationFlag(TrieNodeCacheSavePeriodFlag),
+	altsrc.NewIntFlag(TrieNodeCacheWarmEntriesFlag),
+	altsrc.NewIntFlag(TrieNodeCacheWarmSizeFlag),
 	altsrc.NewStringSliceFlag(TrieNodeCacheRedisEndpointsFlag),
 	altsrc.NewBoolFlag(TrieNodeCacheRedisClusterFlag),
 	altsrc.NewBoolFlag(TrieNodeCacheRedisPublishBlockFlag),
@@ -252,6 +259,8 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewIntFlag(VMLogTargetFlag),
 	altsrc.NewBoolFlag(VMTraceInternalTxFlag),
 	altsrc.NewBoolFlag(VMOpDebugFlag),
+	altsrc.NewBoolFlag(VMParallelExecFlag),
+	altsrc.NewBoolFlag(VMParallelExecCheckFlag),
 	altsrc.NewUint64Flag(NetworkIdFlag),
 	altsrc.NewBoolFlag(MetricsEnabledFlag),
 	altsrc.NewBoolFlag(PrometheusExporterFlag),
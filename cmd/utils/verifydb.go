@@ -0,0 +1,202 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// maxReportedProblems is the number of problems listed in a ChainDBReport.
+const maxReportedProblems = 100
+
+// ChainDBReport is the result of VerifyChainDB.
+type ChainDBReport struct {
+	First, Last uint64 // Range of the checked blocks
+
+	HeadHeader    uint64 // Number of the head header
+	HeadBlock     uint64 // Number of the head block
+	HeadStateRoot common.Hash
+	HeadStateOK   bool // Whether the state root of the head block resolves
+
+	// LastConsistent is the highest block number up to which every checked
+	// block is consistent, and LastWithState is the highest consistent block
+	// whose state root resolves. They are -1 if there is no such block.
+	LastConsistent int64
+	LastWithState  int64
+
+	NumProblems int
+	Problems    []string // The first maxReportedProblems problems
+}
+
+// OK returns true if no problem has been found.
+func (r *ChainDBReport) OK() bool {
+	return r.NumProblems == 0 && r.HeadStateOK
+}
+
+func (r *ChainDBReport) addProblem(format string, args ...interface{}) {
+	if r.NumProblems < maxReportedProblems {
+		r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+	}
+	r.NumProblems++
+}
+
+// Print writes the report in a human readable form.
+func (r *ChainDBReport) Print(w io.Writer) {
+	fmt.Fprintf(w, "Checked blocks:       #%d - #%d\n", r.First, r.Last)
+	fmt.Fprintf(w, "Head header:          #%d\n", r.HeadHeader)
+	fmt.Fprintf(w, "Head block:           #%d\n", r.HeadBlock)
+	fmt.Fprintf(w, "Head state root:      %s (resolves: %v)\n", r.HeadStateRoot.Hex(), r.HeadStateOK)
+	fmt.Fprintf(w, "Last consistent:      %d\n", r.LastConsistent)
+	fmt.Fprintf(w, "Last with state:      %d\n", r.LastWithState)
+	fmt.Fprintf(w, "Problems:             %d\n", r.NumProblems)
+	for _, problem := range r.Problems {
+		fmt.Fprintf(w, "  - %s\n", problem)
+	}
+	if r.NumProblems > len(r.Problems) {
+		fmt.Fprintf(w, "  ... %d more\n", r.NumProblems-len(r.Problems))
+	}
+}
+
+// VerifyChainDB checks the consistency of the blocks in [first, head] across
+// the database partitions: the canonical chain must be continuous up to the
+// head header, and every canonical block up to the head block must have its
+// header, body, receipts and tx lookup entries. The state root of the head
+// block must resolve. If first is greater than the head header, only the head
+// is checked.
+func VerifyChainDB(db database.DBManager, first uint64) (*ChainDBReport, error) {
+	report := &ChainDBReport{First: first, LastConsistent: -1, LastWithState: -1}
+
+	headHeaderHash, headBlockHash := db.ReadHeadHeaderHash(), db.ReadHeadBlockHash()
+	headHeaderNum, headBlockNum := db.ReadHeaderNumber(headHeaderHash), db.ReadHeaderNumber(headBlockHash)
+	if headHeaderNum == nil || headBlockNum == nil {
+		return nil, fmt.Errorf("head header %x or head block %x not found", headHeaderHash, headBlockHash)
+	}
+	report.HeadHeader, report.HeadBlock, report.Last = *headHeaderNum, *headBlockNum, *headHeaderNum
+	if first > report.Last {
+		report.First = report.Last
+	}
+	if report.HeadBlock > report.HeadHeader {
+		report.addProblem("head block #%d is above head header #%d", report.HeadBlock, report.HeadHeader)
+	}
+	if canonical := db.ReadCanonicalHash(report.HeadBlock); canonical != headBlockHash {
+		report.addProblem("head block %x is not canonical at #%d (%x)", headBlockHash, report.HeadBlock, canonical)
+	}
+
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		parent   common.Hash
+		firstBad = int64(-1)
+	)
+	if report.First > 0 {
+		parent = db.ReadCanonicalHash(report.First - 1)
+	}
+	markBad := func(number uint64) {
+		if firstBad < 0 {
+			firstBad = int64(number)
+		}
+	}
+	for number := report.First; number <= report.Last; number++ {
+		hash := db.ReadCanonicalHash(number)
+		if hash == (common.Hash{}) {
+			report.addProblem("canonical hash missing at #%d", number)
+			markBad(number)
+			parent = common.Hash{}
+			continue
+		}
+		header := db.ReadHeader(hash, number)
+		if header == nil {
+			report.addProblem("header missing for #%d %x", number, hash)
+			markBad(number)
+			parent = hash
+			continue
+		}
+		if number > 0 && parent != (common.Hash{}) && header.ParentHash != parent {
+			report.addProblem("header #%d %x does not link to the canonical parent %x", number, hash, parent)
+			markBad(number)
+		}
+		parent = hash
+
+		// Blocks above the head block may be header-only.
+		if number <= report.HeadBlock {
+			if !verifyBlockData(db, report, hash, number) {
+				markBad(number)
+			}
+		}
+		if time.Since(logged) > log.StatsReportLimit {
+			logger.Info("Verifying chain database", "number", number, "head", report.Last, "problems", report.NumProblems, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+
+	lastConsistent := int64(report.HeadBlock)
+	if firstBad >= 0 && firstBad-1 < lastConsistent {
+		lastConsistent = firstBad - 1
+	}
+	report.LastConsistent = lastConsistent
+
+	// Find the highest consistent block whose state is available.
+	sdb := state.NewDatabase(db)
+	if header := db.ReadHeader(headBlockHash, report.HeadBlock); header != nil {
+		report.HeadStateRoot = header.Root
+		_, err := state.New(header.Root, sdb, nil, nil)
+		report.HeadStateOK = err == nil
+	}
+	for number := lastConsistent; number >= 0; number-- {
+		header := db.ReadHeader(db.ReadCanonicalHash(uint64(number)), uint64(number))
+		if header == nil {
+			continue
+		}
+		if _, err := state.New(header.Root, sdb, nil, nil); err == nil {
+			report.LastWithState = number
+			break
+		}
+	}
+	return report, nil
+}
+
+// verifyBlockData checks the body, receipts and tx lookup entries of a block.
+func verifyBlockData(db database.DBManager, report *ChainDBReport, hash common.Hash, number uint64) bool {
+	body := db.ReadBody(hash, number)
+	if body == nil {
+		report.addProblem("body missing for #%d %x", number, hash)
+		return false
+	}
+	if len(body.Transactions) == 0 {
+		return true
+	}
+	ok := true
+	if receipts := db.ReadReceipts(hash, number); len(receipts) != len(body.Transactions) {
+		report.addProblem("block #%d has %d receipts for %d transactions", number, len(receipts), len(body.Transactions))
+		ok = false
+	}
+	for i, tx := range body.Transactions {
+		blockHash, blockNumber, index := db.ReadTxLookupEntry(tx.Hash())
+		if blockHash != hash || blockNumber != number || index != uint64(i) {
+			report.addProblem("tx lookup entry of %x points to #%d %x index %d instead of #%d index %d", tx.Hash(), blockNumber, blockHash, index, number, i)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyChainDB(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Istanbul = params.GetDefaultIstanbulConfig()
+	var (
+		db      = database.NewMemoryDBManager()
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &blockchain.Genesis{
+			Config: &config,
+			Alloc:  blockchain.GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSignerForChainID(gspec.Config.ChainID)
+	)
+	blockchain.InitDeriveSha(gspec.Config)
+	blocks, _ := blockchain.GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, 10, func(i int, block *blockchain.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x1}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		require.NoError(t, err)
+		block.AddTx(tx)
+	})
+	chain, err := blockchain.NewBlockChain(db, nil, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	chain.Stop()
+
+	report, err := VerifyChainDB(db, 0)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), report.Problems)
+	assert.Equal(t, int64(10), report.LastConsistent)
+	assert.Equal(t, int64(10), report.LastWithState)
+
+	// Corrupt the chain: a missing tx lookup entry and a missing body.
+	db.DeleteTxLookupEntry(blocks[6].Transactions()[0].Hash())
+	db.DeleteBody(blocks[4].Hash(), blocks[4].NumberU64())
+
+	report, err = VerifyChainDB(db, 0)
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.Equal(t, 2, report.NumProblems)
+	assert.Equal(t, int64(4), report.LastConsistent)
+	assert.True(t, report.LastWithState >= 0 && report.LastWithState <= report.LastConsistent)
+	target := uint64(report.LastWithState)
+
+	// Checking from a later block only reports the problems above it.
+	report, err = VerifyChainDB(db, 6)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.NumProblems)
+	assert.Equal(t, int64(6), report.LastConsistent)
+
+	// Rewinding to the last consistent block with state repairs the chain.
+	chain, err = blockchain.NewBlockChain(db, nil, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, chain.SetHead(target))
+	chain.Stop()
+
+	report, err = VerifyChainDB(db, 0)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), report.Problems)
+	assert.Equal(t, target, report.HeadBlock)
+}
@@ -421,6 +421,8 @@ func getChainConfig(governance Engine, num *rpc.BlockNumber) *params.ChainConfig
 	config.ShanghaiCompatibleBlock = latestConfig.ShanghaiCompatibleBlock
 	config.CancunCompatibleBlock = latestConfig.CancunCompatibleBlock
 	config.DragonCompatibleBlock = latestConfig.DragonCompatibleBlock
+	config.PragueCompatibleBlock = latestConfig.PragueCompatibleBlock
+	config.OsakaCompatibleBlock = latestConfig.OsakaCompatibleBlock
 	config.Kip103CompatibleBlock = latestConfig.Kip103CompatibleBlock
 	config.Kip103ContractAddress = latestConfig.Kip103ContractAddress
 	config.RandaoCompatibleBlock = latestConfig.RandaoCompatibleBlock
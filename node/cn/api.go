@@ -36,9 +36,11 @@ import (
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/klaytn/klaytn/work"
 )
@@ -209,6 +211,15 @@ func (api *PrivateAdminAPI) StateMigrationStatus() map[string]interface{} {
 	}
 }
 
+// Backup writes a consistent point-in-time copy of every database partition
+// into dir, which must not exist, and returns the manifest of the backup.
+func (api *PrivateAdminAPI) Backup(dir string) (*database.BackupManifest, error) {
+	if _, err := os.Stat(dir); err == nil {
+		return nil, errors.New("location would overwrite an existing directory")
+	}
+	return api.cn.BlockChain().Backup(dir)
+}
+
 func (api *PrivateAdminAPI) SaveTrieNodeCacheToDisk() error {
 	return api.cn.BlockChain().SaveTrieNodeCacheToDisk()
 }
@@ -314,6 +325,48 @@ func (api *PublicDebugAPI) DumpBlock(ctx context.Context, blockNrOrHash rpc.Bloc
 	return stateDb.RawDump(), nil
 }
 
+// AccountRangeMaxResults is the maximum number of accounts returned by a call
+// of debug_accountRange.
+const AccountRangeMaxResults = 256
+
+// AccountRange returns a page of the accounts of the state of the given block,
+// ordered by the hash of their address and starting at the given hash. The
+// next field of the result is the start of the next page. The accounts are read
+// from the snapshot when it covers the state.
+func (api *PublicDebugAPI) AccountRange(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, start hexutil.Bytes, maxResults int, nocode, nostorage bool) (*state.AccountRange, error) {
+	var (
+		stateDb *state.StateDB
+		err     error
+	)
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		_, stateDb = api.cn.miner.Pending()
+		if stateDb == nil {
+			return nil, fmt.Errorf("pending block is not prepared yet")
+		}
+	} else {
+		var block *types.Block
+		if ok && number == rpc.LatestBlockNumber {
+			block = api.cn.APIBackend.CurrentBlock()
+		} else {
+			block, err = api.cn.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+			if block == nil || err != nil {
+				blockNrOrHashString, _ := blockNrOrHash.NumberOrHashString()
+				return nil, fmt.Errorf("block %v not found", blockNrOrHashString)
+			}
+		}
+		stateDb, err = api.cn.BlockChain().StateAt(block.Root())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if maxResults <= 0 || maxResults > AccountRangeMaxResults {
+		maxResults = AccountRangeMaxResults
+	}
+	conf := &state.RangeConfig{Max: maxResults, SkipCode: nocode, SkipStorage: nostorage}
+	copy(conf.Start[:], start)
+	return stateDb.AccountRange(conf)
+}
+
 type Trie struct {
 	Type   string `json:"type"`
 	Hash   string `json:"hash"`
@@ -424,6 +477,20 @@ type storageEntry struct {
 	Value common.Hash  `json:"value"`
 }
 
+// ExecutionWitness returns the witness of the given block: the trie nodes,
+// contract codes and ancestor headers read by its execution, which are enough
+// to execute it again without the state.
+func (api *PrivateDebugAPI) ExecutionWitness(ctx context.Context, blockNr rpc.BlockNumber) (*state.Witness, error) {
+	block, err := api.cn.APIBackend.BlockByNumber(ctx, blockNr)
+	if block == nil || err != nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr.Int64())
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis block has no witness")
+	}
+	return api.cn.blockchain.ExecutionWitness(block)
+}
+
 // StorageRangeAt returns the storage at the given block height and transaction index.
 func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
 	// Retrieve the block
@@ -439,30 +506,41 @@ func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common
 	if st == nil {
 		return StorageRangeResult{}, fmt.Errorf("account %x doesn't exist", contractAddress)
 	}
+	// Read the slots from the snapshot if it serves the state, the start key
+	// is a prefix of the hash of the first slot.
+	var start common.Hash
+	copy(start[:], keyStart)
+	if it, ok := statedb.SnapshotStorageIterator(contractAddress, start); ok {
+		defer it.Release()
+		return storageRange(it, st.GetKey, maxResult)
+	}
 	return storageRangeAt(st, keyStart, maxResult)
 }
 
 func storageRangeAt(st state.Trie, start []byte, maxResult int) (StorageRangeResult, error) {
-	it := statedb.NewIterator(st.NodeIterator(start))
+	return storageRange(state.NewTrieRangeIterator(st, start), st.GetKey, maxResult)
+}
+
+func storageRange(it state.RangeIterator, getKey func([]byte) []byte, maxResult int) (StorageRangeResult, error) {
 	result := StorageRangeResult{Storage: storageMap{}}
 	for i := 0; i < maxResult && it.Next(); i++ {
-		_, content, _, err := rlp.Split(it.Value)
+		_, content, _, err := rlp.Split(it.Value())
 		if err != nil {
 			return StorageRangeResult{}, err
 		}
 		e := storageEntry{Value: common.BytesToHash(content)}
-		if preimage := st.GetKey(it.Key); preimage != nil {
+		if preimage := getKey(it.Hash().Bytes()); preimage != nil {
 			preimage := common.BytesToHash(preimage)
 			e.Key = &preimage
 		}
-		result.Storage[common.BytesToHash(it.Key)] = e
+		result.Storage[it.Hash()] = e
 	}
 	// Add the 'next key' so clients can continue downloading.
 	if it.Next() {
-		next := common.BytesToHash(it.Key)
+		next := it.Hash()
 		result.NextKey = &next
 	}
-	return result, nil
+	return result, it.Error()
 }
 
 // TODO-klaytn: Rearrange PublicDebugAPI and PrivateDebugAPI receivers
@@ -534,6 +612,26 @@ func (api *PublicDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Block
 	return dirty, nil
 }
 
+// GetStateDiff returns the changes of the accounts made by the given block: the
+// old and new balance, nonce, code hash and account key type, and the changed
+// storage slots of each account. The changes are read from the snapshot diff
+// layer of the block if kept, or else computed by comparing the state tries.
+func (api *PublicDebugAPI) GetStateDiff(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDiff, error) {
+	block, err := api.cn.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		blockNrOrHashString, _ := blockNrOrHash.NumberOrHashString()
+		return nil, fmt.Errorf("block %v not found", blockNrOrHashString)
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis block has no parent state")
+	}
+	parent := api.cn.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent block %x not found", block.ParentHash())
+	}
+	return state.DiffStates(api.cn.blockchain.StateCache(), api.cn.blockchain.Snapshots(), parent.Root(), block.Root())
+}
+
 // TODO-klaytn: Rearrange PublicDebugAPI and PrivateDebugAPI receivers
 // getStartAndEndBlock returns start and end block based on the given startNum and endNum.
 func (api *PublicDebugAPI) getStartAndEndBlock(ctx context.Context, startNum rpc.BlockNumber, endNum *rpc.BlockNumber) (*types.Block, *types.Block, error) {
@@ -589,11 +687,12 @@ func (api *PublicDebugAPI) getModifiedStorageNodes(contractAddr common.Address,
 	}
 
 	trieDB := api.cn.blockchain.StateCache().TrieDB()
-	oldTrie, err := statedb.NewSecureStorageTrie(startBlockRoot, trieDB, nil)
+	opts := &statedb.TrieOpts{Owner: crypto.Keccak256Hash(contractAddr[:])}
+	oldTrie, err := statedb.NewSecureStorageTrie(startBlockRoot, trieDB, opts)
 	if err != nil {
 		return 0, err
 	}
-	newTrie, err := statedb.NewSecureStorageTrie(endBlockRoot, trieDB, nil)
+	newTrie, err := statedb.NewSecureStorageTrie(endBlockRoot, trieDB, opts)
 	if err != nil {
 		return 0, err
 	}
@@ -189,7 +189,7 @@ func (b *CNAPIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.B
 	if header == nil || err != nil {
 		return nil, nil, err
 	}
-	stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+	stateDb, err := b.stateAtHeader(header)
 	return stateDb, header, err
 }
 
@@ -202,12 +202,25 @@ func (b *CNAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNr
 		if header == nil {
 			return nil, nil, fmt.Errorf("header for hash not found")
 		}
-		stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+		stateDb, err := b.stateAtHeader(header)
 		return stateDb, header, err
 	}
 	return nil, nil, fmt.Errorf("invalid arguments; neither block nor hash specified")
 }
 
+// stateAtHeader returns the state of the given block from its state trie, or
+// from the state history index if the trie is not available.
+func (b *CNAPIBackend) stateAtHeader(header *types.Header) (*state.StateDB, error) {
+	stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+	if err == nil {
+		return stateDb, nil
+	}
+	if historyDb, historyErr := b.cn.BlockChain().HistoryStateAt(header); historyErr == nil {
+		return historyDb, nil
+	}
+	return nil, err
+}
+
 func (b *CNAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	block := b.cn.blockchain.GetBlockByHash(hash)
 	if block == nil {
@@ -55,6 +55,7 @@ import (
 	"github.com/klaytn/klaytn/reward"
 	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/klaytn/klaytn/work"
 )
 
@@ -194,14 +195,37 @@ func setEngineType(chainConfig *params.ChainConfig) {
 	}
 }
 
-// New creates a new CN object (including the
-// initialisation of the common CN object)
-func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
-	if err := checkSyncMode(config); err != nil {
+// ChainSetup holds the chain components of a CN set up on its chain database.
+type ChainSetup struct {
+	ChainConfig *params.ChainConfig
+	GenesisHash common.Hash
+	GenesisErr  error // A *params.ConfigCompatError the chain should be rewound by, if any
+	Governance  *governance.MixedEngine
+	Engine      consensus.Engine
+	BlockChain  *blockchain.BlockChain
+	ParamSet    *params.GovParamSet // Effective governance parameters of the next block
+}
+
+// SetupChain sets up the chain config, the governance, the consensus engine and
+// the blockchain of a CN on the given chain database, and applies the effective
+// governance parameters to the chain config. It is shared by the CN service and
+// the offline commands which need a chain without any networking service.
+func SetupChain(ctx *node.ServiceContext, config *Config, chainDB database.DBManager) (*ChainSetup, error) {
+	// The trie node scheme is stored before the genesis state is written
+	scheme, err := statedb.SetupTrieNodeScheme(chainDB, config.TrieNodeScheme)
+	if err != nil {
 		return nil, err
 	}
-
-	chainDB := CreateDB(ctx, config, "chaindata")
+	if scheme == statedb.PathScheme && config.LivePruning {
+		return nil, errors.New("live pruning is not supported with the path trie node scheme")
+	}
+	// The state history index starts with the genesis state if enabled first
+	if config.StateHistory && !chainDB.ReadStateHistoryEnabled() {
+		if chainDB.ReadCanonicalHash(0) != (common.Hash{}) {
+			logger.Warn("Enabling the state history index on an existing chain, only the states changed from now on are indexed")
+		}
+		chainDB.WriteStateHistoryEnabled()
+	}
 
 	chainConfig, genesisHash, genesisErr := blockchain.SetupGenesisBlock(chainDB, config.Genesis, config.NetworkId, config.IsPrivate, false)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
@@ -216,30 +240,7 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	governance := governance.NewMixedEngine(chainConfig, chainDB)
 	logger.Info("Initialised chain configuration", "config", chainConfig)
 
-	config.GasPrice = new(big.Int).SetUint64(chainConfig.UnitPrice)
-
-	cn := &CN{
-		config:            config,
-		chainDB:           chainDB,
-		chainConfig:       chainConfig,
-		eventMux:          ctx.EventMux,
-		accountManager:    ctx.AccountManager,
-		engine:            CreateConsensusEngine(ctx, config, chainConfig, chainDB, governance, ctx.NodeType()),
-		networkId:         config.NetworkId,
-		gasPrice:          config.GasPrice,
-		rewardbase:        config.Rewardbase,
-		bloomRequests:     make(chan chan *bloombits.Retrieval),
-		bloomIndexer:      NewBloomIndexer(chainDB, params.BloomBitsBlocks),
-		closeBloomHandler: make(chan struct{}),
-		governance:        governance,
-	}
-
-	// istanbul BFT. Derive and set node's address using nodekey
-	if cn.chainConfig.Istanbul != nil {
-		governance.SetNodeAddress(crypto.PubkeyToAddress(ctx.NodeKey().PublicKey))
-	}
-
-	logger.Info("Initialising Klaytn protocol", "versions", cn.engine.Protocol().Versions, "network", config.NetworkId)
+	engine := CreateConsensusEngine(ctx, config, chainConfig, chainDB, governance, ctx.NodeType())
 
 	if !config.SkipBcVersionCheck {
 		if err := blockchain.CheckBlockChainVersion(chainDB); err != nil {
@@ -258,15 +259,94 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 			SenderTxHashIndexing: config.SenderTxHashIndexing,
 			SnapshotCacheSize:    config.SnapshotCacheSize,
 			SnapshotAsyncGen:     config.SnapshotAsyncGen,
+			PathHistory:          config.PathHistory,
+
+			ParallelExecution:      config.ParallelExecution,
+			ParallelExecutionCheck: config.ParallelExecutionCheck,
 		}
 	)
 
-	bc, err := blockchain.NewBlockChain(chainDB, cacheConfig, cn.chainConfig, cn.engine, vmConfig)
+	bc, err := blockchain.NewBlockChain(chainDB, cacheConfig, chainConfig, engine, vmConfig)
 	if err != nil {
 		return nil, err
 	}
 	bc.SetCanonicalBlock(config.StartBlockNumber)
 
+	governance.SetBlockchain(bc)
+	if err := governance.UpdateParams(bc.CurrentBlock().NumberU64()); err != nil {
+		return nil, err
+	}
+	blockchain.InitDeriveShaWithGov(chainConfig, governance)
+
+	// Synchronize proposerpolicy & useGiniCoeff
+	pset, err := governance.EffectiveParams(bc.CurrentBlock().NumberU64() + 1)
+	if err != nil {
+		return nil, err
+	}
+	if chainConfig.Istanbul != nil {
+		chainConfig.Istanbul.ProposerPolicy = pset.Policy()
+	}
+	if chainConfig.Governance.Reward != nil {
+		chainConfig.Governance.Reward.UseGiniCoeff = pset.UseGiniCoeff()
+	}
+
+	return &ChainSetup{
+		ChainConfig: chainConfig,
+		GenesisHash: genesisHash,
+		GenesisErr:  genesisErr,
+		Governance:  governance,
+		Engine:      engine,
+		BlockChain:  bc,
+		ParamSet:    pset,
+	}, nil
+}
+
+// New creates a new CN object (including the
+// initialisation of the common CN object)
+func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
+	if err := checkSyncMode(config); err != nil {
+		return nil, err
+	}
+
+	chainDB := CreateDB(ctx, config, "chaindata")
+
+	setup, err := SetupChain(ctx, config, chainDB)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		chainConfig = setup.ChainConfig
+		governance  = setup.Governance
+		bc          = setup.BlockChain
+		pset        = setup.ParamSet
+	)
+
+	config.GasPrice = new(big.Int).SetUint64(chainConfig.UnitPrice)
+
+	cn := &CN{
+		config:            config,
+		chainDB:           chainDB,
+		chainConfig:       chainConfig,
+		eventMux:          ctx.EventMux,
+		accountManager:    ctx.AccountManager,
+		engine:            setup.Engine,
+		networkId:         config.NetworkId,
+		gasPrice:          config.GasPrice,
+		rewardbase:        config.Rewardbase,
+		bloomRequests:     make(chan chan *bloombits.Retrieval),
+		bloomIndexer:      NewBloomIndexer(chainDB, params.BloomBitsBlocks),
+		closeBloomHandler: make(chan struct{}),
+		governance:        governance,
+		blockchain:        bc,
+	}
+
+	// istanbul BFT. Derive and set node's address using nodekey
+	if cn.chainConfig.Istanbul != nil {
+		governance.SetNodeAddress(crypto.PubkeyToAddress(ctx.NodeKey().PublicKey))
+	}
+
+	logger.Info("Initialising Klaytn protocol", "versions", cn.engine.Protocol().Versions, "network", config.NetworkId)
+
 	// Write the live pruning flag to database if the node is started for the first time
 	if config.LivePruning && !chainDB.ReadPruningEnabled() {
 		if bc.CurrentBlock().NumberU64() > 0 {
@@ -287,25 +367,6 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		logger.Info("Live pruning is disabled because retention is set to zero")
 	}
 
-	cn.blockchain = bc
-	governance.SetBlockchain(cn.blockchain)
-	if err := governance.UpdateParams(cn.blockchain.CurrentBlock().NumberU64()); err != nil {
-		return nil, err
-	}
-	blockchain.InitDeriveShaWithGov(cn.chainConfig, governance)
-
-	// Synchronize proposerpolicy & useGiniCoeff
-	pset, err := governance.EffectiveParams(bc.CurrentBlock().NumberU64() + 1)
-	if err != nil {
-		return nil, err
-	}
-	if cn.blockchain.Config().Istanbul != nil {
-		cn.blockchain.Config().Istanbul.ProposerPolicy = pset.Policy()
-	}
-	if cn.blockchain.Config().Governance.Reward != nil {
-		cn.blockchain.Config().Governance.Reward.UseGiniCoeff = pset.UseGiniCoeff()
-	}
-
 	if config.SenderTxHashIndexing {
 		ch := make(chan blockchain.ChainEvent, 255)
 		chainEventSubscription := cn.blockchain.SubscribeChainEvent(ch)
@@ -313,10 +374,10 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	}
 
 	// Rewind the chain in case of an incompatible config upgrade.
-	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
+	if compat, ok := setup.GenesisErr.(*params.ConfigCompatError); ok {
 		logger.Error("Rewinding chain to upgrade configuration", "err", compat)
 		cn.blockchain.SetHead(compat.RewindTo)
-		chainDB.WriteChainConfig(genesisHash, cn.chainConfig)
+		chainDB.WriteChainConfig(setup.GenesisHash, cn.chainConfig)
 	}
 	cn.bloomIndexer.Start(cn.blockchain)
 
@@ -327,9 +388,10 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	config.TxPool.NoAccountCreation = config.NoAccountCreation
 	cn.txPool = blockchain.NewTxPool(config.TxPool, cn.chainConfig, bc)
 	governance.SetTxPool(cn.txPool)
+	bc.StartPoolPrefetcher(cn.txPool)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
-	cacheLimit := cacheConfig.TrieNodeCacheConfig.LocalCacheSizeMiB
+	cacheLimit := config.TrieNodeCacheConfig.LocalCacheSizeMiB
 	if cn.protocolManager, err = NewProtocolManager(cn.chainConfig, config.SyncMode, config.NetworkId, cn.eventMux, cn.txPool, cn.engine, cn.blockchain, chainDB, cacheLimit, ctx.NodeType(), config); err != nil {
 		return nil, err
 	}
@@ -54,6 +54,7 @@ func GetDefaultConfig() *Config {
 		TrieNodeCacheConfig:  *statedb.GetEmptyTrieNodeCacheConfig(),
 		TriesInMemory:        blockchain.DefaultTriesInMemory,
 		LivePruningRetention: blockchain.DefaultLivePruningRetention,
+		PathHistory:          statedb.DefaultPathHistory,
 		GasPrice:             big.NewInt(18 * params.Ston),
 
 		TxPool: blockchain.DefaultTxPoolConfig,
@@ -125,6 +126,9 @@ type Config struct {
 	TriesInMemory        uint64
 	LivePruning          bool
 	LivePruningRetention uint64
+	TrieNodeScheme       string
+	PathHistory          uint64
+	StateHistory         bool
 	SenderTxHashIndexing bool
 	ParallelDBWrite      bool
 	TrieNodeCacheConfig  statedb.TrieNodeCacheConfig
@@ -151,6 +155,10 @@ type Config struct {
 	EnableInternalTxTracing bool
 	// Enables collecting and printing opcode execution time when node stops
 	EnableOpDebug bool
+	// Enables executing the transactions of a block in parallel
+	ParallelExecution bool
+	// Enables comparing the results of the parallel and sequential executions
+	ParallelExecutionCheck bool
 
 	// Istanbul options
 	Istanbul istanbul.Config
@@ -171,6 +171,9 @@ func (cn *CN) stateAtTransaction(block *types.Block, txIndex int, reexec uint64)
 	if err != nil {
 		return nil, vm.BlockContext{}, vm.TxContext{}, nil, err
 	}
+	if err := blockchain.ProcessParentBlockHash(cn.blockchain.Config(), block.Header(), statedb); err != nil {
+		return nil, vm.BlockContext{}, vm.TxContext{}, nil, err
+	}
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, vm.TxContext{}, statedb, nil
 	}
@@ -73,6 +73,10 @@ const (
 	// fastCallTracer is the go-version callTracer which is lighter and faster than
 	// Javascript version.
 	fastCallTracer = "fastCallTracer"
+
+	// profileTracer is the go-version tracer which aggregates the gas and the
+	// computation cost per opcode, per contract and per program counter.
+	profileTracer = "profileTracer"
 )
 
 var (
@@ -297,6 +301,7 @@ func (api *CommonAPI) traceChain(start, end *types.Block, config *TraceConfig, n
 		tasks    = make(chan *blockTraceTask, threads)
 		results  = make(chan *blockTraceTask, threads)
 		localctx = context.Background()
+		failed   error
 	)
 	for th := 0; th < threads; th++ {
 		pend.Add(1)
@@ -349,7 +354,6 @@ func (api *CommonAPI) traceChain(start, end *types.Block, config *TraceConfig, n
 			logged  time.Time
 			number  uint64
 			traced  uint64
-			failed  error
 			parent  common.Hash
 			statedb *state.StateDB
 		)
@@ -419,16 +423,22 @@ func (api *CommonAPI) traceChain(start, end *types.Block, config *TraceConfig, n
 				failed = err
 				break
 			}
+			// The parent block hash is stored before any transaction as the state processor does.
+			taskdb := statedb.Copy()
+			if err := blockchain.ProcessParentBlockHash(api.backend.ChainConfig(), next.Header(), taskdb); err != nil {
+				failed = err
+				break
+			}
 			// Send the block over to the concurrent tracers (if not in the fast-forward phase)
 			txs := next.Transactions()
 			if notifier != nil {
 				select {
-				case tasks <- &blockTraceTask{statedb: statedb.Copy(), block: next, rootref: block.Root(), results: make([]*txTraceResult, len(txs))}:
+				case tasks <- &blockTraceTask{statedb: taskdb, block: next, rootref: block.Root(), results: make([]*txTraceResult, len(txs))}:
 				case <-notifier.Closed():
 					return
 				}
 			} else {
-				tasks <- &blockTraceTask{statedb: statedb.Copy(), block: next, rootref: block.Root(), results: make([]*txTraceResult, len(txs))}
+				tasks <- &blockTraceTask{statedb: taskdb, block: next, rootref: block.Root(), results: make([]*txTraceResult, len(txs))}
 			}
 			traced += uint64(len(txs))
 		}
@@ -476,7 +486,11 @@ func (api *CommonAPI) traceChain(start, end *types.Block, config *TraceConfig, n
 		return nil, nil
 	}
 
-	return waitForResult(), nil
+	done := waitForResult()
+	if failed != nil {
+		return nil, failed
+	}
+	return done, nil
 }
 
 // TraceBlockByNumber returns the structured logs created during the execution of
@@ -602,6 +616,9 @@ func (api *CommonAPI) traceBlock(ctx context.Context, block *types.Block, config
 	if err != nil {
 		return nil, err
 	}
+	if err := blockchain.ProcessParentBlockHash(api.backend.ChainConfig(), block.Header(), statedb); err != nil {
+		return nil, err
+	}
 	// Execute all the transaction contained within the block concurrently
 	var (
 		signer  = types.MakeSigner(api.backend.ChainConfig(), block.Number())
@@ -699,6 +716,9 @@ func (api *CommonAPI) standardTraceBlockToFile(ctx context.Context, block *types
 	if err != nil {
 		return nil, err
 	}
+	if err := blockchain.ProcessParentBlockHash(api.backend.ChainConfig(), block.Header(), statedb); err != nil {
+		return nil, err
+	}
 	// Retrieve the tracing configurations, or use default values
 	var (
 		logConfig vm.LogConfig
@@ -901,9 +921,12 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 			}
 		}
 
-		if *config.Tracer == fastCallTracer {
+		switch *config.Tracer {
+		case fastCallTracer:
 			tracer = vm.NewInternalTxTracer()
-		} else {
+		case profileTracer:
+			tracer = vm.NewProfileTracer()
+		default:
 			// Construct the JavaScript tracer to execute with
 			if tracer, err = New(*config.Tracer, new(Context), api.unsafeTrace); err != nil {
 				return nil, err
@@ -919,6 +942,8 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 					t.Stop(errors.New("execution timeout"))
 				case *vm.InternalTxTracer:
 					t.Stop(errors.New("execution timeout"))
+				case *vm.ProfileTracer:
+					t.Stop(errors.New("execution timeout"))
 				default:
 					logger.Warn("unknown tracer type", "type", reflect.TypeOf(t).String())
 				}
@@ -963,6 +988,8 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 		return tracer.GetResult()
 	case *vm.InternalTxTracer:
 		return tracer.GetResult()
+	case *vm.ProfileTracer:
+		return tracer.GetResult()
 
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
@@ -324,6 +324,16 @@ func TestTraceTransaction(t *testing.T) {
 	}) {
 		t.Error("Transaction tracing result is different")
 	}
+
+	// The value transfer executes no opcode, so nothing is profiled.
+	tracer := profileTracer
+	result, err = api.TraceTransaction(context.Background(), target, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		t.Errorf("Failed to profile transaction %v", err)
+	}
+	if profile, ok := result.(*vm.ProfileResult); !ok || profile.Gas != 0 || len(profile.Opcodes) != 0 || len(profile.Pprof) == 0 {
+		t.Errorf("Transaction profiling result is different: %v", result)
+	}
 }
 
 func TestTraceBlock(t *testing.T) {
@@ -176,6 +176,38 @@ const (
 	BlobHashComptationCost     = 165
 	BlobBaseFeeComputationCost = 120
 
+	// computation cost added at PragueCompatible
+	Bls12381G1AddComputationCost          = 25000
+	Bls12381G1MulComputationCost          = 1500000
+	Bls12381G2AddComputationCost          = 35000
+	Bls12381G2MulComputationCost          = 2200000
+	Bls12381PairingBaseComputationCost    = 1200000
+	Bls12381PairingPerPairComputationCost = 1500000
+	Bls12381MapG1ComputationCost          = 250000
+	Bls12381MapG2ComputationCost          = 2400000
+
+	// computation cost added at OsakaCompatible
+	P256VerifyComputationCost      = 260000
+	DataLoadComputationCost        = 300
+	DataLoadNComputationCost       = 200
+	DataSizeComputationCost        = 120
+	DataCopyComputationCost        = 100
+	RjumpComputationCost           = 120
+	RjumpiComputationCost          = 150
+	RjumpvComputationCost          = 200
+	CallfComputationCost           = 250
+	RetfComputationCost            = 200
+	JumpfComputationCost           = 200
+	DupNComputationCost            = 190
+	SwapNComputationCost           = 150
+	ExchangeComputationCost        = 160
+	EofCreateComputationCost       = 10000
+	ReturnContractComputationCost  = 0
+	ReturnDataLoadComputationCost  = 200
+	ExtCallComputationCost         = 5000
+	ExtDelegateCallComputationCost = 696
+	ExtStaticCallComputationCost   = 10000
+
 	// opcode computation cost modification - istanbul
 	AddmodComputationCostIstanbul = 1410
 	MulmodComputationCostIstanbul = 1760
@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/log"
@@ -203,6 +204,8 @@ type ChainConfig struct {
 	ShanghaiCompatibleBlock  *big.Int `json:"shanghaiCompatibleBlock,omitempty"`  // ShanghaiCompatible switch block (nil = no fork, 0 already on shanghai)
 	CancunCompatibleBlock    *big.Int `json:"cancunCompatibleBlock,omitempty"`    // CancunCompatible switch block (nil = no fork, 0 already on Cancun)
 	DragonCompatibleBlock    *big.Int `json:"dragonCompatibleBlock,omitempty"`    // DragonCompatible switch block (nil = no fork, 0 already on Dragon)
+	PragueCompatibleBlock    *big.Int `json:"pragueCompatibleBlock,omitempty"`    // PragueCompatible switch block (nil = no fork, 0 already on Prague)
+	OsakaCompatibleBlock     *big.Int `json:"osakaCompatibleBlock,omitempty"`     // OsakaCompatible switch block (nil = no fork, 0 already on Osaka)
 
 	// KIP103 is a special purpose hardfork feature that can be executed only once
 	// Both Kip103CompatibleBlock and Kip103ContractAddress should be specified to enable KIP103
@@ -214,6 +217,10 @@ type ChainConfig struct {
 	RandaoCompatibleBlock *big.Int        `json:"randaoCompatibleBlock,omitempty"` // RandaoCompatible activate block (nil = no fork)
 	RandaoRegistry        *RegistryConfig `json:"randaoRegistry,omitempty"`        // Registry initial states
 
+	// Precompiles are the custom precompiled contracts of a service chain. Each of them
+	// must be registered in the binary by vm.RegisterPrecompiledContract.
+	Precompiles []*PrecompileConfig `json:"precompiles,omitempty"`
+
 	// Various consensus engines
 	Gxhash   *GxhashConfig   `json:"gxhash,omitempty"` // (deprecated) not supported engine
 	Clique   *CliqueConfig   `json:"clique,omitempty"`
@@ -278,6 +285,37 @@ type RegistryConfig struct {
 	Owner   common.Address            `json:"owner"`
 }
 
+// PrecompileConfig is a custom precompiled contract which is registered in the binary
+// under Name and activated at Address from Block.
+type PrecompileConfig struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	Block   *big.Int       `json:"block"` // activation block (nil = never activated)
+}
+
+// PrecompileFingerprint records a custom precompiled contract which a chain is
+// executed with, including the version of its implementation in the binary.
+type PrecompileFingerprint struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	Block   *big.Int       `json:"block"`
+	Version string         `json:"version"`
+}
+
+// IsActivated returns true if the precompiled contract is activated at num.
+func (p *PrecompileFingerprint) IsActivated(num *big.Int) bool {
+	return isForked(p.Block, num)
+}
+
+// Equal returns true if both record the same precompiled contract.
+func (p *PrecompileFingerprint) Equal(other *PrecompileFingerprint) bool {
+	return p.Name == other.Name && p.Address == other.Address && configNumEqual(p.Block, other.Block) && p.Version == other.Version
+}
+
+func (p *PrecompileFingerprint) String() string {
+	return fmt.Sprintf("%q (version %q) at %v from block %v", p.Name, p.Version, p.Address.Hex(), p.Block)
+}
+
 // GxhashConfig is the consensus engine configs for proof-of-work based sealing.
 // Deprecated: Use IstanbulConfig or CliqueConfig.
 type GxhashConfig struct{}
@@ -320,7 +358,7 @@ func (c *ChainConfig) String() string {
 	kip103 := fmt.Sprintf("KIP103CompatibleBlock: %v KIP103ContractAddress %s", c.Kip103CompatibleBlock, c.Kip103ContractAddress.String())
 
 	if c.Istanbul != nil {
-		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v DragonCompatibleBlock: %v RandaoCompatibleBlock: %v %s SubGroupSize: %d UnitPrice: %d DeriveShaImpl: %d Engine: %v}",
+		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v DragonCompatibleBlock: %v PragueCompatibleBlock: %v OsakaCompatibleBlock: %v RandaoCompatibleBlock: %v %s SubGroupSize: %d UnitPrice: %d DeriveShaImpl: %d Engine: %v}",
 			c.ChainID,
 			c.IstanbulCompatibleBlock,
 			c.LondonCompatibleBlock,
@@ -330,6 +368,8 @@ func (c *ChainConfig) String() string {
 			c.ShanghaiCompatibleBlock,
 			c.CancunCompatibleBlock,
 			c.DragonCompatibleBlock,
+			c.PragueCompatibleBlock,
+			c.OsakaCompatibleBlock,
 			c.RandaoCompatibleBlock,
 			kip103,
 			c.Istanbul.SubGroupSize,
@@ -338,7 +378,7 @@ func (c *ChainConfig) String() string {
 			engine,
 		)
 	} else {
-		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v DragonCompatibleBlock: %v RandaoCompatibleBlock: %v %s UnitPrice: %d DeriveShaImpl: %d Engine: %v }",
+		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v DragonCompatibleBlock: %v PragueCompatibleBlock: %v OsakaCompatibleBlock: %v RandaoCompatibleBlock: %v %s UnitPrice: %d DeriveShaImpl: %d Engine: %v }",
 			c.ChainID,
 			c.IstanbulCompatibleBlock,
 			c.LondonCompatibleBlock,
@@ -348,6 +388,8 @@ func (c *ChainConfig) String() string {
 			c.ShanghaiCompatibleBlock,
 			c.CancunCompatibleBlock,
 			c.DragonCompatibleBlock,
+			c.PragueCompatibleBlock,
+			c.OsakaCompatibleBlock,
 			c.RandaoCompatibleBlock,
 			kip103,
 			c.UnitPrice,
@@ -404,6 +446,16 @@ func (c *ChainConfig) IsDragonForkEnabled(num *big.Int) bool {
 	return isForked(c.DragonCompatibleBlock, num)
 }
 
+// IsPragueForkEnabled returns whether num is either equal to the prague block or greater.
+func (c *ChainConfig) IsPragueForkEnabled(num *big.Int) bool {
+	return isForked(c.PragueCompatibleBlock, num)
+}
+
+// IsOsakaForkEnabled returns whether num is either equal to the osaka block or greater.
+func (c *ChainConfig) IsOsakaForkEnabled(num *big.Int) bool {
+	return isForked(c.OsakaCompatibleBlock, num)
+}
+
 // IsRandaoForkEnabled returns whether num is either equal to the randao block or greater.
 func (c *ChainConfig) IsRandaoForkEnabled(num *big.Int) bool {
 	return isForked(c.RandaoCompatibleBlock, num)
@@ -434,6 +486,51 @@ func (c *ChainConfig) IsRandaoForkBlock(num *big.Int) bool {
 	return c.RandaoCompatibleBlock.Cmp(num) == 0
 }
 
+// NextFork returns the name and the block of the first hard fork scheduled after num.
+// If several forks are scheduled at the block, the name is the last one of them.
+// It returns an empty name and nil if no fork is scheduled.
+func (c *ChainConfig) NextFork(num *big.Int) (string, *big.Int) {
+	var (
+		name  string
+		block *big.Int
+	)
+	for _, fork := range []struct {
+		name  string
+		block *big.Int
+	}{
+		{"Istanbul", c.IstanbulCompatibleBlock},
+		{"London", c.LondonCompatibleBlock},
+		{"EthTxType", c.EthTxTypeCompatibleBlock},
+		{"Magma", c.MagmaCompatibleBlock},
+		{"Kore", c.KoreCompatibleBlock},
+		{"Shanghai", c.ShanghaiCompatibleBlock},
+		{"Cancun", c.CancunCompatibleBlock},
+		{"Randao", c.RandaoCompatibleBlock},
+		{"Dragon", c.DragonCompatibleBlock},
+		{"Prague", c.PragueCompatibleBlock},
+		{"Osaka", c.OsakaCompatibleBlock},
+	} {
+		if fork.block == nil || fork.block.Cmp(num) <= 0 {
+			continue
+		}
+		if block == nil || fork.block.Cmp(block) <= 0 {
+			name, block = fork.name, fork.block
+		}
+	}
+	return name, block
+}
+
+// ActivePrecompiles returns the custom precompiled contracts activated at num.
+func (c *ChainConfig) ActivePrecompiles(num *big.Int) []*PrecompileConfig {
+	var active []*PrecompileConfig
+	for _, p := range c.Precompiles {
+		if isForked(p.Block, num) {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -471,6 +568,8 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		{name: "cancunBlock", block: c.CancunCompatibleBlock},
 		{name: "randaoBlock", block: c.RandaoCompatibleBlock, optional: true},
 		{name: "dragonBlock", block: c.DragonCompatibleBlock},
+		{name: "pragueBlock", block: c.PragueCompatibleBlock},
+		{name: "osakaBlock", block: c.OsakaCompatibleBlock},
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -520,9 +619,59 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.DragonCompatibleBlock, newcfg.DragonCompatibleBlock, head) {
 		return newCompatError("Dragon Block", c.DragonCompatibleBlock, newcfg.DragonCompatibleBlock)
 	}
+	if isForkIncompatible(c.PragueCompatibleBlock, newcfg.PragueCompatibleBlock, head) {
+		return newCompatError("Prague Block", c.PragueCompatibleBlock, newcfg.PragueCompatibleBlock)
+	}
+	if isForkIncompatible(c.OsakaCompatibleBlock, newcfg.OsakaCompatibleBlock, head) {
+		return newCompatError("Osaka Block", c.OsakaCompatibleBlock, newcfg.OsakaCompatibleBlock)
+	}
 	if isForkIncompatible(c.RandaoCompatibleBlock, newcfg.RandaoCompatibleBlock, head) {
 		return newCompatError("Randao Block", c.RandaoCompatibleBlock, newcfg.RandaoCompatibleBlock)
 	}
+	if err := checkPrecompilesCompatible(c.Precompiles, newcfg.Precompiles, head); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkPrecompilesCompatible returns an error if a custom precompiled contract activated
+// at head is added, removed, rescheduled or moved to another address.
+func checkPrecompilesCompatible(stored, newcfgs []*PrecompileConfig, head *big.Int) *ConfigCompatError {
+	byName := func(configs []*PrecompileConfig) map[string]*PrecompileConfig {
+		m := make(map[string]*PrecompileConfig, len(configs))
+		for _, p := range configs {
+			m[p.Name] = p
+		}
+		return m
+	}
+	storedByName, newByName := byName(stored), byName(newcfgs)
+	names := make([]string, 0, len(storedByName)+len(newByName))
+	for name := range storedByName {
+		names = append(names, name)
+	}
+	for name := range newByName {
+		if _, ok := storedByName[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var s1, s2 *big.Int
+		p1, p2 := storedByName[name], newByName[name]
+		if p1 != nil {
+			s1 = p1.Block
+		}
+		if p2 != nil {
+			s2 = p2.Block
+		}
+		if isForkIncompatible(s1, s2, head) {
+			return newCompatError("Precompile "+name+" Block", s1, s2)
+		}
+		if p1 != nil && p2 != nil && p1.Address != p2.Address && isForked(s1, head) {
+			return newCompatError("Precompile "+name+" Address", s1, s2)
+		}
+	}
 	return nil
 }
 
@@ -644,7 +793,11 @@ type Rules struct {
 	IsShanghai  bool
 	IsCancun    bool
 	IsDragon    bool
+	IsPrague    bool
+	IsOsaka     bool
 	IsRandao    bool
+
+	CustomPrecompiles []common.Address // addresses of the activated custom precompiled contracts
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -653,6 +806,10 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	if chainID == nil {
 		chainID = new(big.Int)
 	}
+	var customPrecompiles []common.Address
+	for _, p := range c.ActivePrecompiles(num) {
+		customPrecompiles = append(customPrecompiles, p.Address)
+	}
 	return Rules{
 		ChainID:     new(big.Int).Set(chainID),
 		IsIstanbul:  c.IsIstanbulForkEnabled(num),
@@ -663,7 +820,11 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsShanghai:  c.IsShanghaiForkEnabled(num),
 		IsCancun:    c.IsCancunForkEnabled(num),
 		IsDragon:    c.IsDragonForkEnabled(num),
+		IsPrague:    c.IsPragueForkEnabled(num),
+		IsOsaka:     c.IsOsakaForkEnabled(num),
 		IsRandao:    c.IsRandaoForkEnabled(num),
+
+		CustomPrecompiles: customPrecompiles,
 	}
 }
 
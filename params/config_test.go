@@ -20,6 +20,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/klaytn/klaytn/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -70,9 +71,82 @@ func TestChainConfig_Copy(t *testing.T) {
 	assert.NotEqual(t, a.Governance.Reward.Ratio, b.Governance.Reward.Ratio)
 }
 
+func TestChainConfig_CheckCompatiblePrecompiles(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x0300")
+		addr2 = common.HexToAddress("0x0301")
+		vrf   = &PrecompileConfig{Name: "vrf", Address: addr1, Block: big.NewInt(10)}
+	)
+	testcases := []struct {
+		stored, new []*PrecompileConfig
+		head        uint64
+		expected    *ConfigCompatError
+	}{
+		{stored: []*PrecompileConfig{vrf}, new: []*PrecompileConfig{vrf}, head: 20},
+		// Not activated yet
+		{stored: []*PrecompileConfig{vrf}, new: nil, head: 9},
+		{stored: nil, new: []*PrecompileConfig{vrf}, head: 9},
+		{stored: []*PrecompileConfig{vrf}, new: []*PrecompileConfig{{Name: "vrf", Address: addr2, Block: big.NewInt(15)}}, head: 9},
+		// Activated
+		{
+			stored: []*PrecompileConfig{vrf}, new: nil, head: 10,
+			expected: &ConfigCompatError{What: "Precompile vrf Block", StoredConfig: big.NewInt(10), RewindTo: 9},
+		},
+		{
+			stored: nil, new: []*PrecompileConfig{vrf}, head: 10,
+			expected: &ConfigCompatError{What: "Precompile vrf Block", NewConfig: big.NewInt(10), RewindTo: 9},
+		},
+		{
+			stored: []*PrecompileConfig{vrf}, new: []*PrecompileConfig{{Name: "vrf", Address: addr1, Block: big.NewInt(15)}}, head: 12,
+			expected: &ConfigCompatError{What: "Precompile vrf Block", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(15), RewindTo: 9},
+		},
+		{
+			stored: []*PrecompileConfig{vrf}, new: []*PrecompileConfig{{Name: "vrf", Address: addr2, Block: big.NewInt(10)}}, head: 12,
+			expected: &ConfigCompatError{What: "Precompile vrf Address", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(10), RewindTo: 9},
+		},
+	}
+	for i, tc := range testcases {
+		stored, newcfg := &ChainConfig{Precompiles: tc.stored}, &ChainConfig{Precompiles: tc.new}
+		assert.Equal(t, tc.expected, stored.CheckCompatible(newcfg, tc.head), "testcase %d", i)
+	}
+
+	config := &ChainConfig{Precompiles: []*PrecompileConfig{vrf}}
+	assert.Empty(t, config.ActivePrecompiles(big.NewInt(9)))
+	assert.Equal(t, []*PrecompileConfig{vrf}, config.ActivePrecompiles(big.NewInt(10)))
+	assert.Nil(t, config.Rules(big.NewInt(9)).CustomPrecompiles)
+	assert.Equal(t, []common.Address{addr1}, config.Rules(big.NewInt(10)).CustomPrecompiles)
+}
+
 func BenchmarkChainConfig_Copy(b *testing.B) {
 	a := CypressChainConfig
 	for i := 0; i < b.N; i++ {
 		a.Copy()
 	}
 }
+
+func TestChainConfig_NextFork(t *testing.T) {
+	config := &ChainConfig{
+		IstanbulCompatibleBlock:  big.NewInt(0),
+		LondonCompatibleBlock:    big.NewInt(0),
+		EthTxTypeCompatibleBlock: big.NewInt(0),
+		MagmaCompatibleBlock:     big.NewInt(0),
+		KoreCompatibleBlock:      big.NewInt(10),
+		ShanghaiCompatibleBlock:  big.NewInt(20),
+		CancunCompatibleBlock:    big.NewInt(20),
+	}
+	testcases := []struct {
+		num   int64
+		name  string
+		block *big.Int
+	}{
+		{0, "Kore", big.NewInt(10)},
+		{9, "Kore", big.NewInt(10)},
+		{10, "Cancun", big.NewInt(20)},
+		{20, "", nil},
+	}
+	for _, tc := range testcases {
+		name, block := config.NextFork(big.NewInt(tc.num))
+		assert.Equal(t, tc.name, name, "num %d", tc.num)
+		assert.Equal(t, tc.block, block, "num %d", tc.num)
+	}
+}
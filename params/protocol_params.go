@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"math/big"
 	"time"
+
+	"github.com/klaytn/klaytn/common"
 )
 
 var TargetGasLimit = GenesisGasLimit // The artificial target
@@ -101,6 +103,15 @@ const (
 	// Introduced in Tangerine Whistle (Eip 150)
 	CreateBySelfdestructGas uint64 = 25000
 
+	// The EXTCALL family of EOF retains some gas in the caller and fails without
+	// executing the callee if the gas for the callee is too small (EIP-7069).
+	ExtCallMinRetainedGas uint64 = 5000 // MIN_RETAINED_GAS
+	ExtCallMinCalleeGas   uint64 = 2300 // MIN_CALLEE_GAS
+
+	// Gas of the EOF instructions out of the gas tiers
+	RjumpiGas   uint64 = 4 // Once per RJUMPI and RJUMPV operation
+	DataLoadGas uint64 = 4 // Once per DATALOAD operation
+
 	// Fee for Service Chain
 	// TODO-Klaytn-ServiceChain The following parameters should be fixed.
 	// TODO-Klaytn-Governance The following parameters should be able to be modified by governance.
@@ -126,6 +137,15 @@ const (
 	Bn256PairingPerPointGasByzantium   uint64 = 80000  // Per-point price for an elliptic curve pairing check
 	Bn256PairingPerPointGasIstanbul    uint64 = 34000  // Istanbul version of per-point price for an elliptic curve pairing check
 	BlobTxPointEvaluationPrecompileGas uint64 = 50000  // Gas price for the point evaluation precompile.
+	Bls12381G1AddGas                   uint64 = 375    // Price for BLS12-381 elliptic curve G1 point addition
+	Bls12381G1MulGas                   uint64 = 12000  // Price for BLS12-381 elliptic curve G1 point scalar multiplication
+	Bls12381G2AddGas                   uint64 = 600    // Price for BLS12-381 elliptic curve G2 point addition
+	Bls12381G2MulGas                   uint64 = 22500  // Price for BLS12-381 elliptic curve G2 point scalar multiplication
+	Bls12381PairingBaseGas             uint64 = 37700  // Base gas price for BLS12-381 elliptic curve pairing check
+	Bls12381PairingPerPairGas          uint64 = 32600  // Per-point pair gas price for BLS12-381 elliptic curve pairing check
+	Bls12381MapG1Gas                   uint64 = 5500   // Gas price for BLS12-381 mapping field element to G1 operation
+	Bls12381MapG2Gas                   uint64 = 23800  // Gas price for BLS12-381 mapping field element to G2 operation
+	P256VerifyGas                      uint64 = 3450   // Gas price for the secp256r1 signature verification (RIP-7212)
 	VMLogBaseGas                       uint64 = 100    // Base price for a VMLOG operation
 	VMLogPerByteGas                    uint64 = 20     // Per-byte price for a VMLOG operation
 	FeePayerGas                        uint64 = 300    // Gas needed for calculating the fee payer of the transaction in a smart contract.
@@ -183,6 +203,10 @@ const (
 	TxAccessListAddressGas    uint64 = 2400 // Per address specified in EIP 2930 access list
 	TxAccessListStorageKeyGas uint64 = 1900 // Per storage key specified in EIP 2930 access list
 
+	TxAuthTupleGas uint64 = 12500 // Per authorization of an existing account specified in EIP 7702 authorization list
+
+	HistoryServeWindow uint64 = 8191 // Number of recent block hashes kept in the history storage contract (EIP-2935)
+
 	// ZeroBaseFee exists for supporting Ethereum compatible data structure.
 	ZeroBaseFee uint64 = 0
 )
@@ -206,6 +230,20 @@ var (
 	DurationLimit          = big.NewInt(13)     // The decision boundary on the blocktime duration used to determine whether blockscore should go up or not.
 )
 
+// Gas discount tables for the BLS12-381 G1 and G2 multi-scalar multiplications (EIP-2537),
+// in thousandths, indexed by the number of pairs minus one.
+var (
+	Bls12381G1MultiExpDiscountTable = [128]uint64{1000, 949, 848, 797, 764, 750, 738, 728, 719, 712, 705, 698, 692, 687, 682, 677, 673, 669, 665, 661, 658, 654, 651, 648, 645, 642, 640, 637, 635, 632, 630, 627, 625, 623, 621, 619, 617, 615, 613, 611, 609, 608, 606, 604, 603, 601, 599, 598, 596, 595, 593, 592, 591, 589, 588, 586, 585, 584, 582, 581, 580, 579, 577, 576, 575, 574, 573, 572, 570, 569, 568, 567, 566, 565, 564, 563, 562, 561, 560, 559, 558, 557, 556, 555, 554, 553, 552, 551, 550, 549, 548, 547, 547, 546, 545, 544, 543, 542, 541, 540, 540, 539, 538, 537, 536, 536, 535, 534, 533, 532, 532, 531, 530, 529, 528, 528, 527, 526, 525, 525, 524, 523, 522, 522, 521, 520, 520, 519}
+	Bls12381G2MultiExpDiscountTable = [128]uint64{1000, 1000, 923, 884, 855, 832, 812, 796, 782, 770, 759, 749, 740, 732, 724, 717, 711, 704, 699, 693, 688, 683, 679, 674, 670, 666, 663, 659, 655, 652, 649, 646, 643, 640, 637, 634, 632, 629, 627, 624, 622, 620, 618, 615, 613, 611, 609, 607, 606, 604, 602, 600, 598, 597, 595, 593, 592, 590, 589, 587, 586, 584, 583, 582, 580, 579, 578, 576, 575, 574, 573, 571, 570, 569, 568, 567, 566, 565, 563, 562, 561, 560, 559, 558, 557, 556, 555, 554, 553, 552, 552, 551, 550, 549, 548, 547, 546, 545, 545, 544, 543, 542, 541, 541, 540, 539, 538, 537, 537, 536, 535, 535, 534, 533, 532, 532, 531, 530, 530, 529, 528, 528, 527, 526, 526, 525, 524, 524}
+)
+
+// The history storage contract (EIP-2935) keeps the parent block hashes in a ring buffer
+// of HistoryServeWindow slots. The code is the canonical bytecode defined in the EIP.
+var (
+	HistoryStorageAddress = common.HexToAddress("0x0000F90827F1C53a10cb7A02335B175320002935")
+	HistoryStorageCode    = common.FromHex("3373fffffffffffffffffffffffffffffffffffffffe14604657602036036042575f35600143038111604257611fff81430311604257611fff9006545f5260205ff35b5f5ffd5b5f35611fff60014303065500")
+)
+
 // Parameters for execution time limit
 // These parameters will be re-assigned by init options
 var (
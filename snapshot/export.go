@@ -0,0 +1,475 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+const (
+	// ExportManifestName is the name of the manifest file of an export.
+	ExportManifestName = "manifest.json"
+
+	// DefaultExportChunkSize is the default size limit of an export chunk file.
+	DefaultExportChunkSize = 256 * 1024 * 1024
+
+	exportVersion = 1
+)
+
+// Kinds of the records of an export chunk.
+const (
+	exportAccount uint8 = iota
+	exportStorage
+	exportCode
+)
+
+// ExportManifest describes the chunk files of a state export.
+type ExportManifest struct {
+	Version  uint          `json:"version"`
+	Root     common.Hash   `json:"root"`
+	Header   hexutil.Bytes `json:"header"` // RLP of the header of the exported state
+	Accounts uint64        `json:"accounts"`
+	Slots    uint64        `json:"slots"`
+	Codes    uint64        `json:"codes"`
+	Chunks   []ExportChunk `json:"chunks"`
+}
+
+// ExportChunk is a chunk file of a state export and its sha256 checksum.
+type ExportChunk struct {
+	Name     string `json:"name"`
+	Size     uint64 `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// exportRecord is an entry of an export chunk. The storage slots of an account
+// follow the account, and the code of a contract follows its storage the first
+// time it is exported.
+type exportRecord struct {
+	Kind  uint8
+	Key   common.Hash
+	Value []byte
+}
+
+// chunkWriter writes the records of an export into chunk files of a limited
+// size. A record is never split across chunks.
+type chunkWriter struct {
+	dir   string
+	limit uint64
+
+	file   *os.File
+	buf    *bufio.Writer
+	sha    hash.Hash
+	size   uint64
+	chunks []ExportChunk
+}
+
+func (w *chunkWriter) write(rec *exportRecord) error {
+	if w.file == nil {
+		name := fmt.Sprintf("snapshot-%05d.rlp", len(w.chunks))
+		file, err := os.Create(filepath.Join(w.dir, name))
+		if err != nil {
+			return err
+		}
+		w.file, w.sha, w.size = file, sha256.New(), 0
+		w.buf = bufio.NewWriter(io.MultiWriter(file, w.sha))
+	}
+	enc, err := rlp.EncodeToBytes(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := w.buf.Write(enc); err != nil {
+		return err
+	}
+	w.size += uint64(len(enc))
+	if w.size >= w.limit {
+		return w.close()
+	}
+	return nil
+}
+
+// close finishes the current chunk file, if any.
+func (w *chunkWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.chunks = append(w.chunks, ExportChunk{
+		Name:     filepath.Base(w.file.Name()),
+		Size:     w.size,
+		Checksum: hex.EncodeToString(w.sha.Sum(nil)),
+	})
+	w.file = nil
+	return nil
+}
+
+// Export writes the accounts, storage slots and codes of the state of the given
+// header into chunk files of about chunkSize bytes in dir, and a manifest listing
+// them with their checksums.
+func (t *Tree) Export(header *types.Header, dir string, chunkSize uint64) (*ExportManifest, error) {
+	if chunkSize == 0 {
+		chunkSize = DefaultExportChunkSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		root     = header.Root
+		manifest = &ExportManifest{Version: exportVersion, Root: root, Header: enc}
+		w        = &chunkWriter{dir: dir, limit: chunkSize}
+		codes    = make(map[common.Hash]struct{})
+		start    = time.Now()
+		logged   = time.Now()
+	)
+	defer func() {
+		if w.file != nil {
+			w.file.Close()
+		}
+	}()
+	accIt, err := t.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return nil, err
+	}
+	defer accIt.Release()
+
+	for accIt.Next() {
+		accHash := accIt.Hash()
+		data := account.UnextendSerializedAccount(accIt.Account())
+		if err := w.write(&exportRecord{Kind: exportAccount, Key: accHash, Value: data}); err != nil {
+			return nil, err
+		}
+		manifest.Accounts++
+
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(data, serializer); err != nil {
+			return nil, fmt.Errorf("invalid account %x: %v", accHash, err)
+		}
+		if pa := account.GetProgramAccount(serializer.GetAccount()); pa != nil {
+			slots, err := t.exportStorage(w, root, accHash)
+			if err != nil {
+				return nil, err
+			}
+			manifest.Slots += slots
+
+			codeHash := common.BytesToHash(pa.GetCodeHash())
+			if _, ok := codes[codeHash]; !ok && codeHash != emptyCode {
+				code := t.diskdb.ReadCode(codeHash)
+				if len(code) == 0 {
+					return nil, fmt.Errorf("missing code %x of account %x", codeHash, accHash)
+				}
+				if err := w.write(&exportRecord{Kind: exportCode, Key: codeHash, Value: code}); err != nil {
+					return nil, err
+				}
+				codes[codeHash] = struct{}{}
+				manifest.Codes++
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			logger.Info("Exporting snapshot", "at", accHash, "accounts", manifest.Accounts, "slots", manifest.Slots,
+				"chunks", len(w.chunks), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return nil, err
+	}
+	if err := w.close(); err != nil {
+		return nil, err
+	}
+	manifest.Chunks = w.chunks
+
+	if err := writeExportManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	logger.Info("Exported snapshot", "root", root, "accounts", manifest.Accounts, "slots", manifest.Slots,
+		"codes", manifest.Codes, "chunks", len(manifest.Chunks), "elapsed", common.PrettyDuration(time.Since(start)))
+	return manifest, nil
+}
+
+func (t *Tree) exportStorage(w *chunkWriter, root, accHash common.Hash) (uint64, error) {
+	it, err := t.StorageIterator(root, accHash, common.Hash{})
+	if err != nil {
+		return 0, err
+	}
+	defer it.Release()
+
+	var slots uint64
+	for it.Next() {
+		if err := w.write(&exportRecord{Kind: exportStorage, Key: it.Hash(), Value: it.Slot()}); err != nil {
+			return 0, err
+		}
+		slots++
+	}
+	return slots, it.Error()
+}
+
+func writeExportManifest(dir string, manifest *ExportManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ExportManifestName), data, 0o644)
+}
+
+// ReadExportManifest reads the manifest of the export in dir.
+func ReadExportManifest(dir string) (*ExportManifest, *types.Header, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ExportManifestName))
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest := new(ExportManifest)
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	if manifest.Version != exportVersion {
+		return nil, nil, fmt.Errorf("unsupported export version %d", manifest.Version)
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(manifest.Header, header); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest header: %v", err)
+	}
+	if header.Root != manifest.Root {
+		return nil, nil, fmt.Errorf("manifest root %x does not match header root %x", manifest.Root, header.Root)
+	}
+	return manifest, header, nil
+}
+
+// verifyExportChunks checks the size and the checksum of every chunk file, so
+// that nothing is written from a corrupted export.
+func verifyExportChunks(dir string, manifest *ExportManifest) error {
+	for _, chunk := range manifest.Chunks {
+		if filepath.Base(chunk.Name) != chunk.Name {
+			return fmt.Errorf("invalid chunk name %q", chunk.Name)
+		}
+		file, err := os.Open(filepath.Join(dir, chunk.Name))
+		if err != nil {
+			return err
+		}
+		sha := sha256.New()
+		size, err := io.Copy(sha, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		if uint64(size) != chunk.Size {
+			return fmt.Errorf("chunk %s size mismatch: want %d, have %d", chunk.Name, chunk.Size, size)
+		}
+		if have := hex.EncodeToString(sha.Sum(nil)); have != chunk.Checksum {
+			return fmt.Errorf("chunk %s checksum mismatch: want %s, have %s", chunk.Name, chunk.Checksum, have)
+		}
+	}
+	return nil
+}
+
+// stateImporter rebuilds the tries of an export with stack tries.
+type stateImporter struct {
+	db       database.DBManager
+	accounts *statedb.StackTrie
+
+	lastAccount common.Hash
+	hasAccount  bool
+
+	// The contract whose storage is being imported
+	storage     *statedb.StackTrie
+	storageRoot common.Hash
+	lastSlot    common.Hash
+	hasSlot     bool
+
+	codes    map[common.Hash]bool // Referenced codes, true if imported
+	accCount uint64
+	slots    uint64
+}
+
+func (imp *stateImporter) process(rec *exportRecord) error {
+	switch rec.Kind {
+	case exportAccount:
+		if imp.hasAccount && bytes.Compare(rec.Key[:], imp.lastAccount[:]) <= 0 {
+			return fmt.Errorf("account %x out of order", rec.Key)
+		}
+		if err := imp.finishStorage(); err != nil {
+			return err
+		}
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(rec.Value, serializer); err != nil {
+			return fmt.Errorf("invalid account %x: %v", rec.Key, err)
+		}
+		if err := imp.accounts.TryUpdate(rec.Key[:], rec.Value); err != nil {
+			return err
+		}
+		imp.lastAccount, imp.hasAccount = rec.Key, true
+		imp.accCount++
+
+		if pa := account.GetProgramAccount(serializer.GetAccount()); pa != nil {
+			imp.storage = statedb.NewStackTrie(imp.db)
+			imp.storageRoot = pa.GetStorageRoot().Unextend()
+			imp.hasSlot = false
+
+			codeHash := common.BytesToHash(pa.GetCodeHash())
+			if _, ok := imp.codes[codeHash]; !ok && codeHash != emptyCode {
+				imp.codes[codeHash] = false
+			}
+		}
+
+	case exportStorage:
+		if imp.storage == nil {
+			return fmt.Errorf("slot %x without contract", rec.Key)
+		}
+		if imp.hasSlot && bytes.Compare(rec.Key[:], imp.lastSlot[:]) <= 0 {
+			return fmt.Errorf("slot %x of account %x out of order", rec.Key, imp.lastAccount)
+		}
+		if err := imp.storage.TryUpdate(rec.Key[:], rec.Value); err != nil {
+			return err
+		}
+		imp.lastSlot, imp.hasSlot = rec.Key, true
+		imp.slots++
+
+	case exportCode:
+		if crypto.Keccak256Hash(rec.Value) != rec.Key {
+			return fmt.Errorf("code %x hash mismatch", rec.Key)
+		}
+		imp.db.WriteCode(rec.Key, rec.Value)
+		imp.codes[rec.Key] = true
+
+	default:
+		return fmt.Errorf("unknown record kind %d", rec.Kind)
+	}
+	return nil
+}
+
+// finishStorage commits the storage trie of the last contract and checks its
+// root against the one of the account.
+func (imp *stateImporter) finishStorage() error {
+	if imp.storage == nil {
+		return nil
+	}
+	root := emptyRoot
+	if imp.hasSlot {
+		var err error
+		if root, err = imp.storage.Commit(); err != nil {
+			return err
+		}
+	}
+	if root != imp.storageRoot {
+		return fmt.Errorf("storage root of account %x mismatch: want %x, have %x", imp.lastAccount, imp.storageRoot, root)
+	}
+	imp.storage = nil
+	return nil
+}
+
+// Import rebuilds the state tries of the export in dir into the state trie
+// database of db, and returns the header of the exported state. The root of the
+// rebuilt tries is verified against the header.
+func Import(db database.DBManager, dir string) (*types.Header, error) {
+	if db.ReadTrieNodeScheme() == statedb.PathScheme {
+		return nil, errors.New("snapshot import is not supported by the path trie node scheme")
+	}
+	manifest, header, err := ReadExportManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyExportChunks(dir, manifest); err != nil {
+		return nil, err
+	}
+	var (
+		imp = &stateImporter{
+			db:       db,
+			accounts: statedb.NewStackTrie(db),
+			codes:    make(map[common.Hash]bool),
+		}
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for _, chunk := range manifest.Chunks {
+		file, err := os.Open(filepath.Join(dir, chunk.Name))
+		if err != nil {
+			return nil, err
+		}
+		stream := rlp.NewStream(bufio.NewReader(file), 0)
+		for {
+			rec := new(exportRecord)
+			if err := stream.Decode(rec); err == io.EOF {
+				break
+			} else if err != nil {
+				file.Close()
+				return nil, fmt.Errorf("invalid chunk %s: %v", chunk.Name, err)
+			}
+			if err := imp.process(rec); err != nil {
+				file.Close()
+				return nil, err
+			}
+		}
+		file.Close()
+
+		if time.Since(logged) > 8*time.Second {
+			logger.Info("Importing snapshot", "chunk", chunk.Name, "accounts", imp.accCount, "slots", imp.slots,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := imp.finishStorage(); err != nil {
+		return nil, err
+	}
+	for codeHash, imported := range imp.codes {
+		if !imported && !db.HasCode(codeHash) {
+			return nil, fmt.Errorf("missing code %x", codeHash)
+		}
+	}
+	root := emptyRoot
+	if imp.hasAccount {
+		if root, err = imp.accounts.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	if root != header.Root {
+		return nil, fmt.Errorf("state root mismatch: want %x, have %x", header.Root, root)
+	}
+	if imp.accCount != manifest.Accounts || imp.slots != manifest.Slots {
+		return nil, fmt.Errorf("record count mismatch: want %d accounts and %d slots, have %d and %d",
+			manifest.Accounts, manifest.Slots, imp.accCount, imp.slots)
+	}
+	logger.Info("Imported snapshot", "root", root, "number", header.Number, "accounts", imp.accCount, "slots", imp.slots,
+		"codes", len(imp.codes), "elapsed", common.PrettyDuration(time.Since(start)))
+	return header, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	var (
+		dbm    = database.NewMemoryDBManager()
+		triedb = statedb.NewDatabase(dbm)
+		code   = []byte{0x60, 0x01, 0x60, 0x00, 0x55}
+	)
+	codeHash := crypto.Keccak256Hash(code)
+	dbm.WriteCode(codeHash, code)
+
+	stTrie, _ := statedb.NewSecureTrie(common.Hash{}, triedb, nil)
+	for i := 0; i < 20; i++ {
+		stTrie.Update([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("val-%d", i)))
+	}
+	stTrie.Commit(nil)
+
+	accTrie, _ := statedb.NewSecureTrie(common.Hash{}, triedb, nil)
+	for i := 0; i < 30; i++ {
+		var acc account.Account
+		switch i % 3 {
+		case 0:
+			acc, _ = genSmartContractAccount(1, big.NewInt(int64(i)), stTrie.Hash(), codeHash.Bytes())
+		case 1:
+			acc, _ = genSmartContractAccount(1, big.NewInt(int64(i)), emptyRoot, emptyCode.Bytes())
+		default:
+			acc, _ = genExternallyOwnedAccount(uint64(i), big.NewInt(int64(i)))
+		}
+		val, _ := rlp.EncodeToBytes(account.NewAccountSerializerWithAccount(acc))
+		accTrie.Update([]byte(fmt.Sprintf("acc-%d", i)), val)
+	}
+	root, _ := accTrie.Commit(nil)
+	triedb.Commit(root, false, 0)
+
+	snaps, err := New(dbm, triedb, 16, root, false, true, false)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	header := &types.Header{Number: big.NewInt(7), Root: root, BlockScore: big.NewInt(1), Time: big.NewInt(0)}
+	manifest, err := snaps.Export(header, dir, 512)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(30), manifest.Accounts)
+	assert.Equal(t, uint64(200), manifest.Slots)
+	assert.Equal(t, uint64(1), manifest.Codes)
+	assert.Greater(t, len(manifest.Chunks), 1)
+
+	// Rebuild the state in an empty database
+	newdbm := database.NewMemoryDBManager()
+	imported, err := Import(newdbm, dir)
+	require.NoError(t, err)
+	assert.Equal(t, header.Hash(), imported.Hash())
+	assert.Equal(t, code, newdbm.ReadCode(codeHash))
+
+	newTrie, err := statedb.NewSecureTrie(root, statedb.NewDatabase(newdbm), nil)
+	require.NoError(t, err)
+	for i := 0; i < 30; i++ {
+		key := []byte(fmt.Sprintf("acc-%d", i))
+		assert.Equal(t, accTrie.Get(key), newTrie.Get(key))
+	}
+	newStTrie, err := statedb.NewSecureTrie(stTrie.Hash(), statedb.NewDatabase(newdbm), nil)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		assert.Equal(t, stTrie.Get(key), newStTrie.Get(key))
+	}
+
+	// A corrupted chunk is rejected before anything is written
+	chunk := filepath.Join(dir, manifest.Chunks[1].Name)
+	data, err := os.ReadFile(chunk)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(chunk, data, 0o644))
+
+	emptydbm := database.NewMemoryDBManager()
+	_, err = Import(emptydbm, dir)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.Nil(t, emptydbm.ReadCode(codeHash))
+}
@@ -311,6 +311,26 @@ func (t *Tree) Snapshot(blockRoot common.Hash) Snapshot {
 	return t.layers[blockRoot]
 }
 
+// LayerDiff returns the changes made by the diff layer of the given root on top
+// of the layer of the given parent root: the destructed accounts, and the
+// accounts and storage slots set, keyed by hash, nil meaning deleted. The maps
+// must not be modified. It returns false if there is no such diff layer.
+func (t *Tree) LayerDiff(root, parent common.Hash) (map[common.Hash]struct{}, map[common.Hash][]byte, map[common.Hash]map[common.Hash][]byte, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	dl, ok := t.layers[root].(*diffLayer)
+	if !ok || dl.Stale() {
+		return nil, nil, nil, false
+	}
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+	if dl.parent.Root() != parent {
+		return nil, nil, nil, false
+	}
+	return dl.destructSet, dl.accountData, dl.storageData, true
+}
+
 // Snapshots returns all visited layers from the topmost layer with specific
 // root and traverses downward. The layer amount is limited by the given number.
 // If nodisk is set, then disk layer is excluded.
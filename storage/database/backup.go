@@ -0,0 +1,263 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/klaytn/klaytn/common"
+)
+
+// BackupManifestFile is the name of the manifest file in a backup directory.
+const BackupManifestFile = "MANIFEST.json"
+
+// backupManifestVersion is the version of the backup layout.
+const backupManifestVersion = 1
+
+// BackupPartition describes a database partition stored in a backup.
+type BackupPartition struct {
+	Name   string           `json:"name"`
+	Dir    string           `json:"dir"` // Directory relative to the backup directory
+	Format CheckpointFormat `json:"format"`
+	Shards uint             `json:"shards,omitempty"`
+}
+
+// BackupManifest describes the content of a backup directory. The backup
+// directory has the layout of the chaindata directory it was taken from.
+type BackupManifest struct {
+	Version            int               `json:"version"`
+	Time               time.Time         `json:"time"`
+	DBType             DBType            `json:"dbType"`
+	SingleDB           bool              `json:"singleDB"`
+	NumStateTrieShards uint              `json:"numStateTrieShards"`
+	Partitions         []BackupPartition `json:"partitions"`
+
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	StateRoot   common.Hash `json:"stateRoot"`
+}
+
+type partitionCheckpoint struct {
+	BackupPartition
+	cp checkpoint
+}
+
+// DBCheckpoint is a consistent point-in-time view of every database partition
+// of a DBManager. It is written by Write and must be released by Release.
+type DBCheckpoint struct {
+	config     *DBConfig
+	partitions []partitionCheckpoint
+}
+
+// NewCheckpoint takes a checkpoint of every database partition. Taking the
+// checkpoints is cheap, so the caller can block writes meanwhile to get a view
+// consistent across the partitions.
+func (dbm *databaseManager) NewCheckpoint() (*DBCheckpoint, error) {
+	c := &DBCheckpoint{config: dbm.config}
+	add := func(name, dir string, db Database) error {
+		cp, err := newCheckpoint(db)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		partition := partitionCheckpoint{BackupPartition{Name: name, Dir: dir, Format: cp.Format()}, cp}
+		if sharded, ok := db.(*shardedDB); ok {
+			partition.Shards = sharded.numShards
+		}
+		c.partitions = append(c.partitions, partition)
+		return nil
+	}
+	if dbm.config.SingleDB || dbm.config.DBType == MemoryDB {
+		if err := add("single", ".", dbm.dbs[0]); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	for et := MiscDB; et < databaseEntryTypeSize; et++ {
+		db := dbm.dbs[et]
+		if db == nil {
+			continue
+		}
+		dir := dbBaseDirs[MiscDB]
+		if et != MiscDB {
+			dir = dbm.getDBDir(et)
+		}
+		if err := add(dbBaseDirs[et], dir, db); err != nil {
+			c.Release()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Write writes the checkpoints into the given directory, which must not exist,
+// and returns the manifest describing them. The manifest is not written, so
+// that the caller can complete it.
+func (c *DBCheckpoint) Write(dir string) (*BackupManifest, error) {
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("backup directory %s already exists", dir)
+	}
+	manifest := &BackupManifest{
+		Version:            backupManifestVersion,
+		Time:               time.Now().UTC(),
+		DBType:             c.config.DBType,
+		SingleDB:           c.config.SingleDB,
+		NumStateTrieShards: c.config.NumStateTrieShards,
+	}
+	for _, partition := range c.partitions {
+		start := time.Now()
+		if err := partition.cp.Write(filepath.Join(dir, partition.Dir)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %v", partition.Name, err)
+		}
+		logger.Info("Wrote database checkpoint", "partition", partition.Name, "format", partition.Format, "elapsed", common.PrettyDuration(time.Since(start)))
+		manifest.Partitions = append(manifest.Partitions, partition.BackupPartition)
+	}
+	return manifest, nil
+}
+
+// Release releases the resources held by the checkpoints.
+func (c *DBCheckpoint) Release() {
+	for _, partition := range c.partitions {
+		partition.cp.Release()
+	}
+}
+
+// WriteBackupManifest writes the manifest into the given backup directory.
+func WriteBackupManifest(dir string, manifest *BackupManifest) error {
+	blob, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, BackupManifestFile), blob, 0o644)
+}
+
+// ReadBackupManifest reads the manifest of the given backup directory.
+func ReadBackupManifest(dir string) (*BackupManifest, error) {
+	blob, err := os.ReadFile(filepath.Join(dir, BackupManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(BackupManifest)
+	if err := json.Unmarshal(blob, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != backupManifestVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// RestoreBackup restores the backup stored in backupDir into the chaindata
+// directory chainDataDir, which must not exist or be empty.
+func RestoreBackup(backupDir, chainDataDir string) (*BackupManifest, error) {
+	manifest, err := ReadBackupManifest(backupDir)
+	if err != nil {
+		return nil, err
+	}
+	if entries, err := os.ReadDir(chainDataDir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("chaindata directory %s is not empty", chainDataDir)
+	}
+	for _, partition := range manifest.Partitions {
+		src, dst := filepath.Join(backupDir, partition.Dir), filepath.Join(chainDataDir, partition.Dir)
+		if partition.Shards == 0 {
+			err = restorePartition(partition.Format, src, dst)
+		} else {
+			for i := 0; i < int(partition.Shards) && err == nil; i++ {
+				err = restorePartition(partition.Format, filepath.Join(src, strconv.Itoa(i)), filepath.Join(dst, strconv.Itoa(i)))
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %v", partition.Name, err)
+		}
+		logger.Info("Restored database partition", "partition", partition.Name, "dir", dst)
+	}
+	return manifest, nil
+}
+
+func restorePartition(format CheckpointFormat, src, dst string) error {
+	switch format {
+	case CheckpointLevelDB, CheckpointRocksDB:
+		return copyDir(src, dst)
+	case CheckpointBadger:
+		f, err := os.Open(filepath.Join(src, badgerBackupFile))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := os.MkdirAll(dst, 0o755); err != nil {
+			return err
+		}
+		db, err := badger.Open(getBadgerDBOptions(dst))
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return db.Load(f, 256)
+	default:
+		return fmt.Errorf("unknown checkpoint format %q", format)
+	}
+}
+
+// copyDir copies the regular files of the directory src into dst recursively.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == BackupManifestFile {
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !info.Mode().IsRegular() {
+			return errors.New("not a regular file: " + path)
+		}
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRestore(t *testing.T) {
+	configs := []*DBConfig{
+		{DBType: LevelDB, SingleDB: false, NumStateTrieShards: 1},
+		{DBType: LevelDB, SingleDB: false, NumStateTrieShards: 4},
+		{DBType: LevelDB, SingleDB: true, NumStateTrieShards: 1},
+		{DBType: BadgerDB, SingleDB: false, NumStateTrieShards: 2},
+	}
+	for _, config := range configs {
+		testBackupRestore(t, config)
+	}
+}
+
+func testBackupRestore(t *testing.T, config *DBConfig) {
+	dir, err := os.MkdirTemp("", "klaytn-db-backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := *config
+	src.Dir = filepath.Join(dir, "src")
+	dbm := NewDBManager(&src)
+
+	hash, code := common.Hash{0x1}, []byte{0x2}
+	dbm.WriteCanonicalHash(hash, 1)
+	dbm.WriteCode(hash, code)
+
+	cp, err := dbm.NewCheckpoint()
+	require.NoError(t, err)
+
+	// Writes after the checkpoint must not be part of the backup.
+	dbm.WriteCanonicalHash(common.Hash{0x3}, 2)
+
+	backup := filepath.Join(dir, "backup")
+	manifest, err := cp.Write(backup)
+	require.NoError(t, err)
+	cp.Release()
+	dbm.Close()
+	require.NoError(t, WriteBackupManifest(backup, manifest))
+
+	// Restoring into a non-empty directory must fail.
+	_, err = RestoreBackup(backup, src.Dir)
+	assert.Error(t, err)
+
+	dst := *config
+	dst.Dir = filepath.Join(dir, "dst")
+	restored, err := RestoreBackup(backup, dst.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, config.DBType, restored.DBType)
+	assert.Equal(t, config.SingleDB, restored.SingleDB)
+
+	dbm = NewDBManager(&dst)
+	defer dbm.Close()
+	assert.Equal(t, hash, dbm.ReadCanonicalHash(1))
+	assert.Equal(t, code, dbm.ReadCode(hash))
+	assert.Equal(t, common.Hash{}, dbm.ReadCanonicalHash(2))
+}
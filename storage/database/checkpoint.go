@@ -0,0 +1,258 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dgraph-io/badger"
+	"github.com/dgraph-io/badger/pb"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// CheckpointFormat is the on-disk format of a database checkpoint.
+type CheckpointFormat string
+
+const (
+	// CheckpointLevelDB is a LevelDB directory.
+	CheckpointLevelDB CheckpointFormat = "leveldb"
+	// CheckpointRocksDB is a directory made by the RocksDB checkpoint facility.
+	CheckpointRocksDB CheckpointFormat = "rocksdb"
+	// CheckpointBadger is a file in the badger backup format.
+	CheckpointBadger CheckpointFormat = "badger"
+)
+
+// badgerBackupFile is the name of the badger backup file in a checkpoint directory.
+const badgerBackupFile = "badger.bak"
+
+// checkpoint is a consistent point-in-time view of a database. Taking a
+// checkpoint is cheap, so that it can be done while writes are blocked, and
+// the content is written out afterwards while the database moves on.
+type checkpoint interface {
+	// Format returns the on-disk format written by Write.
+	Format() CheckpointFormat
+
+	// Write writes the content of the checkpoint into the given directory.
+	Write(dir string) error
+
+	// Release releases the resources held by the checkpoint.
+	Release()
+}
+
+// checkpointer is implemented by the databases able to take a checkpoint.
+type checkpointer interface {
+	newCheckpoint() (checkpoint, error)
+}
+
+// newCheckpoint takes a checkpoint of the given database.
+func newCheckpoint(db Database) (checkpoint, error) {
+	cp, ok := db.(checkpointer)
+	if !ok {
+		return nil, fmt.Errorf("checkpoint is not supported by %s", db.Type())
+	}
+	return cp.newCheckpoint()
+}
+
+// ldbCheckpoint is a checkpoint of a LevelDB database, based on a LevelDB snapshot.
+type ldbCheckpoint struct {
+	snap *leveldb.Snapshot
+}
+
+func (db *levelDB) newCheckpoint() (checkpoint, error) {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &ldbCheckpoint{snap: snap}, nil
+}
+
+func (c *ldbCheckpoint) Format() CheckpointFormat { return CheckpointLevelDB }
+
+func (c *ldbCheckpoint) Write(dir string) error {
+	it := c.snap.NewIterator(nil, nil)
+	defer it.Release()
+	return writeLevelDB(dir, it)
+}
+
+func (c *ldbCheckpoint) Release() { c.snap.Release() }
+
+// memCheckpoint is a checkpoint of a memory database, written as a LevelDB database.
+type memCheckpoint struct {
+	db *MemDB
+}
+
+func (db *MemDB) newCheckpoint() (checkpoint, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	copied := NewMemDBWithCap(len(db.db))
+	for key, value := range db.db {
+		copied.db[key] = value
+	}
+	return &memCheckpoint{db: copied}, nil
+}
+
+func (c *memCheckpoint) Format() CheckpointFormat { return CheckpointLevelDB }
+
+func (c *memCheckpoint) Write(dir string) error {
+	it := c.db.NewIterator(nil, nil)
+	defer it.Release()
+	return writeLevelDB(dir, it)
+}
+
+func (c *memCheckpoint) Release() {}
+
+// writeLevelDB writes the entries of the given iterator into a new LevelDB database.
+func writeLevelDB(dir string, it Iterator) error {
+	db, err := leveldb.OpenFile(dir, &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	batch := new(leveldb.Batch)
+	for it.Next() {
+		batch.Put(it.Key(), it.Value())
+		if len(batch.Dump()) >= IdealBatchSize {
+			if err := db.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return db.Write(batch, nil)
+}
+
+// badgerCheckpoint is a checkpoint of a badger database, based on a read
+// transaction pinned at the time of the checkpoint. It is written in the badger
+// backup format, which is restored by badger.DB.Load.
+type badgerCheckpoint struct {
+	txn *badger.Txn
+}
+
+func (bg *badgerDB) newCheckpoint() (checkpoint, error) {
+	return &badgerCheckpoint{txn: bg.db.NewTransaction(false)}, nil
+}
+
+func (c *badgerCheckpoint) Format() CheckpointFormat { return CheckpointBadger }
+
+func (c *badgerCheckpoint) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, badgerBackupFile), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	it := c.txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	list := new(pb.KVList)
+	flush := func() error {
+		if len(list.Kv) == 0 {
+			return nil
+		}
+		blob, err := list.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint64(len(blob))); err != nil {
+			return err
+		}
+		if _, err := w.Write(blob); err != nil {
+			return err
+		}
+		list.Kv = list.Kv[:0]
+		return nil
+	}
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		list.Kv = append(list.Kv, &pb.KV{
+			Key:       item.KeyCopy(nil),
+			Value:     value,
+			UserMeta:  []byte{item.UserMeta()},
+			Version:   item.Version(),
+			ExpiresAt: item.ExpiresAt(),
+		})
+		if len(list.Kv) == 1000 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func (c *badgerCheckpoint) Release() { c.txn.Discard() }
+
+// shardedCheckpoint is a checkpoint of a sharded database. The shard i is
+// written into the sub-directory i, as the sharded database lays them out.
+type shardedCheckpoint struct {
+	shards []checkpoint
+}
+
+func (db *shardedDB) newCheckpoint() (checkpoint, error) {
+	c := &shardedCheckpoint{shards: make([]checkpoint, 0, len(db.shards))}
+	for _, shard := range db.shards {
+		cp, err := newCheckpoint(shard)
+		if err != nil {
+			c.Release()
+			return nil, err
+		}
+		c.shards = append(c.shards, cp)
+	}
+	return c, nil
+}
+
+func (c *shardedCheckpoint) Format() CheckpointFormat { return c.shards[0].Format() }
+
+func (c *shardedCheckpoint) Write(dir string) error {
+	for i, shard := range c.shards {
+		if err := shard.Write(filepath.Join(dir, strconv.Itoa(i))); err != nil {
+			return fmt.Errorf("shard %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (c *shardedCheckpoint) Release() {
+	for _, shard := range c.shards {
+		shard.Release()
+	}
+}
@@ -359,7 +359,12 @@ func TestDBEntryLengthCheck(t *testing.T) {
 			t.Fatalf("Database directory should be specified! index: %v", i)
 		}
 
-		if dbConfigRatio[i] == 0 {
+		// The optional state history partition is sized on top of the others.
+		ratio := dbConfigRatio[i]
+		if DBEntryType(i) == StateHistoryDB {
+			ratio = stateHistoryDBConfigRatio
+		}
+		if ratio == 0 {
 			t.Fatalf("Database configuration ratio should be specified! index: %v", i)
 		}
 
@@ -63,6 +63,7 @@ type DBManager interface {
 	GetMemDB() *MemDB
 	GetDBConfig() *DBConfig
 	getDatabase(DBEntryType) Database
+	NewCheckpoint() (*DBCheckpoint, error)
 	CreateMigrationDBAndSetStatus(blockNum uint64) error
 	FinishStateMigration(succeed bool) chan struct{}
 	GetStateTrieDB() Database
@@ -168,6 +169,7 @@ type DBManager interface {
 	PutTrieNodeToBatch(batch Batch, hash common.ExtHash, node []byte)
 	DeleteTrieNode(hash common.ExtHash)
 	WritePreimages(number uint64, preimages map[common.Hash][]byte)
+	NewPreimageIterator() Iterator
 
 	// Trie pruning
 	ReadPruningEnabled() bool
@@ -181,6 +183,33 @@ type DBManager interface {
 	WriteLastPrunedBlockNumber(blockNumber uint64)
 	ReadLastPrunedBlockNumber() (uint64, error)
 
+	// Path-based trie node scheme
+	ReadTrieNodeScheme() string
+	WriteTrieNodeScheme(scheme string)
+
+	ReadPathTrieNode(owner common.Hash, path []byte) []byte
+	PutPathTrieNodeToBatch(batch Batch, owner common.Hash, path []byte, node []byte)
+	ReadPathTrieHistory(number uint64, owner common.Hash, path []byte) ([]byte, bool)
+	PutPathTrieHistoryToBatch(batch Batch, number uint64, owner common.Hash, path []byte, node []byte)
+	DeletePathTrieHistoryFromBatch(batch Batch, number uint64, owner common.Hash, path []byte)
+	ReadPathTrieDiff(number uint64) *PathTrieDiff
+	PutPathTrieDiffToBatch(batch Batch, number uint64, diff *PathTrieDiff)
+	DeletePathTrieDiffFromBatch(batch Batch, number uint64)
+	ReadPathTrieHead() *PathTrieHead
+	PutPathTrieHeadToBatch(batch Batch, head *PathTrieHead)
+
+	// State history index
+	ReadStateHistoryEnabled() bool
+	WriteStateHistoryEnabled()
+	ReadStateHistoryDiff(number uint64, hash common.Hash) *StateHistoryDiff
+	WriteStateHistoryDiff(number uint64, hash common.Hash, diff *StateHistoryDiff)
+	IndexStateHistory(number uint64, hash common.Hash) error
+	ReadStateHistoryIndex(number uint64) common.Hash
+	ReadStateHistoryTail() (uint64, bool)
+	ReadStateHistoryAccount(accountHash common.Hash, number uint64) (data []byte, changed uint64, ok bool)
+	ReadStateHistoryStorage(accountHash, storageHash common.Hash, number uint64) (value []byte, changed uint64, ok bool)
+	ReadStateHistoryDestruct(accountHash common.Hash, number uint64) (destructed uint64, ok bool)
+
 	// from accessors_indexes.go
 	ReadTxLookupEntry(hash common.Hash) (common.Hash, uint64, uint64)
 	WriteTxLookupEntries(block *types.Block)
@@ -211,6 +240,9 @@ type DBManager interface {
 	ReadChainConfig(hash common.Hash) *params.ChainConfig
 	WriteChainConfig(hash common.Hash, cfg *params.ChainConfig)
 
+	ReadPrecompileFingerprints(hash common.Hash) ([]*params.PrecompileFingerprint, bool)
+	WritePrecompileFingerprints(hash common.Hash, fingerprints []*params.PrecompileFingerprint)
+
 	// from accessors_snapshot.go
 	ReadSnapshotJournal() []byte
 	WriteSnapshotJournal(journal []byte)
@@ -300,6 +332,7 @@ type DBManager interface {
 
 	// DB migration related function
 	StartDBMigration(DBManager) error
+	Reshard(entries []DBEntryType, dbType DBType, numShards uint) error
 
 	// ChainDataFetcher checkpoint function
 	WriteChainDataFetcherCheckpoint(checkpoint uint64)
@@ -323,6 +356,7 @@ const (
 	TxLookUpEntryDB
 	bridgeServiceDB
 	SnapshotDB
+	StateHistoryDB
 	// databaseEntryTypeSize should be the last item in this list!!
 	databaseEntryTypeSize
 )
@@ -348,6 +382,16 @@ func (et DBEntryType) String() string {
 	return dbBaseDirs[et]
 }
 
+// ParseDBEntryType returns the DBEntryType whose base directory is the given name.
+func ParseDBEntryType(name string) (DBEntryType, error) {
+	for et := MiscDB; et < databaseEntryTypeSize; et++ {
+		if dbBaseDirs[et] == name {
+			return et, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown database partition %q", name)
+}
+
 const (
 	notInMigrationFlag = 0
 	inMigrationFlag    = 1
@@ -364,6 +408,7 @@ var dbBaseDirs = [databaseEntryTypeSize]string{
 	"txlookup",
 	"bridgeservice",
 	"snapshot",
+	"statehistory",
 }
 
 // Sum of dbConfigRatio should be 100.
@@ -378,8 +423,14 @@ var dbConfigRatio = [databaseEntryTypeSize]int{
 	2,  // TXLookUpEntryDB
 	1,  // bridgeServiceDB
 	3,  // SnapshotDB
+	0,  // StateHistoryDB, see stateHistoryDBConfigRatio
 }
 
+// stateHistoryDBConfigRatio is the ratio of StateHistoryDB. The partition is
+// optional, so it is only opened if the state history index is enabled, and
+// it is sized on top of the other partitions.
+const stateHistoryDBConfigRatio = 2
+
 // checkDBEntryConfigRatio checks if sum of dbConfigRatio is 100.
 // If it isn't, logger.Crit is called.
 func checkDBEntryConfigRatio() {
@@ -397,6 +448,9 @@ func checkDBEntryConfigRatio() {
 func getDBEntryConfig(originalDBC *DBConfig, i DBEntryType, dbDir string) *DBConfig {
 	newDBC := *originalDBC
 	ratio := dbConfigRatio[i]
+	if i == StateHistoryDB {
+		ratio = stateHistoryDBConfigRatio
+	}
 
 	newDBC.LevelDBCacheSize = originalDBC.LevelDBCacheSize * ratio / 100
 	newDBC.OpenFilesLimit = originalDBC.OpenFilesLimit * ratio / 100
@@ -528,6 +582,13 @@ func databaseDBManager(dbc *DBConfig) (*databaseManager, error) {
 			} else {
 				db, err = newDatabase(newDBC, entryType)
 			}
+		case StateHistoryDB:
+			if enabled, _ := miscDB.Has(stateHistoryEnabledKey); !enabled {
+				// If the state history index is not enabled, skip to set.
+				continue
+			}
+			newDBC := getDBEntryConfig(dbc, entryType, dir)
+			db, err = newDatabase(newDBC, entryType)
 		default:
 			newDBC := getDBEntryConfig(dbc, entryType, dir)
 			db, err = newDatabase(newDBC, entryType)
@@ -1968,6 +2029,11 @@ func (dbm *databaseManager) WritePreimages(number uint64, preimages map[common.H
 	preimageHitCounter.Inc(int64(len(preimages)))
 }
 
+// NewPreimageIterator returns an iterator over all preimages stored in the database.
+func (dbm *databaseManager) NewPreimageIterator() Iterator {
+	return dbm.getDatabase(StateTrieDB).NewIterator(preimagePrefix, nil)
+}
+
 // ReadPruningEnabled reads if the live pruning flag is stored in database.
 func (dbm *databaseManager) ReadPruningEnabled() bool {
 	ok, _ := dbm.getDatabase(MiscDB).Has(pruningEnabledKey)
@@ -2318,6 +2384,39 @@ func (dbm *databaseManager) WriteChainConfig(hash common.Hash, cfg *params.Chain
 	}
 }
 
+// ReadPrecompileFingerprints retrieves the custom precompiled contracts which the
+// chain of the given genesis hash has been executed with. It returns false if
+// they have not been recorded.
+func (dbm *databaseManager) ReadPrecompileFingerprints(hash common.Hash) ([]*params.PrecompileFingerprint, bool) {
+	db := dbm.getDatabase(MiscDB)
+	data, _ := db.Get(precompileFingerprintsKey(hash))
+	if len(data) == 0 {
+		return nil, false
+	}
+	var fingerprints []*params.PrecompileFingerprint
+	if err := json.Unmarshal(data, &fingerprints); err != nil {
+		logger.Error("Invalid precompile fingerprints JSON", "hash", hash, "err", err)
+		return nil, false
+	}
+	return fingerprints, true
+}
+
+// WritePrecompileFingerprints stores the custom precompiled contracts which the
+// chain of the given genesis hash is executed with.
+func (dbm *databaseManager) WritePrecompileFingerprints(hash common.Hash, fingerprints []*params.PrecompileFingerprint) {
+	db := dbm.getDatabase(MiscDB)
+	if fingerprints == nil {
+		fingerprints = []*params.PrecompileFingerprint{}
+	}
+	data, err := json.Marshal(fingerprints)
+	if err != nil {
+		logger.Crit("Failed to JSON encode precompile fingerprints", "err", err)
+	}
+	if err := db.Put(precompileFingerprintsKey(hash), data); err != nil {
+		logger.Crit("Failed to store precompile fingerprints", "err", err)
+	}
+}
+
 // ReadSnapshotJournal retrieves the serialized in-memory diff layers saved at
 // the last shutdown. The blob is expected to be max a few 10s of megabytes.
 func (dbm *databaseManager) ReadSnapshotJournal() []byte {
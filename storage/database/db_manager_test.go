@@ -714,6 +714,30 @@ func TestDBManager_ChainConfig(t *testing.T) {
 	}
 }
 
+// TestDBManager_PrecompileFingerprints tests read/write operations of the custom
+// precompiled contracts of a chain.
+func TestDBManager_PrecompileFingerprints(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
+	for _, dbm := range dbManagers {
+		_, ok := dbm.ReadPrecompileFingerprints(hash2)
+		assert.False(t, ok)
+
+		// No custom precompiled contract is recorded as well.
+		dbm.WritePrecompileFingerprints(hash2, nil)
+		fingerprints, ok := dbm.ReadPrecompileFingerprints(hash2)
+		assert.True(t, ok)
+		assert.Empty(t, fingerprints)
+
+		expected := []*params.PrecompileFingerprint{
+			{Name: "test", Address: common.HexToAddress("0x0300"), Block: big.NewInt(10), Version: "1"},
+		}
+		dbm.WritePrecompileFingerprints(hash2, expected)
+		fingerprints, ok = dbm.ReadPrecompileFingerprints(hash2)
+		assert.True(t, ok)
+		assert.Equal(t, expected, fingerprints)
+	}
+}
+
 // TestDBManager_Preimage tests read/write operations of preimages.
 func TestDBManager_Preimage(t *testing.T) {
 	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
@@ -1279,3 +1303,40 @@ func genRandomData() (common.Hash, []byte) {
 	data := common.MakeRandomBytes(100)
 	return hash, data
 }
+
+func TestDBManager_StateHistoryDB(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-db-statehistory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The sizing of the other partitions does not depend on the optional one.
+	sum := 0
+	for et := MiscDB; et < databaseEntryTypeSize; et++ {
+		if et != StateHistoryDB {
+			sum += dbConfigRatio[et]
+		}
+	}
+	assert.Equal(t, 100, sum)
+
+	dbc := &DBConfig{Dir: dir, DBType: LevelDB, SingleDB: false, NumStateTrieShards: 1, LevelDBCacheSize: 100, OpenFilesLimit: 100}
+	dbm := NewDBManager(dbc).(*databaseManager)
+	assert.Nil(t, dbm.dbs[StateHistoryDB])
+	assert.NoDirExists(t, dir+"/"+dbBaseDirs[StateHistoryDB])
+	assert.Nil(t, dbm.ReadStateHistoryDiff(1, common.Hash{1}))
+	_, ok := dbm.ReadStateHistoryTail()
+	assert.False(t, ok)
+
+	dbm.WriteStateHistoryEnabled()
+	assert.NotNil(t, dbm.dbs[StateHistoryDB])
+	dbm.WriteStateHistoryDiff(1, common.Hash{1}, &StateHistoryDiff{})
+	dbm.Close()
+
+	// The partition is opened with the others once the index is enabled.
+	dbm = NewDBManager(dbc).(*databaseManager)
+	defer dbm.Close()
+	assert.True(t, dbm.ReadStateHistoryEnabled())
+	assert.NotNil(t, dbm.dbs[StateHistoryDB])
+	assert.NotNil(t, dbm.ReadStateHistoryDiff(1, common.Hash{1}))
+}
@@ -17,12 +17,19 @@
 package database
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"os/signal"
 	"path"
+	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/log"
 	"github.com/pkg/errors"
 )
 
@@ -94,11 +101,8 @@ func copyDB(name string, srcDB, dstDB Database, quit chan struct{}) error {
 	return nil
 }
 
-// StartDBMigration migrates a DB to another DB.
-// (e.g. LevelDB -> LevelDB, LevelDB -> BadgerDB, LevelDB -> DynamoDB)
-// Do not migrate db while a node is executing.
-func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
-	// settings for quit signal from os
+// watchQuitSignal returns a channel closed when the process is interrupted.
+func watchQuitSignal() chan struct{} {
 	quit := make(chan struct{})
 	go func() {
 		sigc := make(chan os.Signal, 1)
@@ -114,6 +118,14 @@ func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
 			}
 		}
 	}()
+	return quit
+}
+
+// StartDBMigration migrates a DB to another DB.
+// (e.g. LevelDB -> LevelDB, LevelDB -> BadgerDB, LevelDB -> DynamoDB)
+// Do not migrate db while a node is executing.
+func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
+	quit := watchQuitSignal()
 
 	// from non single DB
 	if !dbm.config.SingleDB {
@@ -175,3 +187,236 @@ func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
 
 	return nil
 }
+
+var errReshardInterrupted = errors.New("resharding interrupted, run it again to resume")
+
+// reshardProgress is the progress of the resharding of a partition. It is
+// stored next to the partition so that an interrupted resharding is resumed.
+type reshardProgress struct {
+	DBType    DBType        `json:"dbType"`
+	NumShards uint          `json:"numShards"`
+	LastKey   hexutil.Bytes `json:"lastKey"` // Last key written into the destination
+	Copied    uint64        `json:"copied"`
+	Done      bool          `json:"done"` // Whether the copy is complete
+}
+
+func readReshardProgress(file string) (*reshardProgress, error) {
+	blob, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	progress := new(reshardProgress)
+	if err := json.Unmarshal(blob, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func writeReshardProgress(file string, progress *reshardProgress) error {
+	blob, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, blob, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Reshard migrates the given partitions, or every partition if entries is
+// empty, in place into databases of dbType. The state trie partitions are split into numShards shards, the others are
+// not sharded. The data is copied into a new database next to the partition,
+// which replaces the partition once complete. An interrupted resharding is
+// resumed by calling Reshard again with the same arguments.
+//
+// The migrated partitions are closed, so the DBManager must only be closed
+// afterwards, and the node must be restarted with the new database settings.
+// Do not reshard while a node is executing.
+func (dbm *databaseManager) Reshard(entries []DBEntryType, dbType DBType, numShards uint) error {
+	if len(entries) == 0 {
+		for et := MiscDB; et < databaseEntryTypeSize; et++ {
+			entries = append(entries, et)
+		}
+	}
+	switch {
+	case dbm.config.SingleDB || dbm.config.DBType == MemoryDB:
+		return errors.New("resharding is not supported for a single database")
+	case dbm.config.DBType.selfShardable() || dbType.selfShardable():
+		return fmt.Errorf("resharding is not supported for %s", DynamoDB)
+	case numShards == 0 || numShards > numShardsLimit || !IsPow2(numShards):
+		return fmt.Errorf("number of shards should be a power of two up to %d, but it is %d", numShardsLimit, numShards)
+	case dbType != dbm.config.DBType && len(entries) != int(databaseEntryTypeSize):
+		// All the partitions of a node are opened with the same database type.
+		for et := MiscDB; et < databaseEntryTypeSize; et++ {
+			if dbm.dbs[et] != nil && !containsDBEntryType(entries, et) {
+				return fmt.Errorf("changing the database type requires resharding every partition, %s is missing", et)
+			}
+		}
+	}
+	quit := watchQuitSignal()
+	for _, et := range entries {
+		if dbm.dbs[et] == nil {
+			logger.Warn("Skipping absent partition", "db", et)
+			continue
+		}
+		shards := uint(1)
+		if et == StateTrieDB || et == StateTrieMigrationDB {
+			shards = numShards
+		}
+		if err := dbm.reshardPartition(et, dbType, shards, quit); err != nil {
+			return fmt.Errorf("%s: %v", et, err)
+		}
+	}
+	return nil
+}
+
+func containsDBEntryType(entries []DBEntryType, et DBEntryType) bool {
+	for _, entry := range entries {
+		if entry == et {
+			return true
+		}
+	}
+	return false
+}
+
+func (dbm *databaseManager) reshardPartition(et DBEntryType, dbType DBType, numShards uint, quit chan struct{}) error {
+	var (
+		name         = dbm.getDBDir(et)
+		dir          = filepath.Join(dbm.config.Dir, name)
+		tmpDir       = dir + ".reshard"
+		oldDir       = dir + ".old"
+		progressFile = dir + ".reshard.json"
+	)
+	if et == MiscDB {
+		name, dir = dbBaseDirs[MiscDB], filepath.Join(dbm.config.Dir, dbBaseDirs[MiscDB])
+	}
+	progress, err := readReshardProgress(progressFile)
+	if err != nil {
+		return err
+	}
+	if err := checkShardLayout(dir, dbm.dbs[et]); err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = &reshardProgress{DBType: dbType, NumShards: numShards}
+	} else if progress.DBType != dbType || progress.NumShards != numShards {
+		return fmt.Errorf("another resharding into %d shards of %s is in progress", progress.NumShards, progress.DBType)
+	}
+
+	if !progress.Done {
+		dbc := getDBEntryConfig(dbm.config, et, name)
+		dbc.Dir, dbc.DBType = tmpDir, dbType
+		var dst Database
+		if numShards > 1 {
+			dst, err = newShardedDB(dbc, et, numShards)
+		} else {
+			dst, err = newDatabase(dbc, et)
+		}
+		if err != nil {
+			return err
+		}
+		logger.Info("Resharding database", "db", name, "dbType", dbType, "shards", numShards, "resume", len(progress.LastKey) > 0)
+		err = reshardCopy(name, dbm.dbs[et], dst, progress, progressFile, quit)
+		dst.Close()
+		if err != nil {
+			return err
+		}
+		progress.Done = true
+		if err := writeReshardProgress(progressFile, progress); err != nil {
+			return err
+		}
+	}
+
+	// Replace the partition by the resharded one. If a previous swap has been
+	// interrupted, the partition directory has been recreated empty.
+	dbm.dbs[et].Close()
+	dbm.dbs[et] = nil
+	if _, err := os.Stat(oldDir); err == nil {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	} else if err := os.Rename(dir, oldDir); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(oldDir); err != nil {
+		return err
+	}
+	logger.Info("Resharded database", "db", name, "dbType", dbType, "shards", numShards, "entries", progress.Copied)
+	return os.Remove(progressFile)
+}
+
+// checkShardLayout checks that the number of shards of the database opened
+// matches the shard directories found on disk, since a mismatch silently hides
+// the entries of the other shards.
+func checkShardLayout(dir string, db Database) error {
+	var opened uint = 1
+	if sharded, ok := db.(*shardedDB); ok {
+		opened = sharded.numShards
+	}
+	var found uint
+	for ; ; found++ {
+		if fi, err := os.Stat(filepath.Join(dir, strconv.Itoa(int(found)))); err != nil || !fi.IsDir() {
+			break
+		}
+	}
+	if found == 0 {
+		found = 1
+	}
+	if found != opened {
+		return fmt.Errorf("database opened with %d shard(s) but %d found in %s, set the current number of shards", opened, found, dir)
+	}
+	return nil
+}
+
+// reshardCopy copies the entries of src from the last copied key into dst, and
+// records the progress after every batch.
+func reshardCopy(name string, src, dst Database, progress *reshardProgress, progressFile string, quit chan struct{}) error {
+	it := src.NewIterator(nil, progress.LastKey)
+	defer it.Release()
+
+	var (
+		batch  = dst.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+	)
+	defer batch.Release()
+
+	for it.Next() {
+		key := common.CopyBytes(it.Key())
+		if err := batch.Put(key, common.CopyBytes(it.Value())); err != nil {
+			return err
+		}
+		progress.Copied++
+		if batch.ValueSize() < IdealBatchSize {
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		progress.LastKey = key
+		if err := writeReshardProgress(progressFile, progress); err != nil {
+			return err
+		}
+		if time.Since(logged) > log.StatsReportLimit {
+			logger.Info("Resharding database", "db", name, "copied", progress.Copied, "key", hexutil.Bytes(key), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		select {
+		case <-quit:
+			logger.Warn("Resharding interrupted", "db", name, "copied", progress.Copied)
+			return errReshardInterrupted
+		default:
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
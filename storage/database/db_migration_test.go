@@ -0,0 +1,84 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReshard(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-db-reshard")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dbc := &DBConfig{Dir: dir, DBType: LevelDB, NumStateTrieShards: 2}
+	dbm := NewDBManager(dbc)
+
+	// Write enough entries to span several batches.
+	nodes := make(map[common.ExtHash][]byte)
+	for i := 0; i < 10000; i++ {
+		blob := bytes.Repeat([]byte{byte(i)}, 100)
+		hash := crypto.Keccak256Hash(blob, []byte{byte(i >> 8)}).ExtendZero()
+		nodes[hash] = blob
+		dbm.WriteTrieNode(hash, blob)
+	}
+	dbm.WriteCanonicalHash(common.Hash{0x1}, 1)
+
+	// An interrupted resharding keeps its progress.
+	quit := make(chan struct{})
+	close(quit)
+	err = dbm.(*databaseManager).reshardPartition(StateTrieDB, LevelDB, 4, quit)
+	assert.Equal(t, errReshardInterrupted, err)
+	progress, err := readReshardProgress(filepath.Join(dir, "statetrie.reshard.json"))
+	require.NoError(t, err)
+	require.NotNil(t, progress)
+	assert.NotEmpty(t, progress.LastKey)
+	assert.False(t, progress.Done)
+
+	// Another resharding cannot start before it is finished.
+	assert.Error(t, dbm.Reshard([]DBEntryType{StateTrieDB}, LevelDB, 8))
+	// Changing the database type requires every partition.
+	assert.Error(t, dbm.Reshard([]DBEntryType{StateTrieDB}, BadgerDB, 4))
+
+	require.NoError(t, dbm.Reshard([]DBEntryType{StateTrieDB}, LevelDB, 4))
+	dbm.Close()
+
+	// Opening with the old number of shards is rejected by a next resharding.
+	dbm = NewDBManager(dbc)
+	assert.Error(t, dbm.Reshard([]DBEntryType{StateTrieDB}, LevelDB, 2))
+	dbm.Close()
+
+	dbc.NumStateTrieShards = 4
+	dbm = NewDBManager(dbc)
+	defer dbm.Close()
+	for hash, blob := range nodes {
+		have, err := dbm.ReadTrieNode(hash)
+		require.NoError(t, err)
+		assert.Equal(t, blob, have)
+	}
+	assert.Equal(t, common.Hash{0x1}, dbm.ReadCanonicalHash(1))
+	_, err = os.Stat(filepath.Join(dir, "statetrie.reshard.json"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// PathTrieHead is the state persisted by the path-based trie node scheme.
+type PathTrieHead struct {
+	Number uint64      // Number of the block whose state is on disk
+	Root   common.Hash // State root of the block whose state is on disk
+
+	// Pending is one plus the number of a diff which is being applied to or
+	// reverted from the disk, or zero. A pending diff is reverted at startup.
+	Pending uint64
+}
+
+// PathTrieDiff is the reverse diff of the trie nodes written by a block in the
+// path-based trie node scheme. The previous value of every node is stored
+// separately as a trie node history entry.
+type PathTrieDiff struct {
+	Parent     uint64      // Number of the block on top of which the diff was written
+	ParentRoot common.Hash // State root before the diff
+	Root       common.Hash // State root after the diff
+	Keys       [][]byte    // Owner and hex path of the written nodes
+}
+
+// pathTrieHistoryAbsent and pathTrieHistoryPresent prefix a trie node history
+// entry, so that a node absent before a block can be told from an empty one.
+const (
+	pathTrieHistoryAbsent  byte = 0
+	pathTrieHistoryPresent byte = 1
+)
+
+// ReadTrieNodeScheme returns the trie node scheme stored in the database, or
+// an empty string if none is stored.
+func (dbm *databaseManager) ReadTrieNodeScheme() string {
+	data, _ := dbm.getDatabase(MiscDB).Get(trieNodeSchemeKey)
+	return string(data)
+}
+
+// WriteTrieNodeScheme stores the trie node scheme of the database.
+func (dbm *databaseManager) WriteTrieNodeScheme(scheme string) {
+	if err := dbm.getDatabase(MiscDB).Put(trieNodeSchemeKey, []byte(scheme)); err != nil {
+		logger.Crit("Failed to store trie node scheme", "err", err)
+	}
+}
+
+// ReadPathTrieNode retrieves the trie node stored at the given owner and hex path.
+func (dbm *databaseManager) ReadPathTrieNode(owner common.Hash, path []byte) []byte {
+	data, _ := dbm.getDatabase(StateTrieDB).Get(pathTrieNodeKey(owner, path))
+	return data
+}
+
+// PutPathTrieNodeToBatch stores the trie node at the given owner and hex path,
+// or deletes it if node is nil.
+func (dbm *databaseManager) PutPathTrieNodeToBatch(batch Batch, owner common.Hash, path []byte, node []byte) {
+	key := pathTrieNodeKey(owner, path)
+	if node == nil {
+		if err := batch.Delete(key); err != nil {
+			logger.Crit("Failed to delete path trie node", "err", err)
+		}
+		return
+	}
+	if err := batch.Put(key, node); err != nil {
+		logger.Crit("Failed to store path trie node", "err", err)
+	}
+}
+
+// ReadPathTrieHistory retrieves the trie node stored at the given owner and hex
+// path before the given block. The node is nil if it was absent, and ok is false
+// if there is no history entry.
+func (dbm *databaseManager) ReadPathTrieHistory(number uint64, owner common.Hash, path []byte) (node []byte, ok bool) {
+	data, err := dbm.getDatabase(StateTrieDB).Get(pathTrieHistoryKey(number, owner, path))
+	if err != nil || len(data) == 0 {
+		return nil, false
+	}
+	if data[0] == pathTrieHistoryAbsent {
+		return nil, true
+	}
+	return data[1:], true
+}
+
+// PutPathTrieHistoryToBatch stores the trie node at the given owner and hex path
+// before the given block. A nil node means that the node was absent.
+func (dbm *databaseManager) PutPathTrieHistoryToBatch(batch Batch, number uint64, owner common.Hash, path []byte, node []byte) {
+	data := []byte{pathTrieHistoryAbsent}
+	if node != nil {
+		data = append([]byte{pathTrieHistoryPresent}, node...)
+	}
+	if err := batch.Put(pathTrieHistoryKey(number, owner, path), data); err != nil {
+		logger.Crit("Failed to store path trie history", "err", err)
+	}
+}
+
+// DeletePathTrieHistoryFromBatch deletes a trie node history entry.
+func (dbm *databaseManager) DeletePathTrieHistoryFromBatch(batch Batch, number uint64, owner common.Hash, path []byte) {
+	if err := batch.Delete(pathTrieHistoryKey(number, owner, path)); err != nil {
+		logger.Crit("Failed to delete path trie history", "err", err)
+	}
+}
+
+// ReadPathTrieDiff retrieves the reverse diff of the given block.
+func (dbm *databaseManager) ReadPathTrieDiff(number uint64) *PathTrieDiff {
+	data, _ := dbm.getDatabase(StateTrieDB).Get(pathTrieDiffKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	diff := new(PathTrieDiff)
+	if err := rlp.DecodeBytes(data, diff); err != nil {
+		logger.Error("Invalid path trie diff RLP", "number", number, "err", err)
+		return nil
+	}
+	return diff
+}
+
+// PutPathTrieDiffToBatch stores the reverse diff of the given block.
+func (dbm *databaseManager) PutPathTrieDiffToBatch(batch Batch, number uint64, diff *PathTrieDiff) {
+	data, err := rlp.EncodeToBytes(diff)
+	if err != nil {
+		logger.Crit("Failed to RLP encode path trie diff", "err", err)
+	}
+	if err := batch.Put(pathTrieDiffKey(number), data); err != nil {
+		logger.Crit("Failed to store path trie diff", "err", err)
+	}
+}
+
+// DeletePathTrieDiffFromBatch deletes the reverse diff of the given block.
+func (dbm *databaseManager) DeletePathTrieDiffFromBatch(batch Batch, number uint64) {
+	if err := batch.Delete(pathTrieDiffKey(number)); err != nil {
+		logger.Crit("Failed to delete path trie diff", "err", err)
+	}
+}
+
+// ReadPathTrieHead retrieves the head of the path-based trie node scheme.
+func (dbm *databaseManager) ReadPathTrieHead() *PathTrieHead {
+	data, _ := dbm.getDatabase(StateTrieDB).Get(pathTrieHeadKey)
+	if len(data) == 0 {
+		return nil
+	}
+	head := new(PathTrieHead)
+	if err := rlp.DecodeBytes(data, head); err != nil {
+		logger.Error("Invalid path trie head RLP", "err", err)
+		return nil
+	}
+	return head
+}
+
+// PutPathTrieHeadToBatch stores the head of the path-based trie node scheme.
+func (dbm *databaseManager) PutPathTrieHeadToBatch(batch Batch, head *PathTrieHead) {
+	data, err := rlp.EncodeToBytes(head)
+	if err != nil {
+		logger.Crit("Failed to RLP encode path trie head", "err", err)
+	}
+	if err := batch.Put(pathTrieHeadKey, data); err != nil {
+		logger.Crit("Failed to store path trie head", "err", err)
+	}
+}
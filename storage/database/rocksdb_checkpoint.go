@@ -0,0 +1,61 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build rocksdb
+// +build rocksdb
+
+package database
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// rdbCheckpoint is a checkpoint made by the RocksDB checkpoint facility. The
+// checkpoint is created next to the database, so that the table files are hard
+// linked, and moved into place when it is written.
+type rdbCheckpoint struct {
+	staging string
+}
+
+func (db *rocksDB) newCheckpoint() (checkpoint, error) {
+	cp, err := db.db.NewCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	defer cp.Destroy()
+
+	staging := fmt.Sprintf("%s.checkpoint-%d", db.path, time.Now().UnixNano())
+	// A log size of zero flushes the memtable, so that no WAL needs to be copied.
+	if err := cp.CreateCheckpoint(staging, 0); err != nil {
+		os.RemoveAll(staging)
+		return nil, err
+	}
+	return &rdbCheckpoint{staging: staging}, nil
+}
+
+func (c *rdbCheckpoint) Format() CheckpointFormat { return CheckpointRocksDB }
+
+func (c *rdbCheckpoint) Write(dir string) error {
+	if err := os.Rename(c.staging, dir); err == nil {
+		return nil
+	}
+	// The backup is on another file system, fall back to copying.
+	return copyDir(c.staging, dir)
+}
+
+func (c *rdbCheckpoint) Release() { os.RemoveAll(c.staging) }
@@ -67,6 +67,7 @@ func compressionStrToType(t string) grocksdb.CompressionType {
 type rocksDB struct {
 	config *RocksDBConfig
 	db     *grocksdb.DB // rocksDB instance
+	path   string
 
 	wo *grocksdb.WriteOptions
 	ro *grocksdb.ReadOptions
@@ -158,6 +159,7 @@ func NewRocksDB(path string, config *RocksDBConfig) (*rocksDB, error) {
 	return &rocksDB{
 		config: config,
 		db:     db,
+		path:   path,
 		wo:     grocksdb.NewDefaultWriteOptions(),
 		ro:     grocksdb.NewDefaultReadOptions(),
 		logger: localLogger,
@@ -93,12 +93,28 @@ var (
 	preimagePrefix = []byte("secure-key-")  // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("klay-config-") // config prefix for the db
 
+	precompileFingerprintsPrefix = []byte("klay-precompiles-") // precompileFingerprintsPrefix + genesis hash -> custom precompiled contracts of the chain
+
 	pruningEnabledKey        = []byte("PruningEnabled")
 	pruningMarkPrefix        = []byte("Pruning-")                                // KIP-111 pruning markings
 	pruningMarkValue         = []byte{0x01}                                      // A nonempty value to store a pruning mark
 	pruningMarkKeyLen        = len(pruningMarkPrefix) + 8 + common.ExtHashLength // prefix + num (uint64) + node hash
 	lastPrunedBlockNumberKey = []byte("lastPrunedBlockNumber")
 
+	trieNodeSchemeKey     = []byte("TrieNodeScheme")
+	pathTrieNodePrefix    = []byte("P")            // spread byte + pathTrieNodePrefix + owner + hex path -> trie node
+	pathTrieHistoryPrefix = []byte("PH")           // pathTrieHistoryPrefix + num (uint64 big endian) + owner + hex path -> previous trie node
+	pathTrieDiffPrefix    = []byte("PD")           // pathTrieDiffPrefix + num (uint64 big endian) -> PathTrieDiff
+	pathTrieHeadKey       = []byte("PathTrieHead") // pathTrieHeadKey -> PathTrieHead
+
+	stateHistoryEnabledKey     = []byte("StateHistoryEnabled")
+	stateHistoryTailKey        = []byte("StateHistoryTail") // stateHistoryTailKey -> first block number of the state history index
+	stateHistoryDiffPrefix     = []byte("SHc")              // stateHistoryDiffPrefix + num (uint64 big endian) + hash -> StateHistoryDiff
+	stateHistoryIndexPrefix    = []byte("SHn")              // stateHistoryIndexPrefix + num (uint64 big endian) -> hash of the indexed block
+	stateHistoryAccountPrefix  = []byte("SHa")              // stateHistoryAccountPrefix + account hash + ^num (uint64 big endian) -> account trie value
+	stateHistoryStoragePrefix  = []byte("SHs")              // stateHistoryStoragePrefix + account hash + storage hash + ^num (uint64 big endian) -> storage trie value
+	stateHistoryDestructPrefix = []byte("SHd")              // stateHistoryDestructPrefix + account hash + ^num (uint64 big endian) -> storage wiped
+
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 
@@ -218,6 +234,11 @@ func configKey(hash common.Hash) []byte {
 	return append(configPrefix, hash.Bytes()...)
 }
 
+// precompileFingerprintsKey = precompileFingerprintsPrefix + hash
+func precompileFingerprintsKey(hash common.Hash) []byte {
+	return append(precompileFingerprintsPrefix, hash.Bytes()...)
+}
+
 func sectionHeadKey(encodedSection []byte) []byte {
 	return append(sectionHeadKeyPrefix, encodedSection...)
 }
@@ -293,3 +314,66 @@ func parsePruningMarkKey(key []byte) PruningMark {
 		Hash:   common.BytesToExtHash(bHash),
 	}
 }
+
+// pathTrieNodeKey = spread byte + pathTrieNodePrefix + owner + hex path
+// The leading byte is derived from the owner and the path so that the nodes of
+// a trie are spread over the shards of the state trie database.
+func pathTrieNodeKey(owner common.Hash, path []byte) []byte {
+	spread := owner[0]
+	if len(path) > 0 {
+		spread ^= path[0] << 4
+	}
+	if len(path) > 1 {
+		spread ^= path[1]
+	}
+	key := make([]byte, 0, 1+len(pathTrieNodePrefix)+common.HashLength+len(path))
+	key = append(key, spread)
+	key = append(key, pathTrieNodePrefix...)
+	key = append(key, owner[:]...)
+	return append(key, path...)
+}
+
+// pathTrieHistoryKey = pathTrieHistoryPrefix + num (uint64 big endian) + owner + hex path
+func pathTrieHistoryKey(number uint64, owner common.Hash, path []byte) []byte {
+	key := make([]byte, 0, len(pathTrieHistoryPrefix)+8+common.HashLength+len(path))
+	key = append(key, pathTrieHistoryPrefix...)
+	key = append(key, common.Int64ToByteBigEndian(number)...)
+	key = append(key, owner[:]...)
+	return append(key, path...)
+}
+
+// pathTrieDiffKey = pathTrieDiffPrefix + num (uint64 big endian)
+func pathTrieDiffKey(number uint64) []byte {
+	return append(append([]byte{}, pathTrieDiffPrefix...), common.Int64ToByteBigEndian(number)...)
+}
+
+// stateHistoryDiffKey = stateHistoryDiffPrefix + num (uint64 big endian) + hash
+func stateHistoryDiffKey(number uint64, hash common.Hash) []byte {
+	return append(append(append([]byte{}, stateHistoryDiffPrefix...), common.Int64ToByteBigEndian(number)...), hash.Bytes()...)
+}
+
+// stateHistoryIndexKey = stateHistoryIndexPrefix + num (uint64 big endian)
+func stateHistoryIndexKey(number uint64) []byte {
+	return append(append([]byte{}, stateHistoryIndexPrefix...), common.Int64ToByteBigEndian(number)...)
+}
+
+// stateHistoryNumber encodes a block number so that the newer blocks sort first.
+func stateHistoryNumber(number uint64) []byte {
+	return common.Int64ToByteBigEndian(^number)
+}
+
+// stateHistoryAccountKey = stateHistoryAccountPrefix + account hash + ^num (uint64 big endian)
+func stateHistoryAccountKey(accountHash common.Hash, number uint64) []byte {
+	return append(append(append([]byte{}, stateHistoryAccountPrefix...), accountHash.Bytes()...), stateHistoryNumber(number)...)
+}
+
+// stateHistoryStorageKey = stateHistoryStoragePrefix + account hash + storage hash + ^num (uint64 big endian)
+func stateHistoryStorageKey(accountHash, storageHash common.Hash, number uint64) []byte {
+	key := append(append([]byte{}, stateHistoryStoragePrefix...), accountHash.Bytes()...)
+	return append(append(key, storageHash.Bytes()...), stateHistoryNumber(number)...)
+}
+
+// stateHistoryDestructKey = stateHistoryDestructPrefix + account hash + ^num (uint64 big endian)
+func stateHistoryDestructKey(accountHash common.Hash, number uint64) []byte {
+	return append(append(append([]byte{}, stateHistoryDestructPrefix...), accountHash.Bytes()...), stateHistoryNumber(number)...)
+}
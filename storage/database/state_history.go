@@ -0,0 +1,269 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// StateHistoryDiff is the change set of a block: the accounts whose storage was
+// wiped, and the new values of the changed accounts and storage slots. Accounts
+// and slots are identified by the hash of their trie key, and the values are
+// trie values, empty if deleted.
+type StateHistoryDiff struct {
+	Destructs []common.Hash
+	Accounts  []StateHistoryAccount
+	Storage   []StateHistoryStorage
+}
+
+// StateHistoryAccount is an account changed by a block.
+type StateHistoryAccount struct {
+	Hash common.Hash
+	Data []byte
+}
+
+// StateHistoryStorage is a storage slot changed by a block.
+type StateHistoryStorage struct {
+	Account common.Hash
+	Slot    common.Hash
+	Value   []byte
+}
+
+// Values of the state history index are prefixed with a marker, so that a
+// deleted account or slot can be told from a missing entry.
+const (
+	stateHistoryDeleted byte = 0
+	stateHistoryPresent byte = 1
+)
+
+func encodeStateHistoryValue(value []byte) []byte {
+	if len(value) == 0 {
+		return []byte{stateHistoryDeleted}
+	}
+	return append([]byte{stateHistoryPresent}, value...)
+}
+
+// ReadStateHistoryEnabled returns true if the state history index is kept.
+func (dbm *databaseManager) ReadStateHistoryEnabled() bool {
+	ok, _ := dbm.getDatabase(MiscDB).Has(stateHistoryEnabledKey)
+	return ok
+}
+
+// WriteStateHistoryEnabled enables the state history index. Once enabled, the
+// change set of every processed block is recorded.
+func (dbm *databaseManager) WriteStateHistoryEnabled() {
+	if err := dbm.openStateHistoryDB(); err != nil {
+		logger.Crit("Failed to open the state history database", "err", err)
+	}
+	if err := dbm.getDatabase(MiscDB).Put(stateHistoryEnabledKey, []byte("42")); err != nil {
+		logger.Crit("Failed to store state history enabled flag", "err", err)
+	}
+}
+
+// openStateHistoryDB opens the state history partition if it has not been
+// opened with the others, as the state history index was not enabled then.
+func (dbm *databaseManager) openStateHistoryDB() error {
+	if dbm.config.SingleDB || dbm.config.DBType == MemoryDB || dbm.dbs[StateHistoryDB] != nil {
+		return nil
+	}
+	newDBC := getDBEntryConfig(dbm.config, StateHistoryDB, dbm.getDBDir(StateHistoryDB))
+	db, err := newDatabase(newDBC, StateHistoryDB)
+	if err != nil {
+		return err
+	}
+	db.Meter(dbMetricPrefix + dbBaseDirs[StateHistoryDB] + "/")
+	dbm.dbs[StateHistoryDB] = db
+	return nil
+}
+
+// ReadStateHistoryDiff retrieves the change set of the given block.
+func (dbm *databaseManager) ReadStateHistoryDiff(number uint64, hash common.Hash) *StateHistoryDiff {
+	db := dbm.getDatabase(StateHistoryDB)
+	if db == nil {
+		return nil
+	}
+	data, _ := db.Get(stateHistoryDiffKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	diff := new(StateHistoryDiff)
+	if err := rlp.DecodeBytes(data, diff); err != nil {
+		logger.Error("Invalid state history diff RLP", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return diff
+}
+
+// WriteStateHistoryDiff stores the change set of the given block. It is only
+// added to the index by IndexStateHistory, once the block is canonical.
+func (dbm *databaseManager) WriteStateHistoryDiff(number uint64, hash common.Hash, diff *StateHistoryDiff) {
+	data, err := rlp.EncodeToBytes(diff)
+	if err != nil {
+		logger.Crit("Failed to RLP encode state history diff", "err", err)
+	}
+	if err := dbm.getDatabase(StateHistoryDB).Put(stateHistoryDiffKey(number, hash), data); err != nil {
+		logger.Crit("Failed to store state history diff", "err", err)
+	}
+}
+
+// ReadStateHistoryIndex returns the hash of the block indexed at the given
+// number, or an empty hash.
+func (dbm *databaseManager) ReadStateHistoryIndex(number uint64) common.Hash {
+	db := dbm.getDatabase(StateHistoryDB)
+	if db == nil {
+		return common.Hash{}
+	}
+	data, _ := db.Get(stateHistoryIndexKey(number))
+	return common.BytesToHash(data)
+}
+
+// ReadStateHistoryTail returns the first block of the contiguous range of
+// indexed blocks, or false if no block is indexed.
+func (dbm *databaseManager) ReadStateHistoryTail() (uint64, bool) {
+	db := dbm.getDatabase(StateHistoryDB)
+	if db == nil {
+		return 0, false
+	}
+	data, _ := db.Get(stateHistoryTailKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// IndexStateHistory adds the change set of the given canonical block to the
+// index, replacing the one of another block indexed at the same number.
+func (dbm *databaseManager) IndexStateHistory(number uint64, hash common.Hash) error {
+	indexed := dbm.ReadStateHistoryIndex(number)
+	if indexed == hash {
+		return nil
+	}
+	diff := dbm.ReadStateHistoryDiff(number, hash)
+	if diff == nil {
+		return fmt.Errorf("missing state history diff of block %d (%x)", number, hash)
+	}
+	batch := dbm.NewBatch(StateHistoryDB)
+	defer batch.Release()
+
+	var err error
+	put := func(key, value []byte) {
+		if err == nil {
+			err = batch.Put(key, value)
+		}
+	}
+	del := func(key []byte) {
+		if err == nil {
+			err = batch.Delete(key)
+		}
+	}
+	// Remove the change set of a block which is not canonical anymore
+	if indexed != (common.Hash{}) {
+		if old := dbm.ReadStateHistoryDiff(number, indexed); old != nil {
+			for _, accountHash := range old.Destructs {
+				del(stateHistoryDestructKey(accountHash, number))
+			}
+			for _, acc := range old.Accounts {
+				del(stateHistoryAccountKey(acc.Hash, number))
+			}
+			for _, slot := range old.Storage {
+				del(stateHistoryStorageKey(slot.Account, slot.Slot, number))
+			}
+		}
+	}
+	for _, accountHash := range diff.Destructs {
+		put(stateHistoryDestructKey(accountHash, number), []byte{stateHistoryPresent})
+	}
+	for _, acc := range diff.Accounts {
+		put(stateHistoryAccountKey(acc.Hash, number), encodeStateHistoryValue(acc.Data))
+	}
+	for _, slot := range diff.Storage {
+		put(stateHistoryStorageKey(slot.Account, slot.Slot, number), encodeStateHistoryValue(slot.Value))
+	}
+	put(stateHistoryIndexKey(number), hash.Bytes())
+
+	// The index only answers for a contiguous range of blocks. If the parent
+	// block is not indexed, the range restarts at this block.
+	tail, ok := dbm.ReadStateHistoryTail()
+	contiguous := ok && number > tail && dbm.ReadStateHistoryIndex(number-1) != (common.Hash{})
+	if !contiguous && !(ok && number == tail) {
+		if ok {
+			logger.Warn("State history index restarted", "number", number, "tail", tail)
+		}
+		put(stateHistoryTailKey, common.Int64ToByteBigEndian(number))
+	}
+	if err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// seekStateHistory returns the newest entry of the given prefix at or before
+// the given block, and the number of its block. The entries of an account or
+// a slot are ordered from the newest block, so the seek is a binary search of
+// its change sets.
+func (dbm *databaseManager) seekStateHistory(prefix []byte, number uint64) ([]byte, uint64, bool) {
+	db := dbm.getDatabase(StateHistoryDB)
+	if db == nil {
+		return nil, 0, false
+	}
+	it := db.NewIterator(prefix, stateHistoryNumber(number))
+	defer it.Release()
+
+	if !it.Next() {
+		return nil, 0, false
+	}
+	key, value := it.Key(), it.Value()
+	if len(key) != len(prefix)+8 || len(value) == 0 {
+		return nil, 0, false
+	}
+	changed := ^binary.BigEndian.Uint64(key[len(prefix):])
+	if value[0] == stateHistoryDeleted {
+		return nil, changed, true
+	}
+	return common.CopyBytes(value[1:]), changed, true
+}
+
+// ReadStateHistoryAccount returns the account trie value of the given account
+// at the given block, and the number of the block which last changed it. The
+// value is nil if the account was deleted, and ok is false if the account was
+// not changed at or before the block.
+func (dbm *databaseManager) ReadStateHistoryAccount(accountHash common.Hash, number uint64) ([]byte, uint64, bool) {
+	prefix := append(append([]byte{}, stateHistoryAccountPrefix...), accountHash.Bytes()...)
+	return dbm.seekStateHistory(prefix, number)
+}
+
+// ReadStateHistoryStorage returns the storage trie value of the given slot at
+// the given block, and the number of the block which last changed it. The
+// value is nil if the slot was deleted, and ok is false if the slot was not
+// changed at or before the block.
+func (dbm *databaseManager) ReadStateHistoryStorage(accountHash, storageHash common.Hash, number uint64) ([]byte, uint64, bool) {
+	prefix := append(append([]byte{}, stateHistoryStoragePrefix...), accountHash.Bytes()...)
+	prefix = append(prefix, storageHash.Bytes()...)
+	return dbm.seekStateHistory(prefix, number)
+}
+
+// ReadStateHistoryDestruct returns the number of the last block at or before
+// the given one which wiped the storage of the given account.
+func (dbm *databaseManager) ReadStateHistoryDestruct(accountHash common.Hash, number uint64) (uint64, bool) {
+	prefix := append(append([]byte{}, stateHistoryDestructPrefix...), accountHash.Bytes()...)
+	_, destructed, ok := dbm.seekStateHistory(prefix, number)
+	return destructed, ok
+}
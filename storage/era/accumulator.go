@@ -0,0 +1,43 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"fmt"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+)
+
+// ComputeAccumulator returns the root of the binary keccak256 merkle tree over the
+// given block hashes. The leaves are padded with empty hashes up to EpochSize, so
+// the shape of the tree does not depend on the number of blocks in the epoch.
+func ComputeAccumulator(hashes []common.Hash) (common.Hash, error) {
+	if len(hashes) > EpochSize {
+		return common.Hash{}, fmt.Errorf("too many block hashes: %d > %d", len(hashes), EpochSize)
+	}
+	level := make([]common.Hash, EpochSize)
+	copy(level, hashes)
+	for len(level) > 1 {
+		next := make([]common.Hash, len(level)/2)
+		for i := range next {
+			next[i] = crypto.Keccak256Hash(level[2*i][:], level[2*i+1][:])
+		}
+		level = next
+	}
+	return level[0], nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package era implements a self-describing archive format for historical blocks and receipts.
+
+An era file stores one epoch of EpochSize consecutive blocks. The file is a sequence
+of typed entries following the e2store layout:
+
+	entry := type (2 bytes, LE) | length (4 bytes, LE) | reserved (2 bytes) | data
+
+and the entries of an era file are ordered as follows:
+
+	era := Version | block-tuple* | Accumulator | BlockIndex
+	block-tuple := CompressedHeader | CompressedBody | CompressedReceipts
+
+Headers, bodies and receipts are RLP encoded and compressed with snappy. Headers are
+stored as is, so the Istanbul extra data (validators, seals and committed seals) is kept
+and the block hash can be recomputed from the file alone.
+
+The accumulator is the root of a binary keccak256 merkle tree over the hashes of the
+blocks in the epoch, padded with empty hashes up to EpochSize leaves. The block index
+holds the starting block number, the offsets of the block tuples relative to the index
+entry and the number of blocks, so that any block can be read without scanning the file.
+
+Source Files
+
+  - e2store.go : reader and writer of the typed entries
+  - era.go     : Builder writing an epoch into an era file and Era reading it back
+  - accumulator.go : computation of the accumulator root
+*/
+package era
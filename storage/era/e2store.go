@@ -0,0 +1,98 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const headerSize = 8
+
+var errReservedNotZero = errors.New("reserved bytes of entry header are not zero")
+
+// entry is a typed record of an e2store file.
+type entry struct {
+	Type  uint16
+	Value []byte
+}
+
+// e2Writer writes entries into an underlying writer.
+type e2Writer struct {
+	w io.Writer
+}
+
+// write writes a single entry and returns the number of bytes written.
+func (w *e2Writer) write(typ uint16, value []byte) (int, error) {
+	var header [headerSize]byte
+	binary.LittleEndian.PutUint16(header[:2], typ)
+	binary.LittleEndian.PutUint32(header[2:6], uint32(len(value)))
+	n, err := w.w.Write(header[:])
+	if err != nil {
+		return n, err
+	}
+	m, err := w.w.Write(value)
+	return n + m, err
+}
+
+// e2Reader reads entries from an underlying io.ReaderAt.
+type e2Reader struct {
+	r io.ReaderAt
+}
+
+// readHeader reads the type and length of the entry at the given offset.
+func (r *e2Reader) readHeader(off int64) (uint16, uint32, error) {
+	var header [headerSize]byte
+	if _, err := r.r.ReadAt(header[:], off); err != nil {
+		return 0, 0, err
+	}
+	if header[6] != 0 || header[7] != 0 {
+		return 0, 0, errReservedNotZero
+	}
+	return binary.LittleEndian.Uint16(header[:2]), binary.LittleEndian.Uint32(header[2:6]), nil
+}
+
+// readAt reads the entry at the given offset and returns it together with the
+// total length of the entry including its header.
+func (r *e2Reader) readAt(off int64) (*entry, int64, error) {
+	typ, length, err := r.readHeader(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	value := make([]byte, length)
+	if _, err := r.r.ReadAt(value, off+headerSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	return &entry{Type: typ, Value: value}, headerSize + int64(length), nil
+}
+
+// readTypedAt reads the entry at the given offset and checks its type.
+func (r *e2Reader) readTypedAt(off int64, typ uint16) (*entry, int64, error) {
+	e, n, err := r.readAt(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	if e.Type != typ {
+		return nil, 0, fmt.Errorf("unexpected entry type at offset %d: have %#x, want %#x", off, e.Type, typ)
+	}
+	return e, n, nil
+}
@@ -0,0 +1,453 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+const (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x05
+	TypeAccumulator        uint16 = 0x07
+	TypeBlockIndex         uint16 = 0x3266
+
+	// EpochSize is the number of blocks stored in an era file.
+	EpochSize = 8192
+
+	// Extension is the file extension of era files.
+	Extension = ".era1"
+)
+
+var (
+	errEmptyEra         = errors.New("era has no block")
+	errBuilderFinalized = errors.New("era builder is already finalized")
+)
+
+// Filename returns the name of the era file of the given epoch. It contains the
+// network name, the epoch and the first four bytes of the accumulator root, e.g.
+// "cypress-00012-5ec1ffb8.era1".
+func Filename(network string, epoch uint64, root common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%x%s", network, epoch, root[:4], Extension)
+}
+
+// ReadDir returns the era files of the given network stored in dir, sorted by epoch.
+func ReadDir(dir, network string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type file struct {
+		epoch uint64
+		name  string
+	}
+	var files []file
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != Extension || !strings.HasPrefix(name, network+"-") {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(name, network+"-"), Extension), "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("malformed era file name: %s", name)
+		}
+		epoch, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed era file name: %s", name)
+		}
+		files = append(files, file{epoch: epoch, name: filepath.Join(dir, name)})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].epoch < files[j].epoch })
+
+	names := make([]string, len(files))
+	for i, f := range files {
+		if i > 0 && f.epoch == files[i-1].epoch {
+			return nil, fmt.Errorf("duplicated era files of epoch %d", f.epoch)
+		}
+		names[i] = f.name
+	}
+	return names, nil
+}
+
+// Builder writes the blocks and receipts of an epoch into an era file.
+//
+//	b := era.NewBuilder(w)
+//	for each block in the epoch {
+//		b.Add(block, receipts)
+//	}
+//	root, err := b.Finalize()
+type Builder struct {
+	w         *e2Writer
+	start     *uint64
+	hashes    []common.Hash
+	indexes   []uint64
+	written   uint64
+	finalized bool
+	buf       *bytes.Buffer
+	snappy    *snappy.Writer
+}
+
+// NewBuilder returns a new Builder writing into w.
+func NewBuilder(w io.Writer) *Builder {
+	buf := new(bytes.Buffer)
+	return &Builder{
+		w:      &e2Writer{w: w},
+		buf:    buf,
+		snappy: snappy.NewBufferedWriter(buf),
+	}
+}
+
+// Add appends a block and its receipts to the era. Blocks must be added in order
+// and an era holds at most EpochSize blocks.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts) error {
+	if b.finalized {
+		return errBuilderFinalized
+	}
+	if len(b.indexes) >= EpochSize {
+		return fmt.Errorf("exceeding epoch size of %d blocks", EpochSize)
+	}
+	if b.start == nil {
+		// Write the version entry before the first block.
+		n, err := b.w.write(TypeVersion, nil)
+		if err != nil {
+			return err
+		}
+		start := block.NumberU64()
+		b.start, b.written = &start, uint64(n)
+	} else if expected := *b.start + uint64(len(b.indexes)); block.NumberU64() != expected {
+		return fmt.Errorf("non contiguous block: have #%d, want #%d", block.NumberU64(), expected)
+	}
+	b.indexes = append(b.indexes, b.written)
+	b.hashes = append(b.hashes, block.Hash())
+
+	storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		storageReceipts[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	for _, item := range []struct {
+		typ uint16
+		val interface{}
+	}{
+		{TypeCompressedHeader, block.Header()},
+		{TypeCompressedBody, block.Body()},
+		{TypeCompressedReceipts, storageReceipts},
+	} {
+		if err := b.writeCompressed(item.typ, item.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCompressed writes the snappy compressed RLP encoding of val as an entry.
+func (b *Builder) writeCompressed(typ uint16, val interface{}) error {
+	b.buf.Reset()
+	b.snappy.Reset(b.buf)
+	if err := rlp.Encode(b.snappy, val); err != nil {
+		return err
+	}
+	if err := b.snappy.Flush(); err != nil {
+		return err
+	}
+	n, err := b.w.write(typ, b.buf.Bytes())
+	b.written += uint64(n)
+	return err
+}
+
+// Finalize writes the accumulator and the block index, and returns the
+// accumulator root. The builder cannot be used afterwards.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if b.start == nil {
+		return common.Hash{}, errEmptyEra
+	}
+	if b.finalized {
+		return common.Hash{}, errBuilderFinalized
+	}
+	b.finalized = true
+
+	root, err := ComputeAccumulator(b.hashes)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	n, err := b.w.write(TypeAccumulator, root[:])
+	if err != nil {
+		return common.Hash{}, err
+	}
+	b.written += uint64(n)
+
+	// The offsets in the index are relative to the beginning of the index entry.
+	count := len(b.indexes)
+	index := make([]byte, 16+8*count)
+	binary.LittleEndian.PutUint64(index, *b.start)
+	for i, offset := range b.indexes {
+		relative := int64(offset) - int64(b.written)
+		binary.LittleEndian.PutUint64(index[8+i*8:], uint64(relative))
+	}
+	binary.LittleEndian.PutUint64(index[8+count*8:], uint64(count))
+	if _, err := b.w.write(TypeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	return root, nil
+}
+
+// ReadAtSeekCloser is the interface of the file an Era is read from.
+type ReadAtSeekCloser interface {
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+}
+
+// Era reads the blocks and receipts stored in an era file.
+type Era struct {
+	f       ReadAtSeekCloser
+	r       *e2Reader
+	start   uint64
+	count   uint64
+	offsets []int64 // absolute offsets of the block tuples
+	length  int64
+}
+
+// Open opens the era file at the given path.
+func Open(path string) (*Era, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	e, err := From(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// From reads an era from an already opened file.
+func From(f ReadAtSeekCloser) (*Era, error) {
+	length, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	r := &e2Reader{r: f}
+	if typ, _, err := r.readHeader(0); err != nil {
+		return nil, err
+	} else if typ != TypeVersion {
+		return nil, fmt.Errorf("invalid version entry: %#x", typ)
+	}
+
+	// The block count is stored in the last 8 bytes of the file.
+	var buf [8]byte
+	if length < headerSize+24 {
+		return nil, errors.New("era file is too short")
+	}
+	if _, err := f.ReadAt(buf[:], length-8); err != nil {
+		return nil, err
+	}
+	count := binary.LittleEndian.Uint64(buf[:])
+	if count == 0 || count > EpochSize {
+		return nil, fmt.Errorf("invalid block count: %d", count)
+	}
+	indexOffset := length - (headerSize + 16 + 8*int64(count))
+	index, _, err := r.readTypedAt(indexOffset, TypeBlockIndex)
+	if err != nil {
+		return nil, err
+	}
+	e := &Era{
+		f:       f,
+		r:       r,
+		start:   binary.LittleEndian.Uint64(index.Value),
+		count:   count,
+		offsets: make([]int64, count),
+		length:  length,
+	}
+	for i := range e.offsets {
+		relative := int64(binary.LittleEndian.Uint64(index.Value[8+i*8:]))
+		e.offsets[i] = indexOffset + relative
+		if e.offsets[i] < 0 || e.offsets[i] >= indexOffset {
+			return nil, fmt.Errorf("invalid offset of block #%d", e.start+uint64(i))
+		}
+	}
+	return e, nil
+}
+
+// Close closes the underlying file.
+func (e *Era) Close() error {
+	return e.f.Close()
+}
+
+// Start returns the number of the first block in the era.
+func (e *Era) Start() uint64 {
+	return e.start
+}
+
+// Count returns the number of blocks in the era.
+func (e *Era) Count() uint64 {
+	return e.count
+}
+
+// readCompressed decodes the compressed entry at the given offset into val and
+// returns the total length of the entry.
+func (e *Era) readCompressed(off int64, typ uint16, val interface{}) (int64, error) {
+	entry, n, err := e.r.readTypedAt(off, typ)
+	if err != nil {
+		return 0, err
+	}
+	if err := rlp.Decode(snappy.NewReader(bytes.NewReader(entry.Value)), val); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (e *Era) checkNumber(num uint64) error {
+	if num < e.start || num >= e.start+e.count {
+		return fmt.Errorf("block #%d is out of range [%d, %d]", num, e.start, e.start+e.count-1)
+	}
+	return nil
+}
+
+// GetHeaderByNumber returns the header of the given block number.
+func (e *Era) GetHeaderByNumber(num uint64) (*types.Header, error) {
+	if err := e.checkNumber(num); err != nil {
+		return nil, err
+	}
+	header := new(types.Header)
+	if _, err := e.readCompressed(e.offsets[num-e.start], TypeCompressedHeader, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// GetBlockByNumber returns the block of the given block number.
+func (e *Era) GetBlockByNumber(num uint64) (*types.Block, error) {
+	block, _, err := e.getBlockAndReceipts(num, false)
+	return block, err
+}
+
+// GetBlockAndReceiptsByNumber returns the block of the given number and its receipts.
+func (e *Era) GetBlockAndReceiptsByNumber(num uint64) (*types.Block, types.Receipts, error) {
+	return e.getBlockAndReceipts(num, true)
+}
+
+func (e *Era) getBlockAndReceipts(num uint64, withReceipts bool) (*types.Block, types.Receipts, error) {
+	if err := e.checkNumber(num); err != nil {
+		return nil, nil, err
+	}
+	off := e.offsets[num-e.start]
+
+	header := new(types.Header)
+	n, err := e.readCompressed(off, TypeCompressedHeader, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	off += n
+
+	body := new(types.Body)
+	if n, err = e.readCompressed(off, TypeCompressedBody, body); err != nil {
+		return nil, nil, err
+	}
+	off += n
+	block := types.NewBlockWithHeader(header).WithBody(body.Transactions)
+	if !withReceipts {
+		return block, nil, nil
+	}
+
+	var storageReceipts []*types.ReceiptForStorage
+	if _, err := e.readCompressed(off, TypeCompressedReceipts, &storageReceipts); err != nil {
+		return nil, nil, err
+	}
+	receipts := make(types.Receipts, len(storageReceipts))
+	for i, receipt := range storageReceipts {
+		receipts[i] = (*types.Receipt)(receipt)
+	}
+	return block, receipts, nil
+}
+
+// Accumulator returns the accumulator root stored in the era.
+func (e *Era) Accumulator() (common.Hash, error) {
+	// The accumulator entry is placed right before the block index.
+	off := e.length - (headerSize + 16 + 8*int64(e.count)) - (headerSize + common.HashLength)
+	entry, _, err := e.r.readTypedAt(off, TypeAccumulator)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(entry.Value), nil
+}
+
+// CanonicalHashReader reads the hash of a canonical block.
+type CanonicalHashReader interface {
+	ReadCanonicalHash(number uint64) common.Hash
+}
+
+// Verify checks the integrity of the era: the blocks are linked, the transaction
+// and receipt roots match the headers and the stored accumulator matches the
+// blocks. If chain is not nil, every block hash is also checked against the
+// canonical chain. types.DeriveSha must be initialized by the caller.
+func (e *Era) Verify(chain CanonicalHashReader) error {
+	hashes := make([]common.Hash, 0, e.count)
+	for num := e.start; num < e.start+e.count; num++ {
+		block, receipts, err := e.GetBlockAndReceiptsByNumber(num)
+		if err != nil {
+			return fmt.Errorf("failed to read block #%d: %v", num, err)
+		}
+		if block.NumberU64() != num {
+			return fmt.Errorf("block number mismatch: have #%d, want #%d", block.NumberU64(), num)
+		}
+		hash := block.Hash()
+		if len(hashes) > 0 && block.ParentHash() != hashes[len(hashes)-1] {
+			return fmt.Errorf("block #%d is not linked to its parent", num)
+		}
+		if chain != nil {
+			if canonical := chain.ReadCanonicalHash(num); canonical != hash {
+				return fmt.Errorf("block #%d hash mismatch: have %x, canonical %x", num, hash, canonical)
+			}
+		}
+		if root := types.DeriveSha(block.Transactions(), block.Number()); root != block.Header().TxHash {
+			return fmt.Errorf("block #%d transaction root mismatch: have %x, want %x", num, root, block.Header().TxHash)
+		}
+		if root := types.DeriveSha(receipts, block.Number()); root != block.ReceiptHash() {
+			return fmt.Errorf("block #%d receipts root mismatch: have %x, want %x", num, root, block.ReceiptHash())
+		}
+		hashes = append(hashes, hash)
+	}
+	want, err := e.Accumulator()
+	if err != nil {
+		return err
+	}
+	root, err := ComputeAccumulator(hashes)
+	if err != nil {
+		return err
+	}
+	if root != want {
+		return fmt.Errorf("accumulator mismatch: have %x, want %x", root, want)
+	}
+	return nil
+}
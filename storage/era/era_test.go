@@ -0,0 +1,183 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/derivesha"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testChain map[uint64]common.Hash
+
+func (c testChain) ReadCanonicalHash(number uint64) common.Hash { return c[number] }
+
+// makeBlocks returns a chain of linked blocks starting at the given number.
+func makeBlocks(start uint64, n int) ([]*types.Block, []types.Receipts) {
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+		parent   common.Hash
+		key, _   = crypto.GenerateKey()
+		signer   = types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	)
+	for i := 0; i < n; i++ {
+		num := start + uint64(i)
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(num),
+			Extra:      []byte{0x1, byte(i)},
+			Time:       big.NewInt(int64(i)),
+		}
+		var (
+			txs []*types.Transaction
+			rs  types.Receipts
+		)
+		for j := 0; j < i%3; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(j), common.Address{byte(i)}, big.NewInt(int64(j)), 21000, big.NewInt(1), nil), signer, key)
+			txs = append(txs, tx)
+			rs = append(rs, &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, Logs: []*types.Log{}, TxHash: txs[j].Hash()})
+		}
+		block := types.NewBlock(header, txs, rs)
+		blocks, receipts = append(blocks, block), append(receipts, rs)
+		parent = block.Hash()
+	}
+	return blocks, receipts
+}
+
+func writeEra(t *testing.T, path string, blocks []*types.Block, receipts []types.Receipts) common.Hash {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	b := NewBuilder(f)
+	for i, block := range blocks {
+		require.NoError(t, b.Add(block, receipts[i]))
+	}
+	root, err := b.Finalize()
+	require.NoError(t, err)
+	return root
+}
+
+func TestEra(t *testing.T) {
+	derivesha.InitDeriveSha(params.TestChainConfig, nil)
+
+	dir, err := os.MkdirTemp("", "klaytn-era")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	blocks, receipts := makeBlocks(EpochSize, 50)
+	path := filepath.Join(dir, "test.era1")
+	root := writeEra(t, path, blocks, receipts)
+
+	e, err := Open(path)
+	require.NoError(t, err)
+	defer e.Close()
+
+	assert.Equal(t, uint64(EpochSize), e.Start())
+	assert.Equal(t, uint64(len(blocks)), e.Count())
+
+	stored, err := e.Accumulator()
+	require.NoError(t, err)
+	assert.Equal(t, root, stored)
+
+	chain := make(testChain)
+	for i, want := range blocks {
+		block, rs, err := e.GetBlockAndReceiptsByNumber(want.NumberU64())
+		require.NoError(t, err)
+		assert.Equal(t, want.Hash(), block.Hash())
+		assert.Equal(t, want.Extra(), block.Extra())
+		assert.Equal(t, len(receipts[i]), len(rs))
+
+		header, err := e.GetHeaderByNumber(want.NumberU64())
+		require.NoError(t, err)
+		assert.Equal(t, want.Hash(), header.Hash())
+
+		chain[want.NumberU64()] = want.Hash()
+	}
+	_, err = e.GetBlockByNumber(EpochSize + uint64(len(blocks)))
+	assert.Error(t, err)
+
+	assert.NoError(t, e.Verify(chain))
+	assert.NoError(t, e.Verify(nil))
+
+	// A block not matching the canonical chain must be detected.
+	chain[EpochSize+10] = common.Hash{0x1}
+	assert.Error(t, e.Verify(chain))
+}
+
+func TestBuilderErrors(t *testing.T) {
+	derivesha.InitDeriveSha(params.TestChainConfig, nil)
+
+	blocks, receipts := makeBlocks(0, 3)
+	b := NewBuilder(new(discard))
+	_, err := b.Finalize()
+	assert.Equal(t, errEmptyEra, err)
+
+	require.NoError(t, b.Add(blocks[0], receipts[0]))
+	assert.Error(t, b.Add(blocks[2], receipts[2]), "non contiguous block must be rejected")
+	require.NoError(t, b.Add(blocks[1], receipts[1]))
+
+	_, err = b.Finalize()
+	require.NoError(t, err)
+	assert.Equal(t, errBuilderFinalized, b.Add(blocks[2], receipts[2]))
+}
+
+func TestReadDir(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-era-dir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, epoch := range []uint64{10, 2, 1} {
+		name := Filename("cypress", epoch, common.Hash{byte(epoch)})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "baobab-00001-00000000.era1"), nil, 0o600))
+
+	files, err := ReadDir(dir, "cypress")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "cypress-00001-01000000.era1"),
+		filepath.Join(dir, "cypress-00002-02000000.era1"),
+		filepath.Join(dir, "cypress-00010-0a000000.era1"),
+	}, files)
+}
+
+func TestAccumulator(t *testing.T) {
+	empty, err := ComputeAccumulator(nil)
+	require.NoError(t, err)
+
+	one, err := ComputeAccumulator([]common.Hash{{0x1}})
+	require.NoError(t, err)
+	assert.NotEqual(t, empty, one)
+
+	_, err = ComputeAccumulator(make([]common.Hash, EpochSize+1))
+	assert.Error(t, err)
+}
+
+type discard struct{}
+
+func (*discard) Write(p []byte) (int, error) { return len(p), nil }
@@ -31,6 +31,8 @@ type TrieNodeCacheConfig struct {
 	CacheType                 TrieNodeCacheType
 	NumFetcherPrefetchWorker  int           // Number of workers used to prefetch a block when fetcher works
 	UseSnapshotForPrefetch    bool          // Enable snapshot functionality while prefetching
+	PoolPrefetchTxs           int           // Number of pending transactions executed to prefetch the next block, 0 means disabled
+	PoolPrefetchCPUPercent    int           // Maximum percentage of a CPU spent by the tx pool prefetcher
 	LocalCacheSizeMiB         int           // Memory allowance (MiB) to use for caching trie nodes in fast cache
 	FastCacheFileDir          string        // Directory where the persistent fastcache data is stored
 	FastCacheSavePeriod       time.Duration // Period of saving in memory trie cache to file if fastcache is used
@@ -38,6 +40,9 @@ type TrieNodeCacheConfig struct {
 	RedisClusterEnable        bool          // Enable cluster-enabled mode of redis cache
 	RedisPublishBlockEnable   bool          // Enable publishing every inserted block to the redis server
 	RedisSubscribeBlockEnable bool          // Enable subscribing blocks from the redis server
+	WarmCacheFile             string        // File where the hot trie nodes are saved on shutdown and restored from on startup
+	WarmCacheMaxEntries       int           // Maximum number of recently accessed trie nodes kept for a warm restart, 0 means disabled
+	WarmCacheMaxSizeMiB       int           // Maximum size (MiB) of the trie nodes saved and restored for a warm restart
 }
 
 func (c *TrieNodeCacheConfig) DumpPeriodically() bool {
@@ -0,0 +1,223 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/rcrowley/go-metrics"
+)
+
+var (
+	memcacheWarmRestoredGauge = metrics.NewRegisteredGauge("trie/memcache/warm/restored", nil)
+	memcacheWarmHitMeter      = metrics.NewRegisteredMeter("trie/memcache/warm/hits", nil)
+
+	errWarmCacheDisabled = errors.New("trie node cache warm restart is disabled")
+)
+
+// warmCacheRecord is an entry of a warm cache file.
+type warmCacheRecord struct {
+	Hash common.ExtHash
+	Node []byte
+}
+
+// warmCache tracks the recently accessed nodes of the trie node cache, whatever
+// its type, so that the hottest nodes can be saved on shutdown and restored in
+// the cache on startup.
+type warmCache struct {
+	maxSize uint64
+
+	lock   sync.Mutex
+	recent *simplelru.LRU // Recently accessed node hashes, the value is the size of the node
+
+	restoredLock sync.Mutex
+	restored     map[common.ExtHash]struct{} // Restored nodes not hit yet
+	restoredLeft int64                       // Number of restored nodes not hit yet
+}
+
+func newWarmCache(config *TrieNodeCacheConfig) *warmCache {
+	if config.WarmCacheFile == "" || config.WarmCacheMaxEntries <= 0 {
+		return nil
+	}
+	recent, _ := simplelru.NewLRU(config.WarmCacheMaxEntries, nil)
+	return &warmCache{
+		maxSize: uint64(config.WarmCacheMaxSizeMiB) * uint64(units.MiB),
+		recent:  recent,
+	}
+}
+
+// touch records an access of the given node. The first hit of a restored node
+// is counted.
+func (w *warmCache) touch(hash common.ExtHash, size int, hit bool) {
+	w.lock.Lock()
+	w.recent.Add(hash, size)
+	w.lock.Unlock()
+
+	if !hit || atomic.LoadInt64(&w.restoredLeft) == 0 {
+		return
+	}
+	w.restoredLock.Lock()
+	if _, ok := w.restored[hash]; ok {
+		delete(w.restored, hash)
+		atomic.AddInt64(&w.restoredLeft, -1)
+		memcacheWarmHitMeter.Mark(1)
+	}
+	w.restoredLock.Unlock()
+}
+
+// hottest returns the recently accessed node hashes, the most recent first,
+// within the size cap.
+func (w *warmCache) hottest() []common.ExtHash {
+	w.lock.Lock()
+	keys := w.recent.Keys()
+	sizes := make([]uint64, len(keys))
+	for i, key := range keys {
+		size, _ := w.recent.Peek(key)
+		sizes[i] = uint64(size.(int))
+	}
+	w.lock.Unlock()
+
+	var (
+		hashes []common.ExtHash
+		total  uint64
+	)
+	for i := len(keys) - 1; i >= 0; i-- {
+		if w.maxSize > 0 && total+sizes[i] > w.maxSize {
+			break
+		}
+		total += sizes[i]
+		hashes = append(hashes, keys[i].(common.ExtHash))
+	}
+	return hashes
+}
+
+// SaveWarmCache writes the recently accessed trie nodes still in the trie node
+// cache to the warm cache file, the most recent first.
+func (db *Database) SaveWarmCache() error {
+	if db.warmCache == nil || db.trieNodeCache == nil {
+		return errWarmCacheDisabled
+	}
+	var (
+		start  = time.Now()
+		path   = db.trieNodeCacheConfig.WarmCacheFile
+		tmp    = path + ".tmp"
+		hashes = db.warmCache.hottest()
+		saved  int
+	)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	for _, hash := range hashes {
+		enc := db.trieNodeCache.Get(hash[:])
+		if enc == nil {
+			continue // Evicted since the access
+		}
+		if err := rlp.Encode(w, &warmCacheRecord{Hash: hash, Node: enc}); err != nil {
+			file.Close()
+			return err
+		}
+		saved++
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	logger.Info("Saved hot trie nodes for warm restart", "file", path, "nodes", saved, "elapsed", time.Since(start))
+	return nil
+}
+
+// restoreWarmCache loads the trie nodes of the warm cache file into the trie
+// node cache, up to the size cap. The file is removed afterwards, so that stale
+// nodes are not restored by a later unclean restart.
+func (db *Database) restoreWarmCache() error {
+	path := db.trieNodeCacheConfig.WarmCacheFile
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	defer file.Close()
+
+	var (
+		start    = time.Now()
+		stream   = rlp.NewStream(bufio.NewReader(file), 0)
+		restored = make(map[common.ExtHash]struct{})
+		order    []common.ExtHash
+		sizes    []int
+		size     uint64
+	)
+	for err == nil {
+		var rec warmCacheRecord
+		if err = stream.Decode(&rec); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			break
+		}
+		if db.warmCache.maxSize > 0 && size+uint64(len(rec.Node)) > db.warmCache.maxSize {
+			break
+		}
+		if err = VerifyNodeHash(rec.Hash, rec.Node); err != nil {
+			break
+		}
+		db.trieNodeCache.Set(rec.Hash[:], rec.Node)
+		restored[rec.Hash] = struct{}{}
+		order = append(order, rec.Hash)
+		sizes = append(sizes, len(rec.Node))
+		size += uint64(len(rec.Node))
+	}
+	// Keep the ranking of the restored nodes until they are accessed again
+	db.warmCache.lock.Lock()
+	for i := len(order) - 1; i >= 0; i-- {
+		db.warmCache.recent.Add(order[i], sizes[i])
+	}
+	db.warmCache.lock.Unlock()
+
+	db.warmCache.restoredLock.Lock()
+	db.warmCache.restored = restored
+	atomic.StoreInt64(&db.warmCache.restoredLeft, int64(len(restored)))
+	db.warmCache.restoredLock.Unlock()
+	memcacheWarmRestoredGauge.Update(int64(len(restored)))
+
+	logger.Info("Restored hot trie nodes for warm restart", "file", path, "nodes", len(restored),
+		"size", common.StorageSize(size), "elapsed", time.Since(start))
+	return err
+}
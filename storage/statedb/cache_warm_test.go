@@ -0,0 +1,102 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestWarmCacheConfig(dir string, entries int) *TrieNodeCacheConfig {
+	return &TrieNodeCacheConfig{
+		CacheType:           CacheTypeLocal,
+		LocalCacheSizeMiB:   32,
+		FastCacheFileDir:    filepath.Join(dir, fmt.Sprintf("fastcache-%d", entries)),
+		WarmCacheFile:       filepath.Join(dir, "triecache.warm"),
+		WarmCacheMaxEntries: entries,
+	}
+}
+
+func TestWarmCache(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		dbm = database.NewMemoryDBManager()
+		db  = NewDatabaseWithNewCache(dbm, getTestWarmCacheConfig(dir, 1000))
+	)
+	trie, _ := NewTrie(common.Hash{}, db, nil)
+	for i := 0; i < 200; i++ {
+		updateString(trie, fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	root, err := trie.Commit(nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Commit(root, false, 0))
+
+	// Reading the trie ranks its nodes
+	trie, err = NewTrie(root, db, nil)
+	require.NoError(t, err)
+	for i := 0; i < 200; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), string(getString(trie, fmt.Sprintf("key%d", i))))
+	}
+	tracked := len(db.warmCache.hottest())
+	require.NotZero(t, tracked)
+	require.NoError(t, db.SaveWarmCache())
+
+	// The nodes are restored in an empty cache on startup
+	restarted := NewDatabaseWithNewCache(dbm, getTestWarmCacheConfig(dir, 1000))
+	assert.Equal(t, int64(tracked), atomic.LoadInt64(&restarted.warmCache.restoredLeft))
+	assert.Len(t, restarted.warmCache.hottest(), tracked)
+	_, err = os.Stat(filepath.Join(dir, "triecache.warm"))
+	assert.True(t, os.IsNotExist(err))
+
+	trie, err = NewTrie(root, restarted, nil)
+	require.NoError(t, err)
+	for i := 0; i < 200; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), string(getString(trie, fmt.Sprintf("key%d", i))))
+	}
+	assert.Zero(t, atomic.LoadInt64(&restarted.warmCache.restoredLeft))
+
+	// The number of saved nodes is capped
+	capped := NewDatabaseWithNewCache(dbm, getTestWarmCacheConfig(dir, 5))
+	trie, err = NewTrie(root, capped, nil)
+	require.NoError(t, err)
+	for i := 0; i < 200; i++ {
+		getString(trie, fmt.Sprintf("key%d", i))
+	}
+	require.NoError(t, capped.SaveWarmCache())
+	restarted = NewDatabaseWithNewCache(dbm, getTestWarmCacheConfig(dir, 1000))
+	assert.Equal(t, int64(5), atomic.LoadInt64(&restarted.warmCache.restoredLeft))
+
+	// A corrupted node stops the restore
+	require.NoError(t, restarted.SaveWarmCache())
+	data, err := os.ReadFile(filepath.Join(dir, "triecache.warm"))
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "triecache.warm"), data, 0o644))
+	restarted = NewDatabaseWithNewCache(dbm, getTestWarmCacheConfig(dir, 1000))
+	assert.Less(t, atomic.LoadInt64(&restarted.warmCache.restoredLeft), int64(5))
+
+	assert.Nil(t, NewDatabaseWithNewCache(dbm, getTestWarmCacheConfig(dir, 0)).warmCache)
+	assert.Equal(t, errWarmCacheDisabled, NewDatabase(dbm).SaveWarmCache())
+}
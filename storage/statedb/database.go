@@ -94,6 +94,9 @@ type Database struct {
 	preimages    map[common.Hash][]byte // Preimages of nodes from the secure trie
 	pruningMarks []database.PruningMark // Trie node pruning marks from the pruning trie
 
+	path     *pathDB                // Path-based disk layer, nil with the hash scheme
+	pathKept map[common.Hash]uint64 // Side chain states kept in memory with the path scheme, by block number
+
 	gctime  time.Duration      // Time spent on garbage collection since last commit
 	gcnodes uint64             // Nodes garbage collected since last commit
 	gcsize  common.StorageSize // Data storage garbage collected since last commit
@@ -111,6 +114,7 @@ type Database struct {
 	trieNodeCache                TrieNodeCache        // GC friendly memory cache of trie node RLPs
 	trieNodeCacheConfig          *TrieNodeCacheConfig // Configuration of trieNodeCache
 	savingTrieNodeCacheTriggered bool                 // Whether saving trie node cache has been triggered or not
+	warmCache                    *warmCache           // Tracker of the hot trie nodes saved for warm restarts
 }
 
 // rawNode is a simple binary blob used to differentiate between collapsed trie
@@ -308,13 +312,23 @@ func NewDatabaseWithNewCache(diskDB database.DBManager, cacheConfig *TrieNodeCac
 		logger.Error("Invalid trie node cache config", "err", err, "config", cacheConfig)
 	}
 
-	return &Database{
+	db := &Database{
 		diskDB:              diskDB,
 		nodes:               map[common.ExtHash]*cachedNode{{}: {}},
 		preimages:           make(map[common.Hash][]byte),
+		path:                openPathDB(diskDB),
+		pathKept:            make(map[common.Hash]uint64),
 		trieNodeCache:       trieNodeCache,
 		trieNodeCacheConfig: cacheConfig,
 	}
+	if trieNodeCache != nil && cacheConfig != nil {
+		if db.warmCache = newWarmCache(cacheConfig); db.warmCache != nil {
+			if err := db.restoreWarmCache(); err != nil {
+				logger.Warn("Failed to restore hot trie nodes", "file", cacheConfig.WarmCacheFile, "err", err)
+			}
+		}
+	}
+	return db
 }
 
 // NewDatabaseWithExistingCache creates a new trie database to store ephemeral trie content
@@ -325,6 +339,8 @@ func NewDatabaseWithExistingCache(diskDB database.DBManager, cache TrieNodeCache
 		diskDB:        diskDB,
 		nodes:         map[common.ExtHash]*cachedNode{{}: {}},
 		preimages:     make(map[common.Hash][]byte),
+		path:          openPathDB(diskDB),
+		pathKept:      make(map[common.Hash]uint64),
 		trieNodeCache: cache,
 	}
 }
@@ -474,6 +490,9 @@ func (db *Database) getCachedNode(hash common.ExtHash) []byte {
 		if enc := db.trieNodeCache.Get(hash[:]); enc != nil {
 			memcacheCleanHitMeter.Mark(1)
 			memcacheCleanReadMeter.Mark(int64(len(enc)))
+			if db.warmCache != nil {
+				db.warmCache.touch(hash, len(enc), true)
+			}
 			return enc
 		}
 	}
@@ -485,6 +504,9 @@ func (db *Database) setCachedNode(hash common.ExtHash, enc []byte) {
 	if db.trieNodeCache != nil {
 		db.trieNodeCache.Set(hash[:], enc)
 		memcacheCleanWriteMeter.Mark(int64(len(enc)))
+		if db.warmCache != nil {
+			db.warmCache.touch(hash, len(enc), false)
+		}
 	}
 }
 
@@ -588,11 +610,15 @@ func (db *Database) DoesExistCachedNode(hash common.ExtHash) bool {
 }
 
 // DoesExistNodeInPersistent returns if the node exists on the persistent database or its cache.
+// With the path scheme, the node must be a state root.
 func (db *Database) DoesExistNodeInPersistent(hash common.ExtHash) bool {
 	// Retrieve the node from DB cache if available
 	if enc := db.getCachedNode(hash); enc != nil {
 		return true
 	}
+	if db.path != nil {
+		return db.path.node(common.Hash{}, nil, hash) != nil
+	}
 
 	// Content unavailable in DB cache, attempt to retrieve from disk
 	enc, err := db.diskDB.ReadTrieNode(hash)
@@ -742,7 +768,12 @@ func (db *Database) dereference(child common.ExtHash, parent common.ExtHash) {
 
 // Cap iteratively flushes old but still referenced trie nodes until the total
 // memory usage goes below the given threshold.
+//
+// With the path scheme, nodes are only flushed by Commit.
 func (db *Database) Cap(limit common.StorageSize) error {
+	if db.path != nil {
+		return nil
+	}
 	// Create a database batch to flush persistent data out. It is important that
 	// outside code doesn't see an inconsistent state (referenced data removed from
 	// memory cache during commit but not yet in persistent database). This is ensured
@@ -900,7 +931,13 @@ func (db *Database) concurrentCommit(hash common.ExtHash, resultCh chan<- commit
 // The root must be a state root.
 //
 // As a side effect, all pre-images accumulated up to this point are also written.
+//
+// With the path scheme, the state replaces the one on disk, and blockNum
+// identifies the reverse diff kept for the replaced state.
 func (db *Database) Commit(root common.Hash, report bool, blockNum uint64) error {
+	if db.path != nil {
+		return db.commitPath(root, report, blockNum)
+	}
 	hash := root.ExtendZero()
 	// Create a database batch to flush persistent data out. It is important that
 	// outside code doesn't see an inconsistent state (referenced data removed from
@@ -21,11 +21,13 @@
 package statedb
 
 import (
+	"fmt"
 	"hash"
 	"sync"
 
 	"github.com/klaytn/klaytn/blockchain/types/account"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/crypto/sha3"
 	"github.com/klaytn/klaytn/rlp"
 )
@@ -271,6 +273,27 @@ func (h *hasher) encodedBytes() []byte {
 	return h.tmp
 }
 
+// VerifyNodeHash checks that an encoded trie node, which may refer to its
+// children with extended hashes, has the given hash.
+func VerifyNodeHash(hash common.ExtHash, enc []byte) error {
+	n, err := decodeNode(nil, enc)
+	if err != nil {
+		return err
+	}
+	h := newHasher(nil)
+	defer returnHasherToPool(h)
+
+	collapsed, _ := h.hashChildren(n, nil, false)
+	hashing, err := rlp.EncodeToBytes(h.nodeForHashing(collapsed))
+	if err != nil {
+		return err
+	}
+	if have := crypto.Keccak256Hash(hashing); have != hash.Unextend() {
+		return fmt.Errorf("trie node hash mismatch: have %x, want %x", have, hash.Unextend())
+	}
+	return nil
+}
+
 func (h *hasher) nodeForHashing(original node) node {
 	return unextendNode(original, false)
 }
@@ -0,0 +1,617 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/rcrowley/go-metrics"
+)
+
+// Trie node schemes. With the hash scheme, trie nodes are stored by hash and
+// every state ever flushed stays on disk until it is pruned. With the path
+// scheme, trie nodes are stored by owner and path, so that only the latest
+// state is on disk, and the recent states are kept as reverse diffs.
+const (
+	HashScheme = "hash"
+	PathScheme = "path"
+)
+
+// DefaultPathHistory is the default number of blocks whose state is kept with
+// the path scheme, in addition to the latest one.
+const DefaultPathHistory = 128
+
+// SetupTrieNodeScheme stores the given trie node scheme in a new database, one
+// without a genesis block, and returns the scheme of the database. An empty
+// scheme selects the stored one, which is the hash scheme if none is stored.
+func SetupTrieNodeScheme(diskDB database.DBManager, scheme string) (string, error) {
+	if scheme != "" && scheme != HashScheme && scheme != PathScheme {
+		return "", fmt.Errorf("invalid trie node scheme %q", scheme)
+	}
+	stored := diskDB.ReadTrieNodeScheme()
+	if stored == "" {
+		if diskDB.ReadCanonicalHash(0) != (common.Hash{}) || scheme == "" {
+			return HashScheme, checkScheme(HashScheme, scheme)
+		}
+		if scheme == PathScheme {
+			diskDB.WriteTrieNodeScheme(scheme)
+		}
+		return scheme, nil
+	}
+	return stored, checkScheme(stored, scheme)
+}
+
+func checkScheme(stored, scheme string) error {
+	if scheme != "" && scheme != stored {
+		return fmt.Errorf("cannot use the %s trie node scheme, the database uses the %s scheme", scheme, stored)
+	}
+	return nil
+}
+
+var (
+	pathHistoryHitMeter  = metrics.NewRegisteredMeter("trie/path/history/hit", nil)
+	pathHistoryMissMeter = metrics.NewRegisteredMeter("trie/path/history/miss", nil)
+	pathCommitTimeGauge  = metrics.NewRegisteredGauge("trie/path/commit/time", nil)
+	pathCommitNodesMeter = metrics.NewRegisteredMeter("trie/path/commit/nodes", nil)
+)
+
+// pathDBs holds the path-based disk layers by disk database, so that every
+// Database opened on the same disk database shares the history index.
+var pathDBs = struct {
+	sync.Mutex
+	dbs map[database.DBManager]*pathDB
+}{dbs: make(map[database.DBManager]*pathDB)}
+
+// pathWrite is a trie node written by a commit with the path scheme.
+type pathWrite struct {
+	key  string // Owner and hex path
+	hash common.ExtHash
+	blob []byte
+}
+
+// pathDB is the disk layer of the path scheme. The trie nodes of the latest
+// state are stored by owner, which is the hash of the account for a storage
+// trie and zero for the account trie, and hex path. Every commit stores the
+// previous value of the nodes it overwrites as a reverse diff, and the diffs of
+// the last history blocks are kept, so that their states can be read and the
+// disk state can be reverted to them.
+//
+// Nodes of deleted subtries are not removed, they stay until a node is written
+// at the same path again. A node is only returned if its hash matches the
+// requested one, so stale nodes are never used.
+type pathDB struct {
+	diskDB  database.DBManager
+	history uint64
+
+	head    *database.PathTrieHead            // The state on disk, nil before the first commit
+	diffs   map[uint64]*database.PathTrieDiff // Reverse diffs by block number
+	numbers []uint64                          // Block numbers of the reverse diffs, ascending
+	index   map[string][]uint64               // Block numbers of the diffs containing a node, ascending
+	lock    sync.RWMutex
+}
+
+// openPathDB returns the path-based disk layer of the given disk database, or
+// nil if the database uses the hash scheme.
+func openPathDB(diskDB database.DBManager) *pathDB {
+	if diskDB.ReadTrieNodeScheme() != PathScheme {
+		return nil
+	}
+	pathDBs.Lock()
+	defer pathDBs.Unlock()
+
+	if l, ok := pathDBs.dbs[diskDB]; ok {
+		return l
+	}
+	l, err := loadPathDB(diskDB)
+	if err != nil {
+		logger.Crit("Failed to load the path trie history", "err", err)
+	}
+	pathDBs.dbs[diskDB] = l
+	return l
+}
+
+func loadPathDB(diskDB database.DBManager) (*pathDB, error) {
+	l := &pathDB{
+		diskDB:  diskDB,
+		history: DefaultPathHistory,
+		head:    diskDB.ReadPathTrieHead(),
+		diffs:   make(map[uint64]*database.PathTrieDiff),
+		index:   make(map[string][]uint64),
+	}
+	if l.head == nil {
+		return l, nil
+	}
+	// A pending diff was interrupted while being applied or reverted.
+	if l.head.Pending != 0 {
+		number := l.head.Pending - 1
+		if diff := diskDB.ReadPathTrieDiff(number); diff != nil {
+			logger.Warn("Reverting an interrupted path trie diff", "number", number, "root", diff.Root)
+			if err := l.revertDiff(number, diff, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+	number, root := l.head.Number, l.head.Root
+	for {
+		diff := diskDB.ReadPathTrieDiff(number)
+		if diff == nil || diff.Root != root || diff.Parent >= number {
+			break
+		}
+		l.numbers = append([]uint64{number}, l.numbers...)
+		l.diffs[number] = diff
+		number, root = diff.Parent, diff.ParentRoot
+	}
+	for _, number := range l.numbers {
+		for _, key := range l.diffs[number].Keys {
+			l.index[string(key)] = append(l.index[string(key)], number)
+		}
+	}
+	logger.Info("Loaded the path trie history", "head", l.head.Number, "root", l.head.Root, "diffs", len(l.numbers))
+	return l, nil
+}
+
+func pathKey(owner common.Hash, path []byte) string {
+	return string(owner[:]) + string(path)
+}
+
+func splitPathKey(key []byte) (common.Hash, []byte) {
+	return common.BytesToHash(key[:common.HashLength]), key[common.HashLength:]
+}
+
+// node returns the trie node of the given hash stored at the given owner and
+// path, in the latest state or in the history.
+func (l *pathDB) node(owner common.Hash, path []byte, hash common.ExtHash) []byte {
+	want := hash.Unextend()
+	if blob := l.diskDB.ReadPathTrieNode(owner, path); blob != nil && crypto.Keccak256Hash(blob) == want {
+		return blob
+	}
+	l.lock.RLock()
+	numbers := append([]uint64{}, l.index[pathKey(owner, path)]...)
+	l.lock.RUnlock()
+
+	// Recent states are queried the most, so look at the newest diffs first.
+	for i := len(numbers) - 1; i >= 0; i-- {
+		if blob, _ := l.diskDB.ReadPathTrieHistory(numbers[i], owner, path); blob != nil && crypto.Keccak256Hash(blob) == want {
+			pathHistoryHitMeter.Mark(1)
+			return blob
+		}
+	}
+	pathHistoryMissMeter.Mark(1)
+	return nil
+}
+
+// commit writes the given nodes of the state of the given block on top of the
+// state on disk, and stores the previous values as the reverse diff of the block.
+func (l *pathDB) commit(root common.Hash, number uint64, nodes []pathWrite) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.head != nil {
+		if l.head.Root == root {
+			return nil
+		}
+		if number <= l.head.Number {
+			return fmt.Errorf("cannot write the state of block %d on top of block %d", number, l.head.Number)
+		}
+	}
+	batch := l.diskDB.NewBatch(database.StateTrieDB)
+	defer batch.Release()
+
+	// Write the reverse diff first, and mark it pending until the nodes are written.
+	var (
+		diff    *database.PathTrieDiff
+		written = make([]pathWrite, 0, len(nodes))
+	)
+	if l.head != nil {
+		diff = &database.PathTrieDiff{Parent: l.head.Number, ParentRoot: l.head.Root, Root: root}
+	}
+	for _, n := range nodes {
+		owner, path := splitPathKey([]byte(n.key))
+		prev := l.diskDB.ReadPathTrieNode(owner, path)
+		if bytes.Equal(prev, n.blob) {
+			continue
+		}
+		written = append(written, n)
+		if diff == nil {
+			continue
+		}
+		l.diskDB.PutPathTrieHistoryToBatch(batch, number, owner, path, prev)
+		diff.Keys = append(diff.Keys, []byte(n.key))
+		if _, err := database.WriteBatchesOverThreshold(batch); err != nil {
+			return err
+		}
+	}
+	if diff != nil {
+		l.diskDB.PutPathTrieDiffToBatch(batch, number, diff)
+		l.diskDB.PutPathTrieHeadToBatch(batch, &database.PathTrieHead{Number: l.head.Number, Root: l.head.Root, Pending: number + 1})
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+
+		l.numbers = append(l.numbers, number)
+		l.diffs[number] = diff
+		for _, key := range diff.Keys {
+			l.index[string(key)] = append(l.index[string(key)], number)
+		}
+	}
+
+	// Write the nodes and move the head.
+	for _, n := range written {
+		owner, path := splitPathKey([]byte(n.key))
+		l.diskDB.PutPathTrieNodeToBatch(batch, owner, path, n.blob)
+		if _, err := database.WriteBatchesOverThreshold(batch); err != nil {
+			return err
+		}
+	}
+	head := &database.PathTrieHead{Number: number, Root: root}
+	l.diskDB.PutPathTrieHeadToBatch(batch, head)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	l.head = head
+	pathCommitNodesMeter.Mark(int64(len(written)))
+
+	return l.prune()
+}
+
+// prune deletes the oldest reverse diffs beyond the history limit.
+func (l *pathDB) prune() error {
+	if uint64(len(l.numbers)) <= l.history {
+		return nil
+	}
+	batch := l.diskDB.NewBatch(database.StateTrieDB)
+	defer batch.Release()
+
+	for uint64(len(l.numbers)) > l.history {
+		number := l.numbers[0]
+		diff := l.diffs[number]
+		for _, key := range diff.Keys {
+			owner, path := splitPathKey(key)
+			l.diskDB.DeletePathTrieHistoryFromBatch(batch, number, owner, path)
+			if numbers := l.index[string(key)]; len(numbers) <= 1 {
+				delete(l.index, string(key))
+			} else {
+				l.index[string(key)] = numbers[1:]
+			}
+			if _, err := database.WriteBatchesOverThreshold(batch); err != nil {
+				return err
+			}
+		}
+		l.diskDB.DeletePathTrieDiffFromBatch(batch, number)
+		delete(l.diffs, number)
+		l.numbers = l.numbers[1:]
+	}
+	return batch.Write()
+}
+
+// revertTo reverts the state on disk to the given state root, which must be
+// the root of one of the kept states.
+func (l *pathDB) revertTo(root common.Hash) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.head == nil || l.head.Root == root {
+		return nil
+	}
+	var reverted []uint64
+	found := false
+	for i := len(l.numbers) - 1; i >= 0 && !found; i-- {
+		diff := l.diffs[l.numbers[i]]
+		if diff.Root == root {
+			found = true
+			break
+		}
+		reverted = append(reverted, l.numbers[i])
+		found = diff.ParentRoot == root
+	}
+	if !found {
+		return fmt.Errorf("state %x is not in the path trie history", root)
+	}
+	for _, number := range reverted {
+		if err := l.revertDiff(number, l.diffs[number], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revertDiff restores the previous values of the nodes written by the given
+// diff, which must be the newest one. An interrupted revert is resumed at
+// startup, and then the history entries already restored are skipped.
+func (l *pathDB) revertDiff(number uint64, diff *database.PathTrieDiff, resume bool) error {
+	batch := l.diskDB.NewBatch(database.StateTrieDB)
+	defer batch.Release()
+
+	l.diskDB.PutPathTrieHeadToBatch(batch, &database.PathTrieHead{Number: l.head.Number, Root: l.head.Root, Pending: number + 1})
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	batch.Reset()
+
+	for _, key := range diff.Keys {
+		owner, path := splitPathKey(key)
+		prev, ok := l.diskDB.ReadPathTrieHistory(number, owner, path)
+		if !ok {
+			if resume {
+				continue
+			}
+			return fmt.Errorf("missing path trie history of block %d at %x", number, key)
+		}
+		l.diskDB.PutPathTrieNodeToBatch(batch, owner, path, prev)
+		l.diskDB.DeletePathTrieHistoryFromBatch(batch, number, owner, path)
+		if _, err := database.WriteBatchesOverThreshold(batch); err != nil {
+			return err
+		}
+	}
+	l.diskDB.DeletePathTrieDiffFromBatch(batch, number)
+	head := &database.PathTrieHead{Number: diff.Parent, Root: diff.ParentRoot}
+	l.diskDB.PutPathTrieHeadToBatch(batch, head)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	l.head = head
+
+	if n := len(l.numbers); n > 0 && l.numbers[n-1] == number {
+		for _, key := range diff.Keys {
+			if numbers := l.index[string(key)]; len(numbers) <= 1 {
+				delete(l.index, string(key))
+			} else {
+				l.index[string(key)] = numbers[:len(numbers)-1]
+			}
+		}
+		delete(l.diffs, number)
+		l.numbers = l.numbers[:n-1]
+	}
+	logger.Info("Reverted a path trie diff", "number", number, "root", diff.Root, "parent", diff.ParentRoot, "nodes", len(diff.Keys))
+	return nil
+}
+
+// Scheme returns the trie node scheme of the database.
+func (db *Database) Scheme() string {
+	if db.path != nil {
+		return PathScheme
+	}
+	return HashScheme
+}
+
+// SetPathHistory sets the number of blocks whose state is kept with the path
+// scheme, in addition to the latest one.
+func (db *Database) SetPathHistory(history uint64) {
+	if db.path == nil {
+		return
+	}
+	db.path.lock.Lock()
+	db.path.history = history
+	db.path.lock.Unlock()
+}
+
+// Recover reverts the state on disk to the given state root with the path
+// scheme. The root must be the latest state or one of the kept states. It is
+// a no-op with the hash scheme.
+func (db *Database) Recover(root common.Hash) error {
+	if db.path == nil {
+		return nil
+	}
+	return db.path.revertTo(root)
+}
+
+// KeepState keeps the state of the given root, which has been committed to
+// memory for a side chain block, so that it can still be written to disk if
+// the block becomes canonical. The state is released once it is written, or
+// once the block falls out of the history. It is a no-op with the hash scheme.
+func (db *Database) KeepState(root common.Hash, blockNum uint64) {
+	if db.path == nil {
+		return
+	}
+	db.lock.Lock()
+	db.pathKept[root] = blockNum
+	db.lock.Unlock()
+}
+
+// pathNode retrieves a trie node from memory, or from the path-based disk layer.
+func (db *Database) pathNode(owner common.Hash, path []byte, hash common.ExtHash) (n node, fromDB bool) {
+	if enc := db.getCachedNode(hash); enc != nil {
+		if dec, err := decodeNode(hash[:], enc); err == nil {
+			return dec, false
+		} else {
+			logger.Error("node from cached trie node fails to be decoded!", "err", err)
+		}
+	}
+	db.lock.RLock()
+	node := db.nodes[hash]
+	db.lock.RUnlock()
+	if node != nil {
+		return node.obj(hash), false
+	}
+
+	enc := db.path.node(owner, path, hash)
+	if enc == nil {
+		return nil, true
+	}
+	db.setCachedNode(hash, enc)
+	recordTrieCacheMiss()
+	return mustDecodeNode(hash[:], enc), true
+}
+
+// pathNodeBlob retrieves an encoded trie node from memory, or from the
+// path-based disk layer.
+func (db *Database) pathNodeBlob(owner common.Hash, path []byte, hash common.ExtHash) ([]byte, error) {
+	if enc := db.getCachedNode(hash); enc != nil {
+		return enc, nil
+	}
+	db.lock.RLock()
+	node := db.nodes[hash]
+	db.lock.RUnlock()
+	if node != nil {
+		return node.rlp(), nil
+	}
+	enc := db.path.node(owner, path, hash)
+	if enc == nil {
+		return nil, &MissingNodeError{NodeHash: hash.Unextend(), Path: path}
+	}
+	db.setCachedNode(hash, enc)
+	recordTrieCacheMiss()
+	return enc, nil
+}
+
+// commitPath writes the nodes of the given state root committed to memory into
+// the path-based disk layer. The state must have been built on top of the state
+// on disk, see Recover. Afterwards, only the nodes of the states kept for side
+// chain blocks stay in memory.
+func (db *Database) commitPath(root common.Hash, report bool, blockNum uint64) error {
+	start := time.Now()
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	// If the state is not in memory, it must be the one on disk already.
+	if _, ok := db.nodes[root.ExtendZero()]; !ok {
+		db.path.lock.RLock()
+		head := db.path.head
+		db.path.lock.RUnlock()
+		if head == nil || head.Root != root {
+			return fmt.Errorf("state %x is neither in memory nor on disk", root)
+		}
+		return nil
+	}
+	db.diskDB.WritePreimages(0, db.preimages)
+	numPreimages := len(db.preimages)
+
+	nodes := db.pathNodes(root)
+	if err := db.path.commit(root, blockNum, nodes); err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		db.setCachedNode(n.hash, n.blob)
+	}
+	db.preimages = make(map[common.Hash][]byte)
+	db.preimagesSize = 0
+	db.releasePath(root, blockNum)
+
+	pathCommitTimeGauge.Update(int64(time.Since(start)))
+	localLogger := logger.Info
+	if !report {
+		localLogger = logger.Debug
+	}
+	localLogger("Persisted trie from memory database by path", "blockNum", blockNum, "root", root,
+		"nodes", len(nodes), "time", time.Since(start), "preimages", numPreimages)
+	return nil
+}
+
+// pathNodes returns the nodes of the given state root which are in memory, by
+// owner and path. The positions are taken from the structure of the state, so
+// that the nodes of discarded tries and of other states, which may have been
+// committed at the same positions, are left out. The nodes which are not in
+// memory are on disk already.
+// Note, this method assumes that the database's lock is held!
+func (db *Database) pathNodes(root common.Hash) []pathWrite {
+	var (
+		nodes []pathWrite
+		walk  func(owner common.Hash, path []byte, n node)
+	)
+	walk = func(owner common.Hash, path []byte, n node) {
+		switch n := n.(type) {
+		case hashNode:
+			hash := common.BytesToExtHash(n)
+			cached, ok := db.nodes[hash]
+			if !ok {
+				return
+			}
+			nodes = append(nodes, pathWrite{key: pathKey(owner, path), hash: hash, blob: cached.rlp()})
+			walk(owner, path, cached.obj(hash))
+
+		case *shortNode:
+			key := concat(path, n.Key...)
+			if value, ok := n.Val.(valueNode); ok {
+				if storageRoot, ok := pathStorageRoot(owner, key, value); ok {
+					walk(common.BytesToHash(hexToKeybytes(key)), nil, hashNode(storageRoot.Bytes()))
+				}
+				return
+			}
+			walk(owner, key, n.Val)
+
+		case *fullNode:
+			for i := 0; i < 16; i++ {
+				if n.Children[i] != nil {
+					walk(owner, concat(path, byte(i)), n.Children[i])
+				}
+			}
+		}
+	}
+	walk(common.Hash{}, nil, hashNode(root.Bytes()))
+	return nodes
+}
+
+// pathStorageRoot returns the storage root of the account stored at the given
+// key of the account trie, if the account has a storage trie.
+func pathStorageRoot(owner common.Hash, key []byte, value []byte) (common.ExtHash, bool) {
+	if owner != (common.Hash{}) || !hasTerm(key) {
+		return common.ExtHash{}, false
+	}
+	serializer := account.NewAccountSerializer()
+	if err := rlp.DecodeBytes(value, serializer); err != nil {
+		return common.ExtHash{}, false
+	}
+	pa := account.GetProgramAccount(serializer.GetAccount())
+	if pa == nil || pa.GetStorageRoot().Unextend() == emptyRoot {
+		return common.ExtHash{}, false
+	}
+	return pa.GetStorageRoot(), true
+}
+
+// releasePath releases the memory of the given state root written to disk, and
+// of the kept states which cannot be written anymore as they are out of the
+// history. Only the nodes reachable from the remaining kept states stay.
+// Note, this method assumes that the database's lock is held!
+func (db *Database) releasePath(root common.Hash, blockNum uint64) {
+	db.path.lock.RLock()
+	history := db.path.history
+	db.path.lock.RUnlock()
+
+	for kept, number := range db.pathKept {
+		if kept == root || number+history <= blockNum {
+			delete(db.pathKept, kept)
+		}
+	}
+	if len(db.pathKept) == 0 {
+		db.nodes = map[common.ExtHash]*cachedNode{{}: {}}
+		db.oldest, db.newest = common.ExtHash{}, common.ExtHash{}
+		db.nodesSize = 0
+		return
+	}
+	reachable := map[common.ExtHash]struct{}{{}: {}}
+	for kept := range db.pathKept {
+		db.accumulate(kept.ExtendZero(), reachable)
+	}
+	for hash, node := range db.nodes {
+		if _, ok := reachable[hash]; ok {
+			continue
+		}
+		db.removeNodeInFlushList(hash)
+		delete(db.nodes, hash)
+		db.nodesSize -= common.StorageSize(common.HashLength + int(node.size))
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitPathBlocks writes one state per block on top of the given root, each
+// adding a key and changing a shared one, and returns the state roots.
+func commitPathBlocks(t *testing.T, db *Database, root common.Hash, first, count uint64) []common.Hash {
+	var roots []common.Hash
+	for i := first; i < first+count; i++ {
+		trie, err := NewTrie(root, db, nil)
+		require.NoError(t, err)
+		updateString(trie, fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+		updateString(trie, "shared", fmt.Sprintf("shared%d", i))
+		root, err = trie.Commit(nil)
+		require.NoError(t, err)
+		require.NoError(t, db.Commit(root, false, i))
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// checkPathState checks that the state of the given block is readable, or not.
+func checkPathState(t *testing.T, dbm database.DBManager, root common.Hash, number uint64, readable bool) {
+	trie, err := NewTrie(root, NewDatabase(dbm), nil)
+	if !readable {
+		assert.Error(t, err, "state of block %d", number)
+		return
+	}
+	require.NoError(t, err, "state of block %d", number)
+	assert.Equal(t, fmt.Sprintf("shared%d", number), string(getString(trie, "shared")))
+	for i := uint64(0); i <= number; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), string(getString(trie, fmt.Sprintf("key%d", i))))
+	}
+	assert.Empty(t, getString(trie, fmt.Sprintf("key%d", number+1)))
+}
+
+func TestPathDB_History(t *testing.T) {
+	dbm := database.NewMemoryDBManager()
+	dbm.WriteTrieNodeScheme(PathScheme)
+	db := NewDatabase(dbm)
+	assert.Equal(t, PathScheme, db.Scheme())
+	db.SetPathHistory(2)
+
+	roots := commitPathBlocks(t, db, common.Hash{}, 0, 5)
+
+	// The latest state and the states of the last two blocks are kept
+	for i, root := range roots {
+		checkPathState(t, dbm, root, uint64(i), i >= 2)
+	}
+
+	// Revert the disk state, the reverted states are not readable anymore
+	require.NoError(t, db.Recover(roots[2]))
+	for i, root := range roots {
+		checkPathState(t, dbm, root, uint64(i), i == 2)
+	}
+	assert.Error(t, db.Recover(roots[4]))
+
+	// Write other states on top of the reverted one
+	roots = append(roots[:3], commitPathBlocks(t, db, roots[2], 3, 2)...)
+	for i, root := range roots {
+		checkPathState(t, dbm, root, uint64(i), i >= 2)
+	}
+
+	// Reopen the database, the history is loaded from disk
+	pathDBs.Lock()
+	delete(pathDBs.dbs, dbm)
+	pathDBs.Unlock()
+
+	db = NewDatabase(dbm)
+	db.SetPathHistory(2)
+	for i, root := range roots {
+		checkPathState(t, dbm, root, uint64(i), i >= 2)
+	}
+	require.NoError(t, db.Recover(roots[3]))
+	checkPathState(t, dbm, roots[3], 3, true)
+	checkPathState(t, dbm, roots[4], 4, false)
+}
+
+// TestPathDB_Conflicts checks that a commit only writes the nodes of its own
+// state, when other states committed to memory have nodes at the same paths,
+// and that the kept states stay in memory.
+func TestPathDB_Conflicts(t *testing.T) {
+	dbm := database.NewMemoryDBManager()
+	dbm.WriteTrieNodeScheme(PathScheme)
+	db := NewDatabase(dbm)
+	db.SetPathHistory(2)
+
+	base := commitPathBlocks(t, db, common.Hash{}, 0, 1)[0]
+
+	// Two competing states of block 1 change the same nodes.
+	commitTrie := func(value string) common.Hash {
+		trie, err := NewTrie(base, db, nil)
+		require.NoError(t, err)
+		updateString(trie, "key1", "value1")
+		updateString(trie, "shared", value)
+		root, err := trie.Commit(nil)
+		require.NoError(t, err)
+		return root
+	}
+	canonical, side := commitTrie("shared1"), commitTrie("side")
+	db.KeepState(side, 1)
+
+	require.NoError(t, db.Commit(canonical, false, 1))
+	checkPathState(t, dbm, canonical, 1, true)
+	assert.True(t, db.DoesExistCachedNode(side.ExtendZero()))
+	assert.False(t, db.DoesExistCachedNode(canonical.ExtendZero()))
+
+	// The kept state can be written after reverting the canonical one.
+	require.NoError(t, db.Recover(base))
+	require.NoError(t, db.Commit(side, false, 1))
+	trie, err := NewTrie(side, NewDatabase(dbm), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "side", string(getString(trie, "shared")))
+	assert.Equal(t, "value1", string(getString(trie, "key1")))
+	assert.False(t, db.DoesExistCachedNode(side.ExtendZero()))
+
+	// A state which is neither in memory nor on disk cannot be written.
+	assert.Error(t, db.Commit(canonical, false, 2))
+}
+
+func TestSetupTrieNodeScheme(t *testing.T) {
+	dbm := database.NewMemoryDBManager()
+	scheme, err := SetupTrieNodeScheme(dbm, "")
+	require.NoError(t, err)
+	assert.Equal(t, HashScheme, scheme)
+
+	scheme, err = SetupTrieNodeScheme(dbm, PathScheme)
+	require.NoError(t, err)
+	assert.Equal(t, PathScheme, scheme)
+	assert.Equal(t, PathScheme, dbm.ReadTrieNodeScheme())
+
+	scheme, err = SetupTrieNodeScheme(dbm, "")
+	require.NoError(t, err)
+	assert.Equal(t, PathScheme, scheme)
+
+	_, err = SetupTrieNodeScheme(dbm, HashScheme)
+	assert.Error(t, err)
+	_, err = SetupTrieNodeScheme(dbm, "flat")
+	assert.Error(t, err)
+
+	// The scheme of a database with a genesis block cannot be changed
+	dbm = database.NewMemoryDBManager()
+	dbm.WriteCanonicalHash(common.HexToHash("0x01"), 0)
+	_, err = SetupTrieNodeScheme(dbm, PathScheme)
+	assert.Error(t, err)
+	assert.Empty(t, dbm.ReadTrieNodeScheme())
+}
@@ -24,8 +24,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/database"
 )
@@ -48,6 +50,7 @@ type ProofDBReader interface {
 func (t *Trie) Prove(key []byte, fromLevel uint, proofDB ProofDBWriter) error {
 	// Collect all nodes on the path to key.
 	key = keybytesToHex(key)
+	hexKey := key
 	nodes := []node{}
 	tn := t.root
 	for len(key) > 0 && tn != nil {
@@ -67,7 +70,7 @@ func (t *Trie) Prove(key []byte, fromLevel uint, proofDB ProofDBWriter) error {
 			nodes = append(nodes, n)
 		case hashNode:
 			var err error
-			tn, err = t.resolveHash(n, nil)
+			tn, err = t.resolveHash(n, hexKey[:len(hexKey)-len(key)])
 			if err != nil {
 				logger.Error(fmt.Sprintf("Unhandled trie error: %v", err))
 				return err
@@ -115,10 +118,70 @@ func (t *SecureTrie) Prove(key []byte, fromLevel uint, proofDB database.DBManage
 	return t.trie.Prove(key, fromLevel, proofDB)
 }
 
+// ProofSet is a set of trie nodes collected from the proofs of many keys, which
+// may belong to several tries. A node shared by several proofs is kept once.
+type ProofSet struct {
+	nodes map[common.Hash][]byte
+}
+
+// NewProofSet returns an empty proof set.
+func NewProofSet() *ProofSet {
+	return &ProofSet{nodes: make(map[common.Hash][]byte)}
+}
+
+// NewProofSetFromList returns a proof set of the given encoded trie nodes.
+func NewProofSetFromList(nodes [][]byte) *ProofSet {
+	set := NewProofSet()
+	for _, node := range nodes {
+		set.nodes[crypto.Keccak256Hash(node)] = common.CopyBytes(node)
+	}
+	return set
+}
+
+// WriteMerkleProof adds a node written by Prove to the set.
+func (s *ProofSet) WriteMerkleProof(key, value []byte) {
+	hash := common.BytesToHash(key)
+	if len(key) != common.HashLength {
+		hash = crypto.Keccak256Hash(value)
+	}
+	if _, ok := s.nodes[hash]; !ok {
+		s.nodes[hash] = common.CopyBytes(value)
+	}
+}
+
+// ReadTrieNode returns the node of the given hash, which lets VerifyProof
+// check a proof against the set.
+func (s *ProofSet) ReadTrieNode(hash common.ExtHash) ([]byte, error) {
+	if node, ok := s.nodes[hash.Unextend()]; ok && hash.IsZeroExtended() {
+		return node, nil
+	}
+	return nil, fmt.Errorf("proof node %x missing", hash)
+}
+
+// Len returns the number of nodes in the set.
+func (s *ProofSet) Len() int {
+	return len(s.nodes)
+}
+
+// List returns the nodes of the set, ordered by their hash.
+func (s *ProofSet) List() [][]byte {
+	hashes := make([]common.Hash, 0, len(s.nodes))
+	for hash := range s.nodes {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+
+	nodes := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		nodes[i] = s.nodes[hash]
+	}
+	return nodes
+}
+
 // VerifyProof checks merkle proofs. The given proof must contain the value for
 // key in a trie with the given root hash. VerifyProof returns an error if the
 // proof contains invalid trie nodes or the wrong value.
-func VerifyProof(rootHash common.Hash, key []byte, proofDB database.DBManager) (value []byte, err error, nodes int) {
+func VerifyProof(rootHash common.Hash, key []byte, proofDB ProofDBReader) (value []byte, err error, nodes int) {
 	key = keybytesToHex(key)
 	wantHash := rootHash
 	for i := 0; ; i++ {
@@ -45,6 +45,21 @@ type TrieOpts struct {
 	// will schedule obsolete nodes to be pruned when the given block number becomes obsolete.
 	// This option is only viable when the pruning is enabled on database.
 	PruningBlockNumber uint64
+
+	// Owner is the hash of the account owning a storage trie, and zero for the
+	// account trie. It is required to read and write storage tries with the
+	// path-based trie node scheme.
+	Owner common.Hash
+
+	// If Witness is set, every trie node resolved from the database is recorded
+	// in it, so that the accessed part of the trie can be served without the
+	// database.
+	Witness NodeRecorder
+}
+
+// NodeRecorder records the encoded trie nodes read by a trie.
+type NodeRecorder interface {
+	AddTrieNode(hash common.ExtHash, enc []byte)
 }
 
 // LeafCallback is a callback type invoked when a trie operation reaches a leaf
@@ -235,7 +250,12 @@ func (t *Trie) tryGetNode(origNode node, path []byte, pos int) (item []byte, new
 		if hash == nil {
 			return nil, origNode, 0, errors.New("non-consensus node")
 		}
-		blob, err := t.db.Node(common.BytesToExtHash(hash))
+		var blob []byte
+		if t.db.path != nil {
+			blob, err = t.db.pathNodeBlob(t.Owner, path[:pos], common.BytesToExtHash(hash))
+		} else {
+			blob, err = t.db.Node(common.BytesToExtHash(hash))
+		}
 		return blob, origNode, 1, err
 	}
 	// Path still needs to be traversed, descend into children
@@ -543,7 +563,18 @@ func (t *Trie) resolve(n node, prefix []byte) (node, error) {
 
 func (t *Trie) resolveHash(n hashNode, prefix []byte) (node, error) {
 	hash := common.BytesToExtHash(n)
-	node, fromDB := t.db.node(hash)
+	if t.Witness != nil {
+		return t.resolveAndRecord(hash, prefix)
+	}
+	var (
+		node   node
+		fromDB bool
+	)
+	if t.db.path != nil {
+		node, fromDB = t.db.pathNode(t.Owner, prefix, hash)
+	} else {
+		node, fromDB = t.db.node(hash)
+	}
 	if t.Prefetching && fromDB {
 		memcacheCleanPrefetchMissMeter.Mark(1)
 	}
@@ -553,6 +584,25 @@ func (t *Trie) resolveHash(n hashNode, prefix []byte) (node, error) {
 	return nil, &MissingNodeError{NodeHash: hash.Unextend(), Path: prefix}
 }
 
+// resolveAndRecord resolves a node from its encoding, which is recorded in the
+// witness of the trie.
+func (t *Trie) resolveAndRecord(hash common.ExtHash, prefix []byte) (node, error) {
+	var (
+		enc []byte
+		err error
+	)
+	if t.db.path != nil {
+		enc, err = t.db.pathNodeBlob(t.Owner, prefix, hash)
+	} else {
+		enc, err = t.db.Node(hash)
+	}
+	if err != nil || enc == nil {
+		return nil, &MissingNodeError{NodeHash: hash.Unextend(), Path: prefix}
+	}
+	t.Witness.AddTrieNode(hash, enc)
+	return mustDecodeNode(hash[:], enc), nil
+}
+
 // Hash returns the root hash of the trie. It does not write to the
 // database and can be used even if the trie doesn't have one.
 func (t *Trie) Hash() common.Hash {
@@ -144,6 +144,16 @@ func generateDefaultTx(sender *TestAccountType, recipient *TestAccountType, txTy
 		return nil, nil, err
 	}
 
+	// An authorization of a new account for set code Txs
+	authKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	auth, err := types.SignSetCode(authKey, types.SetCodeAuthorization{ChainID: big.NewInt(1), Address: recipient.Addr})
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// generate a legacy tx
 	if txType == types.TxTypeLegacyTransaction {
 		tx := types.NewTransaction(sender.Nonce, recipient.Addr, amount, gasLimit, gasPrice, []byte{})
@@ -349,6 +359,27 @@ func generateDefaultTx(sender *TestAccountType, recipient *TestAccountType, txTy
 		values[types.TxValueKeyChainID] = big.NewInt(1)
 		values[types.TxValueKeyData] = dataCode
 		values[types.TxValueKeyAccessList] = types.AccessList{}
+	case types.TxTypeEthereumSetCode:
+		values[types.TxValueKeyNonce] = sender.Nonce
+		values[types.TxValueKeyTo] = &recipient.Addr
+		values[types.TxValueKeyAmount] = amount
+		values[types.TxValueKeyGasLimit] = gasLimit
+		values[types.TxValueKeyGasFeeCap] = gasFeeCap
+		values[types.TxValueKeyGasTipCap] = gasTipCap
+		values[types.TxValueKeyChainID] = big.NewInt(1)
+		values[types.TxValueKeyData] = dataCode
+		values[types.TxValueKeyAccessList] = types.AccessList{}
+		values[types.TxValueKeyAuthorizationList] = []types.SetCodeAuthorization{auth}
+	case types.TxTypeFeeDelegatedSetCode:
+		values[types.TxValueKeyNonce] = sender.Nonce
+		values[types.TxValueKeyFrom] = sender.Addr
+		values[types.TxValueKeyTo] = recipient.Addr
+		values[types.TxValueKeyAmount] = amount
+		values[types.TxValueKeyGasLimit] = gasLimit
+		values[types.TxValueKeyGasPrice] = gasPrice
+		values[types.TxValueKeyData] = dataMemo
+		values[types.TxValueKeyAuthorizationList] = []types.SetCodeAuthorization{auth}
+		values[types.TxValueKeyFeePayer] = recipient.Addr
 	}
 
 	tx, err := types.NewTransactionWithMap(txType, values)
@@ -425,6 +456,7 @@ func TestDefaultTxsWithDefaultAccountKey(t *testing.T) {
 	bcdata.bc.Config().IstanbulCompatibleBlock = big.NewInt(0)
 	bcdata.bc.Config().LondonCompatibleBlock = big.NewInt(0)
 	bcdata.bc.Config().EthTxTypeCompatibleBlock = big.NewInt(0)
+	bcdata.bc.Config().PragueCompatibleBlock = big.NewInt(0)
 	prof.Profile("main_init_blockchain", time.Now().Sub(start))
 	defer bcdata.Shutdown()
 
@@ -1820,6 +1852,7 @@ func TestRoleBasedKeySendTx(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	bcdata.bc.Config().PragueCompatibleBlock = big.NewInt(0)
 	prof.Profile("main_init_blockchain", time.Now().Sub(start))
 	defer bcdata.Shutdown()
 
@@ -18,6 +18,7 @@ package tests
 
 import (
 	"crypto/ecdsa"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -26,13 +27,17 @@ import (
 	"time"
 
 	"github.com/klaytn/klaytn/accounts/abi"
+	"github.com/klaytn/klaytn/blockchain/state"
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/blockchain/vm/runtime"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/compiler"
 	"github.com/klaytn/klaytn/common/profile"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -2222,3 +2227,90 @@ func BenchmarkEvmOp(t *testing.B) {
 		prof.PrintProfileInfo()
 	}
 }
+
+// newEvmBenchmarkConfig returns a runtime config of a state with the code at the address.
+func newEvmBenchmarkConfig(b *testing.B, address common.Address, code []byte, disableSuperinstructions bool) *runtime.Config {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	require.NoError(b, err)
+	statedb.SetCode(address, code)
+	return &runtime.Config{
+		State:    statedb,
+		GasLimit: 10000000,
+		EVMConfig: vm.Config{
+			ComputationCostLimit:     params.OpcodeComputationCostLimitInfinite,
+			DisableSuperinstructions: disableSuperinstructions,
+		},
+	}
+}
+
+// BenchmarkEvmSuperinstructions compares a loop full of the fused opcode
+// sequences executed with and without superinstructions.
+func BenchmarkEvmSuperinstructions(b *testing.B) {
+	address := common.HexToAddress("0xaaaa")
+	code := []byte{
+		byte(vm.PUSH2), 0xff, 0xff, // counter
+		byte(vm.JUMPDEST),
+		byte(vm.PUSH1), 8, byte(vm.JUMP),
+		byte(vm.INVALID),
+		byte(vm.JUMPDEST),
+		byte(vm.PUSH1), 1, byte(vm.SWAP1), byte(vm.SUB),
+		byte(vm.DUP1), byte(vm.DUP1), byte(vm.SWAP1), byte(vm.POP),
+		byte(vm.SWAP1), byte(vm.POP),
+		byte(vm.DUP1), byte(vm.PUSH1), 3, byte(vm.JUMPI),
+		byte(vm.STOP),
+	}
+	for _, bm := range []struct {
+		name    string
+		disable bool
+	}{
+		{"superinstructions", false},
+		{"plain", true},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			cfg := newEvmBenchmarkConfig(b, address, code, bm.disable)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := runtime.Call(address, nil, cfg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEvmCodeAnalysis compares calls into a large contract whose code
+// analysis is cached with the calls into a new code every time.
+func BenchmarkEvmCodeAnalysis(b *testing.B) {
+	address := common.HexToAddress("0xaaaa")
+	code := make([]byte, params.MaxCodeSize)
+	for i := 0; i+33 <= len(code); i += 33 {
+		code[i] = byte(vm.PUSH32)
+	}
+	// Jump to the end of the code over the PUSH32s.
+	copy(code, []byte{byte(vm.PUSH2), byte((len(code) - 8) >> 8), byte(len(code) - 8), byte(vm.JUMP)})
+	copy(code[len(code)-8:], []byte{byte(vm.JUMPDEST), byte(vm.STOP)})
+
+	b.Run("cached", func(b *testing.B) {
+		cfg := newEvmBenchmarkConfig(b, address, code, false)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := runtime.Call(address, nil, cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		cfg := newEvmBenchmarkConfig(b, address, code, false)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// Change the code hash by the bytes after STOP.
+			b.StopTimer()
+			binary.BigEndian.PutUint32(code[len(code)-4:], uint32(i))
+			cfg.State.SetCode(address, code)
+			b.StartTimer()
+			if _, _, err := runtime.Call(address, nil, cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -48,6 +48,41 @@ var Forks = map[string]*params.ChainConfig{
 		KoreCompatibleBlock:      new(big.Int),
 		ShanghaiCompatibleBlock:  new(big.Int),
 	},
+	"Cancun": {
+		ChainID:                  big.NewInt(1),
+		IstanbulCompatibleBlock:  new(big.Int),
+		LondonCompatibleBlock:    new(big.Int),
+		EthTxTypeCompatibleBlock: new(big.Int),
+		MagmaCompatibleBlock:     new(big.Int),
+		KoreCompatibleBlock:      new(big.Int),
+		ShanghaiCompatibleBlock:  new(big.Int),
+		CancunCompatibleBlock:    new(big.Int),
+	},
+	"Prague": {
+		ChainID:                  big.NewInt(1),
+		IstanbulCompatibleBlock:  new(big.Int),
+		LondonCompatibleBlock:    new(big.Int),
+		EthTxTypeCompatibleBlock: new(big.Int),
+		MagmaCompatibleBlock:     new(big.Int),
+		KoreCompatibleBlock:      new(big.Int),
+		ShanghaiCompatibleBlock:  new(big.Int),
+		CancunCompatibleBlock:    new(big.Int),
+		DragonCompatibleBlock:    new(big.Int),
+		PragueCompatibleBlock:    new(big.Int),
+	},
+	"Osaka": {
+		ChainID:                  big.NewInt(1),
+		IstanbulCompatibleBlock:  new(big.Int),
+		LondonCompatibleBlock:    new(big.Int),
+		EthTxTypeCompatibleBlock: new(big.Int),
+		MagmaCompatibleBlock:     new(big.Int),
+		KoreCompatibleBlock:      new(big.Int),
+		ShanghaiCompatibleBlock:  new(big.Int),
+		CancunCompatibleBlock:    new(big.Int),
+		DragonCompatibleBlock:    new(big.Int),
+		PragueCompatibleBlock:    new(big.Int),
+		OsakaCompatibleBlock:     new(big.Int),
+	},
 }
 
 // UnsupportedForkError is returned when a test requests a fork that isn't implemented.
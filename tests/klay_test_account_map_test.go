@@ -165,6 +165,13 @@ func (a *AccountMap) Update(txs types.Transactions, signer types.Signer, picker
 		if tx.Type() == types.TxTypeSmartContractDeploy || tx.Type() == types.TxTypeFeeDelegatedSmartContractDeploy || tx.Type() == types.TxTypeFeeDelegatedSmartContractDeployWithRatio {
 			a.IncNonce(*to)
 		}
+
+		// TODO-Klaytn: This assumes that all authorizations of a set code transaction are valid.
+		for _, auth := range tx.AuthorizationList() {
+			if authority, err := auth.Authority(); err == nil {
+				a.IncNonce(authority)
+			}
+		}
 	}
 
 	return nil
@@ -275,7 +275,9 @@ func (bcdata *BCData) GenABlockWithTxPoolWithoutAccountMap(txPool *blockchain.Tx
 	}
 
 	task := work.NewTask(bcdata.bc.Config(), signer, stateDB, header)
-	task.ApplyTransactions(pooltxs, bcdata.bc, *bcdata.rewardBase)
+	if _, err := task.ApplyTransactions(pooltxs, bcdata.bc, *bcdata.rewardBase); err != nil {
+		return err
+	}
 	newtxs := task.Transactions()
 	receipts := task.Receipts()
 
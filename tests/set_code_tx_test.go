@@ -0,0 +1,259 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/profile"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/klaytn/klaytn/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSetCodeTransaction tests that set code transactions of EIP-7702 delegate
+// the authorities to the authorized addresses after the prague fork.
+func TestSetCodeTransaction(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
+	prof := profile.NewProfiler()
+
+	// Initialize blockchain
+	start := time.Now()
+	bcdata, err := NewBCData(6, 4)
+	assert.Equal(t, nil, err)
+	config := bcdata.bc.Config()
+	config.IstanbulCompatibleBlock = big.NewInt(0)
+	config.LondonCompatibleBlock = big.NewInt(0)
+	config.EthTxTypeCompatibleBlock = big.NewInt(0)
+	config.KoreCompatibleBlock = big.NewInt(0)
+	config.ShanghaiCompatibleBlock = big.NewInt(0)
+	config.CancunCompatibleBlock = big.NewInt(0)
+	config.PragueCompatibleBlock = big.NewInt(0)
+	prof.Profile("main_init_blockchain", time.Now().Sub(start))
+
+	defer bcdata.Shutdown()
+
+	// Initialize address-balance map for verification
+	start = time.Now()
+	accountMap := NewAccountMap()
+	if err := accountMap.Initialize(bcdata); err != nil {
+		t.Fatal(err)
+	}
+	prof.Profile("main_init_accountMap", time.Now().Sub(start))
+
+	// reservoir account
+	reservoir := &TestAccountType{
+		Addr:  *bcdata.addrs[0],
+		Keys:  []*ecdsa.PrivateKey{bcdata.privKeys[0]},
+		Nonce: uint64(0),
+	}
+	feePayer := &TestAccountType{
+		Addr:  *bcdata.addrs[1],
+		Keys:  []*ecdsa.PrivateKey{bcdata.privKeys[1]},
+		Nonce: uint64(0),
+	}
+
+	authority, err := createAnonymousAccount(getRandomPrivateKeyString(t))
+	assert.Equal(t, nil, err)
+	fdAuthority, err := createAnonymousAccount(getRandomPrivateKeyString(t))
+	assert.Equal(t, nil, err)
+
+	// The delegated address has no code, so that a call to the authorities consumes no gas.
+	delegated := common.HexToAddress("0x7702")
+	signer := types.LatestSignerForChainID(config.ChainID)
+	gasPrice := new(big.Int).SetUint64(config.UnitPrice)
+
+	signAuth := func(account *TestAccountType, addr common.Address) []types.SetCodeAuthorization {
+		auth, err := types.SignSetCode(account.Keys[0], types.SetCodeAuthorization{
+			ChainID: config.ChainID,
+			Address: addr,
+			Nonce:   account.Nonce,
+		})
+		assert.Equal(t, nil, err)
+		return []types.SetCodeAuthorization{auth}
+	}
+
+	// 1. Set the code of the authority by TxTypeEthereumSetCode.
+	{
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:             reservoir.GetNonce(),
+			types.TxValueKeyTo:                &authority.Addr,
+			types.TxValueKeyAmount:            common.Big0,
+			types.TxValueKeyData:              []byte{},
+			types.TxValueKeyGasLimit:          gasLimit,
+			types.TxValueKeyGasFeeCap:         gasPrice,
+			types.TxValueKeyGasTipCap:         gasPrice,
+			types.TxValueKeyAccessList:        types.AccessList{},
+			types.TxValueKeyAuthorizationList: signAuth(authority, delegated),
+			types.TxValueKeyChainID:           config.ChainID,
+		}
+		tx, err := types.NewTransactionWithMap(types.TxTypeEthereumSetCode, values)
+		assert.Equal(t, nil, err)
+
+		err = tx.SignWithKeys(signer, reservoir.Keys)
+		assert.Equal(t, nil, err)
+
+		if err := bcdata.GenABlockWithTransactions(accountMap, types.Transactions{tx}, prof); err != nil {
+			t.Fatal(err)
+		}
+		reservoir.AddNonce()
+		authority.AddNonce()
+	}
+
+	// 2. Set the code of another authority by TxTypeFeeDelegatedSetCode.
+	{
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:             reservoir.GetNonce(),
+			types.TxValueKeyFrom:              reservoir.Addr,
+			types.TxValueKeyTo:                fdAuthority.Addr,
+			types.TxValueKeyAmount:            common.Big0,
+			types.TxValueKeyData:              []byte{},
+			types.TxValueKeyGasLimit:          gasLimit,
+			types.TxValueKeyGasPrice:          gasPrice,
+			types.TxValueKeyAuthorizationList: signAuth(fdAuthority, delegated),
+			types.TxValueKeyFeePayer:          feePayer.Addr,
+		}
+		tx, err := types.NewTransactionWithMap(types.TxTypeFeeDelegatedSetCode, values)
+		assert.Equal(t, nil, err)
+
+		err = tx.SignWithKeys(signer, reservoir.Keys)
+		assert.Equal(t, nil, err)
+
+		err = tx.SignFeePayerWithKeys(signer, feePayer.Keys)
+		assert.Equal(t, nil, err)
+
+		if err := bcdata.GenABlockWithTransactions(accountMap, types.Transactions{tx}, prof); err != nil {
+			t.Fatal(err)
+		}
+		reservoir.AddNonce()
+		fdAuthority.AddNonce()
+	}
+
+	statedb, err := bcdata.bc.State()
+	assert.Equal(t, nil, err)
+
+	for _, account := range []*TestAccountType{authority, fdAuthority} {
+		assert.Equal(t, types.AddressToDelegation(delegated), statedb.GetCode(account.Addr))
+		assert.Equal(t, uint64(1), statedb.GetNonce(account.Addr))
+		assert.True(t, statedb.GetKey(account.Addr).Type().IsLegacyAccountKey())
+	}
+
+	// 3. A delegated authority is a program account, so Klaytn value transfer types are not allowed to it.
+	{
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:    reservoir.GetNonce(),
+			types.TxValueKeyFrom:     reservoir.Addr,
+			types.TxValueKeyTo:       authority.Addr,
+			types.TxValueKeyAmount:   big.NewInt(1),
+			types.TxValueKeyGasLimit: gasLimit,
+			types.TxValueKeyGasPrice: gasPrice,
+		}
+		tx, err := types.NewTransactionWithMap(types.TxTypeValueTransfer, values)
+		assert.Equal(t, nil, err)
+
+		err = tx.Validate(statedb, bcdata.bc.CurrentBlock().NumberU64())
+		assert.Equal(t, kerrors.ErrNotForProgramAccount, err)
+	}
+
+	// 4. A set code transaction is not supported before the prague fork.
+	{
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:             reservoir.GetNonce(),
+			types.TxValueKeyFrom:              reservoir.Addr,
+			types.TxValueKeyTo:                fdAuthority.Addr,
+			types.TxValueKeyAmount:            common.Big0,
+			types.TxValueKeyData:              []byte{},
+			types.TxValueKeyGasLimit:          gasLimit,
+			types.TxValueKeyGasPrice:          gasPrice,
+			types.TxValueKeyAuthorizationList: signAuth(fdAuthority, delegated),
+			types.TxValueKeyFeePayer:          feePayer.Addr,
+		}
+		tx, err := types.NewTransactionWithMap(types.TxTypeFeeDelegatedSetCode, values)
+		assert.Equal(t, nil, err)
+
+		config.PragueCompatibleBlock = big.NewInt(1000)
+		err = tx.Validate(statedb, bcdata.bc.CurrentBlock().NumberU64())
+		assert.Equal(t, types.ErrTxTypeNotSupported, err)
+		config.PragueCompatibleBlock = big.NewInt(0)
+	}
+
+	// 5. Clearing the delegation converts the authority back into an EOA.
+	{
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:             reservoir.GetNonce(),
+			types.TxValueKeyTo:                &authority.Addr,
+			types.TxValueKeyAmount:            common.Big0,
+			types.TxValueKeyData:              []byte{},
+			types.TxValueKeyGasLimit:          gasLimit,
+			types.TxValueKeyGasFeeCap:         gasPrice,
+			types.TxValueKeyGasTipCap:         gasPrice,
+			types.TxValueKeyAccessList:        types.AccessList{},
+			types.TxValueKeyAuthorizationList: signAuth(authority, common.Address{}),
+			types.TxValueKeyChainID:           config.ChainID,
+		}
+		tx, err := types.NewTransactionWithMap(types.TxTypeEthereumSetCode, values)
+		assert.Equal(t, nil, err)
+
+		err = tx.SignWithKeys(signer, reservoir.Keys)
+		assert.Equal(t, nil, err)
+
+		if err := bcdata.GenABlockWithTransactions(accountMap, types.Transactions{tx}, prof); err != nil {
+			t.Fatal(err)
+		}
+		reservoir.AddNonce()
+		authority.AddNonce()
+
+		statedb, err := bcdata.bc.State()
+		assert.Equal(t, nil, err)
+		assert.False(t, statedb.IsProgramAccount(authority.Addr))
+		assert.Equal(t, 0, statedb.GetCodeSize(authority.Addr))
+		assert.Equal(t, uint64(2), statedb.GetNonce(authority.Addr))
+		assert.True(t, statedb.GetKey(authority.Addr).Type().IsLegacyAccountKey())
+	}
+
+	// 6. A Klaytn value transfer type is allowed to the authority again.
+	{
+		amount := big.NewInt(1)
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:    reservoir.GetNonce(),
+			types.TxValueKeyFrom:     reservoir.Addr,
+			types.TxValueKeyTo:       authority.Addr,
+			types.TxValueKeyAmount:   amount,
+			types.TxValueKeyGasLimit: gasLimit,
+			types.TxValueKeyGasPrice: gasPrice,
+		}
+		tx, err := types.NewTransactionWithMap(types.TxTypeValueTransfer, values)
+		assert.Equal(t, nil, err)
+
+		err = tx.SignWithKeys(signer, reservoir.Keys)
+		assert.Equal(t, nil, err)
+
+		if err := bcdata.GenABlockWithTransactions(accountMap, types.Transactions{tx}, prof); err != nil {
+			t.Fatal(err)
+		}
+		reservoir.AddNonce()
+
+		statedb, err := bcdata.bc.State()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, amount, statedb.GetBalance(authority.Addr))
+	}
+}
@@ -734,6 +734,49 @@ func genMapForDynamicFeeTransaction(from TestAccount, to TestAccount, gasPrice *
 	return values, intrinsic + gasPayload
 }
 
+func genMapForSetCodeTransaction(from TestAccount, to TestAccount, gasPrice *big.Int, txType types.TxType) (map[types.TxValueKeyType]interface{}, uint64) {
+	intrinsic := getIntrinsicGas(txType)
+	amount := big.NewInt(100000)
+	data := []byte{0x11, 0x22}
+	gasPayload := uint64(len(data)) * params.TxDataGas
+	toAddress := to.GetAddr()
+
+	// The authority is a new account, so the authorization is charged as creating an account.
+	key, _ := crypto.GenerateKey()
+	auth, _ := types.SignSetCode(key, types.SetCodeAuthorization{ChainID: big.NewInt(1), Address: toAddress})
+	authorizationList := []types.SetCodeAuthorization{auth}
+
+	gasPayload += uint64(len(authorizationList)) * params.CallNewAccountGas
+
+	if txType == types.TxTypeEthereumSetCode {
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:             from.GetNonce(),
+			types.TxValueKeyTo:                &toAddress,
+			types.TxValueKeyAmount:            amount,
+			types.TxValueKeyData:              data,
+			types.TxValueKeyGasLimit:          gasLimit,
+			types.TxValueKeyGasFeeCap:         gasPrice,
+			types.TxValueKeyGasTipCap:         gasPrice,
+			types.TxValueKeyAccessList:        types.AccessList{},
+			types.TxValueKeyAuthorizationList: authorizationList,
+			types.TxValueKeyChainID:           big.NewInt(1),
+		}
+		return values, intrinsic + gasPayload
+	}
+
+	values := map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:             from.GetNonce(),
+		types.TxValueKeyFrom:              from.GetAddr(),
+		types.TxValueKeyTo:                toAddress,
+		types.TxValueKeyAmount:            amount,
+		types.TxValueKeyData:              data,
+		types.TxValueKeyGasLimit:          gasLimit,
+		types.TxValueKeyGasPrice:          gasPrice,
+		types.TxValueKeyAuthorizationList: authorizationList,
+	}
+	return values, intrinsic + gasPayload
+}
+
 func genMapForValueTransfer(from TestAccount, to TestAccount, gasPrice *big.Int, txType types.TxType) (map[types.TxValueKeyType]interface{}, uint64) {
 	intrinsic := getIntrinsicGas(txType)
 	amount := big.NewInt(100000)
@@ -1043,6 +1086,10 @@ func getIntrinsicGas(txType types.TxType) uint64 {
 		intrinsic = params.TxGas
 	case types.TxTypeEthereumDynamicFee:
 		intrinsic = params.TxGas
+	case types.TxTypeEthereumSetCode:
+		intrinsic = params.TxGas
+	case types.TxTypeFeeDelegatedSetCode:
+		intrinsic = params.TxGasContractExecution + params.TxGasFeeDelegated
 	case types.TxTypeValueTransfer:
 		intrinsic = params.TxGasValueTransfer
 	case types.TxTypeFeeDelegatedValueTransfer:
@@ -20,6 +20,7 @@ import (
 	params "github.com/klaytn/klaytn/params"
 	rlp "github.com/klaytn/klaytn/rlp"
 	snapshot "github.com/klaytn/klaytn/snapshot"
+	database "github.com/klaytn/klaytn/storage/database"
 )
 
 // MockBlockChain is a mock of BlockChain interface.
@@ -61,6 +62,21 @@ func (mr *MockBlockChainMockRecorder) ApplyTransaction(arg0, arg1, arg2, arg3, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyTransaction", reflect.TypeOf((*MockBlockChain)(nil).ApplyTransaction), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 }
 
+// Backup mocks base method.
+func (m *MockBlockChain) Backup(arg0 string) (*database.BackupManifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backup", arg0)
+	ret0, _ := ret[0].(*database.BackupManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Backup indicates an expected call of Backup.
+func (mr *MockBlockChainMockRecorder) Backup(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backup", reflect.TypeOf((*MockBlockChain)(nil).Backup), arg0)
+}
+
 // BadBlocks mocks base method.
 func (m *MockBlockChain) BadBlocks() ([]blockchain.BadBlockArgs, error) {
 	m.ctrl.T.Helper()
@@ -212,6 +228,21 @@ func (mr *MockBlockChainMockRecorder) Engine() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Engine", reflect.TypeOf((*MockBlockChain)(nil).Engine))
 }
 
+// ExecutionWitness mocks base method.
+func (m *MockBlockChain) ExecutionWitness(arg0 *types.Block) (*state.Witness, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecutionWitness", arg0)
+	ret0, _ := ret[0].(*state.Witness)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecutionWitness indicates an expected call of ExecutionWitness.
+func (mr *MockBlockChainMockRecorder) ExecutionWitness(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecutionWitness", reflect.TypeOf((*MockBlockChain)(nil).ExecutionWitness), arg0)
+}
+
 // Export mocks base method.
 func (m *MockBlockChain) Export(arg0 io.Writer) error {
 	m.ctrl.T.Helper()
@@ -577,6 +608,21 @@ func (mr *MockBlockChainMockRecorder) HasHeader(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasHeader", reflect.TypeOf((*MockBlockChain)(nil).HasHeader), arg0, arg1)
 }
 
+// HistoryStateAt mocks base method.
+func (m *MockBlockChain) HistoryStateAt(arg0 *types.Header) (*state.StateDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HistoryStateAt", arg0)
+	ret0, _ := ret[0].(*state.StateDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HistoryStateAt indicates an expected call of HistoryStateAt.
+func (mr *MockBlockChainMockRecorder) HistoryStateAt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HistoryStateAt", reflect.TypeOf((*MockBlockChain)(nil).HistoryStateAt), arg0)
+}
+
 // InsertChain mocks base method.
 func (m *MockBlockChain) InsertChain(arg0 types.Blocks) (int, error) {
 	m.ctrl.T.Helper()
@@ -274,6 +274,8 @@ type BlockChain interface {
 	PrunableStateAt(root common.Hash, num uint64) (*state.StateDB, error)
 	StateAtWithPersistent(root common.Hash) (*state.StateDB, error)
 	StateAtWithGCLock(root common.Hash) (*state.StateDB, error)
+	HistoryStateAt(header *types.Header) (*state.StateDB, error)
+	ExecutionWitness(block *types.Block) (*state.Witness, error)
 	Export(w io.Writer) error
 	ExportN(w io.Writer, first, last uint64) error
 	Engine() consensus.Engine
@@ -308,6 +310,9 @@ type BlockChain interface {
 	// Save trie node cache to this
 	SaveTrieNodeCacheToDisk() error
 
+	// Backup
+	Backup(dir string) (*database.BackupManifest, error)
+
 	// KES
 	BlockSubscriptionLoop(pool *blockchain.TxPool)
 	CloseBlockSubscriptionLoop()
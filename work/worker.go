@@ -577,7 +577,10 @@ func (self *worker) commitNewWork() {
 	work := self.current
 	if self.nodetype == common.CONSENSUSNODE {
 		txs := types.NewTransactionsByTimeAndNonce(self.current.signer, pending)
-		work.commitTransactions(self.mux, txs, self.chain, self.rewardbase)
+		if err := work.commitTransactions(self.mux, txs, self.chain, self.rewardbase); err != nil {
+			logger.Error("Failed to commit transactions for sealing", "err", err)
+			return
+		}
 		finishedCommitTx := time.Now()
 
 		// Create the new block to seal with the consensus engine
@@ -644,8 +647,11 @@ func (self *worker) updateSnapshot() {
 	self.snapshotState = self.current.state.Copy()
 }
 
-func (env *Task) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByTimeAndNonce, bc BlockChain, rewardbase common.Address) {
-	coalescedLogs := env.ApplyTransactions(txs, bc, rewardbase)
+func (env *Task) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByTimeAndNonce, bc BlockChain, rewardbase common.Address) error {
+	coalescedLogs, err := env.ApplyTransactions(txs, bc, rewardbase)
+	if err != nil {
+		return err
+	}
 
 	if len(coalescedLogs) > 0 || env.tcount > 0 {
 		// make a copy, the state caches the logs and these logs get "upgraded" from pending to mined
@@ -665,11 +671,17 @@ func (env *Task) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 			}
 		}(cpy, env.tcount)
 	}
+	return nil
 }
 
-func (env *Task) ApplyTransactions(txs *types.TransactionsByTimeAndNonce, bc BlockChain, rewardbase common.Address) []*types.Log {
+func (env *Task) ApplyTransactions(txs *types.TransactionsByTimeAndNonce, bc BlockChain, rewardbase common.Address) ([]*types.Log, error) {
 	var coalescedLogs []*types.Log
 
+	// The parent block hash is stored before any transaction as the state processor does.
+	if err := blockchain.ProcessParentBlockHash(env.config, env.header, env.state); err != nil {
+		return nil, err
+	}
+
 	// Limit the execution time of all transactions in a block
 	var abort int32 = 0       // To break the below commitTransaction for loop when timed out
 	chDone := make(chan bool) // To stop the goroutine below when processing txs is completed
@@ -806,7 +818,7 @@ CommitTransactionLoop:
 	// Stop the goroutine that has been handling the timer.
 	chDone <- true
 
-	return coalescedLogs
+	return coalescedLogs, nil
 }
 
 func (env *Task) commitTransaction(tx *types.Transaction, bc BlockChain, rewardbase common.Address, vmConfig *vm.Config) (error, []*types.Log) {